@@ -1,10 +1,91 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Abiggj/structura/config"
 	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/types"
+	"github.com/go-resty/resty/v2"
 )
 
 // DocumentationClient defines the interface for documentation API clients
 type DocumentationClient interface {
-	GenerateDocumentation(file filehandler.FileInfo) (string, error)
-}
\ No newline at end of file
+	GenerateDocumentation(ctx context.Context, file filehandler.FileInfo) (string, error)
+	GenerateProjectOverview(ctx context.Context, files []filehandler.FileInfo) (string, error)
+	Complete(ctx context.Context, prompt string) (string, error)
+	ValidateKey(ctx context.Context) error
+}
+
+// requestAttempts returns how many times a client tries a request: MaxRetries times, or once
+// when the caller retries rate limit and network errors itself
+func requestAttempts(cfg *config.Config) int {
+	if cfg.CallerRetries {
+		return 1
+	}
+	return cfg.MaxRetries
+}
+
+// friendlyError replaces the message of an API error with a user-friendly one based on its type.
+// The error keeps its chain so callers can still tell transient errors apart with errors.Is.
+func friendlyError(err error) error {
+	var apiErr *types.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch {
+	case apiErr.IsInvalidKey:
+		apiErr.Message = "Invalid API key or authentication error. Please check your API key"
+	case apiErr.IsRateLimit:
+		apiErr.Message = "API rate limit exceeded. Please try again later"
+	case apiErr.IsNetworkError:
+		apiErr.Message = "Network error while connecting to API. Please check your internet connection"
+	case apiErr.IsContextTooLong:
+		apiErr.Message = "The file is too long for the model's context window. Try a model with a larger context or a lower token budget"
+	}
+	return err
+}
+
+// modelsEndpoint derives the OpenAI-style models listing endpoint from a chat completions endpoint
+func modelsEndpoint(chatEndpoint string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(chatEndpoint, "/"), "/chat/completions") + "/models"
+}
+
+// validateKey makes a low-cost request to an OpenAI-style models endpoint to check the API key
+func validateKey(ctx context.Context, client *resty.Client, endpoint string) error {
+	resp, err := client.R().
+		SetContext(ctx).
+		Get(endpoint)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return &types.APIError{
+			Message:        fmt.Sprintf("API request failed: %v", err),
+			IsNetworkError: true,
+			Cause:          err,
+		}
+	}
+
+	switch resp.StatusCode() {
+	case 200:
+		return nil
+	case 401, 403:
+		return &types.APIError{
+			StatusCode:   resp.StatusCode(),
+			Message:      "Invalid API key or authentication error. Please check your API key",
+			IsInvalidKey: true,
+			RawResponse:  resp.String(),
+		}
+	default:
+		return &types.APIError{
+			StatusCode:  resp.StatusCode(),
+			Message:     fmt.Sprintf("API key validation failed with status: %d", resp.StatusCode()),
+			RawResponse: resp.String(),
+		}
+	}
+}
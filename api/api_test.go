@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/types"
+)
+
+// newTestClients returns each OpenAI-style client with its chat endpoint pointing at endpoint
+func newTestClients(endpoint string) map[string]DocumentationClient {
+	cfg := newTestConfig(endpoint)
+	cfg.DeepseekAPIKey = "test-key"
+	cfg.GroqAPIKey = "test-key"
+	cfg.CustomAPIKey = "test-key"
+	cfg.DeepseekEndpoint = endpoint
+	cfg.GroqEndpoint = endpoint
+	cfg.CustomEndpoint = endpoint
+	cfg.MaxRetries = 1
+	cfg.APIRateLimit = 0
+	cfg.PerProviderRateLimits = nil
+
+	rateLimiter := NewRateLimiter(0)
+	semaphore := NewSemaphore(1)
+	return map[string]DocumentationClient{
+		"chatgpt":  NewChatGPTClient(cfg, rateLimiter, semaphore),
+		"deepseek": NewDeepseekClient(cfg, rateLimiter, semaphore),
+		"groq":     NewGroqClient(cfg, rateLimiter, semaphore),
+		"custom":   NewCustomClient(cfg, rateLimiter, semaphore),
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		status      int
+		wantErr     bool
+		wantInvalid bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusUnauthorized, true, true},
+		{http.StatusInternalServerError, true, false},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/models" {
+				t.Errorf("request to %s, want /v1/models", r.URL.Path)
+			}
+			if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+				t.Errorf("Authorization header %q, want the API key", got)
+			}
+			w.WriteHeader(tt.status)
+			w.Write([]byte(`{"data": []}`))
+		}))
+
+		for name, client := range newTestClients(server.URL + "/v1/chat/completions") {
+			t.Run(name+"/"+http.StatusText(tt.status), func(t *testing.T) {
+				err := client.ValidateKey(context.Background())
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("ValidateKey() error = %v, want error %v", err, tt.wantErr)
+				}
+				if !tt.wantErr {
+					return
+				}
+
+				var apiErr *types.APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("ValidateKey() error %T is not an *APIError", err)
+				}
+				if apiErr.StatusCode != tt.status {
+					t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+				}
+				if apiErr.IsInvalidKey != tt.wantInvalid {
+					t.Errorf("IsInvalidKey = %v, want %v", apiErr.IsInvalidKey, tt.wantInvalid)
+				}
+			})
+		}
+		server.Close()
+	}
+}
+
+func TestValidateKeyUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	endpoint := server.URL + "/v1/chat/completions"
+	server.Close()
+
+	err := NewChatGPTClient(newTestConfig(endpoint), NewRateLimiter(0), NewSemaphore(1)).ValidateKey(context.Background())
+	if !errors.Is(err, types.ErrNetworkError) {
+		t.Errorf("ValidateKey() against a closed server = %v, want a network error", err)
+	}
+}
+
+// newTestConfig returns a config sending OpenAI requests to endpoint
+func newTestConfig(endpoint string) *config.Config {
+	cfg := config.NewConfig()
+	cfg.OpenAIAPIKey = "test-key"
+	cfg.OpenAIEndpoint = endpoint
+	return cfg
+}
+
+func TestCompleteErrorKinds(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{http.StatusUnauthorized, `{"error": {"message": "Incorrect API key provided"}}`, types.ErrInvalidKey},
+		{http.StatusTooManyRequests, `{"error": {"message": "Rate limit reached"}}`, types.ErrRateLimit},
+		{http.StatusBadRequest, `{"error": {"code": "context_length_exceeded"}}`, types.ErrContextTooLong},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		for name, client := range newTestClients(server.URL + "/v1/chat/completions") {
+			t.Run(name+"/"+http.StatusText(tt.status), func(t *testing.T) {
+				_, err := client.Complete(context.Background(), "Document this")
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("Complete() error = %v, want %v", err, tt.want)
+				}
+
+				var apiErr *types.APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("Complete() error %T is not an *APIError", err)
+				}
+				if apiErr.StatusCode != tt.status {
+					t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.status)
+				}
+			})
+		}
+		server.Close()
+	}
+}
+
+func TestCallerRetriesReturnsTransientErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	clients := newTestClients(server.URL + "/v1/chat/completions")
+	cfg := clients["chatgpt"].(*ChatGPTClient).Config
+	cfg.MaxRetries = 3
+	cfg.CallerRetries = true
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			requests = 0
+			_, err := client.Complete(context.Background(), "Document this")
+			if !errors.Is(err, types.ErrRateLimit) {
+				t.Fatalf("Complete() error = %v, want %v", err, types.ErrRateLimit)
+			}
+			if requests != 1 {
+				t.Errorf("%d requests made, want 1 when the caller retries", requests)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// BatchFileTokens is the largest estimated file size, in tokens, that is documented in a batch.
+// Larger files are documented one per API call.
+const BatchFileTokens = 500
+
+// BatchDocumentationClient is implemented by clients that can document several small files
+// in a single API call
+type BatchDocumentationClient interface {
+	DocumentationClient
+	BatchGenerate(ctx context.Context, files []filehandler.FileInfo, maxBatchTokens int) (map[string]string, error)
+}
+
+// NextBatch returns how many of files, from the first, are documented together in one batch
+// call by client: up to cfg.BatchSize small files of the same package, keyed by path in packages.
+// Directories among them are included but not counted. It returns 0 if the first file is
+// documented on its own.
+func NextBatch(cfg *config.Config, client DocumentationClient, files []filehandler.FileInfo, packages map[string]string) int {
+	if cfg.BatchSize <= 1 {
+		return 0
+	}
+	// Batch prompts ask for the structured style only
+	if cfg.DocumentationStyle != config.StyleStructured {
+		return 0
+	}
+	if _, ok := client.(BatchDocumentationClient); !ok {
+		return 0
+	}
+
+	// Files of one package are batched together so each prompt shares its context
+	n := 0
+	count := 0
+	pkg := ""
+	for ; n < len(files) && count < cfg.BatchSize; n++ {
+		file := files[n]
+		if !file.IsDir {
+			if tokenizer.Estimate(file.Content) >= BatchFileTokens {
+				break
+			}
+			if count > 0 && packages[file.Path] != pkg {
+				break
+			}
+			pkg = packages[file.Path]
+			count++
+		}
+	}
+
+	if count < 2 {
+		return 0
+	}
+	return n
+}
+
+// batchEntry is one element of the JSON array the API returns for a batch
+type batchEntry struct {
+	Path          string `json:"path"`
+	Documentation string `json:"documentation"`
+}
+
+// BuildBatchPrompt builds the prompt asking for documentation of several files at once,
+// returned as a JSON array
+func BuildBatchPrompt(files []filehandler.FileInfo, projectType string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(
+		"Generate structured technical documentation for each of the following %d files in a %s project.\n"+
+			"For each file, begin with a concise summary of its purpose, then document its key types and "+
+			"functions with their parameters, return values and error handling. Format each file's "+
+			"documentation as Markdown.\n\n"+
+			"Respond with only a JSON array containing one object per file, with the string fields "+
+			"\"path\" (the file path exactly as given) and \"documentation\" (the Markdown documentation).\n\n",
+		len(files), projectType))
+
+	for _, file := range files {
+		sb.WriteString(batchFileSection(file))
+	}
+	return sb.String()
+}
+
+// batchFileSection renders one file of a batch prompt
+func batchFileSection(file filehandler.FileInfo) string {
+	return fmt.Sprintf("File path: %s\n\n```%s\n%s\n```\n\n", file.Path, file.Language, file.Content)
+}
+
+// ParseBatchResponse parses the JSON array returned for a batch into a map of file paths to
+// documentation. A Markdown code fence around the array is ignored.
+func ParseBatchResponse(response string) (map[string]string, error) {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "```") {
+		// Drop the opening fence line, with or without a language, and the closing fence
+		if newline := strings.Index(response, "\n"); newline >= 0 {
+			response = response[newline+1:]
+		}
+		response = strings.TrimSuffix(strings.TrimSpace(response), "```")
+	}
+
+	var entries []batchEntry
+	if err := json.Unmarshal([]byte(response), &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse batch response: %w", err)
+	}
+
+	docs := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.Path != "" && entry.Documentation != "" {
+			docs[entry.Path] = entry.Documentation
+		}
+	}
+	return docs, nil
+}
+
+// batchGenerate documents files with as few calls to complete as possible, splitting them into
+// groups whose prompts stay within maxBatchTokens. Files missing from a response are left out
+// of the result, so callers can document them individually.
+func batchGenerate(ctx context.Context, complete func(context.Context, string) (string, error), files []filehandler.FileInfo, projectType string, maxBatchTokens int) (map[string]string, error) {
+	overhead := tokenizer.Estimate(BuildBatchPrompt(nil, projectType))
+
+	var groups [][]filehandler.FileInfo
+	var group []filehandler.FileInfo
+	tokens := overhead
+	for _, file := range files {
+		fileTokens := tokenizer.Estimate(batchFileSection(file))
+		if len(group) > 0 && maxBatchTokens > 0 && tokens+fileTokens > maxBatchTokens {
+			groups = append(groups, group)
+			group = nil
+			tokens = overhead
+		}
+		group = append(group, file)
+		tokens += fileTokens
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+
+	docs := make(map[string]string, len(files))
+	for _, group := range groups {
+		response, err := complete(ctx, BuildBatchPrompt(group, projectType))
+		if err != nil {
+			return docs, err
+		}
+
+		groupDocs, err := ParseBatchResponse(response)
+		if err != nil {
+			return docs, err
+		}
+		for _, file := range group {
+			if doc, ok := groupDocs[file.Path]; ok {
+				docs[file.Path] = doc
+			}
+		}
+	}
+	return docs, nil
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+func TestParseBatchResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "array",
+			response: `[{"path": "a.go", "documentation": "# a.go"}, {"path": "b.go", "documentation": "# b.go"}]`,
+			want:     map[string]string{"a.go": "# a.go", "b.go": "# b.go"},
+		},
+		{
+			name:     "fenced",
+			response: "```json\n[{\"path\": \"a.go\", \"documentation\": \"# a.go\"}]\n```",
+			want:     map[string]string{"a.go": "# a.go"},
+		},
+		{
+			name:     "incomplete entries dropped",
+			response: `[{"path": "a.go", "documentation": ""}, {"documentation": "# b.go"}, {"path": "c.go", "documentation": "# c.go"}]`,
+			want:     map[string]string{"c.go": "# c.go"},
+		},
+		{name: "truncated", response: `[{"path": "a.go", "documentation": "# a.go"`, wantErr: true},
+		{name: "object", response: `{"path": "a.go", "documentation": "# a.go"}`, wantErr: true},
+		{name: "markdown", response: "# a.go\n\nDocumentation without JSON.", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBatchResponse(tt.response)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBatchResponse() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBatchResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchGenerateMalformedGroup(t *testing.T) {
+	files := []filehandler.FileInfo{
+		{Path: "a.go", Language: "go", Content: strings.Repeat("x", 400)},
+		{Path: "b.go", Language: "go", Content: strings.Repeat("y", 400)},
+	}
+
+	// The first group is answered with a valid array, the second with malformed JSON
+	var prompts []string
+	complete := func(_ context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if len(prompts) == 1 {
+			return `[{"path": "a.go", "documentation": "# a.go"}]`, nil
+		}
+		return `[{"path": "b.go", "documentation": `, nil
+	}
+
+	// Leave room for one file per prompt, so each is a group of its own
+	maxTokens := tokenizer.Estimate(BuildBatchPrompt(files[:1], "go")) + 10
+	docs, err := batchGenerate(context.Background(), complete, files, "go", maxTokens)
+	if err == nil {
+		t.Fatal("batchGenerate() with a malformed response returned no error")
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("%d API calls, want one per file", len(prompts))
+	}
+	if want := map[string]string{"a.go": "# a.go"}; !reflect.DeepEqual(docs, want) {
+		t.Errorf("batchGenerate() = %v, want the documentation of the valid group %v", docs, want)
+	}
+}
@@ -0,0 +1,356 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abiggj/structura/awsauth"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/types"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// bedrockMaxTokens is the maximum number of tokens Bedrock models generate per response
+const bedrockMaxTokens = 4096
+
+// BedrockClient is a client for Claude and Titan models on AWS Bedrock. It calls the Bedrock
+// runtime through the AWS SDK, with the configured keys or the default AWS credential chain.
+type BedrockClient struct {
+	Config      *config.Config
+	RateLimiter *RateLimiter
+	Semaphore   *Semaphore
+
+	mu        sync.Mutex
+	awsConfig *aws.Config // Loaded on first use
+}
+
+// BedrockAnthropicRequest is the request body of Claude models on Bedrock
+type BedrockAnthropicRequest struct {
+	AnthropicVersion string                    `json:"anthropic_version"`
+	MaxTokens        int                       `json:"max_tokens"`
+	Messages         []BedrockAnthropicMessage `json:"messages"`
+}
+
+// BedrockAnthropicMessage is a message sent to a Claude model on Bedrock
+type BedrockAnthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// BedrockAnthropicResponse is the response body of Claude models on Bedrock
+type BedrockAnthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// BedrockTitanRequest is the request body of Amazon Titan text models
+type BedrockTitanRequest struct {
+	InputText            string             `json:"inputText"`
+	TextGenerationConfig BedrockTitanConfig `json:"textGenerationConfig"`
+}
+
+// BedrockTitanConfig holds the generation parameters of a Titan request
+type BedrockTitanConfig struct {
+	MaxTokenCount int `json:"maxTokenCount"`
+}
+
+// BedrockTitanResponse is the response body of Amazon Titan text models
+type BedrockTitanResponse struct {
+	Results []struct {
+		OutputText string `json:"outputText"`
+	} `json:"results"`
+}
+
+// NewBedrockClient creates a new AWS Bedrock client
+func NewBedrockClient(cfg *config.Config, rateLimiter *RateLimiter, semaphore *Semaphore) *BedrockClient {
+	return &BedrockClient{
+		Config:      cfg,
+		RateLimiter: rateLimiter,
+		Semaphore:   semaphore,
+	}
+}
+
+// isAnthropicModel reports whether model is a Claude model, as opposed to a Titan model
+func isAnthropicModel(model string) bool {
+	// Cross-region inference profiles prefix the model ID with a region group, e.g. "us."
+	return strings.HasPrefix(model, "anthropic.") || strings.Contains(model, ".anthropic.")
+}
+
+// loadAWSConfig returns the AWS configuration, loading it on first use so credential
+// providers that fetch temporary credentials cache them across requests
+func (bc *BedrockClient) loadAWSConfig(ctx context.Context) (aws.Config, error) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if bc.awsConfig == nil {
+		awsCfg, err := awsauth.LoadConfig(ctx, bc.Config, "")
+		if err != nil {
+			return aws.Config{}, err
+		}
+		bc.awsConfig = &awsCfg
+	}
+	return *bc.awsConfig, nil
+}
+
+// runtimeClient returns a Bedrock runtime client for the AWS configuration. The SDK does not
+// retry requests itself, as makeAPIRequest does.
+func (bc *BedrockClient) runtimeClient(ctx context.Context) (*bedrockruntime.Client, error) {
+	awsCfg, err := bc.loadAWSConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return bedrockruntime.NewFromConfig(awsCfg, func(o *bedrockruntime.Options) {
+		if bc.Config.BedrockEndpoint != "" {
+			o.BaseEndpoint = aws.String(bc.Config.BedrockEndpoint)
+		}
+		o.Retryer = aws.NopRetryer{}
+	}), nil
+}
+
+// responseError returns the HTTP status and error message of an AWS error response. ok is false
+// if err is not one, e.g. because the request never reached AWS.
+func responseError(err error) (status int, message string, ok bool) {
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) {
+		return 0, "", false
+	}
+	message = respErr.Err.Error()
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		message = apiErr.ErrorMessage()
+	}
+	return respErr.HTTPStatusCode(), message, true
+}
+
+// makeAPIRequest invokes model with body, with rate limiting and retries, and returns the
+// response body
+func (bc *BedrockClient) makeAPIRequest(ctx context.Context, model string, body []byte) ([]byte, error) {
+	runtime, err := bc.runtimeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	input := &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(model),
+		Body:        body,
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+	}
+
+	var lastErr error
+
+	attempts := requestAttempts(bc.Config)
+	for attempt := 0; attempt < attempts; attempt++ {
+		// Wait for the shared rate limiter before making the request
+		if err := bc.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Limit the number of requests open at once
+		if err := bc.Semaphore.Acquire(ctx); err != nil {
+			return nil, err
+		}
+
+		// Make the request
+		out, err := runtime.InvokeModel(ctx, input)
+		bc.Semaphore.Release()
+
+		if err == nil {
+			return out.Body, nil
+		}
+
+		// A cancelled context is not a network error and should not be retried
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		status, message, ok := responseError(err)
+		if !ok {
+			var sendErr *smithyhttp.RequestSendError
+			if !errors.As(err, &sendErr) {
+				return nil, err
+			}
+
+			// Handle network errors
+			lastErr = &types.APIError{
+				Message:        fmt.Sprintf("API request failed: %v", err),
+				IsNetworkError: true,
+				Cause:          err,
+			}
+		} else {
+			// Handle API-level errors
+			apiErr := &types.APIError{
+				StatusCode:  status,
+				RawResponse: message,
+			}
+
+			switch status {
+			case 401, 403:
+				apiErr.Message = "AWS authentication failed: check your AWS credentials and their Bedrock permissions"
+				apiErr.IsInvalidKey = true
+				return nil, apiErr
+			case 429:
+				apiErr.Message = "API rate limit exceeded, will retry"
+				apiErr.IsRateLimit = true
+				lastErr = apiErr
+				// Wait longer before retrying rate limit errors
+				if attempt < attempts-1 {
+					if err := sleepContext(ctx, time.Duration(attempt+1)*bc.Config.GetRateLimit(types.APITypeBedrock)); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			default:
+				apiErr.Message = fmt.Sprintf("API request failed with status: %d, body: %s", status, message)
+				apiErr.IsContextTooLong = types.IsContextTooLongResponse(status, message)
+				return nil, apiErr
+			}
+		}
+
+		// Exponential backoff for retries
+		if attempt < attempts-1 {
+			if err := sleepContext(ctx, time.Duration(1<<uint(attempt))*time.Second); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+
+	return nil, fmt.Errorf("API request failed after %d attempts", attempts)
+}
+
+// ValidateKey checks the AWS credentials with STS GetCallerIdentity. Bedrock permissions are
+// only checked by the first request to a model.
+func (bc *BedrockClient) ValidateKey(ctx context.Context) error {
+	awsCfg, err := bc.loadAWSConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	client := sts.NewFromConfig(awsCfg, func(o *sts.Options) { o.Retryer = aws.NopRetryer{} })
+	_, err = client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	status, message, ok := responseError(err)
+	switch {
+	case !ok:
+		return &types.APIError{
+			Message:        fmt.Sprintf("API request failed: %v", err),
+			IsNetworkError: true,
+			Cause:          err,
+		}
+	case status == 401 || status == 403:
+		return &types.APIError{
+			StatusCode:   status,
+			Message:      "Invalid AWS credentials",
+			IsInvalidKey: true,
+			RawResponse:  message,
+		}
+	default:
+		return &types.APIError{
+			StatusCode:  status,
+			Message:     fmt.Sprintf("AWS credential validation failed with status: %d", status),
+			RawResponse: message,
+		}
+	}
+}
+
+// GenerateDocumentation generates documentation for a file using a model on AWS Bedrock
+func (bc *BedrockClient) GenerateDocumentation(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	// Answer from the response cache if this file was documented before
+	return cachedGenerate(ctx, bc.Config, file, bc.Complete)
+}
+
+// GenerateProjectOverview writes an overview of the project from its representative files using a model on AWS Bedrock
+func (bc *BedrockClient) GenerateProjectOverview(ctx context.Context, files []filehandler.FileInfo) (string, error) {
+	return generateProjectOverview(ctx, bc.Complete, files, projectTypeFromConfig(bc.Config), metadataFromConfig(bc.Config))
+}
+
+// Complete sends a prompt to a model on AWS Bedrock and returns the generated text.
+// Claude models use the Anthropic Messages format and all others the Titan text format.
+func (bc *BedrockClient) Complete(ctx context.Context, prompt string) (string, error) {
+	model := bc.Config.GetActiveModel()
+	if model == "" {
+		return "", errors.New("Bedrock model ID is not set")
+	}
+
+	var req interface{}
+	if isAnthropicModel(model) {
+		req = BedrockAnthropicRequest{
+			AnthropicVersion: "bedrock-2023-05-31",
+			MaxTokens:        bedrockMaxTokens,
+			Messages: []BedrockAnthropicMessage{
+				{
+					Role:    "user",
+					Content: prompt,
+				},
+			},
+		}
+	} else {
+		req = BedrockTitanRequest{
+			InputText:            prompt,
+			TextGenerationConfig: BedrockTitanConfig{MaxTokenCount: bedrockMaxTokens},
+		}
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	// Make the request with rate limiting and retries
+	respBody, err := bc.makeAPIRequest(ctx, model, body)
+	if err != nil {
+		return "", friendlyError(err)
+	}
+
+	// Parse the response
+	if isAnthropicModel(model) {
+		var anthropicResp BedrockAnthropicResponse
+		if err := json.Unmarshal(respBody, &anthropicResp); err != nil {
+			return "", fmt.Errorf("failed to parse API response: %w", err)
+		}
+
+		var text strings.Builder
+		for _, block := range anthropicResp.Content {
+			if block.Type == "" || block.Type == "text" {
+				text.WriteString(block.Text)
+			}
+		}
+		if text.Len() == 0 {
+			return "", errors.New("API response contains no text")
+		}
+		recordUsage(bc.Config, prompt, text.String())
+		return text.String(), nil
+	}
+
+	var titanResp BedrockTitanResponse
+	if err := json.Unmarshal(respBody, &titanResp); err != nil {
+		return "", fmt.Errorf("failed to parse API response: %w", err)
+	}
+	if len(titanResp.Results) == 0 {
+		return "", errors.New("API response contains no results")
+	}
+	recordUsage(bc.Config, prompt, titanResp.Results[0].OutputText)
+	return titanResp.Results[0].OutputText, nil
+}
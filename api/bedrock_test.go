@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/types"
+)
+
+// newTestBedrockClient returns a Bedrock client with static keys in us-west-2 sending runtime
+// requests to endpoint, isolated from the AWS files and variables of the machine
+func newTestBedrockClient(t *testing.T, endpoint, model string) *BedrockClient {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	for _, name := range []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+		t.Setenv(name, "")
+	}
+
+	cfg := config.NewConfig()
+	cfg.APIType = types.APITypeBedrock
+	cfg.APIModel = model
+	cfg.AWSRegion = "us-west-2"
+	cfg.AWSAccessKeyID = "AKIDTEST"
+	cfg.AWSSecretAccessKey = "test-secret"
+	cfg.BedrockEndpoint = endpoint
+	cfg.APIRateLimit = 0
+	return NewBedrockClient(cfg, NewRateLimiter(0), NewSemaphore(1))
+}
+
+// checkSignature fails the test unless r is signed with the test keys for service
+func checkSignature(t *testing.T, r *http.Request, service string) {
+	t.Helper()
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(auth, "/us-west-2/"+service+"/aws4_request") {
+		t.Errorf("Authorization header %q is not signed with the configured keys for %s in us-west-2", auth, service)
+	}
+}
+
+func TestBedrockComplete(t *testing.T) {
+	tests := []struct {
+		model    string
+		response string
+	}{
+		{"anthropic.claude-3-haiku-20240307-v1:0", `{"content": [{"type": "text", "text": "Generated docs"}]}`},
+		{"us.anthropic.claude-3-5-sonnet-20240620-v1:0", `{"content": [{"type": "text", "text": "Generated docs"}]}`},
+		{"amazon.titan-text-express-v1", `{"results": [{"outputText": "Generated docs"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if want := "/model/" + tt.model + "/invoke"; r.Method != http.MethodPost || r.URL.Path != want {
+					t.Errorf("request %s %s, want POST %s", r.Method, r.URL.Path, want)
+				}
+				checkSignature(t, r, "bedrock")
+
+				var body map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("request body is not JSON: %v", err)
+				}
+				if isAnthropicModel(tt.model) {
+					if body["anthropic_version"] != "bedrock-2023-05-31" || body["messages"] == nil {
+						t.Errorf("Claude request body %v is not in the Messages format", body)
+					}
+				} else if body["inputText"] != "Document this" {
+					t.Errorf("Titan request body %v does not carry the prompt in inputText", body)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			got, err := newTestBedrockClient(t, server.URL, tt.model).Complete(context.Background(), "Document this")
+			if err != nil {
+				t.Fatalf("Complete() error = %v", err)
+			}
+			if got != "Generated docs" {
+				t.Errorf("Complete() = %q, want the generated text", got)
+			}
+		})
+	}
+}
+
+func TestBedrockCompleteErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		errorType string
+		message   string
+		wantErr   error
+	}{
+		{"access denied", http.StatusForbidden, "AccessDeniedException", "not authorized", types.ErrInvalidKey},
+		{"input too long", http.StatusBadRequest, "ValidationException", "Input is too long for requested model.", types.ErrContextTooLong},
+		{"throttled", http.StatusTooManyRequests, "ThrottlingException", "Too many requests", types.ErrRateLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Amzn-ErrorType", tt.errorType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`{"message": "` + tt.message + `"}`))
+			}))
+			defer server.Close()
+
+			client := newTestBedrockClient(t, server.URL, "anthropic.claude-3-haiku-20240307-v1:0")
+			client.Config.MaxRetries = 2
+			_, err := client.Complete(context.Background(), "Document this")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Complete() error = %v, want %v", err, tt.wantErr)
+			}
+
+			// Only rate limits are retried
+			wantRequests := 1
+			if tt.status == http.StatusTooManyRequests {
+				wantRequests = client.Config.MaxRetries
+			}
+			if requests != wantRequests {
+				t.Errorf("%d requests, want %d", requests, wantRequests)
+			}
+		})
+	}
+}
+
+func TestBedrockValidateKey(t *testing.T) {
+	tests := []struct {
+		status      int
+		wantErr     bool
+		wantInvalid bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusForbidden, true, true},
+		{http.StatusInternalServerError, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				checkSignature(t, r, "sts")
+				if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "GetCallerIdentity" {
+					t.Errorf("request form %v, want the GetCallerIdentity action", r.Form)
+				}
+
+				w.Header().Set("Content-Type", "text/xml")
+				w.WriteHeader(tt.status)
+				if tt.status == http.StatusOK {
+					w.Write([]byte(`<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><GetCallerIdentityResult>` +
+						`<Arn>arn:aws:iam::123456789012:user/test</Arn><UserId>AIDTEST</UserId><Account>123456789012</Account>` +
+						`</GetCallerIdentityResult></GetCallerIdentityResponse>`))
+					return
+				}
+				w.Write([]byte(`<ErrorResponse><Error><Type>Sender</Type><Code>InvalidClientTokenId</Code>` +
+					`<Message>The security token included in the request is invalid.</Message></Error></ErrorResponse>`))
+			}))
+			defer server.Close()
+
+			client := newTestBedrockClient(t, "", "anthropic.claude-3-haiku-20240307-v1:0")
+			t.Setenv("AWS_ENDPOINT_URL_STS", server.URL)
+
+			err := client.ValidateKey(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateKey() error = %v, want error %v", err, tt.wantErr)
+			}
+			var apiErr *types.APIError
+			if tt.wantErr && (!errors.As(err, &apiErr) || apiErr.IsInvalidKey != tt.wantInvalid) {
+				t.Errorf("ValidateKey() error = %#v, want an *APIError with IsInvalidKey %v", err, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestBedrockMissingRegion(t *testing.T) {
+	client := newTestBedrockClient(t, "http://127.0.0.1:1", "anthropic.claude-3-haiku-20240307-v1:0")
+	client.Config.AWSRegion = ""
+
+	if _, err := client.Complete(context.Background(), "Document this"); err == nil || !strings.Contains(err.Error(), "region") {
+		t.Errorf("Complete() without a region = %v, want an error naming the region", err)
+	}
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Abiggj/structura/cache"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// ResponseCache stores API responses on disk as one JSON file per key, so unchanged
+// files are not sent to the API again on the next run
+type ResponseCache struct {
+	Dir string
+	TTL time.Duration // Entries older than this are ignored and removed
+}
+
+// responseCacheEntry is the JSON stored for one response
+type responseCacheEntry struct {
+	Response  string    `json:"response"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DefaultResponseCacheDir returns the directory responses are cached in, e.g.
+// ~/.cache/structura/responses on Linux
+func DefaultResponseCacheDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheDir, "structura", "responses"), nil
+}
+
+// NewResponseCache creates a response cache in dir
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{Dir: dir, TTL: ttl}
+}
+
+// ResponseCacheKey returns the cache key of the response to prompt for a file with content,
+// generated by model
+func ResponseCacheKey(content, model, prompt string) string {
+	sum := sha256.Sum256([]byte(content + "\x00" + model + "\x00" + prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the cached response for key, if there is one that has not expired
+func (rc *ResponseCache) Get(key string) (string, bool) {
+	path := rc.entryPath(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	var entry responseCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return "", false
+	}
+	if time.Since(entry.CreatedAt) > rc.TTL {
+		os.Remove(path)
+		return "", false
+	}
+	return entry.Response, true
+}
+
+// Put stores response under key
+func (rc *ResponseCache) Put(key, response string) error {
+	data, err := json.Marshal(responseCacheEntry{Response: response, CreatedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(rc.Dir, 0700); err != nil {
+		return fmt.Errorf("error creating response cache: %w", err)
+	}
+
+	// Write to a temporary file first so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(rc.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), rc.entryPath(key))
+}
+
+// entryPath returns the path of the file storing key
+func (rc *ResponseCache) entryPath(key string) string {
+	return filepath.Join(rc.Dir, key+".json")
+}
+
+// responseCacheFor returns the response cache configured in cfg, or nil if caching is disabled
+func responseCacheFor(cfg *config.Config) *ResponseCache {
+	if cfg.CacheTTL <= 0 {
+		return nil
+	}
+	dir, err := DefaultResponseCacheDir()
+	if err != nil {
+		return nil
+	}
+	return NewResponseCache(dir, cfg.CacheTTL)
+}
+
+// The in-memory response cache is shared by every client in the process, so it survives
+// the client being recreated, e.g. between runs in watch mode
+var (
+	memoryCacheMu   sync.Mutex
+	memoryCache     *cache.LRU
+	memoryCacheSize int
+	cacheHits       atomic.Int64
+)
+
+// memoryCacheFor returns the in-memory response cache sized as configured in cfg, or nil if
+// it is disabled. Changing the size discards the cached responses.
+func memoryCacheFor(cfg *config.Config) *cache.LRU {
+	memoryCacheMu.Lock()
+	defer memoryCacheMu.Unlock()
+
+	if cfg.InMemoryCacheSize < 1 {
+		return nil
+	}
+	if memoryCache == nil || memoryCacheSize != cfg.InMemoryCacheSize {
+		memoryCache = cache.NewLRU(cfg.InMemoryCacheSize)
+		memoryCacheSize = cfg.InMemoryCacheSize
+	}
+	return memoryCache
+}
+
+// CacheHits returns the number of responses answered from the in-memory or disk response
+// cache instead of the API since the program started
+func CacheHits() int {
+	return int(cacheHits.Load())
+}
+
+// cachedGenerate documents file with complete, answering from the response caches when the
+// same file content was documented with the same model and prompt before. The in-memory cache
+// is checked first, then the disk cache. Concurrent requests for the same key share one API
+// call. Cached responses are not used when regeneration is forced, but fresh ones are still stored.
+// Files filling most of the model's context window, or too long for MaxInputTokens, are
+// documented in chunks, and any chunk the API still rejects as too long is retried with its
+// content halved.
+func cachedGenerate(ctx context.Context, cfg *config.Config, file filehandler.FileInfo, complete func(context.Context, string) (string, error)) (string, error) {
+	return generateInChunks(ctx, file, cfg.GetActiveModel(), projectTypeFromConfig(cfg), metadataFromConfig(cfg), cfg.DocumentationStyle, cfg.MaxInputTokens, func(ctx context.Context, file filehandler.FileInfo) (string, error) {
+		return generateWithinContext(ctx, file, func(ctx context.Context, file filehandler.FileInfo) (string, error) {
+			return cachedGenerateOnce(ctx, cfg, file, complete)
+		})
+	})
+}
+
+// cachedGenerateOnce documents file with complete, answering from the response caches
+func cachedGenerateOnce(ctx context.Context, cfg *config.Config, file filehandler.FileInfo, complete func(context.Context, string) (string, error)) (string, error) {
+	prompt := BuildDocumentationPrompt(file, projectTypeFromConfig(cfg), metadataFromConfig(cfg), cfg.DocumentationStyle)
+	key := ResponseCacheKey(file.Content, cfg.GetActiveModel(), prompt)
+	diskCache := responseCacheFor(cfg)
+	memory := memoryCacheFor(cfg)
+
+	// load answers from the disk cache or the API, storing API responses on disk
+	load := func() (string, error) {
+		if diskCache != nil && !cfg.ForceRegenerate {
+			if response, ok := diskCache.Get(key); ok {
+				cacheHits.Add(1)
+				return response, nil
+			}
+		}
+
+		response, err := complete(ctx, prompt)
+		if err != nil {
+			return "", err
+		}
+
+		// A response that cannot be cached only costs an API call next time
+		if diskCache != nil {
+			diskCache.Put(key, response)
+		}
+		return response, nil
+	}
+
+	if memory == nil {
+		return load()
+	}
+	if cfg.ForceRegenerate {
+		response, err := load()
+		if err == nil {
+			memory.Add(key, response)
+		}
+		return response, err
+	}
+
+	response, hit, err := memory.Do(key, load)
+	if hit {
+		cacheHits.Add(1)
+	}
+	return response, err
+}
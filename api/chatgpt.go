@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,7 +16,8 @@ import (
 type ChatGPTClient struct {
 	Config      *config.Config
 	Client      *resty.Client
-	lastAPICall time.Time
+	RateLimiter *RateLimiter
+	Semaphore   *Semaphore
 }
 
 // ChatGPTMessage represents a message in the ChatGPT API request
@@ -46,7 +48,7 @@ type ChatGPTResponse struct {
 }
 
 // NewChatGPTClient creates a new ChatGPT API client
-func NewChatGPTClient(cfg *config.Config) *ChatGPTClient {
+func NewChatGPTClient(cfg *config.Config, rateLimiter *RateLimiter, semaphore *Semaphore) *ChatGPTClient {
 	client := resty.New()
 	client.SetHeader("Content-Type", "application/json")
 	client.SetHeader("Authorization", fmt.Sprintf("Bearer %s", cfg.OpenAIAPIKey))
@@ -54,33 +56,34 @@ func NewChatGPTClient(cfg *config.Config) *ChatGPTClient {
 	return &ChatGPTClient{
 		Config:      cfg,
 		Client:      client,
-		lastAPICall: time.Now().Add(-cfg.APIRateLimit), // Initialize to allow immediate first call
+		RateLimiter: rateLimiter,
+		Semaphore:   semaphore,
 	}
 }
 
-// enforceRateLimit ensures the API rate limit is respected
-func (cc *ChatGPTClient) enforceRateLimit() {
-	elapsed := time.Since(cc.lastAPICall)
-	if elapsed < cc.Config.APIRateLimit {
-		// Wait for the remaining time
-		time.Sleep(cc.Config.APIRateLimit - elapsed)
-	}
-	cc.lastAPICall = time.Now()
-}
-
 // makeAPIRequest makes an API request with rate limiting and retries
-func (cc *ChatGPTClient) makeAPIRequest(req interface{}) (*resty.Response, error) {
+func (cc *ChatGPTClient) makeAPIRequest(ctx context.Context, req interface{}) (*resty.Response, error) {
 	var lastErr error
 	var resp *resty.Response
 
-	for attempt := 0; attempt < cc.Config.MaxRetries; attempt++ {
-		// Enforce rate limit before making the request
-		cc.enforceRateLimit()
+	attempts := requestAttempts(cc.Config)
+	for attempt := 0; attempt < attempts; attempt++ {
+		// Wait for the shared rate limiter before making the request
+		if err := cc.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Limit the number of requests open at once
+		if err := cc.Semaphore.Acquire(ctx); err != nil {
+			return nil, err
+		}
 
 		// Make the request
 		resp, err := cc.Client.R().
+			SetContext(ctx).
 			SetBody(req).
 			Post(cc.Config.OpenAIEndpoint)
+		cc.Semaphore.Release()
 
 		if err == nil {
 			// Handle successful response
@@ -108,23 +111,36 @@ func (cc *ChatGPTClient) makeAPIRequest(req interface{}) (*resty.Response, error
 				apiErr.IsRateLimit = true
 				lastErr = apiErr
 				// Wait longer before retrying rate limit errors
-				time.Sleep(time.Duration(attempt+1) * cc.Config.APIRateLimit)
+				if attempt < attempts-1 {
+					if err := sleepContext(ctx, time.Duration(attempt+1)*cc.Config.GetRateLimit(types.APITypeChatGPT)); err != nil {
+						return nil, err
+					}
+				}
 				continue
 			default:
 				apiErr.Message = fmt.Sprintf("API request failed with status: %d, body: %s", resp.StatusCode(), resp.String())
+				apiErr.IsContextTooLong = types.IsContextTooLongResponse(resp.StatusCode(), resp.String())
 				return nil, apiErr
 			}
 		} else {
+			// A cancelled context is not a network error and should not be retried
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			
 			// Handle network errors
 			lastErr = &types.APIError{
 				Message: fmt.Sprintf("API request failed: %v", err),
 				IsNetworkError: true,
+				Cause: err,
 			}
 		}
 
 		// Exponential backoff for retries
-		if attempt < cc.Config.MaxRetries-1 {
-			time.Sleep(time.Duration(1<<uint(attempt)) * time.Second)
+		if attempt < attempts-1 {
+			if err := sleepContext(ctx, time.Duration(1<<uint(attempt))*time.Second); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -132,47 +148,40 @@ func (cc *ChatGPTClient) makeAPIRequest(req interface{}) (*resty.Response, error
 		return nil, lastErr
 	}
 
-	return resp, fmt.Errorf("API request failed after %d attempts", cc.Config.MaxRetries)
+	return resp, fmt.Errorf("API request failed after %d attempts", attempts)
+}
+
+// ValidateKey checks the API key by listing the available models
+func (cc *ChatGPTClient) ValidateKey(ctx context.Context) error {
+	return validateKey(ctx, cc.Client, modelsEndpoint(cc.Config.OpenAIEndpoint))
 }
 
 // GenerateDocumentation generates documentation for a file using ChatGPT API
-func (cc *ChatGPTClient) GenerateDocumentation(file filehandler.FileInfo) (string, error) {
+func (cc *ChatGPTClient) GenerateDocumentation(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	// Answer from the response cache if this file was documented before
+	return cachedGenerate(ctx, cc.Config, file, cc.Complete)
+}
+
+// GenerateProjectOverview writes an overview of the project from its representative files using ChatGPT API
+func (cc *ChatGPTClient) GenerateProjectOverview(ctx context.Context, files []filehandler.FileInfo) (string, error) {
+	return generateProjectOverview(ctx, cc.Complete, files, projectTypeFromConfig(cc.Config), metadataFromConfig(cc.Config))
+}
+
+// BatchGenerate documents several small files with as few API calls as possible.
+// Files missing from the response are not in the returned map.
+func (cc *ChatGPTClient) BatchGenerate(ctx context.Context, files []filehandler.FileInfo, maxBatchTokens int) (map[string]string, error) {
+	return batchGenerate(ctx, cc.Complete, files, projectTypeFromConfig(cc.Config), maxBatchTokens)
+}
+
+// Complete sends a prompt to ChatGPT API and returns the generated text
+func (cc *ChatGPTClient) Complete(ctx context.Context, prompt string) (string, error) {
 	if cc.Config.OpenAIAPIKey == "" {
 		return "", errors.New("OpenAI API key is not set")
 	}
 
-	// Get project type from the config
-	projectType := "generic"
-	if fileHandler, ok := cc.Config.FileHandler.(*filehandler.FileHandler); ok && fileHandler != nil {
-		projectType = string(fileHandler.ProjectType)
-	}
-
-	// Prepare the prompt with improved instructions for generating technical documentation
-	prompt := fmt.Sprintf(
-		"Analyze the following %s file in a %s project and generate structured technical documentation that follows these guidelines:\n\n"+
-			"1. Begin with a concise summary of the file's purpose and role within the %s project.\n"+
-			"2. Document all key structures, interfaces, and types with their fields and purpose.\n"+
-			"3. Document each function and method including:\n"+
-			"   - Parameters and their types\n"+
-			"   - Return values and their significance\n"+
-			"   - Error handling approach\n"+
-			"   - Any side effects or state changes\n"+
-			"4. Explain dependencies and interactions with other components.\n"+
-			"5. Include only essential code snippets to illustrate complex logic or patterns.\n"+
-			"6. Format as professional Markdown with appropriate headers, lists, and code blocks.\n\n"+
-			"File path: %s\n\n"+
-			"```%s\n%s\n```",
-		filehandler.GetFileExtension(file.Path),
-		projectType,
-		projectType,
-		file.Path,
-		filehandler.GetFileExtension(file.Path),
-		file.Content,
-	)
-
 	// Create the request
 	req := ChatGPTRequest{
-		Model: cc.Config.APIModel,
+		Model: cc.Config.GetActiveModel(),
 		Messages: []ChatGPTMessage{
 			{
 				Role:    "user",
@@ -182,21 +191,9 @@ func (cc *ChatGPTClient) GenerateDocumentation(file filehandler.FileInfo) (strin
 	}
 
 	// Make the request with rate limiting and retries
-	resp, err := cc.makeAPIRequest(req)
+	resp, err := cc.makeAPIRequest(ctx, req)
 	if err != nil {
-		// Provide more user-friendly errors based on error type
-		if apiErr, ok := err.(*types.APIError); ok {
-			if apiErr.IsInvalidKey {
-				return "", errors.New("Invalid API key or authentication error. Please check your API key")
-			}
-			if apiErr.IsRateLimit {
-				return "", errors.New("API rate limit exceeded. Please try again later")
-			}
-			if apiErr.IsNetworkError {
-				return "", errors.New("Network error while connecting to API. Please check your internet connection")
-			}
-		}
-		return "", err
+		return "", friendlyError(err)
 	}
 
 	// Parse the response
@@ -210,5 +207,7 @@ func (cc *ChatGPTClient) GenerateDocumentation(file filehandler.FileInfo) (strin
 		return "", errors.New("API response contains no choices")
 	}
 
-	return chatGPTResp.Choices[0].Message.Content, nil
+	content := chatGPTResp.Choices[0].Message.Content
+	recordUsage(cc.Config, prompt, content)
+	return content, nil
 }
\ No newline at end of file
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// splitThreshold is the share of a model's context window a file's content may fill before
+// it is documented in chunks
+const splitThreshold = 0.8
+
+// responseReserveTokens is the part of the context window left for the response when a file
+// is split
+const responseReserveTokens = 4096
+
+// chunkDivider separates the documentation of the chunks of a split file
+const chunkDivider = "\n\n---\n\n"
+
+// modelContextWindows holds the context window, in tokens, of the models offered in the TUI
+var modelContextWindows = map[string]int{
+	"deepseek-chat":      64000,
+	"deepseek-coder":     16000,
+	"gpt-3.5-turbo":      16385,
+	"gpt-4":              8192,
+	"gpt-4-turbo":        128000,
+	"gpt-4o":             128000,
+	"gemini-pro":         32760,
+	"gemini-1.5-pro":     1048576,
+	"llama3-70b-8192":    8192,
+	"llama3-8b-8192":     8192,
+	"mixtral-8x7b-32768": 32768,
+
+	"anthropic.claude-3-sonnet-20240229-v1:0": 200000,
+	"anthropic.claude-3-haiku-20240307-v1:0":  200000,
+	"amazon.titan-text-express-v1":            8192,
+}
+
+// definitionStart matches lines starting a top-level function, type or class definition in
+// the supported languages, where a file is preferably split
+var definitionStart = regexp.MustCompile(`^(?:func |type |class |def |async def |fn |pub (?:fn|struct|enum|trait) |impl[ <]|struct |enum |interface |trait |object |fun |module |export |function |async function |(?:public|private|protected|internal|abstract|final|static) )`)
+
+// ContextWindowManager splits file contents too long for a model's context window into
+// chunks that are documented separately
+type ContextWindowManager struct {
+	MaxTokens map[string]int // Context window of each model, in tokens
+}
+
+// NewContextWindowManager creates a manager knowing the context windows of the models
+// offered in the TUI
+func NewContextWindowManager() *ContextWindowManager {
+	maxTokens := make(map[string]int, len(modelContextWindows))
+	for model, tokens := range modelContextWindows {
+		maxTokens[model] = tokens
+	}
+	return &ContextWindowManager{MaxTokens: maxTokens}
+}
+
+// contextWindows is the manager used by the API clients
+var contextWindows = NewContextWindowManager()
+
+// NeedsSplit reports whether content fills more than 80% of model's context window. Content
+// for unknown models, such as those behind a custom endpoint, is never split.
+func (cw *ContextWindowManager) NeedsSplit(content, model string) bool {
+	limit := cw.MaxTokens[model]
+	return limit > 0 && float64(tokenizer.Estimate(content)) > splitThreshold*float64(limit)
+}
+
+// Split divides content into chunks of at most MaxTokens[model] - reserveTokens tokens each,
+// cutting before function, type and class definitions where possible, otherwise between lines.
+// Content that fits, or is for an unknown model, is returned as a single chunk.
+func (cw *ContextWindowManager) Split(content string, model string, reserveTokens int) []string {
+	if cw.MaxTokens[model] <= 0 {
+		return []string{content}
+	}
+	return splitContent(content, cw.MaxTokens[model]-reserveTokens)
+}
+
+// splitContent divides content into chunks of at most budget tokens each, like Split
+func splitContent(content string, budget int) []string {
+	if budget <= 0 || tokenizer.Estimate(content) <= budget {
+		return []string{content}
+	}
+
+	// Estimates of the pieces add up to slightly more than that of the whole, so chunks err
+	// on the small side
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+	add := func(piece string, tokens int) {
+		if currentTokens+tokens > budget && current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+		current.WriteString(piece)
+		currentTokens += tokens
+	}
+
+	for _, block := range definitionBlocks(content) {
+		if tokens := tokenizer.Estimate(block); tokens <= budget {
+			add(block, tokens)
+			continue
+		}
+
+		// A single definition longer than the budget is split between lines, and a single
+		// line longer than the budget wherever it has to be
+		for _, line := range strings.SplitAfter(block, "\n") {
+			for tokenizer.Estimate(line) > budget {
+				head := tokenizer.Truncate(line, budget)
+				add(head, budget)
+				line = line[len(head):]
+			}
+			add(line, tokenizer.Estimate(line))
+		}
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// definitionBlocks splits content before every line starting a definition, keeping each
+// definition's leading comment lines with it
+func definitionBlocks(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	var blocks []string
+	start := 0
+	for i, line := range lines {
+		if i == 0 || !definitionStart.MatchString(line) {
+			continue
+		}
+
+		// Move the cut above the comments directly preceding the definition
+		cut := i
+		for cut > start && isCommentLine(lines[cut-1]) {
+			cut--
+		}
+		if cut > start {
+			blocks = append(blocks, strings.Join(lines[start:cut], ""))
+			start = cut
+		}
+	}
+	return append(blocks, strings.Join(lines[start:], ""))
+}
+
+// isCommentLine reports whether line is a comment, annotation or decorator in one of the
+// supported languages
+func isCommentLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "#", "/*", "*", "--", "@"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// generateInChunks documents file with generate, splitting content that fills most of
+// model's context window, or whose prompt would exceed maxInputTokens, into chunks that are
+// documented separately and joined with a divider. A maxInputTokens of 0 means no limit.
+func generateInChunks(ctx context.Context, file filehandler.FileInfo, model, projectType string, metadata filehandler.ProjectMetadata, style config.DocumentationStyle, maxInputTokens int, generate func(context.Context, filehandler.FileInfo) (string, error)) (string, error) {
+	// Leave room for the instructions around the content, and in the context window for the response
+	empty := file
+	empty.Content = ""
+	overhead := tokenizer.Estimate(BuildDocumentationPrompt(empty, projectType, metadata, style))
+
+	budget := 0
+	if contextWindows.NeedsSplit(file.Content, model) {
+		budget = contextWindows.MaxTokens[model] - overhead - responseReserveTokens
+	}
+	if maxInputTokens > 0 && overhead+tokenizer.Estimate(file.Content) > maxInputTokens {
+		if inputBudget := maxInputTokens - overhead; budget <= 0 || inputBudget < budget {
+			budget = inputBudget
+		}
+	}
+	if budget <= 0 {
+		return generate(ctx, file)
+	}
+
+	chunks := splitContent(file.Content, budget)
+	docs := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		part := file
+		part.Content = chunk
+		if len(chunks) > 1 {
+			part.Path = fmt.Sprintf("%s (part %d of %d)", file.Path, i+1, len(chunks))
+		}
+
+		doc, err := generate(ctx, part)
+		if err != nil {
+			return "", err
+		}
+		docs[i] = strings.TrimSpace(doc)
+	}
+	return strings.Join(docs, chunkDivider), nil
+}
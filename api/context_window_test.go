@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// syntheticGoFile returns Go source of at least size bytes made of commented functions
+func syntheticGoFile(size int) string {
+	var sb strings.Builder
+	sb.WriteString("package synthetic\n\nimport \"fmt\"\n")
+	for i := 0; sb.Len() < size; i++ {
+		fmt.Fprintf(&sb, "\n// Step%d prints the progress of step %d.\nfunc Step%d(total int) {\n", i, i, i)
+		for line := 0; line < 8; line++ {
+			fmt.Fprintf(&sb, "\tfmt.Printf(\"step %d, line %d of %%d\\n\", total)\n", i, line)
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String()
+}
+
+func TestGenerateInChunksMaxInputTokens(t *testing.T) {
+	const maxInputTokens = 4000
+	file := filehandler.FileInfo{Path: "synthetic.go", Language: "Go", Content: syntheticGoFile(50 * 1024)}
+
+	tests := []struct {
+		name           string
+		model          string
+		maxInputTokens int
+		wantLimit      int // Largest prompt allowed
+	}{
+		{"unknown model with limit", "custom-model", maxInputTokens, maxInputTokens},
+		{"known large window with limit", "gpt-4o", maxInputTokens, maxInputTokens},
+		{"known small window without limit", "gpt-4", 0, modelContextWindows["gpt-4"] - responseReserveTokens},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var parts []filehandler.FileInfo
+			doc, err := generateInChunks(context.Background(), file, tt.model, "go", filehandler.ProjectMetadata{}, config.StyleStructured, tt.maxInputTokens,
+				func(_ context.Context, part filehandler.FileInfo) (string, error) {
+					parts = append(parts, part)
+					return fmt.Sprintf("docs of part %d\n", len(parts)), nil
+				})
+			if err != nil {
+				t.Fatalf("generateInChunks() error = %v", err)
+			}
+			if len(parts) < 2 {
+				t.Fatalf("%d KB file documented in %d request(s), want it split", len(file.Content)/1024, len(parts))
+			}
+
+			var joined strings.Builder
+			for i, part := range parts {
+				prompt := BuildDocumentationPrompt(part, "go", filehandler.ProjectMetadata{}, config.StyleStructured)
+				if tokens := tokenizer.Estimate(prompt); tokens > tt.wantLimit {
+					t.Errorf("prompt of part %d has %d tokens, limit %d", i+1, tokens, tt.wantLimit)
+				}
+				if want := fmt.Sprintf("synthetic.go (part %d of %d)", i+1, len(parts)); part.Path != want {
+					t.Errorf("part path %q, want %q", part.Path, want)
+				}
+				// Cuts fall before a function's comment, never inside the function
+				if i > 0 && !strings.HasPrefix(part.Content, "// Step") {
+					t.Errorf("part %d starts with %q, want a function comment", i+1, part.Content[:min(len(part.Content), 40)])
+				}
+				joined.WriteString(part.Content)
+			}
+			if joined.String() != file.Content {
+				t.Error("parts do not add up to the file content")
+			}
+			if got := strings.Count(doc, chunkDivider); got != len(parts)-1 {
+				t.Errorf("documentation has %d dividers, want %d", got, len(parts)-1)
+			}
+		})
+	}
+}
+
+func TestGenerateInChunksFits(t *testing.T) {
+	file := filehandler.FileInfo{Path: "small.go", Language: "Go", Content: syntheticGoFile(2 * 1024)}
+
+	calls := 0
+	_, err := generateInChunks(context.Background(), file, "custom-model", "go", filehandler.ProjectMetadata{}, config.StyleStructured, 4000,
+		func(_ context.Context, part filehandler.FileInfo) (string, error) {
+			calls++
+			if part.Path != file.Path || part.Content != file.Content {
+				t.Errorf("file within the limit was changed to %q", part.Path)
+			}
+			return "docs", nil
+		})
+	if err != nil || calls != 1 {
+		t.Errorf("generateInChunks() = %v after %d calls, want one call", err, calls)
+	}
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/types"
+	"github.com/go-resty/resty/v2"
+	"time"
+)
+
+// CustomClient is a client for custom OpenAI-compatible APIs such as vLLM, LocalAI or LiteLLM
+type CustomClient struct {
+	Config      *config.Config
+	Client      *resty.Client
+	RateLimiter *RateLimiter
+	Semaphore   *Semaphore
+}
+
+// NewCustomClient creates a new client for a custom OpenAI-compatible endpoint
+func NewCustomClient(cfg *config.Config, rateLimiter *RateLimiter, semaphore *Semaphore) *CustomClient {
+	client := resty.New()
+	client.SetHeader("Content-Type", "application/json")
+	// Local servers often run without authentication, so the key is optional
+	if cfg.CustomAPIKey != "" {
+		client.SetHeader("Authorization", fmt.Sprintf("Bearer %s", cfg.CustomAPIKey))
+	}
+
+	return &CustomClient{
+		Config:      cfg,
+		Client:      client,
+		RateLimiter: rateLimiter,
+		Semaphore:   semaphore,
+	}
+}
+
+// makeAPIRequest makes an API request with rate limiting and retries
+func (cc *CustomClient) makeAPIRequest(ctx context.Context, req interface{}) (*resty.Response, error) {
+	var lastErr error
+	var resp *resty.Response
+
+	attempts := requestAttempts(cc.Config)
+	for attempt := 0; attempt < attempts; attempt++ {
+		// Wait for the shared rate limiter before making the request
+		if err := cc.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Limit the number of requests open at once
+		if err := cc.Semaphore.Acquire(ctx); err != nil {
+			return nil, err
+		}
+
+		// Make the request
+		resp, err := cc.Client.R().
+			SetContext(ctx).
+			SetBody(req).
+			Post(cc.Config.CustomEndpoint)
+		cc.Semaphore.Release()
+
+		if err == nil {
+			// Handle successful response
+			if resp.StatusCode() == 200 {
+				return resp, nil
+			}
+
+			// Handle API-level errors
+			apiErr := &types.APIError{
+				StatusCode:  resp.StatusCode(),
+				RawResponse: resp.String(),
+			}
+
+			switch resp.StatusCode() {
+			case 401:
+				apiErr.Message = "API authentication failed: Invalid API key"
+				apiErr.IsInvalidKey = true
+				return nil, apiErr
+			case 403:
+				apiErr.Message = "API access forbidden: API key may be invalid or lacks necessary permissions"
+				apiErr.IsInvalidKey = true
+				return nil, apiErr
+			case 429:
+				apiErr.Message = "API rate limit exceeded, will retry"
+				apiErr.IsRateLimit = true
+				lastErr = apiErr
+				// Wait longer before retrying rate limit errors
+				if attempt < attempts-1 {
+					if err := sleepContext(ctx, time.Duration(attempt+1)*cc.Config.GetRateLimit(types.APITypeCustom)); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			default:
+				apiErr.Message = fmt.Sprintf("API request failed with status: %d, body: %s", resp.StatusCode(), resp.String())
+				apiErr.IsContextTooLong = types.IsContextTooLongResponse(resp.StatusCode(), resp.String())
+				return nil, apiErr
+			}
+		} else {
+			// A cancelled context is not a network error and should not be retried
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			// Handle network errors
+			lastErr = &types.APIError{
+				Message:        fmt.Sprintf("API request failed: %v", err),
+				IsNetworkError: true,
+				Cause:          err,
+			}
+		}
+
+		// Exponential backoff for retries
+		if attempt < attempts-1 {
+			if err := sleepContext(ctx, time.Duration(1<<uint(attempt))*time.Second); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+
+	return resp, fmt.Errorf("API request failed after %d attempts", attempts)
+}
+
+// ValidateKey checks the API key by listing the available models
+func (cc *CustomClient) ValidateKey(ctx context.Context) error {
+	return validateKey(ctx, cc.Client, modelsEndpoint(cc.Config.CustomEndpoint))
+}
+
+// GenerateDocumentation generates documentation for a file using a custom OpenAI-compatible API
+func (cc *CustomClient) GenerateDocumentation(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	// Answer from the response cache if this file was documented before
+	return cachedGenerate(ctx, cc.Config, file, cc.Complete)
+}
+
+// GenerateProjectOverview writes an overview of the project from its representative files using a custom OpenAI-compatible API
+func (cc *CustomClient) GenerateProjectOverview(ctx context.Context, files []filehandler.FileInfo) (string, error) {
+	return generateProjectOverview(ctx, cc.Complete, files, projectTypeFromConfig(cc.Config), metadataFromConfig(cc.Config))
+}
+
+// Complete sends a prompt to a custom OpenAI-compatible API and returns the generated text
+func (cc *CustomClient) Complete(ctx context.Context, prompt string) (string, error) {
+	if cc.Config.CustomEndpoint == "" {
+		return "", errors.New("Custom API endpoint is not set")
+	}
+
+	// Create the request using the OpenAI-compatible format
+	req := ChatGPTRequest{
+		Model: cc.Config.GetActiveModel(),
+		Messages: []ChatGPTMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+	}
+
+	// Make the request with rate limiting and retries
+	resp, err := cc.makeAPIRequest(ctx, req)
+	if err != nil {
+		return "", friendlyError(err)
+	}
+
+	// Parse the response
+	var customResp ChatGPTResponse
+	err = json.Unmarshal(resp.Body(), &customResp)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	if len(customResp.Choices) == 0 {
+		return "", errors.New("API response contains no choices")
+	}
+
+	content := customResp.Choices[0].Message.Content
+	recordUsage(cc.Config, prompt, content)
+	return content, nil
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,7 +16,8 @@ import (
 type DeepseekClient struct {
 	Config      *config.Config
 	Client      *resty.Client
-	lastAPICall time.Time
+	RateLimiter *RateLimiter
+	Semaphore   *Semaphore
 }
 
 // DeepseekMessage represents a message in the DeepSeek API request
@@ -46,7 +48,7 @@ type DeepseekResponse struct {
 }
 
 // NewDeepseekClient creates a new DeepSeek API client
-func NewDeepseekClient(cfg *config.Config) *DeepseekClient {
+func NewDeepseekClient(cfg *config.Config, rateLimiter *RateLimiter, semaphore *Semaphore) *DeepseekClient {
 	client := resty.New()
 	client.SetHeader("Content-Type", "application/json")
 	client.SetHeader("Authorization", fmt.Sprintf("Bearer %s", cfg.DeepseekAPIKey))
@@ -54,33 +56,34 @@ func NewDeepseekClient(cfg *config.Config) *DeepseekClient {
 	return &DeepseekClient{
 		Config:      cfg,
 		Client:      client,
-		lastAPICall: time.Now().Add(-cfg.APIRateLimit), // Initialize to allow immediate first call
+		RateLimiter: rateLimiter,
+		Semaphore:   semaphore,
 	}
 }
 
-// enforceRateLimit ensures the API rate limit is respected
-func (dc *DeepseekClient) enforceRateLimit() {
-	elapsed := time.Since(dc.lastAPICall)
-	if elapsed < dc.Config.APIRateLimit {
-		// Wait for the remaining time
-		time.Sleep(dc.Config.APIRateLimit - elapsed)
-	}
-	dc.lastAPICall = time.Now()
-}
-
 // makeAPIRequest makes an API request with rate limiting and retries
-func (dc *DeepseekClient) makeAPIRequest(req interface{}) (*resty.Response, error) {
+func (dc *DeepseekClient) makeAPIRequest(ctx context.Context, req interface{}) (*resty.Response, error) {
 	var lastErr error
 	var resp *resty.Response
 
-	for attempt := 0; attempt < dc.Config.MaxRetries; attempt++ {
-		// Enforce rate limit before making the request
-		dc.enforceRateLimit()
+	attempts := requestAttempts(dc.Config)
+	for attempt := 0; attempt < attempts; attempt++ {
+		// Wait for the shared rate limiter before making the request
+		if err := dc.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Limit the number of requests open at once
+		if err := dc.Semaphore.Acquire(ctx); err != nil {
+			return nil, err
+		}
 
 		// Make the request
 		resp, err := dc.Client.R().
+			SetContext(ctx).
 			SetBody(req).
 			Post(dc.Config.DeepseekEndpoint)
+		dc.Semaphore.Release()
 
 		if err == nil {
 			// Handle successful response
@@ -108,23 +111,36 @@ func (dc *DeepseekClient) makeAPIRequest(req interface{}) (*resty.Response, erro
 				apiErr.IsRateLimit = true
 				lastErr = apiErr
 				// Wait longer before retrying rate limit errors
-				time.Sleep(time.Duration(attempt+1) * dc.Config.APIRateLimit)
+				if attempt < attempts-1 {
+					if err := sleepContext(ctx, time.Duration(attempt+1)*dc.Config.GetRateLimit(types.APITypeDeepseek)); err != nil {
+						return nil, err
+					}
+				}
 				continue
 			default:
 				apiErr.Message = fmt.Sprintf("API request failed with status: %d, body: %s", resp.StatusCode(), resp.String())
+				apiErr.IsContextTooLong = types.IsContextTooLongResponse(resp.StatusCode(), resp.String())
 				return nil, apiErr
 			}
 		} else {
+			// A cancelled context is not a network error and should not be retried
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			
 			// Handle network errors
 			lastErr = &types.APIError{
 				Message: fmt.Sprintf("API request failed: %v", err),
 				IsNetworkError: true,
+				Cause: err,
 			}
 		}
 
 		// Exponential backoff for retries
-		if attempt < dc.Config.MaxRetries-1 {
-			time.Sleep(time.Duration(1<<uint(attempt)) * time.Second)
+		if attempt < attempts-1 {
+			if err := sleepContext(ctx, time.Duration(1<<uint(attempt))*time.Second); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -132,47 +148,40 @@ func (dc *DeepseekClient) makeAPIRequest(req interface{}) (*resty.Response, erro
 		return nil, lastErr
 	}
 
-	return resp, fmt.Errorf("API request failed after %d attempts", dc.Config.MaxRetries)
+	return resp, fmt.Errorf("API request failed after %d attempts", attempts)
+}
+
+// ValidateKey checks the API key by listing the available models
+func (dc *DeepseekClient) ValidateKey(ctx context.Context) error {
+	return validateKey(ctx, dc.Client, modelsEndpoint(dc.Config.DeepseekEndpoint))
 }
 
 // GenerateDocumentation generates documentation for a file using DeepSeek API
-func (dc *DeepseekClient) GenerateDocumentation(file filehandler.FileInfo) (string, error) {
+func (dc *DeepseekClient) GenerateDocumentation(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	// Answer from the response cache if this file was documented before
+	return cachedGenerate(ctx, dc.Config, file, dc.Complete)
+}
+
+// GenerateProjectOverview writes an overview of the project from its representative files using DeepSeek API
+func (dc *DeepseekClient) GenerateProjectOverview(ctx context.Context, files []filehandler.FileInfo) (string, error) {
+	return generateProjectOverview(ctx, dc.Complete, files, projectTypeFromConfig(dc.Config), metadataFromConfig(dc.Config))
+}
+
+// BatchGenerate documents several small files with as few API calls as possible.
+// Files missing from the response are not in the returned map.
+func (dc *DeepseekClient) BatchGenerate(ctx context.Context, files []filehandler.FileInfo, maxBatchTokens int) (map[string]string, error) {
+	return batchGenerate(ctx, dc.Complete, files, projectTypeFromConfig(dc.Config), maxBatchTokens)
+}
+
+// Complete sends a prompt to DeepSeek API and returns the generated text
+func (dc *DeepseekClient) Complete(ctx context.Context, prompt string) (string, error) {
 	if dc.Config.DeepseekAPIKey == "" {
 		return "", errors.New("DeepSeek API key is not set")
 	}
 
-	// Get project type from the config
-	projectType := "generic"
-	if fileHandler, ok := dc.Config.FileHandler.(*filehandler.FileHandler); ok && fileHandler != nil {
-		projectType = string(fileHandler.ProjectType)
-	}
-
-	// Prepare the prompt with improved instructions for generating technical documentation
-	prompt := fmt.Sprintf(
-		"Analyze the following %s file in a %s project and generate structured technical documentation that follows these guidelines:\n\n"+
-			"1. Begin with a concise summary of the file's purpose and role within the %s project.\n"+
-			"2. Document all key structures, interfaces, and types with their fields and purpose.\n"+
-			"3. Document each function and method including:\n"+
-			"   - Parameters and their types\n"+
-			"   - Return values and their significance\n"+
-			"   - Error handling approach\n"+
-			"   - Any side effects or state changes\n"+
-			"4. Explain dependencies and interactions with other components.\n"+
-			"5. Include only essential code snippets to illustrate complex logic or patterns.\n"+
-			"6. Format as professional Markdown with appropriate headers, lists, and code blocks.\n\n"+
-			"File path: %s\n\n"+
-			"```%s\n%s\n```",
-		filehandler.GetFileExtension(file.Path),
-		projectType,
-		projectType,
-		file.Path,
-		filehandler.GetFileExtension(file.Path),
-		file.Content,
-	)
-
 	// Create the request
 	req := DeepseekRequest{
-		Model: dc.Config.APIModel,
+		Model: dc.Config.GetActiveModel(),
 		Messages: []DeepseekMessage{
 			{
 				Role:    "user",
@@ -182,21 +191,9 @@ func (dc *DeepseekClient) GenerateDocumentation(file filehandler.FileInfo) (stri
 	}
 
 	// Make the request with rate limiting and retries
-	resp, err := dc.makeAPIRequest(req)
+	resp, err := dc.makeAPIRequest(ctx, req)
 	if err != nil {
-		// Provide more user-friendly errors based on error type
-		if apiErr, ok := err.(*types.APIError); ok {
-			if apiErr.IsInvalidKey {
-				return "", errors.New("Invalid API key or authentication error. Please check your API key")
-			}
-			if apiErr.IsRateLimit {
-				return "", errors.New("API rate limit exceeded. Please try again later")
-			}
-			if apiErr.IsNetworkError {
-				return "", errors.New("Network error while connecting to API. Please check your internet connection")
-			}
-		}
-		return "", err
+		return "", friendlyError(err)
 	}
 
 	// Parse the response
@@ -210,5 +207,7 @@ func (dc *DeepseekClient) GenerateDocumentation(file filehandler.FileInfo) (stri
 		return "", errors.New("API response contains no choices")
 	}
 
-	return deepseekResp.Choices[0].Message.Content, nil
+	content := deepseekResp.Choices[0].Message.Content
+	recordUsage(dc.Config, prompt, content)
+	return content, nil
 }
\ No newline at end of file
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// modelInputPrices holds approximate prices in USD per million input tokens
+var modelInputPrices = map[string]float64{
+	"deepseek-chat":      0.27,
+	"deepseek-coder":     0.27,
+	"gpt-3.5-turbo":      0.50,
+	"gpt-4":              30.00,
+	"gpt-4-turbo":        10.00,
+	"gpt-4o":             2.50,
+	"gemini-pro":         0.50,
+	"gemini-1.5-pro":     1.25,
+	"llama3-70b-8192":    0.59,
+	"llama3-8b-8192":     0.05,
+	"mixtral-8x7b-32768": 0.24,
+}
+
+// EstimateCost returns the approximate input cost in USD of sending tokens to model.
+// Unknown models, such as those behind a custom endpoint, are estimated at zero.
+func EstimateCost(model string, tokens int) float64 {
+	return float64(tokens) * modelInputPrices[model] / 1_000_000
+}
+
+// DryRunResult describes a file that would be sent to the API
+type DryRunResult struct {
+	Path      string
+	Ext       string
+	Size      int64
+	EstTokens int
+	EstCost   float64
+}
+
+// DryRunDocumentationClient implements DocumentationClient without making any API calls.
+// It records the prompt size of every file it is asked to document.
+type DryRunDocumentationClient struct {
+	Config *config.Config
+
+	mu      sync.Mutex
+	results []DryRunResult
+}
+
+// NewDryRunDocumentationClient creates a new dry-run client
+func NewDryRunDocumentationClient(cfg *config.Config) *DryRunDocumentationClient {
+	return &DryRunDocumentationClient{
+		Config: cfg,
+	}
+}
+
+// GenerateDocumentation records the estimated cost of documenting file and returns no documentation
+func (dc *DryRunDocumentationClient) GenerateDocumentation(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	prompt := BuildDocumentationPrompt(file, projectTypeFromConfig(dc.Config), metadataFromConfig(dc.Config), dc.Config.DocumentationStyle)
+	tokens := tokenizer.Estimate(prompt)
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.results = append(dc.results, DryRunResult{
+		Path:      file.Path,
+		Ext:       filepath.Ext(file.Path),
+		Size:      file.Size,
+		EstTokens: tokens,
+		EstCost:   EstimateCost(dc.Config.GetActiveModel(), tokens),
+	})
+
+	return "", nil
+}
+
+// GenerateProjectOverview returns an empty overview without calling the API
+func (dc *DryRunDocumentationClient) GenerateProjectOverview(ctx context.Context, files []filehandler.FileInfo) (string, error) {
+	return "", nil
+}
+
+// Complete returns an empty completion without calling the API
+func (dc *DryRunDocumentationClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return "", nil
+}
+
+// ValidateKey always succeeds since no API is used
+func (dc *DryRunDocumentationClient) ValidateKey(ctx context.Context) error {
+	return nil
+}
+
+// Results returns the files recorded so far, in the order they were documented
+func (dc *DryRunDocumentationClient) Results() []DryRunResult {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	return append([]DryRunResult(nil), dc.results...)
+}
+
+// DryRun runs every file through a dry-run client and returns what would be sent to the API
+func DryRun(ctx context.Context, cfg *config.Config, files []filehandler.FileInfo) []DryRunResult {
+	client := NewDryRunDocumentationClient(cfg)
+	for _, file := range files {
+		if !file.IsDir {
+			client.GenerateDocumentation(ctx, file)
+		}
+	}
+	return client.Results()
+}
+
+// DryRunTotal returns the summed size, estimated tokens and estimated cost of results
+func DryRunTotal(results []DryRunResult) DryRunResult {
+	var total DryRunResult
+	for _, result := range results {
+		total.Size += result.Size
+		total.EstTokens += result.EstTokens
+		total.EstCost += result.EstCost
+	}
+	return total
+}
+
+// FormatDryRunTable renders dry-run results as an aligned table with totals.
+// Paths are shown relative to rootDir.
+func FormatDryRunTable(rootDir string, results []DryRunResult) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "PATH\tEXT\tSIZE\tEST_TOKENS\tEST_COST")
+
+	for _, result := range results {
+		path := result.Path
+		if rel, err := filepath.Rel(rootDir, result.Path); err == nil {
+			path = rel
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t$%.4f\n", path, result.Ext, result.Size, result.EstTokens, result.EstCost)
+	}
+
+	total := DryRunTotal(results)
+	fmt.Fprintf(w, "TOTAL (%d files)\t\t%d\t%d\t$%.4f\n", len(results), total.Size, total.EstTokens, total.EstCost)
+	w.Flush()
+
+	return sb.String()
+}
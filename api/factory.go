@@ -1,18 +1,48 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"github.com/Abiggj/structura/config"
 	"github.com/Abiggj/structura/types"
 )
 
-// CreateDocumentationClient creates the appropriate documentation client based on the config
-func CreateDocumentationClient(cfg *config.Config) (DocumentationClient, error) {
+// CreateDocumentationClient creates the appropriate documentation client based on the config.
+// When validateOnCreate is set, the API key is checked before the client is returned.
+func CreateDocumentationClient(cfg *config.Config, validateOnCreate bool) (DocumentationClient, error) {
+	client, err := newDocumentationClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	
+	if validateOnCreate {
+		if err := client.ValidateKey(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	
+	return client, nil
+}
+
+// newDocumentationClient creates the client for the configured API type
+func newDocumentationClient(cfg *config.Config) (DocumentationClient, error) {
+	// A single limiter and semaphore are shared by everything using this client
+	rateLimiter := NewRateLimiter(cfg.GetRateLimit(cfg.APIType))
+	semaphore := NewSemaphore(cfg.MaxConcurrentRequests)
+	
 	switch cfg.APIType {
 	case types.APITypeDeepseek:
-		return NewDeepseekClient(cfg), nil
+		return NewDeepseekClient(cfg, rateLimiter, semaphore), nil
 	case types.APITypeChatGPT:
-		return NewChatGPTClient(cfg), nil
+		return NewChatGPTClient(cfg, rateLimiter, semaphore), nil
+	case types.APITypeGroq:
+		return NewGroqClient(cfg, rateLimiter, semaphore), nil
+	case types.APITypeCustom:
+		return NewCustomClient(cfg, rateLimiter, semaphore), nil
+	case types.APITypeBedrock:
+		return NewBedrockClient(cfg, rateLimiter, semaphore), nil
+	case types.APITypeMock:
+		return NewMockClient(nil), nil
 	case types.APITypeGemini:
 		// Placeholder for future Gemini implementation
 		return nil, fmt.Errorf("Gemini API support coming soon")
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/types"
+	"github.com/go-resty/resty/v2"
+	"time"
+)
+
+// GroqClient is a client for the Groq API
+type GroqClient struct {
+	Config      *config.Config
+	Client      *resty.Client
+	RateLimiter *RateLimiter
+	Semaphore   *Semaphore
+}
+
+// NewGroqClient creates a new Groq API client
+func NewGroqClient(cfg *config.Config, rateLimiter *RateLimiter, semaphore *Semaphore) *GroqClient {
+	client := resty.New()
+	client.SetHeader("Content-Type", "application/json")
+	client.SetHeader("Authorization", fmt.Sprintf("Bearer %s", cfg.GroqAPIKey))
+
+	return &GroqClient{
+		Config:      cfg,
+		Client:      client,
+		RateLimiter: rateLimiter,
+		Semaphore:   semaphore,
+	}
+}
+
+// makeAPIRequest makes an API request with rate limiting and retries
+func (gc *GroqClient) makeAPIRequest(ctx context.Context, req interface{}) (*resty.Response, error) {
+	var lastErr error
+	var resp *resty.Response
+
+	attempts := requestAttempts(gc.Config)
+	for attempt := 0; attempt < attempts; attempt++ {
+		// Wait for the shared rate limiter before making the request
+		if err := gc.RateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Limit the number of requests open at once
+		if err := gc.Semaphore.Acquire(ctx); err != nil {
+			return nil, err
+		}
+
+		// Make the request
+		resp, err := gc.Client.R().
+			SetContext(ctx).
+			SetBody(req).
+			Post(gc.Config.GroqEndpoint)
+		gc.Semaphore.Release()
+
+		if err == nil {
+			// Handle successful response
+			if resp.StatusCode() == 200 {
+				return resp, nil
+			}
+
+			// Handle API-level errors
+			apiErr := &types.APIError{
+				StatusCode:  resp.StatusCode(),
+				RawResponse: resp.String(),
+			}
+
+			switch resp.StatusCode() {
+			case 401:
+				apiErr.Message = "API authentication failed: Invalid API key"
+				apiErr.IsInvalidKey = true
+				return nil, apiErr
+			case 403:
+				apiErr.Message = "API access forbidden: API key may be invalid or lacks necessary permissions"
+				apiErr.IsInvalidKey = true
+				return nil, apiErr
+			case 429:
+				apiErr.Message = "API rate limit exceeded, will retry"
+				apiErr.IsRateLimit = true
+				lastErr = apiErr
+				// Wait longer before retrying rate limit errors
+				if attempt < attempts-1 {
+					if err := sleepContext(ctx, time.Duration(attempt+1)*gc.Config.GetRateLimit(types.APITypeGroq)); err != nil {
+						return nil, err
+					}
+				}
+				continue
+			default:
+				apiErr.Message = fmt.Sprintf("API request failed with status: %d, body: %s", resp.StatusCode(), resp.String())
+				apiErr.IsContextTooLong = types.IsContextTooLongResponse(resp.StatusCode(), resp.String())
+				return nil, apiErr
+			}
+		} else {
+			// A cancelled context is not a network error and should not be retried
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+
+			// Handle network errors
+			lastErr = &types.APIError{
+				Message:        fmt.Sprintf("API request failed: %v", err),
+				IsNetworkError: true,
+				Cause:          err,
+			}
+		}
+
+		// Exponential backoff for retries
+		if attempt < attempts-1 {
+			if err := sleepContext(ctx, time.Duration(1<<uint(attempt))*time.Second); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if lastErr != nil {
+		return nil, lastErr
+	}
+
+	return resp, fmt.Errorf("API request failed after %d attempts", attempts)
+}
+
+// ValidateKey checks the API key by listing the available models
+func (gc *GroqClient) ValidateKey(ctx context.Context) error {
+	return validateKey(ctx, gc.Client, modelsEndpoint(gc.Config.GroqEndpoint))
+}
+
+// GenerateDocumentation generates documentation for a file using Groq API
+func (gc *GroqClient) GenerateDocumentation(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	// Answer from the response cache if this file was documented before
+	return cachedGenerate(ctx, gc.Config, file, gc.Complete)
+}
+
+// GenerateProjectOverview writes an overview of the project from its representative files using Groq API
+func (gc *GroqClient) GenerateProjectOverview(ctx context.Context, files []filehandler.FileInfo) (string, error) {
+	return generateProjectOverview(ctx, gc.Complete, files, projectTypeFromConfig(gc.Config), metadataFromConfig(gc.Config))
+}
+
+// Complete sends a prompt to Groq API and returns the generated text
+func (gc *GroqClient) Complete(ctx context.Context, prompt string) (string, error) {
+	if gc.Config.GroqAPIKey == "" {
+		return "", errors.New("Groq API key is not set")
+	}
+
+	// Create the request (Groq is OpenAI-compatible)
+	req := ChatGPTRequest{
+		Model: gc.Config.GetActiveModel(),
+		Messages: []ChatGPTMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+	}
+
+	// Make the request with rate limiting and retries
+	resp, err := gc.makeAPIRequest(ctx, req)
+	if err != nil {
+		return "", friendlyError(err)
+	}
+
+	// Parse the response
+	var groqResp ChatGPTResponse
+	err = json.Unmarshal(resp.Body(), &groqResp)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse API response: %w", err)
+	}
+
+	if len(groqResp.Choices) == 0 {
+		return "", errors.New("API response contains no choices")
+	}
+
+	content := groqResp.Choices[0].Message.Content
+	recordUsage(gc.Config, prompt, content)
+	return content, nil
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/types"
+)
+
+// MockDocumentationClient implements DocumentationClient without a network connection,
+// for tests and for trying out the TUI. Its behavior is configured through its fields,
+// which must not be changed while requests are running.
+type MockDocumentationClient struct {
+	Responses map[string]string // Canned documentation by file path
+	Delay     time.Duration     // Simulated latency per request
+	ErrorRate float64           // Probability between 0 and 1 that a request fails
+
+	// When Usage is set, every successful request is recorded in it as TokensPerCall tokens,
+	// so token budgets can be exercised without an API
+	Usage         *config.UsageTracker
+	TokensPerCall int
+
+	mu      sync.Mutex
+	callLog []string
+}
+
+// NewMockClient creates a mock client returning the given responses by file path.
+// Files without a canned response get placeholder documentation.
+func NewMockClient(responses map[string]string) *MockDocumentationClient {
+	if responses == nil {
+		responses = make(map[string]string)
+	}
+
+	return &MockDocumentationClient{
+		Responses: responses,
+	}
+}
+
+// CallLog returns the file paths passed to GenerateDocumentation, in call order
+func (mc *MockDocumentationClient) CallLog() []string {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return append([]string(nil), mc.callLog...)
+}
+
+// simulate waits for the configured delay and randomly fails according to ErrorRate
+func (mc *MockDocumentationClient) simulate(ctx context.Context) error {
+	if mc.Delay > 0 {
+		if err := sleepContext(ctx, mc.Delay); err != nil {
+			return err
+		}
+	}
+
+	if mc.ErrorRate > 0 && rand.Float64() < mc.ErrorRate {
+		return &types.APIError{
+			StatusCode: 500,
+			Message:    "mock API error",
+		}
+	}
+	return nil
+}
+
+// GenerateDocumentation returns the canned response for the file's path
+func (mc *MockDocumentationClient) GenerateDocumentation(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	mc.mu.Lock()
+	mc.callLog = append(mc.callLog, file.Path)
+	mc.mu.Unlock()
+
+	if err := mc.simulate(ctx); err != nil {
+		return "", err
+	}
+	mc.recordUsage()
+
+	if response, ok := mc.Responses[file.Path]; ok {
+		return response, nil
+	}
+	return fmt.Sprintf("# %s\n\nMock documentation for %s.\n", filepath.Base(file.Path), file.Path), nil
+}
+
+// GenerateDocumentationStream delivers the documentation for file line by line to onChunk
+func (mc *MockDocumentationClient) GenerateDocumentationStream(ctx context.Context, file filehandler.FileInfo, onChunk func(chunk string)) error {
+	doc, err := mc.GenerateDocumentation(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		if line == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		onChunk(line)
+	}
+	return nil
+}
+
+// GenerateProjectOverview returns a placeholder overview listing the representative files
+func (mc *MockDocumentationClient) GenerateProjectOverview(ctx context.Context, files []filehandler.FileInfo) (string, error) {
+	if err := mc.simulate(ctx); err != nil {
+		return "", err
+	}
+	mc.recordUsage()
+
+	var sb strings.Builder
+	sb.WriteString("# Project Overview\n\nMock overview based on:\n\n")
+	for _, file := range RepresentativeFiles(files, maxOverviewFiles) {
+		sb.WriteString("- `" + filepath.Base(file.Path) + "`\n")
+	}
+	return sb.String(), nil
+}
+
+// Complete returns a placeholder completion
+func (mc *MockDocumentationClient) Complete(ctx context.Context, prompt string) (string, error) {
+	if err := mc.simulate(ctx); err != nil {
+		return "", err
+	}
+	mc.recordUsage()
+	return "Mock completion.", nil
+}
+
+// recordUsage records a successful request in Usage, if set
+func (mc *MockDocumentationClient) recordUsage() {
+	if mc.Usage != nil {
+		mc.Usage.Record("mock", mc.TokensPerCall, 0)
+	}
+}
+
+// ValidateKey always succeeds since the mock needs no API key
+func (mc *MockDocumentationClient) ValidateKey(ctx context.Context) error {
+	return nil
+}
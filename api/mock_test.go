@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/types"
+)
+
+func TestMockClientResponses(t *testing.T) {
+	client := NewMockClient(map[string]string{"main.go": "# main.go\n\nStarts the program.\n"})
+	ctx := context.Background()
+
+	doc, err := client.GenerateDocumentation(ctx, filehandler.FileInfo{Path: "main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc != "# main.go\n\nStarts the program.\n" {
+		t.Errorf("canned response = %q", doc)
+	}
+
+	doc, err = client.GenerateDocumentation(ctx, filehandler.FileInfo{Path: "pkg/util.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(doc, "# util.go") {
+		t.Errorf("placeholder response = %q, want a heading naming the file", doc)
+	}
+
+	if got, want := client.CallLog(), []string{"main.go", "pkg/util.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CallLog() = %v, want %v", got, want)
+	}
+}
+
+func TestMockClientErrorsAndDelay(t *testing.T) {
+	client := NewMockClient(nil)
+	client.ErrorRate = 1
+	_, err := client.GenerateDocumentation(context.Background(), filehandler.FileInfo{Path: "a.go"})
+	var apiErr *types.APIError
+	if !errors.As(err, &apiErr) {
+		t.Errorf("GenerateDocumentation with ErrorRate 1 = %v, want an *APIError", err)
+	}
+
+	client = NewMockClient(nil)
+	client.Delay = time.Hour
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.GenerateDocumentation(ctx, filehandler.FileInfo{Path: "a.go"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GenerateDocumentation past its context deadline = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestMockClientStream(t *testing.T) {
+	doc := "# a.go\n\nFirst line.\nSecond line.\n"
+	client := NewMockClient(map[string]string{"a.go": doc})
+
+	var chunks []string
+	err := client.GenerateDocumentationStream(context.Background(), filehandler.FileInfo{Path: "a.go"}, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 || strings.Join(chunks, "") != doc {
+		t.Errorf("chunks = %q, want the 4 lines of %q", chunks, doc)
+	}
+}
+
+func TestMockClientRecordsUsage(t *testing.T) {
+	client := NewMockClient(nil)
+	client.Usage = config.NewUsageTracker()
+	client.TokensPerCall = 250
+
+	for _, path := range []string{"a.go", "b.go"} {
+		if _, err := client.GenerateDocumentation(context.Background(), filehandler.FileInfo{Path: path}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if run := client.Usage.Run(); run.TotalAPICallsMade != 2 || run.TotalTokensEstimated != 500 {
+		t.Errorf("usage = %d calls, %d tokens, want 2 calls, 500 tokens", run.TotalAPICallsMade, run.TotalTokensEstimated)
+	}
+}
+
+func TestCreateDocumentationClientMock(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.APIType = types.APITypeMock
+	client, err := CreateDocumentationClient(cfg, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := client.(*MockDocumentationClient); !ok {
+		t.Errorf("CreateDocumentationClient for %q = %T, want *MockDocumentationClient", types.APITypeMock, client)
+	}
+}
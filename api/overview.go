@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// maxOverviewFiles is how many representative files the project overview prompt includes
+const maxOverviewFiles = 20
+
+// overviewPreviewChars is how much of each representative file the prompt includes
+const overviewPreviewChars = 200
+
+// entryPointNames are the base names, without extension, of files that usually start a program
+var entryPointNames = map[string]bool{
+	"main": true, "index": true, "app": true, "server": true, "cli": true,
+	"__main__": true, "manage": true, "program": true, "lib": true, "mod": true,
+}
+
+// interfaceDeclaration matches declarations of interfaces, traits, protocols and abstract
+// classes, which define how the parts of a project fit together
+var interfaceDeclaration = regexp.MustCompile(`(?m)^\s*(?:export\s+|public\s+)?(?:type\s+\w+\s+interface\b|interface\s+\w+|(?:pub\s+)?trait\s+\w+|protocol\s+\w+|abstract\s+class\s+\w+|class\s+\w+\((?:ABC|Protocol)\))`)
+
+// RepresentativeFiles returns up to limit files that best show how a project is put
+// together: entry points first, then files declaring interfaces, then the largest files,
+// preferring files near the project root
+func RepresentativeFiles(files []filehandler.FileInfo, limit int) []filehandler.FileInfo {
+	type candidate struct {
+		file  filehandler.FileInfo
+		score int
+		depth int
+	}
+
+	root := commonDir(files)
+	var candidates []candidate
+	for _, file := range files {
+		if file.IsDir || file.Content == "" {
+			continue
+		}
+		rel := relativeTo(root, file.Path)
+		name := strings.ToLower(strings.TrimSuffix(path.Base(rel), path.Ext(rel)))
+
+		score := 0
+		if entryPointNames[name] || strings.HasPrefix(rel, "cmd/") {
+			score += 4
+		}
+		if interfaceDeclaration.MatchString(file.Content) {
+			score += 2
+		}
+		if file.Size > 4096 {
+			score++
+		}
+		candidates = append(candidates, candidate{file: file, score: score, depth: strings.Count(rel, "/")})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.depth != b.depth {
+			return a.depth < b.depth
+		}
+		if a.file.Size != b.file.Size {
+			return a.file.Size > b.file.Size
+		}
+		return a.file.Path < b.file.Path
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	representative := make([]filehandler.FileInfo, len(candidates))
+	for i, c := range candidates {
+		representative[i] = c.file
+	}
+	return representative
+}
+
+// BuildProjectOverviewPrompt builds the prompt asking for a project overview from the paths
+// and the start of the project's representative files
+func BuildProjectOverviewPrompt(files []filehandler.FileInfo, projectType string, metadata filehandler.ProjectMetadata) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("You are documenting a %s project", projectType))
+	if metadata.Name != "" {
+		sb.WriteString(fmt.Sprintf(" named %s", metadata.Name))
+	}
+	sb.WriteString(".\n")
+	if metadata.Description != "" {
+		sb.WriteString("Its manifest describes it as: " + metadata.Description + "\n")
+	}
+	sb.WriteString("\nWrite a project overview of 500 to 1000 words in Markdown, starting with the heading \"# Project Overview\". " +
+		"Explain why the project exists and how it fits together, covering:\n" +
+		"- Architecture\n" +
+		"- Key components and their responsibilities\n" +
+		"- Data flow through the system\n" +
+		"- Notable design decisions\n\n" +
+		"Base the overview on these representative files, given by path with the start of their content:\n\n")
+
+	root := commonDir(files)
+	for _, file := range files {
+		preview := file.Content
+		if runes := []rune(preview); len(runes) > overviewPreviewChars {
+			preview = string(runes[:overviewPreviewChars]) + "..."
+		}
+		sb.WriteString(fmt.Sprintf("File: %s\n```\n%s\n```\n\n", relativeTo(root, file.Path), strings.TrimRight(preview, "\n")))
+	}
+	return sb.String()
+}
+
+// generateProjectOverview asks complete for an overview of the project made up of files
+func generateProjectOverview(ctx context.Context, complete func(context.Context, string) (string, error), files []filehandler.FileInfo, projectType string, metadata filehandler.ProjectMetadata) (string, error) {
+	representative := RepresentativeFiles(files, maxOverviewFiles)
+	if len(representative) == 0 {
+		return "", errors.New("no files to base a project overview on")
+	}
+	return complete(ctx, BuildProjectOverviewPrompt(representative, projectType, metadata))
+}
+
+// commonDir returns the deepest directory containing every file, as a slash-separated path
+func commonDir(files []filehandler.FileInfo) string {
+	dir := ""
+	for i, file := range files {
+		fileDir := path.Dir(filepath.ToSlash(file.Path))
+		if i == 0 {
+			dir = fileDir
+			continue
+		}
+		for dir != "." && dir != "/" && fileDir != dir && !strings.HasPrefix(fileDir, dir+"/") {
+			dir = path.Dir(dir)
+		}
+	}
+	return dir
+}
+
+// relativeTo returns filePath relative to the slash-separated directory dir
+func relativeTo(dir, filePath string) string {
+	filePath = filepath.ToSlash(filePath)
+	if dir == "." || dir == "" {
+		return filePath
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(filePath, strings.TrimSuffix(dir, "/")), "/")
+}
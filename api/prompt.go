@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+	"github.com/Abiggj/structura/types"
+)
+
+// maxContextRetries is how often a file is retried with half its content after the API
+// rejected the prompt as exceeding the model's context length
+const maxContextRetries = 3
+
+// BuildDocumentationPrompt builds the prompt used to generate documentation for a single file
+// in the given documentation style, describing the project with metadata
+func BuildDocumentationPrompt(file filehandler.FileInfo, projectType string, metadata filehandler.ProjectMetadata, style config.DocumentationStyle) string {
+	return config.RenderPrompt(style, config.PromptData{
+		Language:    file.Language,
+		ProjectType: projectType,
+		Guidelines:  schemaGuidelines(file.Language) + projectGuidelines(projectType),
+		Path:        file.Path,
+		Content:     file.Content,
+		Metadata:    metadata,
+	})
+}
+
+// generateWithinContext calls generate for file, and whenever the API reports that the prompt
+// exceeds the model's context length, halves the file content and tries again
+func generateWithinContext(ctx context.Context, file filehandler.FileInfo, generate func(context.Context, filehandler.FileInfo) (string, error)) (string, error) {
+	for retries := 0; ; retries++ {
+		response, err := generate(ctx, file)
+		if !errors.Is(err, types.ErrContextTooLong) || retries == maxContextRetries || file.Content == "" {
+			return response, err
+		}
+		file.Content = tokenizer.Truncate(file.Content, tokenizer.Estimate(file.Content)/2)
+	}
+}
+
+// schemaGuidelines returns extra documentation instructions for schema languages
+func schemaGuidelines(language string) string {
+	switch language {
+	case filehandler.LanguageProtobuf:
+		return "This is a Protocol Buffers schema. Also document:\n" +
+			"- Each service definition and its RPC methods, with request and response message types and streaming modes\n" +
+			"- All message types, with every field's name, type, field number and meaning\n" +
+			"- Enums and their values\n" +
+			"- File, message and field options, and the package and imports used\n\n"
+	case filehandler.LanguageGraphQL:
+		return "This is a GraphQL schema. Also document:\n" +
+			"- Object, input, interface, union, enum and scalar types with their fields and arguments\n" +
+			"- Every query, mutation and subscription, with arguments and return types\n" +
+			"- Custom directives, where they may be used and what they do\n\n"
+	}
+	return ""
+}
+
+// kotlinGuidelines are the documentation instructions for Kotlin idioms
+const kotlinGuidelines = "This is a Kotlin project. Where they apply, also document:\n" +
+	"- Suspend functions and coroutines: the scope and dispatcher they run in, and how they are cancelled\n" +
+	"- Data classes and the values they carry\n" +
+	"- Sealed classes and interfaces, with each of their subtypes and when it is used\n" +
+	"- Extension functions, naming the type they extend\n\n"
+
+// projectGuidelines returns extra documentation instructions for frameworks with conventions
+// of their own
+func projectGuidelines(projectType string) string {
+	switch filehandler.ProjectType(projectType) {
+	case filehandler.ProjectTypeKotlin:
+		return kotlinGuidelines
+	case filehandler.ProjectTypeAndroid:
+		return kotlinGuidelines + "This is an Android app. Where they apply, also document:\n" +
+			"- For activities and fragments, what they do at each lifecycle callback they override and the state they save and restore\n" +
+			"- For ViewModels, the state they hold, the LiveData or flows they expose and what updates them\n" +
+			"- The views, intents, permissions and resources the file relies on\n\n"
+	case filehandler.ProjectTypeLaravel:
+		return "This is a Laravel application following its MVC conventions. Where they apply, also document:\n" +
+			"- For controllers, the routes they serve, the requests they validate and the views or responses they return\n" +
+			"- For Eloquent models, the table, fillable and hidden attributes, casts, relationships and scopes\n" +
+			"- For migrations, the tables and columns created or changed, indexes and foreign keys\n" +
+			"- Middleware, service providers, jobs, events and policies the file registers or relies on\n\n"
+	}
+	return ""
+}
+
+// projectTypeFromConfig returns the project type stored in the config, or "generic" if none is set
+func projectTypeFromConfig(cfg *config.Config) string {
+	if fileHandler, ok := cfg.FileHandler.(*filehandler.FileHandler); ok && fileHandler != nil {
+		return string(fileHandler.ProjectType)
+	}
+	return "generic"
+}
+
+// metadataFromConfig returns the project metadata read by the file handler stored in the
+// config, or none if no handler is set
+func metadataFromConfig(cfg *config.Config) filehandler.ProjectMetadata {
+	if fileHandler, ok := cfg.FileHandler.(*filehandler.FileHandler); ok && fileHandler != nil {
+		return fileHandler.Metadata
+	}
+	return filehandler.ProjectMetadata{}
+}
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestBuildDocumentationPromptSchemaGuidelines(t *testing.T) {
+	proto := filehandler.FileInfo{
+		Path:     "api/user.proto",
+		Language: filehandler.DetectLanguage("api/user.proto"),
+		Content:  "syntax = \"proto3\";\n\nmessage User {\n  string name = 1;\n}\n",
+	}
+	prompt := BuildDocumentationPrompt(proto, string(filehandler.ProjectTypeProtobuf), filehandler.ProjectMetadata{}, config.StyleStructured)
+	for _, want := range []string{"Protocol Buffers", "message types", "field number", "message User"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt for %s is missing %q:\n%s", proto.Path, want, prompt)
+		}
+	}
+
+	goFile := filehandler.FileInfo{Path: "main.go", Language: "Go", Content: "package main\n"}
+	if prompt := BuildDocumentationPrompt(goFile, string(filehandler.ProjectTypeGo), filehandler.ProjectMetadata{}, config.StyleStructured); strings.Contains(prompt, "Protocol Buffers") {
+		t.Errorf("prompt for main.go includes the Protocol Buffers guidelines:\n%s", prompt)
+	}
+}
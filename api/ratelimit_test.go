@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWaitConcurrent(t *testing.T) {
+	const (
+		workers  = 10
+		interval = 50 * time.Millisecond
+		// Timers may fire a little late, which brings the next call closer than interval
+		slack = 10 * time.Millisecond
+	)
+	limiter := NewRateLimiter(interval)
+
+	var (
+		mu    sync.Mutex
+		calls []time.Time
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background()); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			calls = append(calls, time.Now())
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(calls) != workers {
+		t.Fatalf("got %d calls, want %d", len(calls), workers)
+	}
+	sort.Slice(calls, func(i, j int) bool { return calls[i].Before(calls[j]) })
+	for i := 1; i < len(calls); i++ {
+		if gap := calls[i].Sub(calls[i-1]); gap < interval-slack {
+			t.Errorf("calls %d and %d were %s apart, want at least %s", i-1, i, gap, interval)
+		}
+		// However late a timer fires, no call may come before its slot
+		if since := calls[i].Sub(calls[0]); since < time.Duration(i)*interval-slack {
+			t.Errorf("call %d came %s after the first, want at least %s", i, since, time.Duration(i)*interval)
+		}
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	limiter := NewRateLimiter(time.Hour)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Fatal("Wait returned nil for a request an hour away, want an error")
+	}
+}
+
+func TestRateLimiterSetRate(t *testing.T) {
+	limiter := NewRateLimiter(time.Hour)
+	limiter.SetRate(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("Wait %d with rate limiting disabled: %v", i, err)
+		}
+	}
+	if fill := limiter.CurrentFill(); fill != 1 {
+		t.Errorf("CurrentFill() = %v with rate limiting disabled, want 1", fill)
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter is a token-bucket rate limiter that can be shared between clients and workers
+type RateLimiter struct {
+	limiter *rate.Limiter
+}
+
+// NewRateLimiter creates a rate limiter allowing one request per interval.
+// An interval of zero or less disables rate limiting.
+func NewRateLimiter(interval time.Duration) *RateLimiter {
+	limit := rate.Inf
+	if interval > 0 {
+		limit = rate.Every(interval)
+	}
+
+	return &RateLimiter{
+		limiter: rate.NewLimiter(limit, 1),
+	}
+}
+
+// Wait blocks until a request is allowed or the context is done
+func (rl *RateLimiter) Wait(ctx context.Context) error {
+	return rl.limiter.Wait(ctx)
+}
+
+// SetRate changes the number of requests allowed per second.
+// A rate of zero or less disables rate limiting.
+func (rl *RateLimiter) SetRate(requestsPerSecond float64) {
+	if requestsPerSecond <= 0 {
+		rl.limiter.SetLimit(rate.Inf)
+		return
+	}
+	rl.limiter.SetLimit(rate.Limit(requestsPerSecond))
+}
+
+// CurrentFill returns how full the token bucket is, from 0 (the next request has to wait)
+// to 1 (a request can be made immediately). It is 1 when rate limiting is disabled.
+func (rl *RateLimiter) CurrentFill() float64 {
+	if rl.limiter.Limit() == rate.Inf {
+		return 1
+	}
+
+	// Tokens are negative while requests are queued for them
+	fill := rl.limiter.Tokens() / float64(rl.limiter.Burst())
+	return max(0, min(fill, 1))
+}
+
+// ClientRateLimiter returns the rate limiter shared by client's requests, or nil if it has none
+func ClientRateLimiter(client DocumentationClient) *RateLimiter {
+	switch c := client.(type) {
+	case *DeepseekClient:
+		return c.RateLimiter
+	case *ChatGPTClient:
+		return c.RateLimiter
+	case *GroqClient:
+		return c.RateLimiter
+	case *CustomClient:
+		return c.RateLimiter
+	case *BedrockClient:
+		return c.RateLimiter
+	}
+	return nil
+}
+
+// sleepContext pauses for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"context"
+)
+
+// Semaphore limits the number of API requests open at the same time. Some providers
+// limit concurrent connections in addition to the request rate.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// NewSemaphore creates a semaphore allowing size concurrent holders.
+// A size of zero or less allows a single holder.
+func NewSemaphore(size int) *Semaphore {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Semaphore{
+		slots: make(chan struct{}, size),
+	}
+}
+
+// Acquire blocks until a slot is available or the context is done
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release returns a slot taken by Acquire
+func (s *Semaphore) Release() {
+	<-s.slots
+}
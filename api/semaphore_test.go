@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Abiggj/structura/types"
+)
+
+// blockedFor is how long a goroutine has to stay blocked to count as waiting for a slot
+const blockedFor = 50 * time.Millisecond
+
+func TestSemaphoreBlocksThirdHolder(t *testing.T) {
+	sem := NewSemaphore(2)
+	for i := 0; i < 2; i++ {
+		if err := sem.Acquire(context.Background()); err != nil {
+			t.Fatalf("Acquire %d: %v", i, err)
+		}
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if err := sem.Acquire(context.Background()); err != nil {
+			t.Error(err)
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("third Acquire returned while both slots were held")
+	case <-time.After(blockedFor):
+	}
+
+	sem.Release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("third Acquire still blocked after a slot was released")
+	}
+}
+
+func TestSemaphoreAcquireCancelled(t *testing.T) {
+	sem := NewSemaphore(1)
+	if err := sem.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Acquire with every slot held = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestCreateDocumentationClientSharesSemaphore(t *testing.T) {
+	const maxConcurrent = 2
+	var inFlight, peak atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "ok"}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := newTestConfig(server.URL + "/v1/chat/completions")
+	cfg.APIType = types.APITypeChatGPT
+	cfg.APIRateLimit = 0
+	cfg.MaxConcurrentRequests = maxConcurrent
+	client, err := CreateDocumentationClient(cfg, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < maxConcurrent+1; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Complete(context.Background(), "prompt"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	// Wait for the first requests to arrive, then give the extra caller time to get through
+	deadline := time.Now().Add(time.Second)
+	for inFlight.Load() < maxConcurrent && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(blockedFor)
+	if n := inFlight.Load(); n != maxConcurrent {
+		t.Errorf("%d requests in flight with MaxConcurrentRequests %d", n, maxConcurrent)
+	}
+
+	close(release)
+	wg.Wait()
+	if p := peak.Load(); p > maxConcurrent {
+		t.Errorf("peak of %d concurrent requests, want at most %d", p, maxConcurrent)
+	}
+}
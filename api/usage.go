@@ -0,0 +1,16 @@
+package api
+
+import (
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// recordUsage adds a completed API call to the usage of the run, estimating the tokens of
+// the prompt and response and what sending them cost
+func recordUsage(cfg *config.Config, prompt, response string) {
+	if cfg == nil || cfg.Usage == nil {
+		return
+	}
+	tokens := tokenizer.Estimate(prompt) + tokenizer.Estimate(response)
+	cfg.Usage.Record(string(cfg.APIType), tokens, EstimateCost(cfg.GetActiveModel(), tokens))
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/spf13/cobra"
+)
+
+// newAuditCommand creates the command checking previously generated documentation
+func newAuditCommand() *cobra.Command {
+	cmd := newCommand("audit", "[dir]", "Check the documentation in an output directory against the project sources")
+	cmd.Args = cobra.MaximumNArgs(1)
+	fs := cmd.Flags()
+	freshnessCheck := fs.Bool("freshness-check", false, "list source files modified or deleted since their documentation was generated, as recorded in "+filehandler.FreshnessFileName)
+	staged := fs.Bool("staged", false, "only check the files staged for the next git commit in the working directory")
+	quiet := fs.Bool("quiet", false, "print only the paths of stale files, and nothing when all are up to date")
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		if !*freshnessCheck {
+			return fmt.Errorf("nothing to audit: pass --freshness-check")
+		}
+
+		// The output directory defaults to output_dir from .structura.yaml in the working directory
+		outputDir := ""
+		if len(args) > 0 {
+			outputDir = args[0]
+		} else {
+			projectConfig, err := config.LoadProjectConfig(".")
+			if err != nil {
+				return err
+			}
+			if projectConfig != nil {
+				outputDir = projectConfig.OutputDir
+			}
+		}
+		if outputDir == "" {
+			return fmt.Errorf("no output directory: pass [dir] or set output_dir in %s", config.ProjectConfigFileName)
+		}
+
+		if _, err := os.Stat(filepath.Join(outputDir, filehandler.FreshnessFileName)); err != nil {
+			return fmt.Errorf("no %s in %s: generate the documentation first", filehandler.FreshnessFileName, outputDir)
+		}
+		store, err := filehandler.LoadFreshnessStore(outputDir)
+		if err != nil {
+			return err
+		}
+
+		stale := store.StaleFiles()
+		if *staged {
+			if stale, err = filterStaged(stale); err != nil {
+				return err
+			}
+		}
+		if *quiet {
+			workDir, _ := os.Getwd()
+			for _, file := range stale {
+				if relPath, err := filepath.Rel(workDir, file.Path); err == nil && workDir != "" {
+					fmt.Println(relPath)
+				} else {
+					fmt.Println(file.Path)
+				}
+			}
+			if len(stale) > 0 {
+				return errStale
+			}
+			return nil
+		}
+		if len(stale) == 0 {
+			fmt.Printf("All %d documented files are up to date\n", store.Len())
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "File\tModified\tDocumented")
+		for _, file := range stale {
+			modified := "deleted"
+			if !file.ModTime.IsZero() {
+				modified = file.ModTime.Format(time.DateTime)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", file.Path, modified, file.GeneratedAt.Format(time.DateTime))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		return fmt.Errorf("%d of %d documented files are stale", len(stale), store.Len())
+	}
+	return cmd
+}
+
+// errStale is returned by audit --quiet when documentation is stale. The stale files have
+// already been printed.
+var errStale = errors.New("stale documentation")
+
+// staleExitStatus is the exit status for errStale, distinct from other failures so the
+// pre-commit hook only blocks commits on stale documentation
+const staleExitStatus = 4
+
+// filterStaged keeps the stale files that are staged for the next commit in the working directory
+func filterStaged(stale []filehandler.StaleFile) ([]filehandler.StaleFile, error) {
+	stagedPaths, err := filehandler.GitStagedFiles(".")
+	if err != nil {
+		return nil, err
+	}
+	staged := make(map[string]bool, len(stagedPaths))
+	for _, path := range stagedPaths {
+		if absPath, err := filepath.Abs(path); err == nil {
+			staged[absPath] = true
+		}
+	}
+
+	var filtered []filehandler.StaleFile
+	for _, file := range stale {
+		if staged[file.Path] {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,47 @@
+// Package awsauth loads the AWS SDK configuration shared by Bedrock and the S3 output, from
+// the configured region, profile and keys or the SDK's defaults
+package awsauth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+// LoadConfig returns the AWS SDK configuration for cfg.AWSRegion and cfg.AWSProfile, either of
+// which may be empty to use the SDK's defaults. With cfg.AWSAccessKeyID and
+// cfg.AWSSecretAccessKey set, those keys are used instead of the default credential chain.
+// defaultRegion, if not empty, is used when no region is configured anywhere. The credentials
+// are retrieved up front, so missing ones are reported here rather than by the first request.
+func LoadConfig(ctx context.Context, cfg *config.Config, defaultRegion string) (aws.Config, error) {
+	var options []func(*awsconfig.LoadOptions) error
+	if cfg.AWSRegion != "" {
+		options = append(options, awsconfig.WithRegion(cfg.AWSRegion))
+	}
+	if defaultRegion != "" {
+		options = append(options, awsconfig.WithDefaultRegion(defaultRegion))
+	}
+	if cfg.AWSProfile != "" {
+		options = append(options, awsconfig.WithSharedConfigProfile(cfg.AWSProfile))
+	}
+	if cfg.AWSAccessKeyID != "" && cfg.AWSSecretAccessKey != "" {
+		options = append(options, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.AWSAccessKeyID, cfg.AWSSecretAccessKey, "")))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load the AWS configuration: %w", err)
+	}
+	if awsCfg.Region == "" {
+		return aws.Config{}, fmt.Errorf("no AWS region set: configure one for the profile or set AWS_REGION")
+	}
+	if _, err := awsCfg.Credentials.Retrieve(ctx); err != nil {
+		return aws.Config{}, fmt.Errorf("no AWS credentials found: %w", err)
+	}
+	return awsCfg, nil
+}
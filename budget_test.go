@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestGenerateStopsAtTokenBudget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rootDir := t.TempDir()
+	outputDir := t.TempDir()
+	const files = 6
+	for i := 0; i < files; i++ {
+		source := fmt.Sprintf("package main\n\nfunc f%d() {}\n", i)
+		if err := os.WriteFile(filepath.Join(rootDir, fmt.Sprintf("f%d.go", i)), []byte(source), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const tokensPerCall = 1000
+	cfg := config.NewConfig()
+	cfg.MaxConcurrentRequests = 1
+	cfg.CacheTTL = 0
+	cfg.SessionTokenBudget = 2500
+	client := api.NewMockClient(nil)
+	client.Usage = cfg.Usage
+	client.TokensPerCall = tokensPerCall
+
+	fileHandler := filehandler.NewFileHandler()
+	fileHandler.SetProjectType(filehandler.ProjectTypeGo)
+	g := &headlessGenerator{cfg: cfg, client: client, rootDir: rootDir, outputDir: outputDir}
+	err := g.run(context.Background(), fileHandler)
+	if !errors.Is(err, errBudgetExceeded) {
+		t.Fatalf("run() error = %v, want %v", err, errBudgetExceeded)
+	}
+	if code := exitCode(err); code != 3 {
+		t.Errorf("exit code %d, want 3", code)
+	}
+
+	// The third file reaches the budget. The one worker may already have been handed the
+	// fourth, but nothing after it is started.
+	documented := 0
+	for _, call := range client.CallLog() {
+		if filepath.Ext(call) == ".go" {
+			documented++
+		}
+	}
+	if documented < 3 || documented > 4 {
+		t.Errorf("%d of %d files documented with a budget of 2.5 files, want 3 or 4", documented, files)
+	}
+	if used := cfg.Usage.Run().TotalTokensEstimated; used < cfg.SessionTokenBudget {
+		t.Errorf("%d tokens recorded, want at least the budget of %d", used, cfg.SessionTokenBudget)
+	}
+}
+
+func TestGenerateWithinTokenBudget(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rootDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(rootDir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.NewConfig()
+	cfg.CacheTTL = 0
+	cfg.SessionTokenBudget = 1000000
+	client := api.NewMockClient(nil)
+	client.Usage = cfg.Usage
+	client.TokensPerCall = 1000
+
+	g := &headlessGenerator{cfg: cfg, client: client, rootDir: rootDir, outputDir: t.TempDir()}
+	if err := g.run(context.Background(), filehandler.NewFileHandler()); err != nil {
+		t.Fatalf("run() error = %v, want none within the budget", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errUsage, 2},
+		{errStale, staleExitStatus},
+		{errChanged, 1},
+		{errBudgetExceeded, 3},
+		{errors.New("3 files failed"), 1},
+	}
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package cache provides an in-memory cache of API responses
+package cache
+
+import (
+	"container/list"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// LRU is a fixed-size in-memory cache evicting the least recently used entry when full.
+// Do additionally deduplicates concurrent lookups of the same missing key. It is safe
+// for concurrent use.
+type LRU struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List // Front is the most recently used entry
+	entries map[string]*list.Element
+	group   singleflight.Group
+}
+
+// lruEntry is the value of an element of LRU.order
+type lruEntry struct {
+	key   string
+	value string
+}
+
+// NewLRU creates a cache holding up to capacity entries. A capacity below 1 disables caching:
+// nothing is stored, though Do still deduplicates concurrent calls.
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key, marking it as recently used
+func (c *LRU) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*lruEntry).value, true
+}
+
+// Add stores value under key, evicting the least recently used entry if the cache is full
+func (c *LRU) Add(key, value string) {
+	if c.capacity < 1 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*lruEntry).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the number of entries in the cache
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// Do returns the value stored under key, or calls load and stores its result. Concurrent
+// calls for the same missing key wait for a single call of load and share its result.
+// hit reports whether the value came from the cache. Errors are not stored.
+func (c *LRU) Do(key string, load func() (string, error)) (value string, hit bool, err error) {
+	if value, ok := c.Get(key); ok {
+		return value, true, nil
+	}
+
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		// Another call may have stored the value between the lookup above and this one
+		if value, ok := c.Get(key); ok {
+			return value, nil
+		}
+
+		value, err := load()
+		if err != nil {
+			return "", err
+		}
+		c.Add(key, value)
+		return value, nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return v.(string), false, nil
+}
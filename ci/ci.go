@@ -0,0 +1,30 @@
+// Package ci reports the results of a documentation run to CI systems
+package ci
+
+import "os"
+
+// Reporter receives the results of a documentation run. Its methods may be called from
+// several goroutines at once.
+type Reporter interface {
+	// FileProcessed records that the documentation of the file at path was written
+	FileProcessed(path string)
+	// FileFailed records that the file at path could not be documented
+	FileFailed(path string, err error)
+	// Finish reports the results once the run is over
+	Finish(summary Summary) error
+}
+
+// Summary describes the outcome of a run beyond the individual files
+type Summary struct {
+	OutputDir string // Directory the documentation was written to
+	IndexFile string // Path of the documentation index, empty if it was not written
+}
+
+// DetectReporter returns the reporter for the CI system the program runs in, or nil
+// outside of CI
+func DetectReporter() Reporter {
+	if os.Getenv("GITHUB_ACTIONS") == "true" {
+		return NewGitHubActionsReporter()
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package ci
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// maxSummaryFiles is the number of documented files listed in the step summary, which
+// GitHub limits to 1 MiB. Failed files are always listed.
+const maxSummaryFiles = 200
+
+// GitHubActionsReporter reports a run to GitHub Actions: failed files become error annotations
+// shown in pull request diffs, the results are appended to the job summary as a table, and
+// processed_count, error_count, output_dir and index_file are set as step outputs
+type GitHubActionsReporter struct {
+	Workspace   string    // Annotation paths are relative to this directory, the repository checkout
+	SummaryFile string    // Job summary file, from GITHUB_STEP_SUMMARY
+	OutputFile  string    // Step output file, from GITHUB_OUTPUT
+	Out         io.Writer // Workflow commands are written here
+
+	mu        sync.Mutex
+	processed []string
+	failed    []failedFile
+}
+
+// failedFile is a file that could not be documented
+type failedFile struct {
+	path    string
+	message string
+}
+
+// NewGitHubActionsReporter creates a reporter configured from the GitHub Actions environment
+func NewGitHubActionsReporter() *GitHubActionsReporter {
+	workspace := os.Getenv("GITHUB_WORKSPACE")
+	if workspace == "" {
+		workspace, _ = os.Getwd()
+	}
+
+	return &GitHubActionsReporter{
+		Workspace:   workspace,
+		SummaryFile: os.Getenv("GITHUB_STEP_SUMMARY"),
+		OutputFile:  os.Getenv("GITHUB_OUTPUT"),
+		Out:         os.Stdout,
+	}
+}
+
+// FileProcessed records a documented file for the summary
+func (r *GitHubActionsReporter) FileProcessed(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.processed = append(r.processed, r.relPath(path))
+}
+
+// FileFailed emits an error annotation for the file and records it for the summary
+func (r *GitHubActionsReporter) FileFailed(path string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file := failedFile{path: r.relPath(path), message: err.Error()}
+	r.failed = append(r.failed, file)
+	fmt.Fprintf(r.Out, "::error file=%s::%s\n", escapeProperty(file.path), escapeData(file.message))
+}
+
+// Finish appends the summary table to the job summary and writes the step outputs
+func (r *GitHubActionsReporter) Finish(summary Summary) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.SummaryFile != "" {
+		if err := appendFile(r.SummaryFile, r.summaryTable(summary)); err != nil {
+			return fmt.Errorf("error writing job summary: %w", err)
+		}
+	}
+
+	if r.OutputFile != "" {
+		outputs := fmt.Sprintf("processed_count=%d\nerror_count=%d\noutput_dir=%s\nindex_file=%s\n",
+			len(r.processed), len(r.failed), singleLine(summary.OutputDir), singleLine(summary.IndexFile))
+		if err := appendFile(r.OutputFile, outputs); err != nil {
+			return fmt.Errorf("error writing step outputs: %w", err)
+		}
+	}
+	return nil
+}
+
+// summaryTable renders the Markdown job summary, listing failed files first
+func (r *GitHubActionsReporter) summaryTable(summary Summary) string {
+	var sb strings.Builder
+	sb.WriteString("## Structura documentation\n\n")
+	sb.WriteString(fmt.Sprintf("%d files documented, %d failed. Documentation written to `%s`.\n\n",
+		len(r.processed), len(r.failed), summary.OutputDir))
+
+	if len(r.processed) == 0 && len(r.failed) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("| File | Status |\n|------|--------|\n")
+	for _, file := range r.failed {
+		sb.WriteString(fmt.Sprintf("| `%s` | ❌ %s |\n", escapeCell(file.path), escapeCell(singleLine(file.message))))
+	}
+	for i, path := range r.processed {
+		if i == maxSummaryFiles {
+			sb.WriteString(fmt.Sprintf("| … | %d more documented |\n", len(r.processed)-maxSummaryFiles))
+			break
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | ✅ Documented |\n", escapeCell(path)))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// relPath returns path relative to the workspace, slash-separated as GitHub expects, or path
+// itself if it is outside the workspace
+func (r *GitHubActionsReporter) relPath(path string) string {
+	absPath, err := filepath.Abs(path)
+	if err != nil || r.Workspace == "" {
+		return filepath.ToSlash(path)
+	}
+	relPath, err := filepath.Rel(r.Workspace, absPath)
+	if err != nil || !filepath.IsLocal(relPath) {
+		return filepath.ToSlash(absPath)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// appendFile appends content to the file at path, creating it if needed
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// escapeCell escapes text for a Markdown table cell
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// singleLine joins the lines of s with spaces, for values that must fit on one line
+func singleLine(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(s))
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// errUsage is returned when the arguments could not be parsed. The problem and the
+// command's usage have already been printed.
+var errUsage = errors.New("invalid usage")
+
+// newCommand creates a subcommand whose --help prints its usage line, description and flags.
+// args lists the positional arguments shown in the usage line, e.g. "[dir]".
+func newCommand(name, args, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   strings.TrimSpace(name + " " + args),
+		Short: short,
+	}
+}
+
+// stringList is a flag that may be given more than once, collecting every value
+type stringList []string
+
+// String returns the values separated by commas
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set adds a value
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// Type names the flag's value in the help output
+func (s *stringList) Type() string {
+	return "path"
+}
+
+// newRootCommand creates the structura command. Without a subcommand it runs the TUI like
+// the run command, so `structura --readme` still works. The completion and help commands
+// are added by cobra.
+func newRootCommand() *cobra.Command {
+	root := newRunCommand()
+	root.Use = "structura [dir]"
+	root.Short = "Structura generates technical documentation for a project's source files."
+	root.Long = root.Short + "\n\nWithout a command, run is used."
+	root.SilenceErrors = true
+	root.SilenceUsage = true
+
+	// Report flag errors with the command's usage, exiting with status 2
+	root.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		fmt.Fprintln(cmd.ErrOrStderr(), "Error:", err)
+		fmt.Fprintln(cmd.ErrOrStderr(), cmd.UsageString())
+		return errUsage
+	})
+
+	root.AddCommand(
+		newRunCommand(),
+		newGenerateCommand(),
+		newConfigCommand(),
+		newAuditCommand(),
+		newDiffCommand(),
+		newServeCommand(),
+		newSearchCommand(),
+		newStatsCommand(),
+		newHooksCommand(),
+	)
+	return root
+}
+
+// execute runs the subcommand named by the first argument, or the TUI without one
+func execute(args []string) error {
+	root := newRootCommand()
+	root.SetArgs(args)
+	return root.Execute()
+}
@@ -1,10 +1,29 @@
 package config
 
 import (
+	"fmt"
+	"github.com/Abiggj/structura/output"
 	"github.com/Abiggj/structura/types"
+	"os"
 	"time"
 )
 
+// Output formats for the generated documentation
+const (
+	OutputFormatMarkdown = "markdown"
+	OutputFormatHTML     = "html" // Markdown plus an HTML page per file and index.html
+	OutputFormatJSON     = "json" // Markdown plus a JSON file per source file
+)
+
+// ValidateOutputFormat returns an error if format is not one of the OutputFormat constants
+func ValidateOutputFormat(format string) error {
+	switch format {
+	case OutputFormatMarkdown, OutputFormatHTML, OutputFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q: expected markdown, html or json", format)
+}
+
 // Config holds the application configuration
 type Config struct {
 	// API Configuration
@@ -13,37 +32,260 @@ type Config struct {
 	DeepseekAPIKey string
 	OpenAIAPIKey   string
 	GeminiAPIKey   string
+	GroqAPIKey     string
+	
+	// Custom OpenAI-compatible API
+	CustomEndpoint  string
+	CustomAPIKey    string
+	CustomModelName string
 	
 	// API Endpoints
 	DeepseekEndpoint string
 	OpenAIEndpoint   string
 	GeminiEndpoint   string
+	GroqEndpoint     string
+	BedrockEndpoint  string // Overrides the regional Bedrock runtime endpoint, e.g. for a VPC endpoint
+	
+	// AWS, for Bedrock and the output bucket; without explicit keys, credentials come from
+	// the default AWS credential chain
+	AWSRegion          string // Region of Bedrock and the output bucket (default: AWS_REGION or the profile's region)
+	AWSProfile         string // Profile in the shared AWS config files (default: AWS_PROFILE or "default")
+	AWSAccessKeyID     string // Used with AWSSecretAccessKey instead of the credential chain
+	AWSSecretAccessKey string
+	
+	// Cloud storage output; the output directory is synced to the bucket after each run
+	OutputBucket         string // S3 bucket, or bucket of an S3-compatible service such as MinIO or GCS (empty = no upload)
+	OutputBucketPrefix   string // Key prefix the files are uploaded under, e.g. "docs/main"
+	OutputBucketEndpoint string // Endpoint of an S3-compatible service, e.g. https://storage.googleapis.com (default: AWS S3)
 	
 	// Common Config
-	FileHandler    interface{}
-	APIRateLimit   time.Duration // Duration to wait between API calls
-	MaxRetries     int           // Maximum number of retries for failed API calls
+	FileHandler           interface{}
+	APIRateLimit          time.Duration // Duration to wait between API calls
+	MaxRetries            int           // Maximum number of retries for failed API calls
+	CallerRetries         bool          // Rate limit and network errors are returned after one attempt, for the caller to retry
+	MaxInputTokens        int           // Maximum estimated prompt tokens per API call; longer files are documented in chunks (0 disables the limit)
+	SessionTokenBudget    int           // Estimated prompt and response tokens one run may use before it stops (0 is unlimited)
+	MaxConcurrentRequests int           // Maximum number of API requests in flight at once
+	BatchSize             int           // Maximum number of small files documented per API call (1 disables batching)
+	CacheTTL              time.Duration // How long API responses are cached for unchanged files (0 disables the cache)
+	InMemoryCacheSize     int           // Number of recent API responses also kept in memory (0 disables the in-memory cache)
+	
+	// Documentation Output
+	OutputDir                  string             // Default output directory, e.g. from .structura.yaml
+	OutputFormat               string             // One of the OutputFormat constants
+	OutputFormats              []string           // Formats of output.Renderers each file's documentation is written in, side by side
+	DocumentationStyle         DocumentationStyle // Prompt used to document each file, e.g. from .structura.yaml
+	GenerateDependencyGraph    bool               // Embed a Mermaid import graph in PROJECT_STRUCTURE.md (enabled for Go projects)
+	GenerateDirectorySummaries bool               // Write an AI summary to README_SUMMARY.md per package (costs extra API calls)
+	GenerateSetupDoc           bool               // Ask the API to write PROJECT_SETUP.md from the project's setup files
+	GenerateReadme             bool               // Ask the API to write README.md once all files are documented
+	GenerateProjectOverview    bool               // Ask the API to write PROJECT_OVERVIEW.md from the project's representative files
+	GenerateArchitectureDocs   bool               // Ask the API to write ARCHITECTURE.md from the tree and a summary of each file's documentation
+	GenerateGlossary           bool               // Ask the API to define the project's domain terms in GLOSSARY.md
+	GlossaryTerms              int                // Maximum number of terms in GLOSSARY.md
+	GenerateTechDebt           bool               // List TODO, FIXME and similar comments in TECH_DEBT.md
+	GenerateMkdocsConfig       bool               // Write mkdocs.yml with a nav of the documentation
+	ForceRegenerate            bool               // Document every file again, even if its source is unchanged
+	PromptVersion              string             // Prompt version recorded with each documented file; files recorded with another are documented again
+	RedocumentPromptVersion    string             // Files documented with this prompt version are documented again, even if unchanged
+	AddFrontmatter             bool               // Start every file's documentation with a YAML frontmatter block
+	MaxOutputFileBytes         int64              // Longer documentation is truncated at a heading (0 = unlimited)
+	MinOutputFileBytes         int64              // Shorter responses are treated as errors and retried (0 = no minimum)
+	CrossReferenceLinks        bool               // Link mentions of other documented files once all files are documented
+	InjectCrossReferences      bool               // Link mentions of functions and types documented in other files
+	MinDocScore                float64            // Documentation scoring lower in COVERAGE.md is flagged for review
+	LintOutput                 bool               // Append a quality note for each Markdown problem found in a file's documentation
+	
+	// Output file names
+	OutputFileNaming   OutputFileNaming // How documentation files are named in the output directory
+	OutputFileTemplate string           // text/template of the path for NamingCustomTemplate, executed with OutputPathData
+	
+	// Notifications
+	DesktopNotification bool   // Show a desktop notification when processing completes
+	WebhookURL          string // Receives a JSON POST with the results when processing completes
+	WebhookSecret       string // Signs webhook requests, from $STRUCTURA_WEBHOOK_SECRET
+	
+	// TUI
+	FilePreview     bool   // Show the source of the last documented file beside the progress on wide terminals
+	VerboseProgress bool   // List the last files finished with their timings instead of the current file
+	LogFile         string // Each finished file is appended here with its timing, if set
+	
+	// PerProviderRateLimits overrides APIRateLimit for specific API types
+	PerProviderRateLimits map[types.APIType]time.Duration
+	
+	// Secrets
+	UseKeyring bool // Load API keys from, and offer to save them to, the OS keychain
+	
+	// Usage accumulates the API calls of this run, flushed to the usage file when it ends
+	Usage *UsageTracker
+	
+	envAPIKeys map[types.APIType]bool // API types whose key was read from the environment
 }
 
 // NewConfig creates a new configuration
 func NewConfig() *Config {
-	return &Config{
+	cfg := &Config{
 		// Default API settings
 		APIType:        types.APITypeDeepseek,
 		APIModel:       "deepseek-chat",
 		DeepseekAPIKey: "",
 		OpenAIAPIKey:   "",
 		GeminiAPIKey:   "",
+		GroqAPIKey:     "",
+		
+		// Custom OpenAI-compatible API
+		CustomEndpoint:  "",
+		CustomAPIKey:    "",
+		CustomModelName: "",
 		
 		// API Endpoints
 		DeepseekEndpoint: "https://api.deepseek.com/chat/completions",
 		OpenAIEndpoint:   "https://api.openai.com/v1/chat/completions",
 		GeminiEndpoint:   "https://generativelanguage.googleapis.com/v1/models/gemini-pro:generateContent",
+		GroqEndpoint:     "https://api.groq.com/openai/v1/chat/completions",
+		BedrockEndpoint:  "", // Derived from the region
+		
+		// AWS
+		AWSRegion:          "",
+		AWSProfile:         "",
+		AWSAccessKeyID:     "",
+		AWSSecretAccessKey: "",
+		
+		// Cloud storage output
+		OutputBucket:         "",
+		OutputBucketPrefix:   "",
+		OutputBucketEndpoint: "",
 		
 		// Common Config
-		FileHandler:    nil,
-		APIRateLimit:   time.Second * 1, // Default: 1 second between API calls
-		MaxRetries:     3,               // Default: retry 3 times
+		FileHandler:           nil,
+		APIRateLimit:          time.Second * 1, // Default: 1 second between API calls
+		MaxRetries:            3,               // Default: retry 3 times
+		MaxInputTokens:        6000,            // Default: fits the smallest supported context window
+		SessionTokenBudget:    0,               // Default: unlimited
+		MaxConcurrentRequests: 1,               // Default: one request at a time
+		BatchSize:             1,               // Default: one file per API call
+		CacheTTL:              time.Hour * 168, // Default: a week
+		InMemoryCacheSize:     100,             // Default: 100 responses
+		
+		// Documentation Output
+		OutputDir:                  "",
+		OutputFormat:               OutputFormatMarkdown,
+		OutputFormats:              []string{output.MarkdownFormat},
+		DocumentationStyle:         StyleStructured,
+		GenerateDependencyGraph:    false, // Enabled when a Go project type is selected
+		GenerateDirectorySummaries: false, // Disabled since it costs extra API calls
+		GenerateSetupDoc:           true,  // A single extra API call per run
+		GenerateReadme:             false, // Disabled since most projects already have a README
+		GenerateProjectOverview:    true,  // A single extra API call per run
+		GenerateArchitectureDocs:   false, // Opt-in since it costs an extra API call
+		GenerateGlossary:           false, // Opt-in since it costs an extra API call
+		GlossaryTerms:              30,
+		GenerateTechDebt:           false,
+		GenerateMkdocsConfig:       false,
+		ForceRegenerate:            false, // Unchanged files keep their existing documentation
+		PromptVersion:              CurrentPromptVersion,
+		RedocumentPromptVersion:    "",
+		AddFrontmatter:             false, // Only needed by static site generators
+		MaxOutputFileBytes:         0,     // Keep the whole response
+		MinOutputFileBytes:         0,     // Accept any response
+		CrossReferenceLinks:        false, // Opt-in since it rewrites the generated documentation
+		InjectCrossReferences:      false, // Opt-in since it rewrites the generated documentation
+		MinDocScore:                0.6,   // Flags documentation missing two of the checks
+		LintOutput:                 false,
+		
+		// Output file names
+		OutputFileNaming:   NamingMirrored,
+		OutputFileTemplate: "",
+		
+		// Notifications
+		DesktopNotification: false, // Opt-in so that CI runs do not try to reach a desktop
+		WebhookURL:          "",
+		WebhookSecret:       os.Getenv(WebhookSecretEnvVar), // Kept out of flags and profiles
+		
+		// TUI
+		FilePreview:     true,
+		VerboseProgress: false,
+		LogFile:         "",
+		
+		// Groq enforces requests-per-minute limits more strictly
+		PerProviderRateLimits: map[types.APIType]time.Duration{
+			types.APITypeGroq: time.Second * 2,
+		},
+		
+		// Secrets
+		UseKeyring: KeyringEnabled,
+		
+		// Usage statistics
+		Usage: NewUsageTracker(),
+	}
+	
+	cfg.loadAPIKeys()
+	return cfg
+}
+
+// Environment variables selecting the API type and model, so the TUI can skip those screens
+const (
+	APITypeEnvVar  = "STRUCTURA_API_TYPE"
+	APIModelEnvVar = "STRUCTURA_MODEL"
+)
+
+// WebhookSecretEnvVar is the environment variable the webhook signing secret is read from
+const WebhookSecretEnvVar = "STRUCTURA_WEBHOOK_SECRET"
+
+// apiKeyEnvVars maps API types to the environment variables their keys can be read from
+var apiKeyEnvVars = map[types.APIType]string{
+	types.APITypeDeepseek: "DEEPSEEK_API_KEY",
+	types.APITypeChatGPT:  "OPENAI_API_KEY",
+	types.APITypeGemini:   "GEMINI_API_KEY",
+	types.APITypeGroq:     "GROQ_API_KEY",
+	types.APITypeCustom:   "STRUCTURA_CUSTOM_API_KEY",
+}
+
+// APIKeyEnvVar returns the environment variable the API key for apiType is read from
+func APIKeyEnvVar(apiType types.APIType) string {
+	return apiKeyEnvVars[apiType]
+}
+
+// EnvironmentAPI returns the API type and model set in STRUCTURA_API_TYPE and STRUCTURA_MODEL.
+// Either is empty when its variable is unset. An unknown API type is an error.
+func EnvironmentAPI() (types.APIType, string, error) {
+	apiType := types.APIType(os.Getenv(APITypeEnvVar))
+	model := os.Getenv(APIModelEnvVar)
+	if apiType == "" {
+		return "", model, nil
+	}
+	for _, known := range types.APITypes() {
+		if apiType == known {
+			return apiType, model, nil
+		}
+	}
+	return "", "", fmt.Errorf("unknown API type %q in %s", apiType, APITypeEnvVar)
+}
+
+// loadAPIKeys fills in API keys from the OS keychain, falling back to environment variables
+func (c *Config) loadAPIKeys() {
+	c.envAPIKeys = make(map[types.APIType]bool)
+	
+	var store *KeyringStore
+	if c.UseKeyring {
+		store = NewKeyringStore()
+	}
+	
+	for _, apiType := range types.APITypes() {
+		if !RequiresAPIKey(apiType) {
+			continue
+		}
+		if store != nil {
+			if key, err := store.Get(KeyringService, KeyringKeyName(apiType)); err == nil && key != "" {
+				c.SetAPIKey(apiType, key)
+				continue
+			}
+		}
+		
+		if key := os.Getenv(apiKeyEnvVars[apiType]); key != "" {
+			c.SetAPIKey(apiType, key)
+			c.envAPIKeys[apiType] = true
+		}
 	}
 }
 
@@ -54,19 +296,105 @@ func (c *Config) GetActiveEndpoint() string {
 		return c.OpenAIEndpoint
 	case types.APITypeGemini:
 		return c.GeminiEndpoint
+	case types.APITypeGroq:
+		return c.GroqEndpoint
+	case types.APITypeCustom:
+		return c.CustomEndpoint
+	case types.APITypeBedrock:
+		return c.BedrockEndpoint
 	default:
 		return c.DeepseekEndpoint
 	}
 }
 
+// GetActiveModel returns the model name for the currently selected API type
+func (c *Config) GetActiveModel() string {
+	if c.APIType == types.APITypeCustom && c.CustomModelName != "" {
+		return c.CustomModelName
+	}
+	return c.APIModel
+}
+
 // GetActiveAPIKey returns the API key for the currently selected API type
 func (c *Config) GetActiveAPIKey() string {
-	switch c.APIType {
+	return c.GetAPIKey(c.APIType)
+}
+
+// GetAPIKey returns the API key for the given API type
+func (c *Config) GetAPIKey(apiType types.APIType) string {
+	switch apiType {
 	case types.APITypeChatGPT:
 		return c.OpenAIAPIKey
 	case types.APITypeGemini:
 		return c.GeminiAPIKey
+	case types.APITypeGroq:
+		return c.GroqAPIKey
+	case types.APITypeCustom:
+		return c.CustomAPIKey
+	case types.APITypeBedrock, types.APITypeMock:
+		return ""
 	default:
 		return c.DeepseekAPIKey
 	}
+}
+
+// SetAPIKey sets the API key for the given API type
+func (c *Config) SetAPIKey(apiType types.APIType, key string) {
+	switch apiType {
+	case types.APITypeChatGPT:
+		c.OpenAIAPIKey = key
+	case types.APITypeGemini:
+		c.GeminiAPIKey = key
+	case types.APITypeGroq:
+		c.GroqAPIKey = key
+	case types.APITypeCustom:
+		c.CustomAPIKey = key
+	case types.APITypeBedrock, types.APITypeMock:
+		// No API key
+	default:
+		c.DeepseekAPIKey = key
+	}
+}
+
+// RequiresAPIKey reports whether apiType authenticates with an API key. Bedrock uses AWS
+// credentials instead, and the mock API needs none.
+func RequiresAPIKey(apiType types.APIType) bool {
+	return apiType != types.APITypeBedrock && apiType != types.APITypeMock
+}
+
+// APIKeyFromEnv reports whether the API key for apiType was read from the environment
+// rather than the OS keychain
+func (c *Config) APIKeyFromEnv(apiType types.APIType) bool {
+	return c.envAPIKeys[apiType]
+}
+
+// GetRateLimit returns the duration to wait between API calls for the given API type
+func (c *Config) GetRateLimit(apiType types.APIType) time.Duration {
+	if rateLimit, ok := c.PerProviderRateLimits[apiType]; ok {
+		return rateLimit
+	}
+	return c.APIRateLimit
+}
+
+// ConfiguredAPITypes returns the API types that have credentials or an endpoint configured
+func (c *Config) ConfiguredAPITypes() []types.APIType {
+	var configured []types.APIType
+	for _, apiType := range types.APITypes() {
+		if apiType == types.APITypeCustom {
+			if c.CustomEndpoint != "" {
+				configured = append(configured, apiType)
+			}
+			continue
+		}
+		if apiType == types.APITypeBedrock {
+			if c.AWSRegion != "" || c.AWSProfile != "" {
+				configured = append(configured, apiType)
+			}
+			continue
+		}
+		if c.GetAPIKey(apiType) != "" {
+			configured = append(configured, apiType)
+		}
+	}
+	return configured
 }
\ No newline at end of file
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/Abiggj/structura/types"
+)
+
+// clearEnvironment unsets every variable NewConfig and EnvironmentAPI read, and the keychain,
+// for the duration of the test
+func clearEnvironment(t *testing.T) {
+	t.Helper()
+	for _, name := range apiKeyEnvVars {
+		t.Setenv(name, "")
+	}
+	t.Setenv(APITypeEnvVar, "")
+	t.Setenv(APIModelEnvVar, "")
+
+	keyringEnabled := KeyringEnabled
+	KeyringEnabled = false
+	t.Cleanup(func() { KeyringEnabled = keyringEnabled })
+}
+
+func TestNewConfigReadsAPIKeysFromEnvironment(t *testing.T) {
+	tests := []struct {
+		apiType types.APIType
+		envVar  string
+	}{
+		{types.APITypeDeepseek, "DEEPSEEK_API_KEY"},
+		{types.APITypeChatGPT, "OPENAI_API_KEY"},
+		{types.APITypeGemini, "GEMINI_API_KEY"},
+		{types.APITypeGroq, "GROQ_API_KEY"},
+		{types.APITypeCustom, "STRUCTURA_CUSTOM_API_KEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.envVar, func(t *testing.T) {
+			clearEnvironment(t)
+			t.Setenv(tt.envVar, "key-from-env")
+
+			if got := APIKeyEnvVar(tt.apiType); got != tt.envVar {
+				t.Errorf("APIKeyEnvVar(%s) = %q, want %q", tt.apiType, got, tt.envVar)
+			}
+
+			cfg := NewConfig()
+			cfg.APIType = tt.apiType
+			if got := cfg.GetActiveAPIKey(); got != "key-from-env" {
+				t.Errorf("GetActiveAPIKey() = %q, want the key from %s", got, tt.envVar)
+			}
+			for _, other := range tests {
+				if got := cfg.APIKeyFromEnv(other.apiType); got != (other.apiType == tt.apiType) {
+					t.Errorf("APIKeyFromEnv(%s) = %v with only %s set", other.apiType, got, tt.envVar)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvironmentAPI(t *testing.T) {
+	tests := []struct {
+		name      string
+		apiType   string
+		model     string
+		wantType  types.APIType
+		wantModel string
+		wantErr   bool
+	}{
+		{name: "unset"},
+		{name: "type only", apiType: "chatgpt", wantType: types.APITypeChatGPT},
+		{name: "type and model", apiType: "groq", model: "llama3-70b-8192", wantType: types.APITypeGroq, wantModel: "llama3-70b-8192"},
+		{name: "model only", model: "gpt-4o", wantModel: "gpt-4o"},
+		{name: "unknown type", apiType: "openai", model: "gpt-4o", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnvironment(t)
+			t.Setenv(APITypeEnvVar, tt.apiType)
+			t.Setenv(APIModelEnvVar, tt.model)
+
+			apiType, model, err := EnvironmentAPI()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EnvironmentAPI() error = %v, want error %v", err, tt.wantErr)
+			}
+			if apiType != tt.wantType || model != tt.wantModel {
+				t.Errorf("EnvironmentAPI() = %q, %q, want %q, %q", apiType, model, tt.wantType, tt.wantModel)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"github.com/99designs/keyring"
+	"github.com/Abiggj/structura/types"
+)
+
+// KeyringService is the service name API keys are stored under in the OS keychain
+const KeyringService = "structura"
+
+// KeyringEnabled controls whether NewConfig reads API keys from the OS keychain.
+// It can be switched off before creating a config, e.g. by the --no-keyring flag.
+var KeyringEnabled = true
+
+// keyringKeyNames maps API types to their key names in the OS keychain
+var keyringKeyNames = map[types.APIType]string{
+	types.APITypeDeepseek: "deepseek_api_key",
+	types.APITypeChatGPT:  "openai_api_key",
+	types.APITypeGemini:   "gemini_api_key",
+	types.APITypeGroq:     "groq_api_key",
+	types.APITypeCustom:   "custom_api_key",
+}
+
+// KeyringKeyName returns the keychain key name for an API type
+func KeyringKeyName(apiType types.APIType) string {
+	return keyringKeyNames[apiType]
+}
+
+// KeyringStore reads and writes secrets in the OS keychain (macOS Keychain,
+// Windows Credential Manager or the Linux Secret Service)
+type KeyringStore struct{}
+
+// NewKeyringStore creates a new keyring store
+func NewKeyringStore() *KeyringStore {
+	return &KeyringStore{}
+}
+
+// open opens the keychain for the given service using only OS-backed secure backends
+func (ks *KeyringStore) open(service string) (keyring.Keyring, error) {
+	return keyring.Open(keyring.Config{
+		ServiceName: service,
+		AllowedBackends: []keyring.BackendType{
+			keyring.KeychainBackend,
+			keyring.WinCredBackend,
+			keyring.SecretServiceBackend,
+			keyring.KWalletBackend,
+		},
+	})
+}
+
+// Get returns the secret stored under key for the given service
+func (ks *KeyringStore) Get(service, key string) (string, error) {
+	ring, err := ks.open(service)
+	if err != nil {
+		return "", err
+	}
+
+	item, err := ring.Get(key)
+	if err != nil {
+		return "", err
+	}
+
+	return string(item.Data), nil
+}
+
+// Set stores value under key for the given service
+func (ks *KeyringStore) Set(service, key string, value string) error {
+	ring, err := ks.open(service)
+	if err != nil {
+		return err
+	}
+
+	return ring.Set(keyring.Item{
+		Key:   key,
+		Data:  []byte(value),
+		Label: "Structura " + key,
+	})
+}
@@ -0,0 +1,13 @@
+package config
+
+// ProjectMetadata describes the project being documented, as declared in its manifest
+// (go.mod, package.json, pyproject.toml, setup.py or pom.xml). Fields the manifest does not
+// declare are empty.
+type ProjectMetadata struct {
+	Name        string
+	Version     string
+	Author      string
+	License     string
+	Description string
+	Repository  string // URL of the source repository
+}
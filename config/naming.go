@@ -0,0 +1,117 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// OutputFileNaming controls how documentation files are named in the output directory
+type OutputFileNaming string
+
+const (
+	NamingMirrored       OutputFileNaming = "mirrored"  // src/utils/parser.go.md, mirroring the source tree
+	NamingFlat           OutputFileNaming = "flat"      // src_utils_parser.go.md, all in the output directory
+	NamingStripExt       OutputFileNaming = "strip-ext" // src/utils/parser.md
+	NamingCustomTemplate OutputFileNaming = "template"  // Config.OutputFileTemplate
+)
+
+// OutputFileNamings returns all supported naming strategies
+func OutputFileNamings() []OutputFileNaming {
+	return []OutputFileNaming{
+		NamingMirrored,
+		NamingFlat,
+		NamingStripExt,
+		NamingCustomTemplate,
+	}
+}
+
+// ParseOutputFileNaming returns the naming strategy with the given name
+func ParseOutputFileNaming(name string) (OutputFileNaming, error) {
+	for _, naming := range OutputFileNamings() {
+		if string(naming) == name {
+			return naming, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output file naming %q: expected mirrored, flat, strip-ext or template", name)
+}
+
+// OutputPathData is what Config.OutputFileTemplate is executed with. For src/utils/parser.go,
+// RelPath is "src/utils/parser.go", Base "parser", Ext ".go" and Dir "src/utils". Paths are
+// slash-separated, and Dir is "." for files in the project root.
+type OutputPathData struct {
+	RelPath string
+	Base    string
+	Ext     string
+	Dir     string
+}
+
+// ValidateOutputFileTemplate returns an error if text is not a template producing a path
+// inside the output directory
+func ValidateOutputFileTemplate(text string) error {
+	if text == "" {
+		return fmt.Errorf("output file template is empty")
+	}
+	_, err := executeOutputFileTemplate(text, "src/example.go")
+	return err
+}
+
+// ComputeOutputPath returns the path of the documentation of filePath, a file below inputRoot,
+// relative to the output directory and named according to cfg.OutputFileNaming
+func ComputeOutputPath(cfg *Config, inputRoot, filePath string) string {
+	relPath, err := filepath.Rel(inputRoot, filePath)
+	if err != nil {
+		relPath = filepath.Base(filePath)
+	}
+	return OutputRelPath(cfg, relPath)
+}
+
+// OutputRelPath returns the path of the documentation of the source file at relPath, relative
+// to the project root, as a path relative to the output directory. A template failing for the
+// file falls back to mirrored naming.
+func OutputRelPath(cfg *Config, relPath string) string {
+	slashPath := filepath.ToSlash(relPath)
+
+	switch cfg.OutputFileNaming {
+	case NamingFlat:
+		return strings.ReplaceAll(slashPath, "/", "_") + ".md"
+	case NamingStripExt:
+		return filepath.FromSlash(strings.TrimSuffix(slashPath, path.Ext(slashPath)) + ".md")
+	case NamingCustomTemplate:
+		if outputPath, err := executeOutputFileTemplate(cfg.OutputFileTemplate, slashPath); err == nil {
+			return outputPath
+		}
+	}
+	return relPath + ".md"
+}
+
+// executeOutputFileTemplate executes the output file template text for the source file at the
+// slash-separated slashPath
+func executeOutputFileTemplate(text, slashPath string) (string, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid output file template: %w", err)
+	}
+
+	ext := path.Ext(slashPath)
+	data := OutputPathData{
+		RelPath: slashPath,
+		Base:    strings.TrimSuffix(path.Base(slashPath), ext),
+		Ext:     ext,
+		Dir:     path.Dir(slashPath),
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid output file template: %w", err)
+	}
+
+	// Keep the documentation inside the output directory
+	outputPath := filepath.Clean(filepath.FromSlash(strings.TrimSpace(buf.String())))
+	if outputPath == "." || !filepath.IsLocal(outputPath) {
+		return "", fmt.Errorf("output file template gives %q for %s, which is not a file in the output directory", buf.String(), slashPath)
+	}
+	return outputPath, nil
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestComputeOutputPath(t *testing.T) {
+	tests := []struct {
+		naming   OutputFileNaming
+		template string
+		file     string
+		want     string // Slash-separated
+	}{
+		{NamingMirrored, "", "src/utils/parser.go", "src/utils/parser.go.md"},
+		{NamingMirrored, "", "main.go", "main.go.md"},
+		{NamingFlat, "", "src/utils/parser.go", "src_utils_parser.go.md"},
+		{NamingFlat, "", "main.go", "main.go.md"},
+		{NamingStripExt, "", "src/utils/parser.go", "src/utils/parser.md"},
+		{NamingStripExt, "", "Makefile", "Makefile.md"},
+		{NamingCustomTemplate, "{{.Dir}}/{{.Base}}{{.Ext}}.txt", "src/utils/parser.go", "src/utils/parser.go.txt"},
+		{NamingCustomTemplate, "{{.Base}}.md", "main.go", "main.md"},
+		{NamingCustomTemplate, "docs/{{.RelPath}}.md", "src/utils/parser.go", "docs/src/utils/parser.go.md"},
+		// Templates escaping the output directory fall back to mirrored naming
+		{NamingCustomTemplate, "../{{.Base}}.md", "src/utils/parser.go", "src/utils/parser.go.md"},
+		{NamingCustomTemplate, "{{.Missing}}.md", "src/utils/parser.go", "src/utils/parser.go.md"},
+	}
+
+	root := filepath.Join("home", "project")
+	for _, tt := range tests {
+		t.Run(string(tt.naming)+"/"+tt.file, func(t *testing.T) {
+			cfg := NewConfig()
+			cfg.OutputFileNaming = tt.naming
+			cfg.OutputFileTemplate = tt.template
+
+			got := ComputeOutputPath(cfg, root, filepath.Join(root, filepath.FromSlash(tt.file)))
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("ComputeOutputPath(%s) = %q, want %q", tt.file, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateOutputFileTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  bool
+	}{
+		{"{{.Dir}}/{{.Base}}.md", false},
+		{"", true},
+		{"{{.Base", true},
+		{"{{.Unknown}}.md", true},
+		{"/{{.Base}}.md", true},
+		{"../{{.Base}}.md", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateOutputFileTemplate(tt.template); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateOutputFileTemplate(%q) error = %v, want error %v", tt.template, err, tt.wantErr)
+		}
+	}
+}
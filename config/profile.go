@@ -0,0 +1,333 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/Abiggj/structura/types"
+	"github.com/BurntSushi/toml"
+)
+
+// ProfilesFileName is the name of the file named profiles are stored in, under ~/.config/structura
+const ProfilesFileName = "profiles.toml"
+
+// Profile is a named set of settings that can be reused across runs, e.g. one per project.
+// It can set every Config field except the API keys and other secrets, and fields left out
+// keep their current value when the profile is applied. API keys are never stored; APIKeyEnv
+// names the environment variable to read the key from.
+type Profile struct {
+	ProjectType string `toml:"project_type,omitempty"`
+	APIKeyEnv   string `toml:"api_key_env,omitempty"`
+
+	// API
+	APIType          *types.APIType `toml:"api_type,omitempty"`
+	APIModel         *string        `toml:"api_model,omitempty"`
+	CustomEndpoint   *string        `toml:"custom_endpoint,omitempty"`
+	CustomModelName  *string        `toml:"custom_model_name,omitempty"`
+	DeepseekEndpoint *string        `toml:"deepseek_endpoint,omitempty"`
+	OpenAIEndpoint   *string        `toml:"openai_endpoint,omitempty"`
+	GeminiEndpoint   *string        `toml:"gemini_endpoint,omitempty"`
+	GroqEndpoint     *string        `toml:"groq_endpoint,omitempty"`
+	BedrockEndpoint  *string        `toml:"bedrock_endpoint,omitempty"`
+
+	// AWS and cloud storage output
+	AWSRegion            *string `toml:"aws_region,omitempty"`
+	AWSProfile           *string `toml:"aws_profile,omitempty"`
+	OutputBucket         *string `toml:"output_bucket,omitempty"`
+	OutputBucketPrefix   *string `toml:"output_bucket_prefix,omitempty"`
+	OutputBucketEndpoint *string `toml:"output_bucket_endpoint,omitempty"`
+
+	// Requests
+	APIRateLimit          *time.Duration                  `toml:"api_rate_limit,omitempty"`
+	PerProviderRateLimits map[types.APIType]time.Duration `toml:"per_provider_rate_limits,omitempty"`
+	MaxRetries            *int                            `toml:"max_retries,omitempty"`
+	MaxInputTokens        *int                            `toml:"max_input_tokens,omitempty"`
+	SessionTokenBudget    *int                            `toml:"session_token_budget,omitempty"`
+	MaxConcurrentRequests *int                            `toml:"max_concurrent_requests,omitempty"`
+	BatchSize             *int                            `toml:"batch_size,omitempty"`
+	CacheTTL              *time.Duration                  `toml:"cache_ttl,omitempty"`
+	InMemoryCacheSize     *int                            `toml:"in_memory_cache_size,omitempty"`
+	UseKeyring            *bool                           `toml:"use_keyring,omitempty"`
+
+	// Documentation output
+	OutputDir                  *string             `toml:"output_dir,omitempty"`
+	OutputFormat               *string             `toml:"output_format,omitempty"`
+	OutputFormats              []string            `toml:"output_formats,omitempty"`
+	DocumentationStyle         *DocumentationStyle `toml:"documentation_style,omitempty"`
+	GenerateDependencyGraph    *bool               `toml:"generate_dependency_graph,omitempty"`
+	GenerateDirectorySummaries *bool               `toml:"generate_directory_summaries,omitempty"`
+	GenerateSetupDoc           *bool               `toml:"generate_setup_doc,omitempty"`
+	GenerateReadme             *bool               `toml:"generate_readme,omitempty"`
+	GenerateProjectOverview    *bool               `toml:"generate_project_overview,omitempty"`
+	GenerateArchitectureDocs   *bool               `toml:"generate_architecture_docs,omitempty"`
+	GenerateGlossary           *bool               `toml:"generate_glossary,omitempty"`
+	GlossaryTerms              *int                `toml:"glossary_terms,omitempty"`
+	GenerateTechDebt           *bool               `toml:"generate_tech_debt,omitempty"`
+	GenerateMkdocsConfig       *bool               `toml:"generate_mkdocs_config,omitempty"`
+	ForceRegenerate            *bool               `toml:"force_regenerate,omitempty"`
+	RedocumentPromptVersion    *string             `toml:"redocument_prompt_version,omitempty"`
+	AddFrontmatter             *bool               `toml:"add_frontmatter,omitempty"`
+	MaxOutputFileBytes         *int64              `toml:"max_output_file_bytes,omitempty"`
+	MinOutputFileBytes         *int64              `toml:"min_output_file_bytes,omitempty"`
+	CrossReferenceLinks        *bool               `toml:"cross_reference_links,omitempty"`
+	InjectCrossReferences      *bool               `toml:"inject_cross_references,omitempty"`
+	MinDocScore                *float64            `toml:"min_doc_score,omitempty"`
+	LintOutput                 *bool               `toml:"lint_output,omitempty"`
+	OutputFileNaming           *OutputFileNaming   `toml:"output_file_naming,omitempty"`
+	OutputFileTemplate         *string             `toml:"output_file_template,omitempty"`
+
+	// Notifications and TUI
+	DesktopNotification *bool   `toml:"desktop_notification,omitempty"`
+	WebhookURL          *string `toml:"webhook_url,omitempty"`
+	FilePreview         *bool   `toml:"file_preview,omitempty"`
+	VerboseProgress     *bool   `toml:"verbose_progress,omitempty"`
+	LogFile             *string `toml:"log_file,omitempty"`
+}
+
+// Profiles maps profile names to their settings
+type Profiles map[string]*Profile
+
+// profilesFile is the layout of profiles.toml, one [profile.<name>] table per profile
+type profilesFile struct {
+	Profiles Profiles `toml:"profile"`
+}
+
+// ProfilesPath returns the path of the profiles file, ~/.config/structura/profiles.toml
+func ProfilesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "structura", ProfilesFileName), nil
+}
+
+// LoadProfiles reads the saved profiles. It returns an empty set if none have been saved yet.
+func LoadProfiles() (Profiles, error) {
+	path, err := ProfilesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Profiles{}, nil
+		}
+		return nil, fmt.Errorf("error reading %s: %w", ProfilesFileName, err)
+	}
+
+	var file profilesFile
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", ProfilesFileName, err)
+	}
+	if file.Profiles == nil {
+		file.Profiles = Profiles{}
+	}
+	for name, profile := range file.Profiles {
+		if profile.DocumentationStyle != nil {
+			if _, err := ParseDocumentationStyle(string(*profile.DocumentationStyle)); err != nil {
+				return nil, fmt.Errorf("error parsing profile %q in %s: %w", name, ProfilesFileName, err)
+			}
+		}
+	}
+
+	return file.Profiles, nil
+}
+
+// Save writes the profiles to ~/.config/structura/profiles.toml
+func (p Profiles) Save() error {
+	path, err := ProfilesPath()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(profilesFile{Profiles: p}); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// Names returns the profile names in alphabetical order
+func (p Profiles) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProfile captures the settings of cfg, along with the selected project type, as a profile
+func NewProfile(cfg *Config, projectType string) *Profile {
+	return &Profile{
+		ProjectType: projectType,
+		APIKeyEnv:   apiKeyEnvVars[cfg.APIType],
+
+		APIType:          ptr(cfg.APIType),
+		APIModel:         ptr(cfg.APIModel),
+		CustomEndpoint:   ptr(cfg.CustomEndpoint),
+		CustomModelName:  ptr(cfg.CustomModelName),
+		DeepseekEndpoint: ptr(cfg.DeepseekEndpoint),
+		OpenAIEndpoint:   ptr(cfg.OpenAIEndpoint),
+		GeminiEndpoint:   ptr(cfg.GeminiEndpoint),
+		GroqEndpoint:     ptr(cfg.GroqEndpoint),
+		BedrockEndpoint:  ptr(cfg.BedrockEndpoint),
+
+		AWSRegion:            ptr(cfg.AWSRegion),
+		AWSProfile:           ptr(cfg.AWSProfile),
+		OutputBucket:         ptr(cfg.OutputBucket),
+		OutputBucketPrefix:   ptr(cfg.OutputBucketPrefix),
+		OutputBucketEndpoint: ptr(cfg.OutputBucketEndpoint),
+
+		APIRateLimit:          ptr(cfg.APIRateLimit),
+		PerProviderRateLimits: maps.Clone(cfg.PerProviderRateLimits),
+		MaxRetries:            ptr(cfg.MaxRetries),
+		MaxInputTokens:        ptr(cfg.MaxInputTokens),
+		SessionTokenBudget:    ptr(cfg.SessionTokenBudget),
+		MaxConcurrentRequests: ptr(cfg.MaxConcurrentRequests),
+		BatchSize:             ptr(cfg.BatchSize),
+		CacheTTL:              ptr(cfg.CacheTTL),
+		InMemoryCacheSize:     ptr(cfg.InMemoryCacheSize),
+		UseKeyring:            ptr(cfg.UseKeyring),
+
+		OutputDir:                  ptr(cfg.OutputDir),
+		OutputFormat:               ptr(cfg.OutputFormat),
+		OutputFormats:              slices.Clone(cfg.OutputFormats),
+		DocumentationStyle:         ptr(cfg.DocumentationStyle),
+		GenerateDependencyGraph:    ptr(cfg.GenerateDependencyGraph),
+		GenerateDirectorySummaries: ptr(cfg.GenerateDirectorySummaries),
+		GenerateSetupDoc:           ptr(cfg.GenerateSetupDoc),
+		GenerateReadme:             ptr(cfg.GenerateReadme),
+		GenerateProjectOverview:    ptr(cfg.GenerateProjectOverview),
+		GenerateArchitectureDocs:   ptr(cfg.GenerateArchitectureDocs),
+		GenerateGlossary:           ptr(cfg.GenerateGlossary),
+		GlossaryTerms:              ptr(cfg.GlossaryTerms),
+		GenerateTechDebt:           ptr(cfg.GenerateTechDebt),
+		GenerateMkdocsConfig:       ptr(cfg.GenerateMkdocsConfig),
+		ForceRegenerate:            ptr(cfg.ForceRegenerate),
+		RedocumentPromptVersion:    ptr(cfg.RedocumentPromptVersion),
+		AddFrontmatter:             ptr(cfg.AddFrontmatter),
+		MaxOutputFileBytes:         ptr(cfg.MaxOutputFileBytes),
+		MinOutputFileBytes:         ptr(cfg.MinOutputFileBytes),
+		CrossReferenceLinks:        ptr(cfg.CrossReferenceLinks),
+		InjectCrossReferences:      ptr(cfg.InjectCrossReferences),
+		MinDocScore:                ptr(cfg.MinDocScore),
+		LintOutput:                 ptr(cfg.LintOutput),
+		OutputFileNaming:           ptr(cfg.OutputFileNaming),
+		OutputFileTemplate:         ptr(cfg.OutputFileTemplate),
+
+		DesktopNotification: ptr(cfg.DesktopNotification),
+		WebhookURL:          ptr(cfg.WebhookURL),
+		FilePreview:         ptr(cfg.FilePreview),
+		VerboseProgress:     ptr(cfg.VerboseProgress),
+		LogFile:             ptr(cfg.LogFile),
+	}
+}
+
+// Summary describes the API the profile selects, e.g. "deepseek / deepseek-chat"
+func (p *Profile) Summary() string {
+	apiType, model := "default API", "default model"
+	if p.APIType != nil {
+		apiType = string(*p.APIType)
+	}
+	if p.APIModel != nil {
+		model = *p.APIModel
+	}
+	return apiType + " / " + model
+}
+
+// Apply copies the settings the profile sets into cfg. The API key is read from the environment
+// variable named by APIKeyEnv, if it is set; otherwise the key already loaded is kept.
+func (p *Profile) Apply(cfg *Config) {
+	override(&cfg.APIType, p.APIType)
+	override(&cfg.APIModel, p.APIModel)
+	override(&cfg.CustomEndpoint, p.CustomEndpoint)
+	override(&cfg.CustomModelName, p.CustomModelName)
+	override(&cfg.DeepseekEndpoint, p.DeepseekEndpoint)
+	override(&cfg.OpenAIEndpoint, p.OpenAIEndpoint)
+	override(&cfg.GeminiEndpoint, p.GeminiEndpoint)
+	override(&cfg.GroqEndpoint, p.GroqEndpoint)
+	override(&cfg.BedrockEndpoint, p.BedrockEndpoint)
+
+	override(&cfg.AWSRegion, p.AWSRegion)
+	override(&cfg.AWSProfile, p.AWSProfile)
+	override(&cfg.OutputBucket, p.OutputBucket)
+	override(&cfg.OutputBucketPrefix, p.OutputBucketPrefix)
+	override(&cfg.OutputBucketEndpoint, p.OutputBucketEndpoint)
+
+	override(&cfg.APIRateLimit, p.APIRateLimit)
+	if len(p.PerProviderRateLimits) > 0 {
+		cfg.PerProviderRateLimits = maps.Clone(p.PerProviderRateLimits)
+	}
+	override(&cfg.MaxRetries, p.MaxRetries)
+	override(&cfg.MaxInputTokens, p.MaxInputTokens)
+	override(&cfg.SessionTokenBudget, p.SessionTokenBudget)
+	override(&cfg.MaxConcurrentRequests, p.MaxConcurrentRequests)
+	override(&cfg.BatchSize, p.BatchSize)
+	override(&cfg.CacheTTL, p.CacheTTL)
+	override(&cfg.InMemoryCacheSize, p.InMemoryCacheSize)
+	override(&cfg.UseKeyring, p.UseKeyring)
+
+	override(&cfg.OutputDir, p.OutputDir)
+	override(&cfg.OutputFormat, p.OutputFormat)
+	if len(p.OutputFormats) > 0 {
+		cfg.OutputFormats = slices.Clone(p.OutputFormats)
+	}
+	override(&cfg.DocumentationStyle, p.DocumentationStyle)
+	override(&cfg.GenerateDependencyGraph, p.GenerateDependencyGraph)
+	override(&cfg.GenerateDirectorySummaries, p.GenerateDirectorySummaries)
+	override(&cfg.GenerateSetupDoc, p.GenerateSetupDoc)
+	override(&cfg.GenerateReadme, p.GenerateReadme)
+	override(&cfg.GenerateProjectOverview, p.GenerateProjectOverview)
+	override(&cfg.GenerateArchitectureDocs, p.GenerateArchitectureDocs)
+	override(&cfg.GenerateGlossary, p.GenerateGlossary)
+	override(&cfg.GlossaryTerms, p.GlossaryTerms)
+	override(&cfg.GenerateTechDebt, p.GenerateTechDebt)
+	override(&cfg.GenerateMkdocsConfig, p.GenerateMkdocsConfig)
+	override(&cfg.ForceRegenerate, p.ForceRegenerate)
+	override(&cfg.RedocumentPromptVersion, p.RedocumentPromptVersion)
+	override(&cfg.AddFrontmatter, p.AddFrontmatter)
+	override(&cfg.MaxOutputFileBytes, p.MaxOutputFileBytes)
+	override(&cfg.MinOutputFileBytes, p.MinOutputFileBytes)
+	override(&cfg.CrossReferenceLinks, p.CrossReferenceLinks)
+	override(&cfg.InjectCrossReferences, p.InjectCrossReferences)
+	override(&cfg.MinDocScore, p.MinDocScore)
+	override(&cfg.LintOutput, p.LintOutput)
+	override(&cfg.OutputFileNaming, p.OutputFileNaming)
+	override(&cfg.OutputFileTemplate, p.OutputFileTemplate)
+
+	override(&cfg.DesktopNotification, p.DesktopNotification)
+	override(&cfg.WebhookURL, p.WebhookURL)
+	override(&cfg.FilePreview, p.FilePreview)
+	override(&cfg.VerboseProgress, p.VerboseProgress)
+	override(&cfg.LogFile, p.LogFile)
+
+	if p.APIKeyEnv != "" {
+		if key := os.Getenv(p.APIKeyEnv); key != "" {
+			cfg.SetAPIKey(cfg.APIType, key)
+		}
+	}
+}
+
+// ptr returns a pointer to a copy of v
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// override sets *field to *value if the profile sets it
+func override[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Abiggj/structura/types"
+)
+
+func TestLoadProfilesTOML(t *testing.T) {
+	clearEnvironment(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("OPENAI_API_KEY", "key-from-env")
+
+	profiles := `[profile.backend]
+project_type = "go"
+api_key_env = "OPENAI_API_KEY"
+api_type = "chatgpt"
+api_model = "gpt-4o-mini"
+max_concurrent_requests = 4
+cache_ttl = "24h"
+generate_project_overview = false
+output_formats = ["markdown", "html"]
+
+[profile.backend.per_provider_rate_limits]
+chatgpt = "500ms"
+`
+	path := filepath.Join(home, ".config", "structura", ProfilesFileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(profiles), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadProfiles()
+	if err != nil {
+		t.Fatalf("LoadProfiles() error = %v", err)
+	}
+	profile, ok := loaded["backend"]
+	if !ok {
+		t.Fatalf("LoadProfiles() = %v, want the backend profile", loaded.Names())
+	}
+
+	cfg := NewConfig()
+	cfg.MaxRetries = 7
+	profile.Apply(cfg)
+	if cfg.APIType != types.APITypeChatGPT || cfg.APIModel != "gpt-4o-mini" {
+		t.Errorf("API = %s / %s, want chatgpt / gpt-4o-mini", cfg.APIType, cfg.APIModel)
+	}
+	if cfg.GetActiveAPIKey() != "key-from-env" {
+		t.Errorf("API key not read from %s", profile.APIKeyEnv)
+	}
+	if cfg.MaxConcurrentRequests != 4 || cfg.CacheTTL != 24*time.Hour || cfg.GenerateProjectOverview {
+		t.Errorf("MaxConcurrentRequests = %d, CacheTTL = %s, GenerateProjectOverview = %v, want 4, 24h and false",
+			cfg.MaxConcurrentRequests, cfg.CacheTTL, cfg.GenerateProjectOverview)
+	}
+	if len(cfg.OutputFormats) != 2 || cfg.PerProviderRateLimits[types.APITypeChatGPT] != 500*time.Millisecond {
+		t.Errorf("OutputFormats = %v, PerProviderRateLimits = %v", cfg.OutputFormats, cfg.PerProviderRateLimits)
+	}
+	// Fields the profile leaves out are not changed
+	if cfg.MaxRetries != 7 || !cfg.GenerateSetupDoc || cfg.DocumentationStyle != StyleStructured {
+		t.Errorf("MaxRetries = %d, GenerateSetupDoc = %v, DocumentationStyle = %s, want them unchanged",
+			cfg.MaxRetries, cfg.GenerateSetupDoc, cfg.DocumentationStyle)
+	}
+}
+
+func TestProfilesSaveRoundTrip(t *testing.T) {
+	clearEnvironment(t)
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := NewConfig()
+	cfg.APIType = types.APITypeGroq
+	cfg.APIModel = "llama3-70b-8192"
+	cfg.BatchSize = 5
+	cfg.MinDocScore = 0.8
+	cfg.DocumentationStyle = StyleTerse
+	cfg.OutputFileNaming = NamingFlat
+	cfg.GroqAPIKey = "secret"
+	if err := (Profiles{"saved": NewProfile(cfg, "python")}).Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	path, _ := ProfilesPath()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("%s contains the API key:\n%s", ProfilesFileName, data)
+	}
+
+	loaded, err := LoadProfiles()
+	if err != nil {
+		t.Fatalf("LoadProfiles() error = %v", err)
+	}
+	profile := loaded["saved"]
+	if profile == nil || profile.ProjectType != "python" || profile.APIKeyEnv != "GROQ_API_KEY" {
+		t.Fatalf("loaded profile %+v, want the saved python profile reading GROQ_API_KEY", profile)
+	}
+
+	restored := NewConfig()
+	profile.Apply(restored)
+	if restored.APIType != cfg.APIType || restored.APIModel != cfg.APIModel || restored.BatchSize != 5 ||
+		restored.MinDocScore != 0.8 || restored.DocumentationStyle != StyleTerse || restored.OutputFileNaming != NamingFlat {
+		t.Errorf("restored %s / %s, batch size %d, score %v, style %s, naming %s; want the saved settings",
+			restored.APIType, restored.APIModel, restored.BatchSize, restored.MinDocScore, restored.DocumentationStyle, restored.OutputFileNaming)
+	}
+}
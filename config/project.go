@@ -0,0 +1,74 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectConfigFileName is the name of the project-level configuration file
+const ProjectConfigFileName = ".structura.yaml"
+
+// ProjectConfig holds settings committed to a project in .structura.yaml
+type ProjectConfig struct {
+	ProjectType        string   `yaml:"project_type"`
+	IgnorePatterns     []string `yaml:"ignore_patterns"`
+	IncludePatterns    []string `yaml:"include_patterns"`
+	DocumentationStyle string   `yaml:"documentation_style"`
+	APIModel           string   `yaml:"api_model"`
+	OutputDir          string   `yaml:"output_dir"`
+	MaxDepth           int      `yaml:"max_depth"` // 0 means unlimited
+}
+
+// LoadProjectConfig reads .structura.yaml from rootDir.
+// It returns nil without an error if the project has no configuration file.
+func LoadProjectConfig(rootDir string) (*ProjectConfig, error) {
+	path := filepath.Join(rootDir, ProjectConfigFileName)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading %s: %w", ProjectConfigFileName, err)
+	}
+
+	var projectConfig ProjectConfig
+	if err := yaml.Unmarshal(data, &projectConfig); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", ProjectConfigFileName, err)
+	}
+
+	if projectConfig.DocumentationStyle != "" {
+		if _, err := ParseDocumentationStyle(projectConfig.DocumentationStyle); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", ProjectConfigFileName, err)
+		}
+	}
+
+	// Resolve a relative output directory against the project root
+	if projectConfig.OutputDir != "" && !filepath.IsAbs(projectConfig.OutputDir) {
+		projectConfig.OutputDir = filepath.Join(rootDir, projectConfig.OutputDir)
+	}
+
+	return &projectConfig, nil
+}
+
+// MergeProjectConfig applies the non-empty project settings over the current config.
+// Callers should apply command-line flags afterwards so that they take precedence.
+func MergeProjectConfig(cfg *Config, projectConfig *ProjectConfig) {
+	if projectConfig == nil {
+		return
+	}
+
+	if projectConfig.APIModel != "" {
+		cfg.APIModel = projectConfig.APIModel
+	}
+	if projectConfig.DocumentationStyle != "" {
+		cfg.DocumentationStyle = DocumentationStyle(projectConfig.DocumentationStyle)
+	}
+	if projectConfig.OutputDir != "" {
+		cfg.OutputDir = projectConfig.OutputDir
+	}
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Abiggj/structura/output"
+)
+
+// DocumentationStyle selects the prompt used to document each file
+type DocumentationStyle string
+
+const (
+	StyleStructured DocumentationStyle = "structured" // Summary, types and functions in Markdown sections and lists
+	StyleJSDoc      DocumentationStyle = "jsdoc"      // /** ... */ comments with @param, @returns and @throws
+	StyleTerse      DocumentationStyle = "terse"      // One sentence per function
+	StyleNarrative  DocumentationStyle = "narrative"  // Flowing prose without lists
+)
+
+// CurrentPromptVersion is the version of the built-in prompt templates. It is recorded with
+// each documented file, and raised whenever the templates change so that files documented
+// with older prompts are documented again.
+const CurrentPromptVersion = "v1"
+
+// OutdatedPromptVersion reports whether documentation generated with the prompts of version
+// is to be generated again: when version is not PromptVersion, or is RedocumentPromptVersion
+func (c *Config) OutdatedPromptVersion(version string) bool {
+	return version != c.PromptVersion || (c.RedocumentPromptVersion != "" && version == c.RedocumentPromptVersion)
+}
+
+// DocumentationStyles returns all supported documentation styles
+func DocumentationStyles() []DocumentationStyle {
+	return []DocumentationStyle{
+		StyleStructured,
+		StyleJSDoc,
+		StyleTerse,
+		StyleNarrative,
+	}
+}
+
+// ParseDocumentationStyle returns the documentation style with the given name
+func ParseDocumentationStyle(name string) (DocumentationStyle, error) {
+	for _, style := range DocumentationStyles() {
+		if string(style) == name {
+			return style, nil
+		}
+	}
+	return "", fmt.Errorf("unknown documentation style %q: expected structured, jsdoc, terse or narrative", name)
+}
+
+// Description returns a one-line description of the style for selection lists
+func (s DocumentationStyle) Description() string {
+	switch s {
+	case StyleJSDoc:
+		return "/** */ comments with @param, @returns and @throws"
+	case StyleTerse:
+		return "one sentence per function"
+	case StyleNarrative:
+		return "flowing prose without bullet points"
+	}
+	return "summary, types and functions in sections and lists"
+}
+
+// PromptData is what the documentation prompt templates are executed with
+type PromptData struct {
+	Language    string
+	ProjectType string
+	Guidelines  string // Extra instructions for the file's language, ending with a blank line if set
+	Path        string
+	Content     string
+	Metadata    ProjectMetadata
+}
+
+// promptTemplates are the prompt templates of output.PromptTemplates, parsed and executed once
+// when the package loads so that rendering them cannot fail
+var promptTemplates = mustParsePromptTemplates()
+
+// mustParsePromptTemplates parses the prompt templates and checks that every style has one
+// that executes with PromptData
+func mustParsePromptTemplates() *template.Template {
+	templates := template.Must(template.ParseFS(output.PromptTemplates, "templates/*.tmpl"))
+	for _, style := range DocumentationStyles() {
+		if err := templates.ExecuteTemplate(new(strings.Builder), string(style)+".tmpl", PromptData{}); err != nil {
+			panic(fmt.Sprintf("prompt template of documentation style %s: %s", style, err))
+		}
+	}
+	return templates
+}
+
+// RenderPrompt renders the documentation prompt of style for one file. Unknown styles,
+// including the empty style, render the structured prompt.
+func RenderPrompt(style DocumentationStyle, data PromptData) string {
+	if _, err := ParseDocumentationStyle(string(style)); err != nil {
+		style = StyleStructured
+	}
+
+	var sb strings.Builder
+	// Cannot fail: every template was executed with PromptData in mustParsePromptTemplates
+	_ = promptTemplates.ExecuteTemplate(&sb, string(style)+".tmpl", data)
+
+	// Template files end with a newline that is not part of the prompt
+	return strings.TrimSuffix(sb.String(), "\n")
+}
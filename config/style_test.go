@@ -0,0 +1,64 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderPromptStyles(t *testing.T) {
+	data := PromptData{
+		Language:    "go",
+		ProjectType: "Go",
+		Path:        "internal/parser.go",
+		Content:     "package parser\n\nfunc Parse(s string) error { return nil }",
+		Metadata:    ProjectMetadata{Name: "parser", Version: "1.2.0"},
+	}
+	// An instruction only the style's prompt gives
+	markers := map[DocumentationStyle]string{
+		StyleStructured: "structured technical documentation",
+		StyleJSDoc:      "@param name - meaning",
+		StyleTerse:      "exactly one sentence",
+		StyleNarrative:  "Do not use bullet points",
+	}
+
+	prompts := make(map[string]DocumentationStyle)
+	for _, style := range DocumentationStyles() {
+		prompt := RenderPrompt(style, data)
+		if other, ok := prompts[prompt]; ok {
+			t.Errorf("styles %s and %s render the same prompt", style, other)
+		}
+		prompts[prompt] = style
+
+		for _, want := range []string{"File path: internal/parser.go", "```go\n" + data.Content + "\n```", "Project: parser 1.2.0"} {
+			if !strings.Contains(prompt, want) {
+				t.Errorf("%s prompt does not contain %q:\n%s", style, want, prompt)
+			}
+		}
+		for markerStyle, marker := range markers {
+			if got := strings.Contains(prompt, marker); got != (markerStyle == style) {
+				t.Errorf("%s prompt contains %q (the %s instruction): %v", style, marker, markerStyle, got)
+			}
+		}
+	}
+}
+
+func TestRenderPromptUnknownStyle(t *testing.T) {
+	data := PromptData{Language: "go", Path: "main.go", Content: "package main"}
+	want := RenderPrompt(StyleStructured, data)
+	for _, style := range []DocumentationStyle{"", "verbose"} {
+		if got := RenderPrompt(style, data); got != want {
+			t.Errorf("RenderPrompt(%q) differs from the structured prompt", style)
+		}
+	}
+}
+
+func TestParseDocumentationStyle(t *testing.T) {
+	for _, style := range DocumentationStyles() {
+		if got, err := ParseDocumentationStyle(string(style)); err != nil || got != style {
+			t.Errorf("ParseDocumentationStyle(%q) = %q, %v", style, got, err)
+		}
+	}
+	if _, err := ParseDocumentationStyle("Terse"); err == nil {
+		t.Error("ParseDocumentationStyle(\"Terse\") succeeded, want names to be case-sensitive")
+	}
+}
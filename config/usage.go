@@ -0,0 +1,207 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// UsageFileName is the name of the file API usage is accumulated in across runs
+const UsageFileName = "usage.json"
+
+// ProviderUsage is the API usage of one API type
+type ProviderUsage struct {
+	APICalls        int     `json:"api_calls"`
+	TokensEstimated int     `json:"tokens_estimated"`
+	CostEstimated   float64 `json:"cost_estimated"` // USD
+}
+
+// UsageStats is the API usage of a run, or of every run since Since
+type UsageStats struct {
+	TotalAPICallsMade    int                      `json:"total_api_calls_made"`
+	TotalTokensEstimated int                      `json:"total_tokens_estimated"`
+	TotalCostEstimated   float64                  `json:"total_cost_estimated"` // USD
+	Providers            map[string]ProviderUsage `json:"providers,omitempty"`  // By API type
+	Since                time.Time                `json:"since,omitempty"`      // When usage was first recorded
+}
+
+// Add returns the sum of u and other, keeping the earlier Since
+func (u UsageStats) Add(other UsageStats) UsageStats {
+	sum := UsageStats{
+		TotalAPICallsMade:    u.TotalAPICallsMade + other.TotalAPICallsMade,
+		TotalTokensEstimated: u.TotalTokensEstimated + other.TotalTokensEstimated,
+		TotalCostEstimated:   u.TotalCostEstimated + other.TotalCostEstimated,
+		Providers:            make(map[string]ProviderUsage, len(u.Providers)),
+		Since:                u.Since,
+	}
+	if sum.Since.IsZero() || (!other.Since.IsZero() && other.Since.Before(sum.Since)) {
+		sum.Since = other.Since
+	}
+	for _, providers := range []map[string]ProviderUsage{u.Providers, other.Providers} {
+		for name, usage := range providers {
+			total := sum.Providers[name]
+			total.APICalls += usage.APICalls
+			total.TokensEstimated += usage.TokensEstimated
+			total.CostEstimated += usage.CostEstimated
+			sum.Providers[name] = total
+		}
+	}
+	return sum
+}
+
+// ProviderNames returns the API types with recorded usage, most API calls first
+func (u UsageStats) ProviderNames() []string {
+	names := make([]string, 0, len(u.Providers))
+	for name := range u.Providers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if u.Providers[names[i]].APICalls != u.Providers[names[j]].APICalls {
+			return u.Providers[names[i]].APICalls > u.Providers[names[j]].APICalls
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// BudgetWarningFraction is the share of the session token budget after which a warning is shown
+const BudgetWarningFraction = 0.9
+
+// BudgetUsed returns the share of a token budget used by u, where 1 means the budget is
+// exhausted. A budget of 0 is unlimited, so none of it is ever used.
+func (u UsageStats) BudgetUsed(budget int) float64 {
+	if budget <= 0 {
+		return 0
+	}
+	return float64(u.TotalTokensEstimated) / float64(budget)
+}
+
+// UsagePath returns the path of the usage file, ~/.local/share/structura/usage.json
+func UsagePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", "structura", UsageFileName), nil
+}
+
+// LoadUsage reads the usage accumulated across runs. It returns no usage if none has been
+// recorded yet.
+func LoadUsage() (UsageStats, error) {
+	path, err := UsagePath()
+	if err != nil {
+		return UsageStats{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return UsageStats{}, nil
+		}
+		return UsageStats{}, fmt.Errorf("error reading %s: %w", UsageFileName, err)
+	}
+
+	var usage UsageStats
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return UsageStats{}, fmt.Errorf("error parsing %s: %w", UsageFileName, err)
+	}
+	return usage, nil
+}
+
+// Save writes u to ~/.local/share/structura/usage.json
+func (u UsageStats) Save() error {
+	path, err := UsagePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", UsageFileName, err)
+	}
+	return nil
+}
+
+// UsageTracker accumulates the API usage of a run in memory until it is flushed to the
+// usage file. It is safe for concurrent use.
+type UsageTracker struct {
+	mu      sync.Mutex
+	run     UsageStats // Everything recorded by this run
+	unsaved UsageStats // Recorded since the last flush
+	saved   UsageStats // Read from the usage file, once loaded
+	loaded  bool
+}
+
+// NewUsageTracker creates a tracker with nothing recorded
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{}
+}
+
+// Record adds an API call to provider using tokens at an estimated cost in USD
+func (t *UsageTracker) Record(provider string, tokens int, cost float64) {
+	call := UsageStats{
+		TotalAPICallsMade:    1,
+		TotalTokensEstimated: tokens,
+		TotalCostEstimated:   cost,
+		Providers:            map[string]ProviderUsage{provider: {APICalls: 1, TokensEstimated: tokens, CostEstimated: cost}},
+		Since:                time.Now(),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.run = t.run.Add(call)
+	t.unsaved = t.unsaved.Add(call)
+}
+
+// Run returns the usage recorded by this run
+func (t *UsageTracker) Run() UsageStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// Adding nothing copies the provider map, which later calls would otherwise change
+	return t.run.Add(UsageStats{})
+}
+
+// AllTime returns the usage accumulated across runs, including what has not been flushed yet.
+// The usage file is read the first time; if it cannot be read, only this run's usage counts.
+func (t *UsageTracker) AllTime() UsageStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.loaded {
+		t.saved, _ = LoadUsage()
+		t.loaded = true
+	}
+	return t.saved.Add(t.unsaved)
+}
+
+// Flush adds the usage recorded since the last flush to the usage file. The file is read
+// again first, so runs at the same time do not overwrite each other's usage.
+func (t *UsageTracker) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.unsaved.TotalAPICallsMade == 0 {
+		return nil
+	}
+
+	saved, err := LoadUsage()
+	if err != nil {
+		return err
+	}
+	total := saved.Add(t.unsaved)
+	if err := total.Save(); err != nil {
+		return err
+	}
+	t.saved, t.loaded = total, true
+	t.unsaved = UsageStats{}
+	return nil
+}
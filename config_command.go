@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/types"
+	"github.com/spf13/cobra"
+)
+
+// newConfigCommand creates the command printing the effective configuration
+func newConfigCommand() *cobra.Command {
+	cmd := newCommand("config", "[dir]", "Show the configuration used for a project and the saved profiles")
+	cmd.Args = cobra.MaximumNArgs(1)
+	fs := cmd.Flags()
+	profile := fs.String("profile", "", "apply the named profile before printing")
+	noKeyring := fs.Bool("no-keyring", false, "do not use the OS keychain for API keys")
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		rootDir := "."
+		if len(args) > 0 {
+			rootDir = args[0]
+		}
+		rootDir, err := filepath.Abs(rootDir)
+		if err != nil {
+			return err
+		}
+
+		config.KeyringEnabled = !*noKeyring
+		cfg := config.NewConfig()
+
+		projectConfig, err := config.LoadProjectConfig(rootDir)
+		if err != nil {
+			return err
+		}
+		config.MergeProjectConfig(cfg, projectConfig)
+
+		profiles, err := config.LoadProfiles()
+		if err != nil {
+			return err
+		}
+		if *profile != "" {
+			p, ok := profiles[*profile]
+			if !ok {
+				return fmt.Errorf("profile %q not found", *profile)
+			}
+			p.Apply(cfg)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "API type\t%s\n", cfg.APIType)
+		fmt.Fprintf(w, "Model\t%s\n", cfg.APIModel)
+		fmt.Fprintf(w, "Endpoint\t%s\n", cfg.GetActiveEndpoint())
+		for _, apiType := range types.APITypes() {
+			status := "not set"
+			if cfg.GetAPIKey(apiType) != "" {
+				status = "set"
+			}
+			fmt.Fprintf(w, "API key (%s)\t%s\n", apiType, status)
+		}
+		fmt.Fprintf(w, "Output directory\t%s\n", cfg.OutputDir)
+		fmt.Fprintf(w, "Documentation style\t%s\n", cfg.DocumentationStyle)
+		fmt.Fprintf(w, "Max retries\t%d\n", cfg.MaxRetries)
+		fmt.Fprintf(w, "Max input tokens\t%d\n", cfg.MaxInputTokens)
+		fmt.Fprintf(w, "Max concurrent requests\t%d\n", cfg.MaxConcurrentRequests)
+		fmt.Fprintf(w, "OS keychain\t%t\n", cfg.UseKeyring)
+		if projectConfig != nil {
+			fmt.Fprintf(w, "Project config\t%s\n", filepath.Join(rootDir, config.ProjectConfigFileName))
+		}
+		if path, err := config.ProfilesPath(); err == nil {
+			fmt.Fprintf(w, "Profiles file\t%s\n", path)
+		}
+		fmt.Fprintf(w, "Profiles\t%s\n", strings.Join(profiles.Names(), ", "))
+		return w.Flush()
+	}
+	return cmd
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/spf13/cobra"
+)
+
+// Kinds of change diff reports, as single letters like git diff --name-status
+const (
+	diffAdded         = "A" // Not documented yet
+	diffModified      = "M" // Source changed since it was documented
+	diffDeleted       = "D" // Documented, but the source is gone
+	diffPromptVersion = "P" // Unchanged, but documented with outdated prompts
+)
+
+// sourceChange is a source file whose documentation a generate run would update
+type sourceChange struct {
+	kind    string
+	relPath string
+}
+
+// newDiffCommand creates the command listing source files changed since they were documented
+func newDiffCommand() *cobra.Command {
+	cmd := newCommand("diff", "[dir]", "List the source files added, modified or deleted since their documentation was generated")
+	cmd.Args = cobra.MaximumNArgs(1)
+	fs := cmd.Flags()
+	output := fs.String("output", "", "directory holding the documentation (default: output_dir from .structura.yaml)")
+	projectType := fs.String("type", "", "project type (default: detected from the project)")
+	var excludePaths stringList
+	fs.Var(&excludePaths, "exclude-path", excludePathUsage)
+	redocumentPromptVersion := fs.String("re-document-prompt-version", "", redocumentPromptVersionUsage)
+	exitCode := fs.Bool("exit-code", false, "exit with status 1 when any file changed, like git diff --exit-code")
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		rootDir := "."
+		if len(args) > 0 {
+			rootDir = args[0]
+		}
+		rootDir, err := filepath.Abs(rootDir)
+		if err != nil {
+			return err
+		}
+
+		cfg := config.NewConfig()
+		projectConfig, err := config.LoadProjectConfig(rootDir)
+		if err != nil {
+			return err
+		}
+		config.MergeProjectConfig(cfg, projectConfig)
+		cfg.RedocumentPromptVersion = *redocumentPromptVersion
+
+		outputDir := *output
+		if outputDir == "" {
+			outputDir = cfg.OutputDir
+		}
+		if outputDir == "" {
+			return fmt.Errorf("no output directory: pass --output or set output_dir in %s", config.ProjectConfigFileName)
+		}
+		if _, err := os.Stat(filepath.Join(outputDir, filehandler.ChecksumFileName)); err != nil {
+			return fmt.Errorf("no %s in %s: generate the documentation first", filehandler.ChecksumFileName, outputDir)
+		}
+		store, err := filehandler.LoadChecksumStore(outputDir)
+		if err != nil {
+			return err
+		}
+
+		fileHandler := filehandler.NewFileHandler()
+		selectedType := *projectType
+		if selectedType == "" && projectConfig != nil {
+			selectedType = projectConfig.ProjectType
+		}
+		if selectedType == "" {
+			fileHandler.SetProjectType(filehandler.DetectProjectType(rootDir))
+		} else {
+			fileHandler.SetProjectType(filehandler.ProjectType(selectedType))
+		}
+		fileHandler.ExcludePaths = excludePaths
+
+		files, err := fileHandler.TraverseDirectoryConcurrent(rootDir)
+		if err != nil {
+			return fmt.Errorf("failed to traverse directory: %w", err)
+		}
+
+		changes := diffSources(cfg, store, rootDir, files)
+		if len(changes) == 0 {
+			fmt.Printf("The documentation of all %d files is up to date\n", len(store.Paths()))
+			return nil
+		}
+		for _, change := range changes {
+			fmt.Printf("%s\t%s\n", change.kind, change.relPath)
+		}
+		if *exitCode {
+			return errChanged
+		}
+		return nil
+	}
+	return cmd
+}
+
+// diffSources compares files with the checksums their documentation was generated from. It
+// returns the files that are new, modified or documented with outdated prompts, in the order
+// of files, followed by the recorded files that no longer exist in rootDir.
+func diffSources(cfg *config.Config, store *filehandler.ChecksumStore, rootDir string, files []filehandler.FileInfo) []sourceChange {
+	var changes []sourceChange
+	seen := make(map[string]bool)
+	for _, file := range files {
+		if file.IsDir || file.TooLarge() {
+			continue
+		}
+		relPath, err := filepath.Rel(rootDir, file.Path)
+		if err != nil {
+			continue
+		}
+		seen[relPath] = true
+
+		entry, ok := store.Get(relPath)
+		switch {
+		case !ok:
+			changes = append(changes, sourceChange{diffAdded, relPath})
+		case entry.Checksum != file.Checksum:
+			changes = append(changes, sourceChange{diffModified, relPath})
+		case cfg.OutdatedPromptVersion(entry.PromptVersion):
+			changes = append(changes, sourceChange{diffPromptVersion, relPath})
+		}
+	}
+
+	// Files left out of the traversal by ignore rules are not deleted
+	for _, relPath := range store.Paths() {
+		if _, err := os.Stat(filepath.Join(rootDir, relPath)); !seen[relPath] && os.IsNotExist(err) {
+			changes = append(changes, sourceChange{diffDeleted, relPath})
+		}
+	}
+	return changes
+}
+
+// errChanged is returned by diff --exit-code when any file is listed. The files have already
+// been printed.
+var errChanged = errors.New("documentation out of date")
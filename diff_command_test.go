@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestDiffSources(t *testing.T) {
+	root := t.TempDir()
+	outputDir := t.TempDir()
+	sources := map[string]string{
+		"new.go":       "package main\n",
+		"modified.go":  "package main\n\nfunc changed() {}\n",
+		"unchanged.go": "package main\n\nfunc same() {}\n",
+		"outdated.go":  "package main\n\nfunc old() {}\n",
+		"ignored.log":  "not documented\n",
+	}
+	for name, content := range sources {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewConfig()
+	store, err := filehandler.LoadChecksumStore(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded := []struct {
+		path, content, promptVersion string
+	}{
+		{"modified.go", "package main\n", cfg.PromptVersion},
+		{"unchanged.go", sources["unchanged.go"], cfg.PromptVersion},
+		{"outdated.go", sources["outdated.go"], "v0"},
+		{"deleted.go", "package main\n", cfg.PromptVersion},
+		{"ignored.log", "changed since, but ignored\n", cfg.PromptVersion},
+	}
+	for _, r := range recorded {
+		if err := store.Set(r.path, filehandler.Checksum(r.content), r.promptVersion); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	fileHandler := filehandler.NewFileHandler()
+	files, err := fileHandler.TraverseDirectory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []sourceChange{
+		{diffAdded, "new.go"},
+		{diffModified, "modified.go"},
+		{diffPromptVersion, "outdated.go"},
+		{diffDeleted, "deleted.go"},
+	}
+	got := diffSources(cfg, store, root, files)
+	if !reflect.DeepEqual(changesByPath(got), changesByPath(want)) {
+		t.Errorf("diffSources() = %v, want %v", got, want)
+	}
+}
+
+// changesByPath indexes changes by path, as traversal order is not part of the comparison
+func changesByPath(changes []sourceChange) map[string]string {
+	byPath := make(map[string]string, len(changes))
+	for _, change := range changes {
+		byPath[change.relPath] = change.kind
+	}
+	return byPath
+}
@@ -0,0 +1,101 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// ArchitectureFileName is the name of the architecture overview written to the output directory
+const ArchitectureFileName = "ARCHITECTURE.md"
+
+// Token budgets for the parts of the architecture prompt, 8000 tokens in total
+const (
+	architectureTreeTokens      = 2000
+	architectureSummariesTokens = 6000
+)
+
+// maxSummaryChars is the maximum length of a file's one-line summary
+const maxSummaryChars = 200
+
+// GenerateArchitecture asks the API to write ARCHITECTURE.md. tree is the project's directory
+// tree, as rendered by RenderTree, and files holds the generated documentation of each file,
+// with Path set to the source file. Only the first sentence of each file's documentation is
+// sent, and the inputs are truncated to fit the prompt.
+func GenerateArchitecture(ctx context.Context, tree string, files []filehandler.FileInfo, client api.DocumentationClient) (string, error) {
+	var summaries strings.Builder
+	for _, file := range files {
+		if summary := FirstSentence(file.Content); summary != "" {
+			summaries.WriteString(fmt.Sprintf("- %s: %s\n", file.Path, summary))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Based on these file summaries, write a structured architecture overview including: " +
+		"major components, data flow, key design patterns, and entry points. Format as Markdown " +
+		"starting with the heading \"# Architecture\".\n\n")
+	if tree != "" {
+		sb.WriteString("# Directory tree\n\n```\n" + tokenizer.Truncate(tree, architectureTreeTokens) + "\n```\n\n")
+	}
+	sb.WriteString("# File summaries\n\n" + tokenizer.Truncate(summaries.String(), architectureSummariesTokens) + "\n")
+
+	architecture, err := client.Complete(ctx, sb.String())
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(architecture) + "\n", nil
+}
+
+// FirstSentence returns the first sentence of the first prose paragraph of a Markdown
+// document, skipping headings, code blocks, lists and tables. It is cut short at
+// maxSummaryChars characters, and empty if the document has no prose.
+func FirstSentence(markdown string) string {
+	var paragraph []string
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		if trimmed == "" || isNotProse(trimmed) {
+			if len(paragraph) > 0 {
+				break
+			}
+			continue
+		}
+		paragraph = append(paragraph, trimmed)
+	}
+
+	text := strings.Join(paragraph, " ")
+	for i := 0; i < len(text)-1; i++ {
+		if (text[i] == '.' || text[i] == '!' || text[i] == '?') && text[i+1] == ' ' {
+			text = text[:i+1]
+			break
+		}
+	}
+
+	if runes := []rune(text); len(runes) > maxSummaryChars {
+		text = strings.TrimSpace(string(runes[:maxSummaryChars-1])) + "…"
+	}
+	return text
+}
+
+// isNotProse reports whether a line is a heading, list item, quote, table row, rule or HTML
+func isNotProse(line string) bool {
+	for _, prefix := range []string{"#", "- ", "* ", "+ ", ">", "|", "---", "***", "<"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+// Live pages of structura serve: reload when the documentation changes, and filter the
+// sidebar with the search box, using search-index.json when the output directory has one.
+(function () {
+  var script = document.currentScript;
+  var events = new EventSource(new URL("events", script.src));
+  events.onmessage = function () { location.reload(); };
+
+  var box = document.getElementById("search");
+  if (!box) { return; }
+
+  // Sidebar links by the Markdown page they show, e.g. "pkg/main.go.md"
+  var links = Array.prototype.slice.call(document.querySelectorAll("nav li a"));
+  var root = new URL("..", script.src).pathname;
+  function pageOf(link) {
+    return decodeURIComponent(link.pathname.slice(root.length)).replace(/\.html$/, ".md");
+  }
+
+  var index = null;
+  fetch(new URL("../search-index.json", script.src))
+    .then(function (resp) { return resp.ok ? resp.json() : null; })
+    .then(function (entries) { index = entries; })
+    .catch(function () {});
+
+  function matchingPages(query) {
+    var pages = {};
+    (index || []).forEach(function (entry) {
+      var text = [entry.file, entry.title, (entry.headings || []).join(" "), entry.body_preview].join(" ");
+      if (text.toLowerCase().indexOf(query) >= 0) { pages[entry.file] = true; }
+    });
+    return pages;
+  }
+
+  box.addEventListener("input", function () {
+    var query = box.value.trim().toLowerCase();
+    var pages = query ? matchingPages(query) : {};
+    links.forEach(function (link) {
+      var page = pageOf(link);
+      var match = !query || pages[page] || page.toLowerCase().indexOf(query) >= 0;
+      link.parentNode.style.display = match ? "" : "none";
+    });
+    document.querySelectorAll("nav details").forEach(function (details) {
+      var visible = details.querySelector("li:not([style*='none'])");
+      details.style.display = visible ? "" : "none";
+      if (query && visible) { details.open = true; }
+    });
+  });
+})();
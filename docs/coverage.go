@@ -0,0 +1,94 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/linter"
+)
+
+// CoverageFileName is the name of the documentation coverage report written to the output directory
+const CoverageFileName = "COVERAGE.md"
+
+// FileFailure is a file that could not be documented
+type FileFailure struct {
+	Path  string // Slash-separated path relative to the project root
+	Error string
+}
+
+// ProcessingStats are the outcomes of a documentation run, reported in COVERAGE.md
+type ProcessingStats struct {
+	Discovered       int // Files found by the traversal
+	Documented       int // Files documented in this run
+	SkippedIgnored   int // Files and directories left out by the ignore rules
+	SkippedUnchanged int // Files whose documentation was up to date
+	SkippedTooLarge  int // Files too large to document
+	Failures         []FileFailure
+
+	Scores   []linter.FileScore // Documentation scores of the documented files
+	MinScore float64            // Scores below this are flagged for review
+}
+
+// Failed returns the number of files that could not be documented
+func (s ProcessingStats) Failed() int {
+	return len(s.Failures)
+}
+
+// Percentage returns the share of discovered files with up-to-date documentation, from 0 to
+// 100. A run discovering no files is fully covered.
+func (s ProcessingStats) Percentage() float64 {
+	if s.Discovered == 0 {
+		return 100
+	}
+	return float64(s.Documented+s.SkippedUnchanged) * 100 / float64(s.Discovered)
+}
+
+// GenerateCoverageReport writes COVERAGE.md to outputDir: how many of the discovered files
+// were documented, skipped or failed, the error of each failed file, and the documentation
+// scores of the documented files
+func GenerateCoverageReport(outputDir string, stats ProcessingStats) error {
+	var sb strings.Builder
+	sb.WriteString("# Documentation Coverage\n\n")
+	sb.WriteString(fmt.Sprintf("%.1f%% of the %d files discovered have up-to-date documentation.\n\n", stats.Percentage(), stats.Discovered))
+
+	sb.WriteString("| Files | Count |\n|-------|-------|\n")
+	for _, row := range []struct {
+		label string
+		count int
+	}{
+		{"Discovered", stats.Discovered},
+		{"Documented", stats.Documented},
+		{"Skipped (ignored)", stats.SkippedIgnored},
+		{"Skipped (unchanged)", stats.SkippedUnchanged},
+		{"Skipped (too large)", stats.SkippedTooLarge},
+		{"Failed", stats.Failed()},
+	} {
+		sb.WriteString(fmt.Sprintf("| %s | %d |\n", row.label, row.count))
+	}
+
+	if len(stats.Failures) > 0 {
+		failures := append([]FileFailure(nil), stats.Failures...)
+		sort.SliceStable(failures, func(i, j int) bool { return failures[i].Path < failures[j].Path })
+
+		sb.WriteString("\n## Failed Files\n\n| File | Error |\n|------|-------|\n")
+		for _, failure := range failures {
+			sb.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeTableCell(failure.Path), escapeTableCell(failure.Error)))
+		}
+	}
+
+	sb.WriteString("\n" + linter.ScoreReport(stats.Scores, stats.MinScore))
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, CoverageFileName), []byte(sb.String()), 0644)
+}
+
+// escapeTableCell makes text safe to put in a Markdown table cell
+func escapeTableCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.ReplaceAll(text, "|", "\\|")
+}
@@ -0,0 +1,269 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+var (
+	// camelCaseSymbol matches identifiers with at least two words, e.g. NewClient or parseFile
+	camelCaseSymbol = regexp.MustCompile(`\b[A-Za-z][a-z0-9]*(?:[A-Z][a-z0-9]*)+\b`)
+	// snakeCaseSymbol matches identifiers with at least two words, e.g. load_config
+	snakeCaseSymbol = regexp.MustCompile(`\b[a-z][a-z0-9]*(?:_[a-z0-9]+)+\b`)
+	// quotedSymbol matches a backtick-quoted identifier, e.g. `Client`, which is taken as a
+	// symbol whatever its case
+	quotedSymbol = regexp.MustCompile("`([A-Za-z_][A-Za-z0-9_]*)`")
+	// markdownLinkOrCode matches existing links and inline code spans, which are not linked inside
+	markdownLinkOrCode = regexp.MustCompile("\\[[^\\]]*\\]\\([^)]*\\)|`[^`]*`")
+)
+
+// camelCaseExtensions are the file extensions of languages naming functions and types in
+// camelCase or PascalCase, where snake_case words in headings are not symbols
+var camelCaseExtensions = map[string]bool{
+	".go": true, ".java": true, ".kt": true, ".kts": true, ".swift": true, ".cs": true,
+	".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true, ".cjs": true,
+	".dart": true, ".scala": true,
+}
+
+// BuildCrossReferenceIndex maps the symbols documented in outputDir to the documentation
+// defining them. files are the documented files, with paths relative to the project root;
+// docPath returns the path of a file's documentation relative to outputDir.
+//
+// Symbols are the function and type names in the headings of each file's documentation:
+// backtick-quoted identifiers, and unquoted identifiers of two or more words in the naming
+// style of the file's language. Go, Java, JavaScript and other camelCase languages only use
+// camelCase and PascalCase names; Python, Ruby, Rust and the others also use snake_case.
+// Values are slash-separated documentation paths relative to outputDir, followed by the
+// anchor of the heading, e.g. "api/client.go.md#newclient". A heading that is only the
+// symbol, or quotes it, is preferred to other headings mentioning it. Symbols found by
+// equally ranked headings in several files are left out since they cannot be linked
+// unambiguously.
+func BuildCrossReferenceIndex(outputDir string, files []filehandler.FileInfo, docPath func(relPath string) string) (map[string]string, error) {
+	index := make(map[string]string)
+	ranks := make(map[string]int)
+	ambiguous := make(map[string]bool)
+
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		doc := filepath.ToSlash(docPath(file.Path))
+		content, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(doc)))
+		if os.IsNotExist(err) {
+			continue // Failed or skipped files have no documentation
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading documentation of %s: %w", file.Path, err)
+		}
+
+		camelCaseOnly := camelCaseExtensions[strings.ToLower(path.Ext(file.Path))]
+		for symbol, heading := range headingSymbols(string(content), camelCaseOnly) {
+			// A heading naming only the symbol defines it; other mentions only count without one
+			switch best, ok := ranks[symbol]; {
+			case !ok || heading.rank > best:
+				delete(ambiguous, symbol)
+			case heading.rank == best:
+				ambiguous[symbol] = true
+				continue
+			default:
+				continue
+			}
+			ranks[symbol] = heading.rank
+			index[symbol] = doc + "#" + heading.anchor
+		}
+	}
+
+	for symbol := range ambiguous {
+		delete(index, symbol)
+	}
+	return index, nil
+}
+
+// LinkCrossReferences builds the cross reference index of the documentation in outputDir and
+// injects it into each file's documentation. files and docPath are as for BuildCrossReferenceIndex.
+func LinkCrossReferences(outputDir string, files []filehandler.FileInfo, docPath func(relPath string) string) error {
+	index, err := BuildCrossReferenceIndex(outputDir, files, docPath)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		doc := filepath.ToSlash(docPath(file.Path))
+		docFile := filepath.Join(outputDir, filepath.FromSlash(doc))
+		content, err := os.ReadFile(docFile)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading documentation of %s: %w", file.Path, err)
+		}
+
+		linked := InjectCrossReferences(string(content), RelativeCrossReferences(index, doc))
+		if linked == string(content) {
+			continue
+		}
+		if err := os.WriteFile(docFile, []byte(linked), 0644); err != nil {
+			return fmt.Errorf("error writing cross references to %s: %w", docFile, err)
+		}
+	}
+	return nil
+}
+
+// symbolHeading is the heading a symbol is found in
+type symbolHeading struct {
+	anchor string
+	rank   int // 2 if the symbol is quoted or the whole heading, 1 otherwise
+}
+
+// headingSymbols returns the symbols named in the headings of doc, mapped to the highest
+// ranked heading naming them, the first of equal ones. Code blocks are skipped.
+func headingSymbols(doc string, camelCaseOnly bool) map[string]symbolHeading {
+	symbols := make(map[string]symbolHeading)
+	add := func(symbol, anchor string, rank int) {
+		if existing, ok := symbols[symbol]; !ok || rank > existing.rank {
+			symbols[symbol] = symbolHeading{anchor: anchor, rank: rank}
+		}
+	}
+
+	inFence := false
+	for _, line := range strings.Split(doc, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		match := headingLine.FindStringSubmatch(line)
+		if inFence || match == nil {
+			continue
+		}
+
+		text := match[2]
+		anchor := slugify(text)
+		whole := strings.TrimSuffix(text, "()")
+		rank := func(symbol string) int {
+			if symbol == whole {
+				return 2
+			}
+			return 1
+		}
+		for _, quoted := range quotedSymbol.FindAllStringSubmatch(text, -1) {
+			add(quoted[1], anchor, 2)
+		}
+		for _, symbol := range camelCaseSymbol.FindAllString(text, -1) {
+			add(symbol, anchor, rank(symbol))
+		}
+		if !camelCaseOnly {
+			for _, symbol := range snakeCaseSymbol.FindAllString(text, -1) {
+				add(symbol, anchor, rank(symbol))
+			}
+		}
+	}
+	return symbols
+}
+
+// RelativeCrossReferences rewrites the targets of a cross reference index, relative to the
+// output directory, to be relative to the documentation at docPath, and leaves out the
+// symbols docPath defines itself
+func RelativeCrossReferences(index map[string]string, docPath string) map[string]string {
+	docPath = filepath.ToSlash(docPath)
+	dir := path.Dir(docPath)
+
+	relative := make(map[string]string, len(index))
+	for symbol, target := range index {
+		targetPath, anchor, _ := strings.Cut(target, "#")
+		if targetPath == docPath {
+			continue
+		}
+		rel, err := filepath.Rel(dir, targetPath)
+		if err != nil {
+			continue
+		}
+		link := markdownLink(filepath.ToSlash(rel))
+		if !strings.HasPrefix(link, "../") {
+			link = "./" + link
+		}
+		relative[symbol] = link + "#" + anchor
+	}
+	return relative
+}
+
+// InjectCrossReferences links the first mention of each symbol in index within docContent
+// to its target, e.g. [NewClient](./api/client.go.md#newclient). A mention is a whole word,
+// or a code span holding only the symbol. Headings, code blocks, existing links and longer
+// code spans are left alone, and symbols already linked count as mentioned.
+// Use RelativeCrossReferences for documentation outside the root of the output directory.
+func InjectCrossReferences(docContent string, index map[string]string) string {
+	if len(index) == 0 {
+		return docContent
+	}
+
+	// Longer symbols are tried first so that no symbol matches within another
+	symbols := make([]string, 0, len(index))
+	for symbol := range index {
+		symbols = append(symbols, regexp.QuoteMeta(symbol))
+	}
+	sort.Slice(symbols, func(i, j int) bool { return len(symbols[i]) > len(symbols[j]) })
+	mention := regexp.MustCompile(`\b(?:` + strings.Join(symbols, "|") + `)\b`)
+
+	linked := make(map[string]bool)
+	for _, existing := range markdownLinkOrCode.FindAllString(docContent, -1) {
+		if strings.HasPrefix(existing, "[") {
+			text := strings.Trim(existing[1:strings.Index(existing, "]")], "`")
+			linked[text] = true
+		}
+	}
+
+	lines := strings.SplitAfter(docContent, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || headingLine.MatchString(strings.TrimRight(line, "\n")) {
+			continue
+		}
+		lines[i] = injectLine(line, index, mention, linked)
+	}
+	return strings.Join(lines, "")
+}
+
+// injectLine links the first mentions in one line, outside existing links and code spans
+// other than those holding only a symbol
+func injectLine(line string, index map[string]string, mention *regexp.Regexp, linked map[string]bool) string {
+	linkText := func(text string) string {
+		return mention.ReplaceAllStringFunc(text, func(symbol string) string {
+			if linked[symbol] {
+				return symbol
+			}
+			linked[symbol] = true
+			return fmt.Sprintf("[%s](%s)", symbol, index[symbol])
+		})
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, loc := range markdownLinkOrCode.FindAllStringIndex(line, -1) {
+		sb.WriteString(linkText(line[last:loc[0]]))
+
+		span := line[loc[0]:loc[1]]
+		symbol := strings.Trim(span, "`")
+		if _, ok := index[symbol]; ok && span == "`"+symbol+"`" && !linked[symbol] {
+			linked[symbol] = true
+			span = fmt.Sprintf("[%s](%s)", span, index[symbol])
+		}
+		sb.WriteString(span)
+		last = loc[1]
+	}
+	sb.WriteString(linkText(line[last:]))
+	return sb.String()
+}
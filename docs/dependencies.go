@@ -0,0 +1,183 @@
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Dependency is a package a project depends on, as declared in its manifest
+type Dependency struct {
+	Package string
+	Version string // Version or version specifier, e.g. v1.2.3, ^4.17.0 or >=2.0,<3
+	Type    string // For go.mod, "direct" or "indirect"
+}
+
+// GoModule is the content of a go.mod file relevant to setting up the project
+type GoModule struct {
+	Module       string
+	GoVersion    string
+	Dependencies []Dependency
+}
+
+// ParseGoMod reads the module, go and require directives of a go.mod file. Requirements marked
+// "// indirect" have the Type indirect; all others are direct.
+func ParseGoMod(content string) GoModule {
+	var mod GoModule
+	inRequire := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		indirect := strings.Contains(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inRequire {
+			if fields[0] == ")" {
+				inRequire = false
+			} else if len(fields) >= 2 {
+				mod.Dependencies = append(mod.Dependencies, goDependency(fields[0], fields[1], indirect))
+			}
+			continue
+		}
+
+		switch {
+		case fields[0] == "module" && len(fields) >= 2:
+			mod.Module = strings.Trim(fields[1], `"`)
+		case fields[0] == "go" && len(fields) >= 2:
+			mod.GoVersion = fields[1]
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require" && len(fields) >= 3:
+			mod.Dependencies = append(mod.Dependencies, goDependency(fields[1], fields[2], indirect))
+		}
+	}
+	return mod
+}
+
+// goDependency returns a requirement of a go.mod file
+func goDependency(path, version string, indirect bool) Dependency {
+	dependency := Dependency{Package: strings.Trim(path, `"`), Version: version, Type: "direct"}
+	if indirect {
+		dependency.Type = "indirect"
+	}
+	return dependency
+}
+
+// ParsePackageJSON returns the dependencies and devDependencies of a package.json file, each
+// sorted by package name
+func ParsePackageJSON(content string) (dependencies, devDependencies []Dependency, err error) {
+	var manifest struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return nil, nil, err
+	}
+	return sortedDependencies(manifest.Dependencies), sortedDependencies(manifest.DevDependencies), nil
+}
+
+// sortedDependencies returns the packages of a name to version map, sorted by name
+func sortedDependencies(versions map[string]string) []Dependency {
+	dependencies := make([]Dependency, 0, len(versions))
+	for name, version := range versions {
+		dependencies = append(dependencies, Dependency{Package: name, Version: version})
+	}
+	sort.Slice(dependencies, func(i, j int) bool { return dependencies[i].Package < dependencies[j].Package })
+	return dependencies
+}
+
+// ParseRequirements returns the packages of a pip requirements.txt file with their version
+// specifiers, e.g. ==2.31.0 or >=1.0,<2. Comments, options such as -r and -e, extras and
+// environment markers are left out. Packages without a specifier have the Version "any".
+func ParseRequirements(content string) []Dependency {
+	var dependencies []Dependency
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		name, version := line, "any"
+		if i := strings.Index(line, " @ "); i >= 0 {
+			// A direct reference, e.g. pkg @ https://example.com/pkg.zip
+			name, version = line[:i], strings.TrimSpace(line[i+2:])
+		} else if i := strings.IndexAny(line, "=<>!~"); i >= 0 {
+			name, version = line[:i], strings.ReplaceAll(line[i:], " ", "")
+		}
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		dependencies = append(dependencies, Dependency{Package: strings.TrimSpace(name), Version: version})
+	}
+	return dependencies
+}
+
+// DependencyTables renders the dependencies declared in a go.mod, package.json or
+// requirements.txt file as Markdown tables. It returns false for other files, and for files
+// that cannot be parsed, which are better shown as they are.
+func DependencyTables(fileName, content string) (string, bool) {
+	var sb strings.Builder
+	switch fileName {
+	case "go.mod":
+		mod := ParseGoMod(content)
+		if mod.Module == "" {
+			return "", false
+		}
+		sb.WriteString(fmt.Sprintf("Module: `%s`\n\n", mod.Module))
+		if mod.GoVersion != "" {
+			sb.WriteString(fmt.Sprintf("Go version: %s\n\n", mod.GoVersion))
+		}
+		writeDependencyTable(&sb, mod.Dependencies, true)
+
+	case "package.json":
+		dependencies, devDependencies, err := ParsePackageJSON(content)
+		if err != nil {
+			return "", false
+		}
+		sb.WriteString("#### Dependencies\n\n")
+		writeDependencyTable(&sb, dependencies, false)
+		sb.WriteString("#### Dev Dependencies\n\n")
+		writeDependencyTable(&sb, devDependencies, false)
+
+	case "requirements.txt":
+		writeDependencyTable(&sb, ParseRequirements(content), false)
+
+	default:
+		return "", false
+	}
+	return sb.String(), true
+}
+
+// writeDependencyTable writes a table of dependencies, with a Module Type column if withType is set
+func writeDependencyTable(sb *strings.Builder, dependencies []Dependency, withType bool) {
+	if len(dependencies) == 0 {
+		sb.WriteString("None.\n\n")
+		return
+	}
+
+	if withType {
+		sb.WriteString("| Package | Version | Module Type |\n|---------|---------|-------------|\n")
+	} else {
+		sb.WriteString("| Package | Version |\n|---------|---------|\n")
+	}
+	for _, dependency := range dependencies {
+		row := fmt.Sprintf("| `%s` | `%s` |", escapeTableCell(dependency.Package), escapeTableCell(dependency.Version))
+		if withType {
+			row += fmt.Sprintf(" %s |", dependency.Type)
+		}
+		sb.WriteString(row + "\n")
+	}
+	sb.WriteString("\n")
+}
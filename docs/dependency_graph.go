@@ -0,0 +1,383 @@
+package docs
+
+import (
+	"bufio"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+var (
+	pythonImportRegex     = regexp.MustCompile(`^\s*import\s+([\w.]+)`)
+	pythonFromImportRegex = regexp.MustCompile(`^\s*from\s+([\w.]+)\s+import\s`)
+	jsRequireRegex        = regexp.MustCompile(`require\(\s*['"]([^'"]+)['"]\s*\)`)
+	jsImportRegex         = regexp.MustCompile(`(?:import|export)\s+(?:[\w*{}\s,$]+\s+from\s+)?['"]([^'"]+)['"]`)
+)
+
+// jsExtensions are tried in order when resolving extensionless Node/React imports
+var jsExtensions = []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs"}
+
+// dependencyGraph is an adjacency list keyed by project-relative node names
+type dependencyGraph map[string]map[string]bool
+
+// addEdge records that from depends on to
+func (g dependencyGraph) addEdge(from, to string) {
+	if g[from] == nil {
+		g[from] = make(map[string]bool)
+	}
+	g[from][to] = true
+}
+
+// BuildDependencyGraph maps imports between the project's own files and returns a Mermaid graph.
+// Go projects are graphed per package, Python and Node/React projects per file. Imports of
+// external packages are left out. An empty string is returned for unsupported project types.
+func BuildDependencyGraph(files []filehandler.FileInfo, projectType filehandler.ProjectType) (string, error) {
+	var graph dependencyGraph
+
+	switch projectType {
+	case filehandler.ProjectTypeGo:
+		graph = buildGoGraph(files)
+	case filehandler.ProjectTypePython, filehandler.ProjectTypeDjango:
+		graph = buildPythonGraph(files)
+	case filehandler.ProjectTypeNode, filehandler.ProjectTypeReact, filehandler.ProjectTypeTypeScript:
+		graph = buildJSGraph(files)
+	default:
+		return "", nil
+	}
+
+	return renderMermaid(graph), nil
+}
+
+// buildGoGraph builds a package-level graph from the import declarations of .go files
+func buildGoGraph(files []filehandler.FileInfo) dependencyGraph {
+	root := commonDir(files)
+	graph := make(dependencyGraph)
+
+	// The module path identifies which imports belong to this project
+	modulePath := ""
+	for _, file := range files {
+		if filepath.Base(file.Path) == "go.mod" {
+			modulePath = parseModulePath(file.Content)
+			break
+		}
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if file.IsDir || filepath.Ext(file.Path) != ".go" {
+			continue
+		}
+
+		parsed, err := parser.ParseFile(fset, file.Path, file.Content, parser.ImportsOnly)
+		if err != nil {
+			// Skip files that do not parse rather than failing the whole graph
+			continue
+		}
+
+		from := relativeName(root, filepath.Dir(file.Path))
+		for _, imp := range parsed.Imports {
+			importPath, err := strconv.Unquote(imp.Path.Value)
+			if err != nil || modulePath == "" {
+				continue
+			}
+
+			if importPath != modulePath && !strings.HasPrefix(importPath, modulePath+"/") {
+				continue
+			}
+
+			to := strings.TrimPrefix(strings.TrimPrefix(importPath, modulePath), "/")
+			if to == "" {
+				to = "."
+			}
+			if to != from {
+				graph.addEdge(from, to)
+			}
+		}
+	}
+
+	return graph
+}
+
+// buildPythonGraph builds a file-level graph from import and from-import statements
+func buildPythonGraph(files []filehandler.FileInfo) dependencyGraph {
+	root := commonDir(files)
+	graph := make(dependencyGraph)
+
+	known := make(map[string]bool)
+	for _, file := range files {
+		known[relativeName(root, file.Path)] = true
+	}
+
+	for _, file := range files {
+		if file.IsDir || filepath.Ext(file.Path) != ".py" {
+			continue
+		}
+
+		from := relativeName(root, file.Path)
+		scanner := bufio.NewScanner(strings.NewReader(file.Content))
+		for scanner.Scan() {
+			line := scanner.Text()
+
+			var module string
+			if match := pythonFromImportRegex.FindStringSubmatch(line); match != nil {
+				module = match[1]
+			} else if match := pythonImportRegex.FindStringSubmatch(line); match != nil {
+				module = match[1]
+			} else {
+				continue
+			}
+
+			if to := resolvePythonModule(from, module, known); to != "" && to != from {
+				graph.addEdge(from, to)
+			}
+		}
+	}
+
+	return graph
+}
+
+// resolvePythonModule maps a dotted module name to a known project file, or "" if it is external
+func resolvePythonModule(from, module string, known map[string]bool) string {
+	base := ""
+	if strings.HasPrefix(module, ".") {
+		// Relative import: each leading dot beyond the first moves up one package
+		base = path.Dir(from)
+		trimmed := strings.TrimLeft(module, ".")
+		for i := 1; i < len(module)-len(trimmed); i++ {
+			base = path.Dir(base)
+		}
+		module = trimmed
+	}
+
+	modulePath := path.Join(base, strings.ReplaceAll(module, ".", "/"))
+	for _, candidate := range []string{modulePath + ".py", path.Join(modulePath, "__init__.py")} {
+		if known[candidate] {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// buildJSGraph builds a file-level graph from require() calls and import/export statements
+func buildJSGraph(files []filehandler.FileInfo) dependencyGraph {
+	root := commonDir(files)
+	graph := make(dependencyGraph)
+
+	known := make(map[string]bool)
+	for _, file := range files {
+		known[relativeName(root, file.Path)] = true
+	}
+
+	for _, file := range files {
+		ext := filepath.Ext(file.Path)
+		if file.IsDir || !isJSExtension(ext) {
+			continue
+		}
+
+		from := relativeName(root, file.Path)
+		var specifiers []string
+		for _, match := range jsRequireRegex.FindAllStringSubmatch(file.Content, -1) {
+			specifiers = append(specifiers, match[1])
+		}
+		for _, match := range jsImportRegex.FindAllStringSubmatch(file.Content, -1) {
+			specifiers = append(specifiers, match[1])
+		}
+
+		for _, specifier := range specifiers {
+			// Only relative specifiers refer to project files
+			if !strings.HasPrefix(specifier, "./") && !strings.HasPrefix(specifier, "../") {
+				continue
+			}
+
+			if to := resolveJSModule(path.Join(path.Dir(from), specifier), known); to != "" && to != from {
+				graph.addEdge(from, to)
+			}
+		}
+	}
+
+	return graph
+}
+
+// resolveJSModule maps an import target to a known project file, trying extensions and index files
+func resolveJSModule(target string, known map[string]bool) string {
+	if known[target] {
+		return target
+	}
+	for _, ext := range jsExtensions {
+		if known[target+ext] {
+			return target + ext
+		}
+	}
+	for _, ext := range jsExtensions {
+		if index := path.Join(target, "index"+ext); known[index] {
+			return index
+		}
+	}
+	return ""
+}
+
+// isJSExtension reports whether ext belongs to a JavaScript or TypeScript source file
+func isJSExtension(ext string) bool {
+	for _, jsExt := range jsExtensions {
+		if ext == jsExt {
+			return true
+		}
+	}
+	return false
+}
+
+// renderMermaid renders the graph as a Mermaid "graph LR" code block.
+// Edges that are part of an import cycle are labelled "cycle".
+func renderMermaid(graph dependencyGraph) string {
+	// Collect and sort nodes so the output is stable between runs
+	nodeSet := make(map[string]bool)
+	for from, targets := range graph {
+		nodeSet[from] = true
+		for to := range targets {
+			nodeSet[to] = true
+		}
+	}
+
+	nodes := make([]string, 0, len(nodeSet))
+	for node := range nodeSet {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+
+	ids := make(map[string]string, len(nodes))
+	for i, node := range nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+	}
+
+	components := stronglyConnectedComponents(nodes, graph)
+
+	var sb strings.Builder
+	sb.WriteString("```mermaid\ngraph LR\n")
+	for _, node := range nodes {
+		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", ids[node], strings.ReplaceAll(node, "\"", "'")))
+	}
+	for _, from := range nodes {
+		targets := make([]string, 0, len(graph[from]))
+		for to := range graph[from] {
+			targets = append(targets, to)
+		}
+		sort.Strings(targets)
+
+		for _, to := range targets {
+			if components[from] == components[to] {
+				sb.WriteString(fmt.Sprintf("    %s -->|cycle| %s\n", ids[from], ids[to]))
+			} else {
+				sb.WriteString(fmt.Sprintf("    %s --> %s\n", ids[from], ids[to]))
+			}
+		}
+	}
+	sb.WriteString("```\n")
+
+	return sb.String()
+}
+
+// stronglyConnectedComponents assigns each node a component number using Tarjan's algorithm.
+// Two nodes share a component exactly when they import each other directly or indirectly.
+func stronglyConnectedComponents(nodes []string, graph dependencyGraph) map[string]int {
+	index := 0
+	indices := make(map[string]int)
+	lowLinks := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	components := make(map[string]int)
+	component := 0
+
+	var visit func(node string)
+	visit = func(node string) {
+		indices[node] = index
+		lowLinks[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for to := range graph[node] {
+			if _, visited := indices[to]; !visited {
+				visit(to)
+				if lowLinks[to] < lowLinks[node] {
+					lowLinks[node] = lowLinks[to]
+				}
+			} else if onStack[to] && indices[to] < lowLinks[node] {
+				lowLinks[node] = indices[to]
+			}
+		}
+
+		if lowLinks[node] == indices[node] {
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				components[top] = component
+				if top == node {
+					break
+				}
+			}
+			component++
+		}
+	}
+
+	for _, node := range nodes {
+		if _, visited := indices[node]; !visited {
+			visit(node)
+		}
+	}
+
+	return components
+}
+
+// parseModulePath extracts the module path from the contents of a go.mod file
+func parseModulePath(goMod string) string {
+	for _, line := range strings.Split(goMod, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), "\"")
+		}
+	}
+	return ""
+}
+
+// commonDir returns the deepest directory containing all of the given files
+func commonDir(files []filehandler.FileInfo) string {
+	root := ""
+	for _, file := range files {
+		dir := filepath.Dir(file.Path)
+		if file.IsDir {
+			dir = file.Path
+		}
+
+		if root == "" {
+			root = dir
+			continue
+		}
+
+		for root != dir && !strings.HasPrefix(dir, root+string(filepath.Separator)) {
+			parent := filepath.Dir(root)
+			if parent == root {
+				break
+			}
+			root = parent
+		}
+	}
+	return root
+}
+
+// relativeName returns path relative to root using forward slashes
+func relativeName(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
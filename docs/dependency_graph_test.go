@@ -0,0 +1,64 @@
+package docs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestBuildDependencyGraphGoCycle(t *testing.T) {
+	files := []filehandler.FileInfo{
+		{Path: "/project/go.mod", Content: "module example.com/app\n\ngo 1.22\n"},
+		{Path: "/project/a/a.go", Content: "package a\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/b\"\n)\n"},
+		{Path: "/project/b/b.go", Content: "package b\n\nimport \"example.com/app/a\"\n"},
+	}
+
+	graph, err := BuildDependencyGraph(files, filehandler.ProjectTypeGo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nodes are numbered in sorted order, so a is n0 and b is n1
+	for _, want := range []string{`n0["a"]`, `n1["b"]`, "n0 -->|cycle| n1", "n1 -->|cycle| n0"} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("graph is missing %q:\n%s", want, graph)
+		}
+	}
+	if strings.Contains(graph, "fmt") {
+		t.Errorf("graph includes the standard library:\n%s", graph)
+	}
+}
+
+func TestBuildPythonGraph(t *testing.T) {
+	files := []filehandler.FileInfo{
+		{Path: "/project/main.py", Content: "import os\nfrom app.utils import helper\n"},
+		{Path: "/project/app/__init__.py"},
+		{Path: "/project/app/utils.py", Content: "from .models import User\n"},
+		{Path: "/project/app/models.py", Content: "import requests\n"},
+	}
+
+	want := dependencyGraph{
+		"main.py":      {"app/utils.py": true},
+		"app/utils.py": {"app/models.py": true},
+	}
+	if got := buildPythonGraph(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("buildPythonGraph() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildJSGraph(t *testing.T) {
+	files := []filehandler.FileInfo{
+		{Path: "/project/src/index.js", Content: "import React from 'react'\nimport App from './App'\nconst util = require('../lib/util')\n"},
+		{Path: "/project/src/App.jsx", Content: "export default function App() {}\n"},
+		{Path: "/project/lib/util/index.ts", Content: "export const id = (x) => x\n"},
+	}
+
+	want := dependencyGraph{
+		"src/index.js": {"src/App.jsx": true, "lib/util/index.ts": true},
+	}
+	if got := buildJSGraph(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("buildJSGraph() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// SummaryFileName is the name of the per-package summary written to the output directory
+const SummaryFileName = "README_SUMMARY.md"
+
+// summaryInputTokens is the token budget shared by all file documentation in a summary prompt
+const summaryInputTokens = 6000
+
+// GenerateDirectorySummary asks the API for a short summary of a package or directory based on
+// the documentation already generated for its files. fileDocs maps file paths within it to their
+// documentation.
+func GenerateDirectorySummary(ctx context.Context, dir string, fileDocs map[string]string, client api.DocumentationClient) (string, error) {
+	if len(fileDocs) == 0 {
+		return "", fmt.Errorf("no documentation found for directory %s", dir)
+	}
+
+	names := make([]string, 0, len(fileDocs))
+	for name := range fileDocs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Share the budget evenly so every file is represented
+	perFileTokens := summaryInputTokens / len(names)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(
+		"The following is the technical documentation for each file in the `%s` module of a project.\n"+
+			"Write a 2-3 paragraph summary of what this module does as a whole: its responsibility "+
+			"within the project, how its files work together, and its most important entry points.\n"+
+			"Format the summary as Markdown without a top-level heading.\n\n", dir))
+
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("## %s\n\n%s\n\n", name, tokenizer.Truncate(fileDocs[name], perFileTokens)))
+	}
+
+	summary, err := client.Complete(ctx, sb.String())
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("# %s\n\n%s\n", dir, strings.TrimSpace(summary)), nil
+}
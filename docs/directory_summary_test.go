@@ -0,0 +1,66 @@
+package docs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/api"
+)
+
+// completeRecorder answers Complete with a fixed response and records the prompts it was sent
+type completeRecorder struct {
+	*api.MockDocumentationClient
+
+	response string
+	prompts  []string
+}
+
+func newCompleteRecorder(response string) *completeRecorder {
+	return &completeRecorder{MockDocumentationClient: api.NewMockClient(nil), response: response}
+}
+
+func (c *completeRecorder) Complete(ctx context.Context, prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	return c.response, nil
+}
+
+func TestGenerateDirectorySummary(t *testing.T) {
+	client := newCompleteRecorder("\nThe auth module signs and checks session tokens.\n\n")
+	fileDocs := map[string]string{
+		"auth/verify.go": "# verify.go\n\nChecks the signature of a session token.",
+		"auth/sign.go":   "# sign.go\n\nSigns session tokens with the server key.",
+	}
+
+	summary, err := GenerateDirectorySummary(context.Background(), "auth", fileDocs, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "# auth\n\nThe auth module signs and checks session tokens.\n"; summary != want {
+		t.Errorf("summary = %q, want %q", summary, want)
+	}
+	if len(client.prompts) != 1 {
+		t.Fatalf("Complete() called %d times, want 1", len(client.prompts))
+	}
+	prompt := client.prompts[0]
+	for _, want := range []string{"the `auth` module", "## auth/sign.go", "Signs session tokens", "## auth/verify.go", "Checks the signature"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+	// Files are listed in sorted order
+	if strings.Index(prompt, "auth/sign.go") > strings.Index(prompt, "auth/verify.go") {
+		t.Errorf("prompt lists auth/verify.go before auth/sign.go:\n%s", prompt)
+	}
+}
+
+func TestGenerateDirectorySummaryWithoutDocs(t *testing.T) {
+	client := newCompleteRecorder("unused")
+	if _, err := GenerateDirectorySummary(context.Background(), "empty", nil, client); err == nil {
+		t.Error("GenerateDirectorySummary() with no documentation succeeded, want an error")
+	}
+	if len(client.prompts) != 0 {
+		t.Errorf("Complete() called %d times, want 0", len(client.prompts))
+	}
+}
@@ -0,0 +1,215 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// GlossaryFileName is the name of the glossary written to the output directory
+const GlossaryFileName = "GLOSSARY.md"
+
+// Term patterns for ExtractTerms
+var (
+	codeSpanTerm   = regexp.MustCompile("`([A-Za-z_][A-Za-z0-9_.]*)(?:\\(\\))?`")
+	identifierTerm = regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9]*(?:_[A-Za-z0-9]+)+\b|\b[A-Za-z][a-z0-9]+[A-Z][A-Za-z0-9]*\b`)
+	capitalTerm    = regexp.MustCompile(`\b[A-Z][a-z]{2,}\b`)
+	acronymTerm    = regexp.MustCompile(`\b[A-Z]{2,}[0-9]?s?\b`)
+)
+
+// minTermCount is how often a term must occur to be a glossary candidate
+const minTermCount = 2
+
+// stopwords are common English words, and words every generated documentation file uses,
+// that are never glossary terms even when capitalized at the start of a sentence or heading
+var stopwords = wordSet(`a about above after again all also an and any are as at be because been before
+being below between both but by can could did do does doing down during each either else even every
+few for from further had has have having here how however if in into is it its itself just may might
+more most must no nor not now of off on once only or other our out over own same should since so some
+such than that the their them then there these they this those through to too under until up upon use
+used uses using very via was we were what when where whether which while who why will with within
+without would yet you your
+overview summary description details purpose example examples usage note notes parameters parameter
+returns return value values function functions method methods type types struct structs field fields
+file files package packages module modules class classes interface interfaces error errors handling
+dependencies dependency key main new see section sections code snippet snippets documentation project
+structure overall role behavior behaviour side effects effect logic implementation default data input
+output first second finally each returned provides defines contains allows ensures includes based
+true false nil null none todo`)
+
+// languageKeywords are the keywords and built-in type names of a project type's languages,
+// which documentation mentions often but a glossary need not define
+var languageKeywords = map[filehandler.ProjectType]map[string]bool{
+	filehandler.ProjectTypeGo:         wordSet("func struct interface string int int64 uint byte rune bool error map chan go defer goroutine context ctx err"),
+	filehandler.ProjectTypePython:     wordSet("def self cls none str int dict list tuple bool async await lambda init"),
+	filehandler.ProjectTypeDjango:     wordSet("def self cls none str int dict list tuple bool models views"),
+	filehandler.ProjectTypeNode:       wordSet("const let var function async await promise require exports undefined string number boolean object"),
+	filehandler.ProjectTypeReact:      wordSet("const let var function async await props state jsx tsx usestate useeffect undefined string number boolean"),
+	filehandler.ProjectTypeTypeScript: wordSet("const let var function async await promise undefined string number boolean object readonly"),
+	filehandler.ProjectTypeJava:       wordSet("public private protected static final void string int long boolean class override"),
+	filehandler.ProjectTypeKotlin:     wordSet("fun val var string int long boolean unit override companion suspend data sealed object"),
+	filehandler.ProjectTypeAndroid:    wordSet("fun val var string int long boolean unit override companion suspend data sealed object context bundle intent"),
+	filehandler.ProjectTypeRust:       wordSet("fn struct enum impl trait pub mut self string str usize option result"),
+	filehandler.ProjectTypePHP:        wordSet("function public private protected static array string int bool null this namespace use echo"),
+	filehandler.ProjectTypeLaravel:    wordSet("function public private protected static array string int bool null this namespace use echo request response"),
+}
+
+// wordSet returns the lower-case words of the space-separated list
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(words) {
+		set[strings.ToLower(word)] = true
+	}
+	return set
+}
+
+// ExtractTerms returns the domain terms of generated documentation, most relevant first:
+// names quoted as code, capitalized words, compound identifiers and acronyms that occur
+// repeatedly. Each paragraph counts as a document for a TF-IDF-style score, so a term used
+// often in a few places ranks above one scattered thinly everywhere. Code blocks, stopwords
+// and keywords of the project type's languages are skipped. Callers take as many as they need.
+func ExtractTerms(docContent string, lang filehandler.ProjectType) []string {
+	paragraphs := splitParagraphs(docContent)
+
+	counts := make(map[string]int)         // Occurrences of each term, by lower-case key
+	paragraphsWith := make(map[string]int) // Paragraphs each term occurs in
+	spelling := make(map[string]map[string]int)
+	for _, paragraph := range paragraphs {
+		seen := make(map[string]bool)
+		for _, term := range paragraphTerms(paragraph) {
+			key := strings.ToLower(term)
+			if stopwords[key] || languageKeywords[lang][key] || len(key) < 3 {
+				continue
+			}
+			counts[key]++
+			if spelling[key] == nil {
+				spelling[key] = make(map[string]int)
+			}
+			spelling[key][term]++
+			if !seen[key] {
+				seen[key] = true
+				paragraphsWith[key]++
+			}
+		}
+	}
+
+	type scoredTerm struct {
+		term  string
+		score float64
+	}
+	var scored []scoredTerm
+	for key, count := range counts {
+		if count < minTermCount {
+			continue
+		}
+		idf := math.Log(1 + float64(len(paragraphs))/float64(paragraphsWith[key]))
+		scored = append(scored, scoredTerm{term: mostCommon(spelling[key]), score: float64(count) * idf})
+	}
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].score != scored[j].score {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].term < scored[j].term
+	})
+
+	terms := make([]string, len(scored))
+	for i, s := range scored {
+		terms[i] = s.term
+	}
+	return terms
+}
+
+// splitParagraphs splits Markdown into blank-line separated paragraphs, leaving out
+// fenced code blocks
+func splitParagraphs(markdown string) []string {
+	var paragraphs []string
+	var current []string
+	inCode := false
+	flush := func() {
+		if len(current) > 0 {
+			paragraphs = append(paragraphs, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			inCode = !inCode
+			flush()
+		case inCode:
+		case trimmed == "":
+			flush()
+		default:
+			current = append(current, trimmed)
+		}
+	}
+	flush()
+	return paragraphs
+}
+
+// paragraphTerms returns the candidate terms of a paragraph. Names quoted as code are
+// taken whole; in the remaining text, compound identifiers such as parseConfig or MAX_SIZE,
+// acronyms, and capitalized words inside a sentence are candidates. Words capitalized only
+// because they start a sentence, heading or list item are not.
+func paragraphTerms(paragraph string) []string {
+	var terms []string
+	for _, match := range codeSpanTerm.FindAllStringSubmatch(paragraph, -1) {
+		terms = append(terms, match[1])
+	}
+
+	text := codeSpanTerm.ReplaceAllString(paragraph, " ")
+	terms = append(terms, identifierTerm.FindAllString(text, -1)...)
+	for _, loc := range capitalTerm.FindAllStringIndex(text, -1) {
+		before := strings.TrimRight(text[:loc[0]], " ")
+		if before != "" && strings.IndexByte(".!?:#-*>\n", before[len(before)-1]) < 0 {
+			terms = append(terms, text[loc[0]:loc[1]])
+		}
+	}
+	for _, acronym := range acronymTerm.FindAllString(text, -1) {
+		terms = append(terms, strings.TrimSuffix(acronym, "s"))
+	}
+	return terms
+}
+
+// mostCommon returns the spelling used most often, preferring the alphabetically first on ties
+func mostCommon(spellings map[string]int) string {
+	best, bestCount := "", 0
+	for spelling, count := range spellings {
+		if count > bestCount || count == bestCount && spelling < best {
+			best, bestCount = spelling, count
+		}
+	}
+	return best
+}
+
+// GenerateGlossary asks the API to define terms in the context of a project of the given
+// type and returns the glossary as Markdown
+func GenerateGlossary(ctx context.Context, terms []string, client api.DocumentationClient, projectType string) (string, error) {
+	if len(terms) == 0 {
+		return "", fmt.Errorf("no terms to define")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Write a glossary for a %s project, defining each of the terms below in one or two sentences "+
+		"as it is used in this kind of project. Terms are names from its documentation, such as types, functions "+
+		"and domain concepts; where a term is a code identifier, explain what it represents. Format as Markdown "+
+		"starting with the heading \"# Glossary\", with one \"**Term**: definition\" line per term, sorted "+
+		"alphabetically. Do not add terms that are not listed.\n\nTerms:\n", projectType))
+	for _, term := range terms {
+		sb.WriteString("- " + term + "\n")
+	}
+
+	glossary, err := client.Complete(ctx, sb.String())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(glossary) + "\n", nil
+}
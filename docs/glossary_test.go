@@ -0,0 +1,71 @@
+package docs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestExtractTerms(t *testing.T) {
+	doc := strings.Join([]string{
+		"# Overview",
+		"The `ParseConfig()` function reads settings through the Kubernetes client. It validates the JWT first, then `ParseConfig` checks the rest.",
+		"",
+		"## Functions",
+		"`ParseConfig` returns a `Settings` value. Each request to Kubernetes carries a JWT and a context.",
+		"",
+		"- Returns an error when `ParseConfig` fails to decode MAX_RETRIES or the JWTs expire.",
+		"",
+		"```go",
+		"func ParseConfig(ctx context.Context) (*Settings, error) { return loadFromEtcd(ctx) }",
+		"```",
+		"",
+		"Values are cached in memory, unlike those fetched from Etcd once. The context and the `Settings` are reused, and MAX_RETRIES bounds retries.",
+	}, "\n")
+
+	terms := ExtractTerms(doc, filehandler.ProjectTypeGo)
+
+	want := []string{"ParseConfig", "JWT", "Kubernetes", "Settings", "MAX_RETRIES"}
+	for _, term := range want {
+		if !containsString(terms, term) {
+			t.Errorf("ExtractTerms() = %v, missing %q", terms, term)
+		}
+	}
+	// Single occurrences, sentence-initial capitals, stopwords, Go keywords and code blocks
+	for _, term := range []string{"Etcd", "Values", "Returns", "Overview", "Functions", "context", "loadFromEtcd"} {
+		if containsString(terms, term) {
+			t.Errorf("ExtractTerms() = %v, want %q left out", terms, term)
+		}
+	}
+	if len(terms) > 0 && terms[0] != "ParseConfig" {
+		t.Errorf("ExtractTerms() = %v, want the most used term ParseConfig first", terms)
+	}
+}
+
+func TestExtractTermsProjectKeywords(t *testing.T) {
+	doc := "The handler reads `self` and the `Request`.\n\nEvery call passes `self` and the `Request` along.\n"
+
+	if terms := ExtractTerms(doc, filehandler.ProjectTypePython); !reflect.DeepEqual(terms, []string{"Request"}) {
+		t.Errorf("ExtractTerms() for Python = %v, want only Request", terms)
+	}
+	if terms := ExtractTerms(doc, filehandler.ProjectTypeGo); !containsString(terms, "self") {
+		t.Errorf("ExtractTerms() for Go = %v, want self kept", terms)
+	}
+}
+
+func TestExtractTermsEmpty(t *testing.T) {
+	if terms := ExtractTerms("", filehandler.ProjectTypeGo); len(terms) != 0 {
+		t.Errorf("ExtractTerms(\"\") = %v, want no terms", terms)
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
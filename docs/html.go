@@ -0,0 +1,236 @@
+package docs
+
+import (
+	_ "embed"
+	"fmt"
+	"html"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/frontmatter"
+	"github.com/Abiggj/structura/output"
+)
+
+// HTMLIndexFileName is the name of the HTML page linking to all documentation pages
+const HTMLIndexFileName = "index.html"
+
+// htmlStyleFileName is the stylesheet written next to the HTML pages
+const htmlStyleFileName = "style.css"
+
+// markdownHref matches relative links to Markdown pages, such as those in INDEX.md, which
+// are pointed at the corresponding HTML pages
+var markdownHref = regexp.MustCompile(`href="([^":]+)\.md(#[^"]*)?"`)
+
+// firstHeading matches the first top-level heading of a Markdown page
+var firstHeading = regexp.MustCompile(`(?m)^# (.+)$`)
+
+//go:embed assets/style.css
+var htmlStyle string
+
+// WriteHTMLSite converts every Markdown file in outputDir to an HTML page next to it, with a
+// navigation sidebar of all pages, and writes index.html linking to them. It returns the path
+// of index.html.
+func WriteHTMLSite(outputDir, projectName string) (string, error) {
+	pages, err := markdownPages(outputDir)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, htmlStyleFileName), []byte(htmlStyle), 0644); err != nil {
+		return "", err
+	}
+
+	for _, page := range pages {
+		content, err := renderMarkdownPage(outputDir, page)
+		if err != nil {
+			return "", err
+		}
+
+		htmlPage := htmlPageName(page)
+		document := renderHTMLPage(projectName, strings.TrimSuffix(page, ".md"), htmlPage, content, pages, false)
+		if err := os.WriteFile(filepath.Join(outputDir, filepath.FromSlash(htmlPage)), []byte(document), 0644); err != nil {
+			return "", err
+		}
+	}
+
+	indexPath := filepath.Join(outputDir, HTMLIndexFileName)
+	document := renderHTMLPage(projectName, projectName, HTMLIndexFileName, indexPageContent(projectName, pages), pages, false)
+	if err := os.WriteFile(indexPath, []byte(document), 0644); err != nil {
+		return "", err
+	}
+
+	return indexPath, nil
+}
+
+// markdownPages returns the Markdown files below outputDir, relative to it with slashes, sorted
+func markdownPages(outputDir string) ([]string, error) {
+	var pages []string
+	err := filepath.WalkDir(outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(path, ".md") {
+			relPath, err := filepath.Rel(outputDir, path)
+			if err != nil {
+				return err
+			}
+			pages = append(pages, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing documentation in %s: %w", outputDir, err)
+	}
+	sort.Strings(pages)
+	return pages, nil
+}
+
+// renderMarkdownPage returns the HTML content of the Markdown file page, relative to outputDir,
+// with links to other Markdown pages pointed at their HTML pages
+func renderMarkdownPage(outputDir, page string) (string, error) {
+	markdown, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(page)))
+	if err != nil {
+		return "", err
+	}
+
+	content := RenderMarkdown(frontmatter.Strip(string(markdown)))
+	return markdownHref.ReplaceAllString(content, `href="$1.html$2"`), nil
+}
+
+// indexPageContent returns the content of the index page, listing every page grouped by
+// directory like the sidebar
+func indexPageContent(projectName string, pages []string) string {
+	return fmt.Sprintf("<h1>%s</h1>\n<p>Documentation for %d files.</p>\n%s",
+		html.EscapeString(projectName), len(pages), renderPageTree(pages, HTMLIndexFileName, false))
+}
+
+// htmlPageName returns the HTML page written for a Markdown file, e.g. main.go.md -> main.go.html
+func htmlPageName(markdownPage string) string {
+	return strings.TrimSuffix(markdownPage, ".md") + ".html"
+}
+
+// renderHTMLPage wraps content in the page layout with the navigation sidebar.
+// current is the page's path relative to the output directory. Pages served by Server are
+// live: they get a search box filtering the sidebar and reload when the documentation changes.
+func renderHTMLPage(projectName, title, current, content string, pages []string, live bool) string {
+	root := relativeRoot(current)
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	sb.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(title)))
+	sb.WriteString(fmt.Sprintf("<link rel=\"stylesheet\" href=\"%s%s\">\n", root, htmlStyleFileName))
+	sb.WriteString("</head>\n<body>\n<nav>\n")
+	sb.WriteString(fmt.Sprintf("<p class=\"title\"><a href=\"%s%s\">%s</a></p>\n", root, HTMLIndexFileName, html.EscapeString(projectName)))
+	if live {
+		sb.WriteString("<input type=\"search\" id=\"search\" placeholder=\"Search documentation\" autocomplete=\"off\">\n")
+	}
+	sb.WriteString(renderPageTree(pages, current, true))
+	sb.WriteString("</nav>\n<main>\n")
+	sb.WriteString(content)
+	sb.WriteString("</main>\n")
+	if live {
+		sb.WriteString(fmt.Sprintf("<script src=\"%s%s\"></script>\n", root, serverScriptPath))
+	}
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// renderPageTree renders links to pages grouped by directory, relative to the current page.
+// In the sidebar, directories are collapsible and the current page is highlighted.
+func renderPageTree(pages []string, current string, sidebar bool) string {
+	root := relativeRoot(current)
+
+	// Group pages by directory, keeping the sorted order
+	var dirs []string
+	byDir := make(map[string][]string)
+	for _, page := range pages {
+		dir := filepath.ToSlash(filepath.Dir(page))
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], page)
+	}
+	sort.Strings(dirs)
+
+	var sb strings.Builder
+	for _, dir := range dirs {
+		label := dir
+		if dir == "." {
+			label = "Project"
+		}
+
+		if sidebar {
+			open := ""
+			if dir == "." || dir == filepath.ToSlash(filepath.Dir(current)) {
+				open = " open"
+			}
+			sb.WriteString(fmt.Sprintf("<details%s><summary>%s</summary>\n", open, html.EscapeString(label)))
+		} else {
+			sb.WriteString(fmt.Sprintf("<h2>%s</h2>\n", html.EscapeString(label)))
+		}
+
+		sb.WriteString("<ul>\n")
+		for _, page := range byDir[dir] {
+			htmlPage := htmlPageName(page)
+			class := ""
+			if htmlPage == current {
+				class = ` class="current"`
+			}
+			name := strings.TrimSuffix(filepath.Base(page), ".md")
+			sb.WriteString(fmt.Sprintf("<li><a href=\"%s%s\"%s>%s</a></li>\n", root, html.EscapeString(htmlPage), class, html.EscapeString(name)))
+		}
+		sb.WriteString("</ul>\n")
+
+		if sidebar {
+			sb.WriteString("</details>\n")
+		}
+	}
+	return sb.String()
+}
+
+// relativeRoot returns the prefix leading from a page back to the output directory, e.g. "../../"
+func relativeRoot(page string) string {
+	return strings.Repeat("../", strings.Count(page, "/"))
+}
+
+// HTMLFormat is the name of the format writing an HTML page next to each file's Markdown
+const HTMLFormat = "html"
+
+func init() {
+	output.Renderers.Register(HTMLFormat, func() output.Renderer { return HTMLRenderer{} })
+}
+
+// HTMLRenderer renders a file's documentation as a standalone HTML page with the stylesheet
+// inlined, so the page can be published on its own
+type HTMLRenderer struct{}
+
+// Extension returns ".html"
+func (HTMLRenderer) Extension() string {
+	return ".html"
+}
+
+// Render returns content as an HTML page titled after its first top-level heading, with
+// links to other Markdown pages pointed at their HTML pages
+func (HTMLRenderer) Render(content string) ([]byte, error) {
+	markdown := frontmatter.Strip(content)
+	title := "Documentation"
+	if match := firstHeading.FindStringSubmatch(markdown); match != nil {
+		title = strings.TrimSpace(match[1])
+	}
+	body := markdownHref.ReplaceAllString(RenderMarkdown(markdown), `href="$1.html$2"`)
+
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+	sb.WriteString(fmt.Sprintf("<title>%s</title>\n", html.EscapeString(title)))
+	sb.WriteString("<style>\n" + htmlStyle + "</style>\n")
+	sb.WriteString("</head>\n<body>\n<main>\n")
+	sb.WriteString(body)
+	sb.WriteString("</main>\n</body>\n</html>\n")
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,49 @@
+package docs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/output"
+)
+
+func TestWriteMarkdownAndHTMLFromOneResponse(t *testing.T) {
+	client := api.NewMockClient(map[string]string{
+		"src/parser.go": "# parser.go\n\nParses input. See [lexer](lexer.go.md#tokens).\n",
+	})
+	response, err := client.GenerateDocumentation(context.Background(), filehandler.FileInfo{Path: "src/parser.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renderers, err := output.Renderers.New([]string{HTMLFormat})
+	if err != nil {
+		t.Fatalf("Renderers.New() error = %v", err)
+	}
+	dir := t.TempDir()
+	if err := output.WriteRendered(filepath.Join(dir, "parser.go.md"), response, renderers); err != nil {
+		t.Fatalf("WriteRendered() error = %v", err)
+	}
+
+	if calls := client.CallLog(); len(calls) != 1 {
+		t.Errorf("%d API calls, want one response for both formats", len(calls))
+	}
+	markdown, err := os.ReadFile(filepath.Join(dir, "parser.go.md"))
+	if err != nil || string(markdown) != response {
+		t.Errorf("parser.go.md = %q, %v, want the response", markdown, err)
+	}
+	page, err := os.ReadFile(filepath.Join(dir, "parser.go.html"))
+	if err != nil {
+		t.Fatalf("parser.go.html not written: %v", err)
+	}
+	for _, want := range []string{"<title>parser.go</title>", `<h1 id="parsergo">parser.go</h1>`, `<a href="lexer.go.html#tokens">lexer</a>`} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("parser.go.html does not contain %q", want)
+		}
+	}
+}
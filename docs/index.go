@@ -0,0 +1,181 @@
+package docs
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/techdebt"
+)
+
+// IndexFileName is the name of the table of contents written to the output directory
+const IndexFileName = "INDEX.md"
+
+// StructureFileName is the name of the project structure overview written to the output directory
+const StructureFileName = "PROJECT_STRUCTURE.md"
+
+// OverviewFileName is the name of the AI-written project overview written to the output directory
+const OverviewFileName = "PROJECT_OVERVIEW.md"
+
+// projectDocs are the project-level documents listed at the top of the index, in order
+var projectDocs = []struct {
+	name  string
+	title string
+}{
+	{ReadmeFileName, "README"},
+	{OverviewFileName, "Project overview"},
+	{ArchitectureFileName, "Architecture"},
+	{StructureFileName, "Project structure"},
+	{SetupFileName, "Project setup"},
+	{GlossaryFileName, "Glossary"},
+	{CoverageFileName, "Documentation coverage"},
+	{techdebt.FileName, "Technical debt"},
+}
+
+// indexDir is a directory of the index tree
+type indexDir struct {
+	summary string // Path of the directory's README_SUMMARY.md, if any
+	files   []string
+	dirs    map[string]*indexDir
+}
+
+// GenerateIndex walks outputDir and writes INDEX.md, linking to the project documents and
+// to every file's documentation in a nested list mirroring the directory hierarchy. A
+// directory links to its README_SUMMARY.md when there is one. It returns the path of INDEX.md.
+func GenerateIndex(outputDir string) (string, error) {
+	root, found, err := collectDocs(outputDir)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Documentation Index\n\n")
+
+	if len(found) > 0 {
+		sb.WriteString("## Project documentation\n\n")
+		for _, doc := range projectDocs {
+			for _, name := range found {
+				if name == doc.name {
+					sb.WriteString(fmt.Sprintf("- [%s](%s)\n", doc.title, markdownLink(name)))
+				}
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Files\n\n")
+	if root.summary != "" {
+		sb.WriteString(fmt.Sprintf("- [Project summary](%s)\n", markdownLink(root.summary)))
+	}
+	root.write(&sb, 0)
+
+	indexPath := filepath.Join(outputDir, IndexFileName)
+	if err := os.WriteFile(indexPath, []byte(sb.String()), 0644); err != nil {
+		return "", err
+	}
+	return indexPath, nil
+}
+
+// collectDocs walks outputDir and returns the tree of file documentation below it, and the
+// project documents found in outputDir itself. INDEX.md and the MkDocs home page are left
+// out of both.
+func collectDocs(outputDir string) (root *indexDir, found []string, err error) {
+	root = &indexDir{dirs: make(map[string]*indexDir)}
+	err = filepath.WalkDir(outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".md") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(outputDir, p)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		dir, name := path.Split(relPath)
+
+		switch {
+		case dir == "" && isProjectDoc(name):
+			found = append(found, name)
+		case dir == "" && name == IndexFileName, relPath == MkdocsHomePage:
+		case name == SummaryFileName:
+			root.subdir(dir).summary = relPath
+		default:
+			node := root.subdir(dir)
+			node.files = append(node.files, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error listing documentation in %s: %w", outputDir, err)
+	}
+	return root, found, nil
+}
+
+// isProjectDoc reports whether name is one of the project-level documents
+func isProjectDoc(name string) bool {
+	for _, doc := range projectDocs {
+		if doc.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// subdir returns the node for dir, a slash-separated path ending in "/", creating it if needed
+func (d *indexDir) subdir(dir string) *indexDir {
+	node := d
+	for _, part := range strings.Split(strings.TrimSuffix(dir, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		child, ok := node.dirs[part]
+		if !ok {
+			child = &indexDir{dirs: make(map[string]*indexDir)}
+			node.dirs[part] = child
+		}
+		node = child
+	}
+	return node
+}
+
+// write renders the directory's subdirectories, then its files, as list items at the given depth
+func (d *indexDir) write(sb *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	names := make([]string, 0, len(d.dirs))
+	for name := range d.dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := d.dirs[name]
+		if child.summary != "" {
+			sb.WriteString(fmt.Sprintf("%s- [%s/](%s)\n", indent, name, markdownLink(child.summary)))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s- %s/\n", indent, name))
+		}
+		child.write(sb, depth+1)
+	}
+
+	sort.Strings(d.files)
+	for _, file := range d.files {
+		sb.WriteString(fmt.Sprintf("%s- [%s](%s)\n", indent, strings.TrimSuffix(path.Base(file), ".md"), markdownLink(file)))
+	}
+}
+
+// markdownLink escapes each segment of a slash-separated path for use as a link target
+func markdownLink(relPath string) string {
+	parts := strings.Split(relPath, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
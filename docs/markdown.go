@@ -0,0 +1,178 @@
+package docs
+
+import (
+	"bytes"
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	mdhtml "github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+)
+
+// headingLine matches a Markdown heading, capturing its level and text
+var headingLine = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// markdownExtensions are the Markdown features the documentation prompts produce
+const markdownExtensions = parser.NoIntraEmphasis | parser.Tables | parser.FencedCode |
+	parser.Autolink | parser.Strikethrough | parser.SpaceHeadings | parser.BackslashLineBreak
+
+// codeFormatter highlights code blocks with the classes of codeStyle, which style.css colors
+var codeFormatter = chromahtml.New(chromahtml.WithClasses(true), chromahtml.PreventSurroundingPre(true))
+
+// codeStyle is the chroma style the highlighting classes in style.css were generated from
+const codeStyle = "github"
+
+// RenderMarkdown converts the Markdown produced by the documentation prompts to HTML.
+// Code blocks are highlighted for their language. Raw HTML is escaped, and links and images
+// are kept only for http, https, mailto and relative targets; others become plain text.
+func RenderMarkdown(source string) string {
+	p := parser.NewWithExtensions(markdownExtensions)
+	doc := p.Parse([]byte(strings.ReplaceAll(source, "\r\n", "\n")))
+	prepareDocument(doc)
+
+	renderer := mdhtml.NewRenderer(mdhtml.RendererOptions{RenderNodeHook: renderNode})
+	return string(markdown.Render(doc, renderer))
+}
+
+// prepareDocument gives every heading the anchor slugify derives from its text, and replaces
+// links and images with unsafe targets by their text
+func prepareDocument(doc ast.Node) {
+	var unsafe []ast.Node
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if !entering {
+			return ast.GoToNext
+		}
+		switch n := node.(type) {
+		case *ast.Heading:
+			n.HeadingID = slugify(plainText(n))
+		case *ast.Link:
+			if !safeLinkTarget(string(n.Destination)) {
+				unsafe = append(unsafe, n)
+			}
+		case *ast.Image:
+			if !safeLinkTarget(string(n.Destination)) {
+				unsafe = append(unsafe, n)
+			}
+		}
+		return ast.GoToNext
+	})
+
+	for _, node := range unsafe {
+		replaceWithChildren(node)
+	}
+}
+
+// safeLinkTarget reports whether a link target is an http, https or mailto URL, or a relative
+// one. Entities are decoded first, as browsers do, so javascript&#58; is not let through.
+func safeLinkTarget(target string) bool {
+	u, err := url.Parse(strings.TrimSpace(html.UnescapeString(target)))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// replaceWithChildren puts the children of node in its place in the parent
+func replaceWithChildren(node ast.Node) {
+	parent := node.GetParent()
+	var children []ast.Node
+	for _, child := range parent.GetChildren() {
+		if child != node {
+			children = append(children, child)
+			continue
+		}
+		for _, grandchild := range node.GetChildren() {
+			grandchild.SetParent(parent)
+			children = append(children, grandchild)
+		}
+	}
+	parent.SetChildren(children)
+}
+
+// plainText returns the text of node and its descendants without formatting
+func plainText(node ast.Node) string {
+	var sb strings.Builder
+	ast.WalkFunc(node, func(n ast.Node, entering bool) ast.WalkStatus {
+		if leaf := n.AsLeaf(); entering && leaf != nil {
+			sb.Write(leaf.Literal)
+		}
+		return ast.GoToNext
+	})
+	return sb.String()
+}
+
+// renderNode escapes raw HTML and highlights code blocks; other nodes are rendered as usual
+func renderNode(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	switch n := node.(type) {
+	case *ast.HTMLBlock:
+		io.WriteString(w, "<p>"+html.EscapeString(string(n.Literal))+"</p>\n")
+		return ast.GoToNext, true
+	case *ast.HTMLSpan:
+		io.WriteString(w, html.EscapeString(string(n.Literal)))
+		return ast.GoToNext, true
+	case *ast.CodeBlock:
+		language := strings.Fields(string(n.Info))
+		class := ""
+		if len(language) > 0 {
+			class = ` class="language-` + html.EscapeString(language[0]) + `"`
+		}
+		io.WriteString(w, `<pre class="chroma"><code`+class+`>`)
+		io.WriteString(w, highlightCode(strings.TrimSuffix(string(n.Literal), "\n"), language))
+		io.WriteString(w, "</code></pre>\n")
+		return ast.GoToNext, true
+	}
+	return ast.GoToNext, false
+}
+
+// slugify turns a heading into an anchor id
+func slugify(text string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case r == ' ' || r == '-' || r == '_':
+			sb.WriteRune('-')
+		}
+	}
+	return sb.String()
+}
+
+// highlightCode returns code as HTML with chroma's highlighting classes. The lexer is chosen by
+// the fence's language, if any, or else guessed from the code; code is only escaped if neither
+// works.
+func highlightCode(code string, language []string) string {
+	var lexer chroma.Lexer
+	if len(language) > 0 {
+		lexer = lexers.Get(language[0])
+	}
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		return html.EscapeString(code)
+	}
+
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err != nil {
+		return html.EscapeString(code)
+	}
+	var buf bytes.Buffer
+	if err := codeFormatter.Format(&buf, styles.Get(codeStyle), iterator); err != nil {
+		return html.EscapeString(code)
+	}
+	return buf.String()
+}
@@ -0,0 +1,68 @@
+package docs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownLinkTargets(t *testing.T) {
+	tests := []struct {
+		markdown string
+		want     string // Expected in the HTML
+		unwanted string // Must not be in the HTML
+	}{
+		{"[docs](main.go.md#usage)", `<a href="main.go.md#usage">docs</a>`, ""},
+		{"[up](../README.md)", `<a href="../README.md">up</a>`, ""},
+		{"[site](https://example.com/a?b=1&c=2)", `<a href="https://example.com/a?b=1&amp;c=2">site</a>`, ""},
+		{"[mail](mailto:dev@example.com)", `<a href="mailto:dev@example.com">mail</a>`, ""},
+		{"[click](javascript:alert(1))", "click", "<a"},
+		{"[click](JavaScript:alert(1))", "click", "<a"},
+		{"[click](javascript&#58;alert(1))", "click", "<a"},
+		{"[click](vbscript:msgbox)", "click", "<a"},
+		{"[click](data:text/html;base64,PHNjcmlwdD4=)", "click", "<a"},
+		{"![logo](javascript:alert(1))", "logo", "<img"},
+		{"![logo](images/logo.png)", `<img src="images/logo.png" alt="logo"`, ""},
+		{`[quote](https://example.com/"onmouseover="alert(1))`, "&quot;", `"onmouseover`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.markdown, func(t *testing.T) {
+			got := RenderMarkdown(tt.markdown)
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("RenderMarkdown(%q) = %q, want it to contain %q", tt.markdown, got, tt.want)
+			}
+			if tt.unwanted != "" && strings.Contains(got, tt.unwanted) {
+				t.Errorf("RenderMarkdown(%q) = %q, must not contain %q", tt.markdown, got, tt.unwanted)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownEscapesHTML(t *testing.T) {
+	got := RenderMarkdown("Text <script>alert(1)</script>\n\n<div onclick=\"alert(1)\">block</div>\n")
+	for _, unwanted := range []string{"<script", "<div"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("RenderMarkdown kept raw HTML %s: %q", unwanted, got)
+		}
+	}
+	if !strings.Contains(got, "&lt;script&gt;") {
+		t.Errorf("RenderMarkdown dropped the escaped HTML: %q", got)
+	}
+}
+
+func TestRenderMarkdownHighlightsCode(t *testing.T) {
+	got := RenderMarkdown("```go\nfunc main() {}\n```\n")
+	if !strings.Contains(got, `<pre class="chroma"><code class="language-go">`) {
+		t.Errorf("code block not wrapped for highlighting: %q", got)
+	}
+	if !strings.Contains(got, `<span class="kd">func</span>`) {
+		t.Errorf("keyword not highlighted: %q", got)
+	}
+}
+
+func TestRenderMarkdownHeadingIDs(t *testing.T) {
+	got := RenderMarkdown("## `ParseConfig()` function\n")
+	if !strings.Contains(got, `<h2 id="parseconfig-function">`) {
+		t.Errorf("heading anchor differs from slugify: %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package docs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MkdocsFileName is the name of the MkDocs configuration written to the output directory
+const MkdocsFileName = "mkdocs.yml"
+
+// MkdocsHomePage is the page written for MkDocs setups expecting a docs directory, relative to
+// the output directory. It is not listed in the index or the nav.
+const MkdocsHomePage = "docs/index.md"
+
+// mkdocsTheme is the MkDocs theme the configuration selects
+const mkdocsTheme = "material"
+
+// GenerateMkdocsConfig writes mkdocs.yml to outputDir, so the documentation can be built with
+// MkDocs and the Material theme. Its nav starts with INDEX.md and the project documents, then
+// lists the documentation of every file by directory, directories before files. It also writes
+// docs/index.md linking to INDEX.md, unless that file exists.
+func GenerateMkdocsConfig(outputDir, projectName string) error {
+	root, found, err := collectDocs(outputDir)
+	if err != nil {
+		return err
+	}
+
+	nav := sequenceNode()
+	if _, err := os.Stat(filepath.Join(outputDir, IndexFileName)); err == nil {
+		nav.Content = append(nav.Content, navEntry("Documentation index", IndexFileName))
+	}
+	for _, doc := range projectDocs {
+		for _, name := range found {
+			if name == doc.name {
+				nav.Content = append(nav.Content, navEntry(doc.title, name))
+			}
+		}
+	}
+	if root.summary != "" {
+		nav.Content = append(nav.Content, navEntry("Project summary", root.summary))
+	}
+	nav.Content = append(nav.Content, root.navEntries()...)
+
+	config := mappingNode()
+	config.Content = append(config.Content,
+		stringNode("site_name"), stringNode(projectName),
+		stringNode("docs_dir"), stringNode("."),
+		stringNode("theme"), mappingNode(stringNode("name"), stringNode(mkdocsTheme)),
+		stringNode("nav"), nav,
+	)
+	var data bytes.Buffer
+	encoder := yaml.NewEncoder(&data)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{config}}); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, MkdocsFileName), data.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	homePage := filepath.Join(outputDir, filepath.FromSlash(MkdocsHomePage))
+	if _, err := os.Stat(homePage); !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(homePage), 0755); err != nil {
+		return err
+	}
+	home := fmt.Sprintf("# %s\n\nThe documentation starts at the [documentation index](../%s).\n", projectName, IndexFileName)
+	return os.WriteFile(homePage, []byte(home), 0644)
+}
+
+// navEntries returns the nav entries of the directory's subdirectories, then its files, each
+// sorted by name. A subdirectory's README_SUMMARY.md comes first among its entries.
+func (d *indexDir) navEntries() []*yaml.Node {
+	var entries []*yaml.Node
+
+	names := make([]string, 0, len(d.dirs))
+	for name := range d.dirs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		child := d.dirs[name]
+		children := child.navEntries()
+		if child.summary != "" {
+			children = append([]*yaml.Node{navEntry("Overview", child.summary)}, children...)
+		}
+		if len(children) > 0 {
+			entries = append(entries, mappingNode(stringNode(name), sequenceNode(children...)))
+		}
+	}
+
+	sort.Strings(d.files)
+	for _, file := range d.files {
+		entries = append(entries, navEntry(strings.TrimSuffix(path.Base(file), ".md"), file))
+	}
+	return entries
+}
+
+// navEntry returns a nav entry titled title, linking to the page at relPath
+func navEntry(title, relPath string) *yaml.Node {
+	return mappingNode(stringNode(title), stringNode(relPath))
+}
+
+// stringNode returns a YAML scalar holding s, quoted if needed
+func stringNode(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// mappingNode returns a YAML mapping of the given keys and values, alternating
+func mappingNode(content ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Content: content}
+}
+
+// sequenceNode returns a YAML sequence of the given items
+func sequenceNode(items ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Content: items}
+}
@@ -0,0 +1,107 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerateMkdocsConfig(t *testing.T) {
+	outputDir := t.TempDir()
+	pages := []string{
+		IndexFileName,
+		GlossaryFileName,
+		ReadmeFileName,
+		"main.go.md",
+		"yes.md", // A YAML 1.1 boolean unless quoted
+		"cmd/tool/main.go.md",
+		"internal/" + SummaryFileName,
+		"internal/#config: v2.go.md",
+		"internal/- list.go.md",
+	}
+	for _, page := range pages {
+		path := filepath.Join(outputDir, filepath.FromSlash(page))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("# "+page+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projectName := "widget: the *best* # tool"
+	if err := GenerateMkdocsConfig(outputDir, projectName); err != nil {
+		t.Fatalf("GenerateMkdocsConfig() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, MkdocsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		SiteName string `yaml:"site_name"`
+		DocsDir  string `yaml:"docs_dir"`
+		Theme    struct {
+			Name string `yaml:"name"`
+		} `yaml:"theme"`
+		Nav []interface{} `yaml:"nav"`
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		t.Fatalf("%s is not valid YAML: %v\n%s", MkdocsFileName, err, data)
+	}
+
+	if config.SiteName != projectName || config.DocsDir != "." || config.Theme.Name != mkdocsTheme {
+		t.Errorf("site_name %q, docs_dir %q, theme %q, want %q, \".\", %q", config.SiteName, config.DocsDir, config.Theme.Name, projectName, mkdocsTheme)
+	}
+
+	want := []interface{}{
+		map[string]interface{}{"Documentation index": IndexFileName},
+		map[string]interface{}{"README": ReadmeFileName},
+		map[string]interface{}{"Glossary": GlossaryFileName},
+		map[string]interface{}{"cmd": []interface{}{
+			map[string]interface{}{"tool": []interface{}{
+				map[string]interface{}{"main.go": "cmd/tool/main.go.md"},
+			}},
+		}},
+		map[string]interface{}{"internal": []interface{}{
+			map[string]interface{}{"Overview": "internal/" + SummaryFileName},
+			map[string]interface{}{"#config: v2.go": "internal/#config: v2.go.md"},
+			map[string]interface{}{"- list.go": "internal/- list.go.md"},
+		}},
+		map[string]interface{}{"main.go": "main.go.md"},
+		map[string]interface{}{"yes": "yes.md"},
+	}
+	if !reflect.DeepEqual(config.Nav, want) {
+		t.Errorf("nav = %#v\nwant %#v\n%s", config.Nav, want, data)
+	}
+
+	home, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(MkdocsHomePage)))
+	if err != nil || !strings.Contains(string(home), "(../"+IndexFileName+")") {
+		t.Errorf("%s = %q, %v, want a link to the index", MkdocsHomePage, home, err)
+	}
+}
+
+func TestGenerateMkdocsConfigKeepsHomePage(t *testing.T) {
+	outputDir := t.TempDir()
+	homePage := filepath.Join(outputDir, filepath.FromSlash(MkdocsHomePage))
+	if err := os.MkdirAll(filepath.Dir(homePage), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(homePage, []byte("# Custom home\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := GenerateMkdocsConfig(outputDir, "widget"); err != nil {
+		t.Fatalf("GenerateMkdocsConfig() error = %v", err)
+	}
+	if home, _ := os.ReadFile(homePage); string(home) != "# Custom home\n" {
+		t.Errorf("%s overwritten with %q", MkdocsHomePage, home)
+	}
+	if data, _ := os.ReadFile(filepath.Join(outputDir, MkdocsFileName)); strings.Contains(string(data), "docs/index.md") {
+		t.Errorf("the home page is listed in the nav:\n%s", data)
+	}
+}
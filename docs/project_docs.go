@@ -0,0 +1,294 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// ProjectDocs writes the documents describing the project as a whole into the output directory.
+// The TUI and generate both use it, so they write the same documents.
+type ProjectDocs struct {
+	Client      api.DocumentationClient
+	Config      *config.Config
+	RootDir     string
+	OutputDir   string
+	ProjectType filehandler.ProjectType
+	Files       []filehandler.FileInfo // Every file found in RootDir, documented or not
+}
+
+// WriteStructure writes PROJECT_STRUCTURE.md and returns its path
+func (p *ProjectDocs) WriteStructure() (string, error) {
+	return p.write(StructureFileName, p.structure(), "project structure")
+}
+
+// WriteSetup writes PROJECT_SETUP.md and returns its path. The setup guide is generated by the
+// API if enabled, falling back to generic instructions. A failed API call is returned as an
+// error even though the generic instructions were written.
+func (p *ProjectDocs) WriteSetup(ctx context.Context) (string, error) {
+	var apiErr error
+	setupDoc := ""
+	if p.Config.GenerateSetupDoc {
+		generator := NewProjectSetupGenerator(p.Client, p.ProjectType)
+		doc, err := generator.Generate(ctx, p.RootDir, p.Files)
+		if err == nil {
+			setupDoc = doc
+		} else if len(generator.SetupFiles(p.Files)) > 0 {
+			// Missing setup files are expected; failed API calls are not
+			apiErr = fmt.Errorf("error generating setup documentation: %w", err)
+		}
+	}
+	if setupDoc == "" {
+		setupDoc = staticSetup(p.Files, p.ProjectType)
+	}
+
+	path, err := p.write(SetupFileName, setupDoc, "setup documentation")
+	if err != nil {
+		return "", err
+	}
+	return path, apiErr
+}
+
+// WriteReadme writes README.md from the structure, the setup guide and the file documentation,
+// and returns its path. The structure and setup guide are read from the output directory.
+func (p *ProjectDocs) WriteReadme(ctx context.Context) (string, error) {
+	structure, _ := os.ReadFile(filepath.Join(p.OutputDir, StructureFileName))
+	setup, _ := os.ReadFile(filepath.Join(p.OutputDir, SetupFileName))
+
+	readme, err := GenerateReadme(ctx, p.FileDocumentation(), string(structure), string(setup), p.Client)
+	if err != nil {
+		return "", fmt.Errorf("error generating README: %w", err)
+	}
+	return p.write(ReadmeFileName, readme, "README")
+}
+
+// WriteOverview writes PROJECT_OVERVIEW.md, asking the API how the project fits together, and
+// returns its path
+func (p *ProjectDocs) WriteOverview(ctx context.Context) (string, error) {
+	overview, err := p.Client.GenerateProjectOverview(ctx, p.Files)
+	if err != nil {
+		return "", fmt.Errorf("error generating project overview: %w", err)
+	}
+	return p.write(OverviewFileName, overview, "project overview")
+}
+
+// WriteArchitecture writes ARCHITECTURE.md from the directory tree and the first sentence of
+// each file's documentation, and returns its path
+func (p *ProjectDocs) WriteArchitecture(ctx context.Context) (string, error) {
+	tree := RenderTree(p.RootDir, p.Files)
+	architecture, err := GenerateArchitecture(ctx, tree, p.FileDocumentation(), p.Client)
+	if err != nil {
+		return "", fmt.Errorf("error generating architecture documentation: %w", err)
+	}
+	return p.write(ArchitectureFileName, architecture, "architecture documentation")
+}
+
+// WriteGlossary writes GLOSSARY.md defining the terms used most in the file documentation, and
+// returns its path. Nothing is written, and the path is empty, if no terms are found.
+func (p *ProjectDocs) WriteGlossary(ctx context.Context) (string, error) {
+	var content strings.Builder
+	for _, doc := range p.FileDocumentation() {
+		content.WriteString(doc.Content)
+		content.WriteString("\n\n")
+	}
+
+	terms := ExtractTerms(content.String(), p.ProjectType)
+	if len(terms) > p.Config.GlossaryTerms {
+		terms = terms[:p.Config.GlossaryTerms]
+	}
+	if len(terms) == 0 {
+		return "", nil
+	}
+
+	glossary, err := GenerateGlossary(ctx, terms, p.Client, string(p.ProjectType))
+	if err != nil {
+		return "", fmt.Errorf("error generating glossary: %w", err)
+	}
+	return p.write(GlossaryFileName, glossary, "glossary")
+}
+
+// FileDocumentation returns the documentation written for each file, keyed by the file's
+// slash-separated path relative to RootDir. Files without documentation are left out.
+func (p *ProjectDocs) FileDocumentation() []filehandler.FileInfo {
+	var fileDocs []filehandler.FileInfo
+	for _, file := range p.Files {
+		if file.IsDir {
+			continue
+		}
+		relPath, err := filepath.Rel(p.RootDir, file.Path)
+		if err != nil {
+			continue
+		}
+		doc, err := os.ReadFile(filepath.Join(p.OutputDir, config.ComputeOutputPath(p.Config, p.RootDir, file.Path)))
+		if err != nil {
+			continue
+		}
+		fileDocs = append(fileDocs, filehandler.FileInfo{Path: filepath.ToSlash(relPath), Content: string(doc)})
+	}
+	return fileDocs
+}
+
+// write saves content as name in the output directory and returns its path
+func (p *ProjectDocs) write(name, content, what string) (string, error) {
+	if err := os.MkdirAll(p.OutputDir, 0755); err != nil {
+		return "", fmt.Errorf("error creating directory %s: %w", p.OutputDir, err)
+	}
+	path := filepath.Join(p.OutputDir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("error writing %s to %s: %w", what, path, err)
+	}
+	return path, nil
+}
+
+// structure builds PROJECT_STRUCTURE.md: the directory tree, the files in each directory and,
+// if enabled, the import graph between them
+func (p *ProjectDocs) structure() string {
+	structureDoc := "# Project Structure\n\n"
+	structureDoc += "This document provides an overview of the project's directory structure and organization.\n\n"
+	structureDoc += "```\n" + RenderTree(p.RootDir, p.Files) + "```\n\n"
+
+	// Create a map to track directories and their files
+	dirMap := make(map[string][]string)
+
+	// Organize files by directory
+	for _, file := range p.Files {
+		if file.IsDir {
+			continue
+		}
+
+		// Get directory path
+		dir := filepath.Dir(file.Path)
+		relDir, err := filepath.Rel(p.RootDir, dir)
+		if err != nil {
+			continue
+		}
+
+		if relDir == "." {
+			relDir = "Root"
+		}
+
+		// Add file to directory map
+		dirMap[relDir] = append(dirMap[relDir], filepath.Base(file.Path))
+	}
+
+	// Add directories and files to documentation
+	structureDoc += "## Directory Structure\n\n"
+	for dir, files := range dirMap {
+		structureDoc += fmt.Sprintf("### %s\n\n", dir)
+
+		// Add files in the directory
+		if len(files) > 0 {
+			structureDoc += "Files:\n"
+			for _, file := range files {
+				structureDoc += fmt.Sprintf("- `%s`\n", file)
+			}
+			structureDoc += "\n"
+		}
+	}
+
+	// Add the import graph between project files
+	if p.Config.GenerateDependencyGraph {
+		if graph, err := BuildDependencyGraph(p.Files, p.ProjectType); err == nil && graph != "" {
+			structureDoc += "## Dependency Graph\n\n" + graph + "\n"
+		}
+	}
+
+	return structureDoc
+}
+
+// staticSetup builds generic setup instructions from the project's setup files
+func staticSetup(files []filehandler.FileInfo, projectType filehandler.ProjectType) string {
+	setupDoc := "# Project Setup\n\n"
+	setupDoc += "This document provides information on how to set up and run this project.\n\n"
+
+	// Look for common setup files
+	setupFiles := []string{
+		"package.json", "go.mod", "requirements.txt", "Gemfile",
+		"pom.xml", "build.gradle", "Makefile", "pubspec.yaml",
+		"composer.json", "setup.py", "CMakeLists.txt",
+		"Cargo.toml", "build.gradle.kts", "settings.gradle.kts", "Package.swift", "tsconfig.json",
+	}
+
+	// Section for dependencies
+	setupDoc += "## Dependencies\n\n"
+
+	// Find and document setup files
+	foundSetupFiles := false
+	for _, file := range files {
+		fileName := filepath.Base(file.Path)
+		for _, setupFileName := range setupFiles {
+			if fileName == setupFileName {
+				foundSetupFiles = true
+				setupDoc += fmt.Sprintf("### %s\n\n", fileName)
+
+				// Manifests listing dependencies are shown as tables
+				if tables, ok := DependencyTables(fileName, file.Content); ok {
+					setupDoc += tables
+					continue
+				}
+
+				setupDoc += "```\n"
+				// Limit content size to avoid overly large documents
+				content := file.Content
+				if len(content) > 2000 {
+					content = content[:2000] + "\n... (content truncated)"
+				}
+				setupDoc += content + "\n"
+				setupDoc += "```\n\n"
+			}
+		}
+	}
+
+	if !foundSetupFiles {
+		setupDoc += "No standard setup files found in the project.\n\n"
+	}
+
+	// Add installation and running instructions
+	setupDoc += "## Installation\n\n"
+	setupDoc += "Please follow these steps to install and set up the project:\n\n"
+	setupDoc += "1. Clone the repository\n"
+	setupDoc += "2. Install dependencies\n"
+
+	// Add project type specific instructions
+	switch projectType {
+	case filehandler.ProjectTypeNode, filehandler.ProjectTypeReact, filehandler.ProjectTypeTypeScript:
+		setupDoc += "   ```\n   npm install\n   ```\n"
+	case filehandler.ProjectTypeGo:
+		setupDoc += "   ```\n   go mod download\n   ```\n"
+	case filehandler.ProjectTypePython, filehandler.ProjectTypeDjango:
+		setupDoc += "   ```\n   pip install -r requirements.txt\n   ```\n"
+	case filehandler.ProjectTypeRuby, filehandler.ProjectTypeRails:
+		setupDoc += "   ```\n   bundle install\n   ```\n"
+	case filehandler.ProjectTypeJava:
+		setupDoc += "   ```\n   mvn install\n   ```\n"
+	case filehandler.ProjectTypeFlutter:
+		setupDoc += "   ```\n   flutter pub get\n   ```\n"
+	case filehandler.ProjectTypeRust:
+		setupDoc += "   ```\n   cargo build\n   ```\n"
+	case filehandler.ProjectTypeCSharp:
+		setupDoc += "   ```\n   dotnet restore\n   ```\n"
+	case filehandler.ProjectTypeKotlin:
+		setupDoc += "   ```\n   ./gradlew build\n   ```\n"
+	case filehandler.ProjectTypeAndroid:
+		setupDoc += "   ```\n   ./gradlew assembleDebug\n   ./gradlew installDebug\n   ```\n"
+	case filehandler.ProjectTypeSwift:
+		setupDoc += "   ```\n   swift build\n   ```\n"
+	case filehandler.ProjectTypeProtobuf:
+		setupDoc += "   ```\n   buf generate\n   ```\n"
+	case filehandler.ProjectTypePHP:
+		setupDoc += "   ```\n   composer install\n   ```\n"
+	case filehandler.ProjectTypeLaravel:
+		setupDoc += "   ```\n   composer install\n   cp .env.example .env\n   php artisan key:generate\n   php artisan migrate\n   ```\n"
+	}
+
+	setupDoc += "\n## Running the Project\n\n"
+	setupDoc += "Specific instructions for running this project will depend on its configuration.\n"
+
+	return setupDoc
+}
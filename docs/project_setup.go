@@ -0,0 +1,120 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// SetupFileName is the name of the setup guide written to the output directory
+const SetupFileName = "PROJECT_SETUP.md"
+
+// setupInputTokens is the token budget shared by all setup files in the prompt
+const setupInputTokens = 6000
+
+// setupSummaryChars is how much of each setup file is kept when they do not all fit the budget
+const setupSummaryChars = 500
+
+// setupFileNames are the build, dependency and tooling files that describe how a project is set up
+var setupFileNames = map[string]bool{
+	"package.json":        true,
+	"go.mod":              true,
+	"requirements.txt":    true,
+	"Pipfile":             true,
+	"pyproject.toml":      true,
+	"setup.py":            true,
+	"Gemfile":             true,
+	"pom.xml":             true,
+	"build.gradle":        true,
+	"build.gradle.kts":    true,
+	"settings.gradle.kts": true,
+	"Makefile":            true,
+	"pubspec.yaml":        true,
+	"composer.json":       true,
+	"CMakeLists.txt":      true,
+	"Cargo.toml":          true,
+	"Package.swift":       true,
+	"tsconfig.json":       true,
+	"Dockerfile":          true,
+	"docker-compose.yml":  true,
+	".env.example":        true,
+}
+
+// ProjectSetupGenerator asks the API to write setup instructions based on the
+// setup files actually present in a project
+type ProjectSetupGenerator struct {
+	client      api.DocumentationClient
+	projectType filehandler.ProjectType
+}
+
+// NewProjectSetupGenerator creates a new project setup generator
+func NewProjectSetupGenerator(client api.DocumentationClient, projectType filehandler.ProjectType) *ProjectSetupGenerator {
+	return &ProjectSetupGenerator{
+		client:      client,
+		projectType: projectType,
+	}
+}
+
+// SetupFiles returns the setup files among files, in traversal order
+func (g *ProjectSetupGenerator) SetupFiles(files []filehandler.FileInfo) []filehandler.FileInfo {
+	var setupFiles []filehandler.FileInfo
+	for _, file := range files {
+		if !file.IsDir && setupFileNames[filepath.Base(file.Path)] {
+			setupFiles = append(setupFiles, file)
+		}
+	}
+	return setupFiles
+}
+
+// Generate returns the Markdown for PROJECT_SETUP.md. rootDir is used to show setup files
+// relative to the project root. If the setup files do not fit the token budget together,
+// only the start of each file is sent.
+func (g *ProjectSetupGenerator) Generate(ctx context.Context, rootDir string, files []filehandler.FileInfo) (string, error) {
+	setupFiles := g.SetupFiles(files)
+	if len(setupFiles) == 0 {
+		return "", fmt.Errorf("no setup files found in %s", rootDir)
+	}
+
+	total := 0
+	for _, file := range setupFiles {
+		total += tokenizer.Estimate(file.Content)
+	}
+	summarize := total > setupInputTokens
+
+	var sb strings.Builder
+	sb.WriteString("The following are the build, dependency and tooling files of a")
+	if g.projectType != "" && g.projectType != filehandler.ProjectTypeGeneric {
+		sb.WriteString(fmt.Sprintf(" %s", g.projectType))
+	}
+	sb.WriteString(" project.\n" +
+		"Write a setup guide for a new developer based only on what these files show. Include:\n" +
+		"1. Prerequisites: required tools and language versions\n" +
+		"2. Installing dependencies\n" +
+		"3. Building, running and testing the project, using the scripts or targets defined in the files\n" +
+		"4. Required environment variables or configuration, if any\n\n" +
+		"Do not invent commands that the files do not support. " +
+		"Format the guide as Markdown starting with the heading \"# Project Setup\".\n\n")
+	if summarize {
+		sb.WriteString(fmt.Sprintf("Only the first %d characters of each file are shown.\n\n", setupSummaryChars))
+	}
+
+	for _, file := range setupFiles {
+		content := file.Content
+		if summarize && len(content) > setupSummaryChars {
+			content = strings.ToValidUTF8(content[:setupSummaryChars], "") + "\n..."
+		}
+		sb.WriteString(fmt.Sprintf("## %s\n\n```\n%s\n```\n\n", relativeName(rootDir, file.Path), content))
+	}
+
+	setupDoc, err := g.client.Complete(ctx, sb.String())
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(setupDoc) + "\n", nil
+}
@@ -0,0 +1,58 @@
+package docs
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestProjectSetupGenerator(t *testing.T) {
+	files := []filehandler.FileInfo{
+		{Path: "/project/package.json", Content: `{"scripts": {"build": "webpack", "test": "jest"}}`},
+		{Path: "/project/main.go", Content: "package main\n"},
+		{Path: "/project/backend/go.mod", Content: "module example.com/backend\n\ngo 1.22\n"},
+		{Path: "/project/src", IsDir: true},
+	}
+	client := newCompleteRecorder("# Project Setup\n\nRun npm install.\n\n")
+	generator := NewProjectSetupGenerator(client, filehandler.ProjectTypeNode)
+
+	setupFiles := generator.SetupFiles(files)
+	if len(setupFiles) != 2 || setupFiles[0].Path != "/project/package.json" || setupFiles[1].Path != "/project/backend/go.mod" {
+		t.Errorf("SetupFiles() = %v, want package.json and backend/go.mod", setupFiles)
+	}
+
+	setup, err := generator.Generate(context.Background(), "/project", files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Project Setup\n\nRun npm install.\n"; setup != want {
+		t.Errorf("Generate() = %q, want %q", setup, want)
+	}
+
+	if len(client.prompts) != 1 {
+		t.Fatalf("Complete() called %d times, want 1", len(client.prompts))
+	}
+	prompt := client.prompts[0]
+	for _, want := range []string{"a node project", "## package.json", `"test": "jest"`, "## backend/go.mod", "module example.com/backend"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "main.go") || strings.Contains(prompt, "Only the first") {
+		t.Errorf("prompt includes source files or summarizes small setup files:\n%s", prompt)
+	}
+}
+
+func TestProjectSetupGeneratorWithoutSetupFiles(t *testing.T) {
+	client := newCompleteRecorder("unused")
+	files := []filehandler.FileInfo{{Path: "/project/main.go", Content: "package main\n"}}
+
+	if _, err := NewProjectSetupGenerator(client, filehandler.ProjectTypeGo).Generate(context.Background(), "/project", files); err == nil {
+		t.Error("Generate() without setup files succeeded, want an error")
+	}
+	if len(client.prompts) != 0 {
+		t.Errorf("Complete() called %d times, want 0", len(client.prompts))
+	}
+}
@@ -0,0 +1,80 @@
+package docs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/tokenizer"
+)
+
+// ReadmeFileName is the name of the generated README written to the output directory
+const ReadmeFileName = "README.md"
+
+// Token budgets for the parts of the README prompt, 8000 tokens in total
+const (
+	readmeStructureTokens = 2000
+	readmeSetupTokens     = 1500
+	readmeFileDocsTokens  = 4500
+)
+
+// readmeSampleSize is the maximum number of file documentation excerpts included in the prompt
+const readmeSampleSize = 20
+
+// GenerateReadme asks the API to write a README.md for the project. files holds the generated
+// documentation of each file, with Path set to the source file; structure and setup are the
+// contents of PROJECT_STRUCTURE.md and PROJECT_SETUP.md. Inputs are truncated to fit the prompt.
+func GenerateReadme(ctx context.Context, files []filehandler.FileInfo, structure, setup string, client api.DocumentationClient) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("Write a complete README.md for the project described below. Use these sections, in order:\n" +
+		"Overview, Features, Installation, Usage, Project Structure, Contributing, License.\n" +
+		"Base every statement on the information provided. Where a section cannot be filled from it, " +
+		"such as License, say briefly that it is not specified. Format as Markdown starting with a " +
+		"top-level heading containing the project name.\n\n")
+
+	if structure != "" {
+		sb.WriteString("# Project structure\n\n" + tokenizer.Truncate(structure, readmeStructureTokens) + "\n\n")
+	}
+	if setup != "" {
+		sb.WriteString("# Setup\n\n" + tokenizer.Truncate(setup, readmeSetupTokens) + "\n\n")
+	}
+
+	sample := sampleFiles(files, readmeSampleSize)
+	if len(sample) > 0 {
+		perFileTokens := readmeFileDocsTokens / len(sample)
+
+		sb.WriteString("# Documentation excerpts for a sample of files\n\n")
+		for _, file := range sample {
+			sb.WriteString(fmt.Sprintf("## %s\n\n%s\n\n", file.Path, tokenizer.Truncate(file.Content, perFileTokens)))
+		}
+	}
+
+	readme, err := client.Complete(ctx, sb.String())
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(readme) + "\n", nil
+}
+
+// sampleFiles picks up to n files spread evenly over files, skipping directories
+func sampleFiles(files []filehandler.FileInfo, n int) []filehandler.FileInfo {
+	var candidates []filehandler.FileInfo
+	for _, file := range files {
+		if !file.IsDir && file.Content != "" {
+			candidates = append(candidates, file)
+		}
+	}
+
+	if len(candidates) <= n {
+		return candidates
+	}
+
+	sample := make([]filehandler.FileInfo, 0, n)
+	for i := 0; i < n; i++ {
+		sample = append(sample, candidates[i*len(candidates)/n])
+	}
+	return sample
+}
@@ -0,0 +1,64 @@
+package docs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestWriteReadme(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rootDir := t.TempDir()
+	outputDir := t.TempDir()
+	cfg := config.NewConfig()
+
+	mainPath := filepath.Join(rootDir, "main.go")
+	docPath := filepath.Join(outputDir, config.ComputeOutputPath(cfg, rootDir, mainPath))
+	if err := os.MkdirAll(filepath.Dir(docPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(docPath, []byte("# main.go\n\nStarts the HTTP server.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, SetupFileName), []byte("# Project Setup\n\nRun go build.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := newCompleteRecorder("# Server\n\nAn HTTP server.\n\n")
+	projectDocs := &ProjectDocs{
+		Client:    client,
+		Config:    cfg,
+		RootDir:   rootDir,
+		OutputDir: outputDir,
+		Files:     []filehandler.FileInfo{{Path: mainPath, Content: "package main\n"}},
+	}
+
+	path, err := projectDocs.WriteReadme(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(outputDir, ReadmeFileName); path != want {
+		t.Errorf("WriteReadme() = %s, want %s", path, want)
+	}
+	readme, err := os.ReadFile(filepath.Join(outputDir, ReadmeFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Server\n\nAn HTTP server.\n"; string(readme) != want {
+		t.Errorf("README.md = %q, want %q", readme, want)
+	}
+
+	if len(client.prompts) != 1 {
+		t.Fatalf("Complete() called %d times, want 1", len(client.prompts))
+	}
+	for _, want := range []string{"Run go build.", "## main.go", "Starts the HTTP server."} {
+		if !strings.Contains(client.prompts[0], want) {
+			t.Errorf("prompt is missing %q:\n%s", want, client.prompts[0])
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package docs
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// serverScriptPath is where Server serves the script of live pages, relative to the root
+const serverScriptPath = "_structura/serve.js"
+
+// serverEventsPath is the server-sent events stream telling live pages to reload
+const serverEventsPath = "/_structura/events"
+
+// DefaultServerReloadDelay is how long the Markdown files must stay unchanged before a Server
+// reloads the open pages
+const DefaultServerReloadDelay = 100 * time.Millisecond
+
+//go:embed assets/serve.js
+var serverScript string
+
+// Server serves the documentation in an output directory as HTML, rendering each Markdown
+// page when it is requested so edits show up without regenerating the site. Pages get a
+// sidebar of all pages with a search box, and reload in the browser when a Markdown file
+// changes. Other files, such as search-index.json, are served as they are.
+type Server struct {
+	OutputDir   string
+	ProjectName string
+	ReloadDelay time.Duration // How long the Markdown files must stay unchanged before pages reload
+
+	mu      sync.Mutex
+	clients map[chan struct{}]bool // Open event streams
+}
+
+// NewServer creates a server for the documentation in outputDir
+func NewServer(outputDir, projectName string) *Server {
+	return &Server{
+		OutputDir:   outputDir,
+		ProjectName: projectName,
+		ReloadDelay: DefaultServerReloadDelay,
+		clients:     make(map[chan struct{}]bool),
+	}
+}
+
+// ServeHTTP serves the index, the rendered pages, the embedded assets and the event stream
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	urlPath := path.Clean("/" + r.URL.Path)
+	switch urlPath {
+	case "/", "/" + HTMLIndexFileName:
+		s.serveIndex(w)
+		return
+	case "/" + htmlStyleFileName:
+		w.Header().Set("Content-Type", "text/css; charset=utf-8")
+		fmt.Fprint(w, htmlStyle)
+		return
+	case "/" + serverScriptPath:
+		w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+		fmt.Fprint(w, serverScript)
+		return
+	case serverEventsPath:
+		s.serveEvents(w, r)
+		return
+	}
+
+	relPath := strings.TrimPrefix(urlPath, "/")
+	if !filepath.IsLocal(filepath.FromSlash(relPath)) {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Markdown pages are linked as .html; the .md address redirects there
+	if strings.HasSuffix(relPath, ".md") {
+		http.Redirect(w, r, "/"+htmlPageName(relPath), http.StatusFound)
+		return
+	}
+	if page := strings.TrimSuffix(relPath, ".html") + ".md"; strings.HasSuffix(relPath, ".html") && s.exists(page) {
+		s.servePage(w, page)
+		return
+	}
+	http.ServeFile(w, r, filepath.Join(s.OutputDir, filepath.FromSlash(relPath)))
+}
+
+// exists reports whether the file page, relative to the output directory, exists
+func (s *Server) exists(page string) bool {
+	info, err := os.Stat(filepath.Join(s.OutputDir, filepath.FromSlash(page)))
+	return err == nil && !info.IsDir()
+}
+
+// serveIndex serves the page listing every Markdown page
+func (s *Server) serveIndex(w http.ResponseWriter) {
+	pages, err := markdownPages(s.OutputDir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	document := renderHTMLPage(s.ProjectName, s.ProjectName, HTMLIndexFileName, indexPageContent(s.ProjectName, pages), pages, true)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, document)
+}
+
+// servePage serves the Markdown file page, relative to the output directory, rendered as HTML
+func (s *Server) servePage(w http.ResponseWriter, page string) {
+	pages, err := markdownPages(s.OutputDir)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	content, err := renderMarkdownPage(s.OutputDir, page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	document := renderHTMLPage(s.ProjectName, strings.TrimSuffix(page, ".md"), htmlPageName(page), content, pages, true)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, document)
+}
+
+// serveEvents streams a server-sent event whenever the Markdown files change, until the
+// client disconnects
+func (s *Server) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	changed := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.clients[changed] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.clients, changed)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+			fmt.Fprint(w, "data: reload\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// Watch tells the open pages to reload when a Markdown file in the output directory is
+// created, modified or removed, until ctx is done. Changes are received from the operating
+// system through fsnotify, and a burst of them, such as a regeneration, reloads the pages once
+// the files have stayed unchanged for ReloadDelay.
+func (s *Server) Watch(ctx context.Context) error {
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("error creating file watcher: %w", err)
+	}
+	defer notifier.Close()
+	if err := watchDirs(notifier, s.OutputDir); err != nil {
+		return err
+	}
+
+	timer := time.NewTimer(s.ReloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-notifier.Errors:
+			// A missed event only delays the reload until the next change
+		case event := <-notifier.Events:
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// A new directory may already hold pages, so it counts as a change
+					watchDirs(notifier, event.Name)
+					timer.Reset(s.ReloadDelay)
+					continue
+				}
+			}
+			if (strings.HasSuffix(event.Name, ".md") && !event.Has(fsnotify.Chmod)) || event.Has(fsnotify.Remove) {
+				timer.Reset(s.ReloadDelay)
+			}
+		case <-timer.C:
+			s.notifyClients()
+		}
+	}
+}
+
+// notifyClients tells every open event stream to reload its page
+func (s *Server) notifyClients() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for client := range s.clients {
+		// A client that has not handled the previous change reloads only once
+		select {
+		case client <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// watchDirs adds a watch for dir and every directory below it, as fsnotify watches are not recursive
+func watchDirs(notifier *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if err := notifier.Add(path); err != nil {
+				return fmt.Errorf("error watching %s: %w", path, err)
+			}
+		}
+		return nil
+	})
+}
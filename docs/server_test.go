@@ -0,0 +1,100 @@
+package docs
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServerRendersPages(t *testing.T) {
+	outputDir := t.TempDir()
+	page := "# main.go\n\n[evil](javascript:alert(1)) and [next](util.go.md#usage)\n"
+	if err := os.WriteFile(filepath.Join(outputDir, "main.go.md"), []byte(page), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(NewServer(outputDir, "project"))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/main.go.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	html := string(body)
+	if !strings.Contains(html, `<a href="util.go.html#usage">next</a>`) {
+		t.Errorf("page does not link to the rendered page of util.go:\n%s", html)
+	}
+	if strings.Contains(html, "javascript:") {
+		t.Errorf("page keeps the javascript: link:\n%s", html)
+	}
+}
+
+func TestServerReloadsOnChange(t *testing.T) {
+	outputDir := t.TempDir()
+	s := NewServer(outputDir, "project")
+	s.ReloadDelay = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watchErr := make(chan error, 1)
+	go func() { watchErr <- s.Watch(ctx) }()
+
+	server := httptest.NewServer(s)
+	defer server.Close()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+serverEventsPath, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	events := make(chan string, 8)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+				events <- line
+			}
+		}
+		close(events)
+	}()
+
+	// The watch may not be in place yet, so the page is written until the reload arrives,
+	// in a directory created after the server started
+	pageDir := filepath.Join(outputDir, "pkg")
+	if err := os.Mkdir(pageDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if event != "data: reload" {
+				t.Fatalf("event %q, want a reload", event)
+			}
+			cancel()
+			if err := <-watchErr; err != nil {
+				t.Errorf("Watch() error = %v", err)
+			}
+			return
+		case <-ticker.C:
+			if err := os.WriteFile(filepath.Join(pageDir, "util.go.md"), []byte("# util.go\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		case <-timeout:
+			t.Fatal("no reload event after changing a page")
+		}
+	}
+}
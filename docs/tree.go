@@ -0,0 +1,96 @@
+package docs
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// treeNode is a directory of the rendered tree
+type treeNode struct {
+	size  int64 // Total size of the files below the directory
+	files []string
+	dirs  map[string]*treeNode
+}
+
+// RenderTree renders files as an ASCII directory tree rooted at rootDir, with each
+// directory's total size in parentheses. Only the given files are shown, so the tree follows
+// the ignore rules of the traversal that produced them.
+func RenderTree(rootDir string, files []filehandler.FileInfo) string {
+	root := &treeNode{dirs: make(map[string]*treeNode)}
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		relPath, err := filepath.Rel(rootDir, file.Path)
+		if err != nil {
+			continue
+		}
+
+		parts := strings.Split(filepath.ToSlash(relPath), "/")
+		node := root
+		node.size += file.Size
+		for _, dir := range parts[:len(parts)-1] {
+			child, ok := node.dirs[dir]
+			if !ok {
+				child = &treeNode{dirs: make(map[string]*treeNode)}
+				node.dirs[dir] = child
+			}
+			node = child
+			node.size += file.Size
+		}
+		node.files = append(node.files, parts[len(parts)-1])
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s/ (%s)\n", filepath.Base(rootDir), formatSize(root.size)))
+	root.render(&sb, "")
+	return sb.String()
+}
+
+// render writes the directory's subdirectories, then its files, each line starting with prefix
+func (n *treeNode) render(sb *strings.Builder, prefix string) {
+	dirs := make([]string, 0, len(n.dirs))
+	for name := range n.dirs {
+		dirs = append(dirs, name)
+	}
+	sort.Strings(dirs)
+	sort.Strings(n.files)
+
+	count := len(dirs) + len(n.files)
+	for i, name := range dirs {
+		branch, indent := treeBranch(i == count-1)
+		child := n.dirs[name]
+		sb.WriteString(fmt.Sprintf("%s%s%s/ (%s)\n", prefix, branch, name, formatSize(child.size)))
+		child.render(sb, prefix+indent)
+	}
+	for i, name := range n.files {
+		branch, _ := treeBranch(len(dirs)+i == count-1)
+		sb.WriteString(prefix + branch + name + "\n")
+	}
+}
+
+// treeBranch returns the connector for an entry and the indent continuing below it
+func treeBranch(last bool) (branch, indent string) {
+	if last {
+		return "└── ", "    "
+	}
+	return "├── ", "│   "
+}
+
+// formatSize formats a byte count with a binary unit, e.g. 1536 -> "1.5 KB"
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
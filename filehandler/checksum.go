@@ -0,0 +1,151 @@
+package filehandler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ChecksumFileName is the name of the file in the output directory that records the
+// checksum of each source file, and the prompt version, when it was documented
+const ChecksumFileName = ".structura_checksums.json"
+
+// Checksum returns the hex-encoded SHA-256 of content
+func Checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// legacyPromptVersion is the prompt version of files recorded before prompt versions were
+const legacyPromptVersion = "v1"
+
+// ChecksumEntry records the checksum of the content a file's documentation was generated
+// from, and the version of the prompts it was generated with
+type ChecksumEntry struct {
+	Checksum      string `json:"checksum"`
+	PromptVersion string `json:"prompt_version"`
+}
+
+// UnmarshalJSON also reads the bare checksums of stores written before prompt versions were
+// recorded, as documented with the v1 prompts
+func (e *ChecksumEntry) UnmarshalJSON(data []byte) error {
+	var checksum string
+	if err := json.Unmarshal(data, &checksum); err == nil {
+		*e = ChecksumEntry{Checksum: checksum, PromptVersion: legacyPromptVersion}
+		return nil
+	}
+
+	type entry ChecksumEntry // Without this method
+	return json.Unmarshal(data, (*entry)(e))
+}
+
+// ChecksumStore maps source file paths, relative to the project root, to the checksum and
+// prompt version their documentation was generated with. It is safe for concurrent use.
+type ChecksumStore struct {
+	path      string
+	mu        sync.Mutex
+	checksums map[string]ChecksumEntry
+	unsaved   int // Entries set since the store was last written
+}
+
+// storeSaveInterval is how many entries are set between writes of a store, so an interrupted
+// run loses little progress without the whole file being rewritten for every file
+const storeSaveInterval = 50
+
+// LoadChecksumStore reads the checksum store from outputDir.
+// A missing file yields an empty store.
+func LoadChecksumStore(outputDir string) (*ChecksumStore, error) {
+	store := &ChecksumStore{
+		path:      filepath.Join(outputDir, ChecksumFileName),
+		checksums: make(map[string]ChecksumEntry),
+	}
+
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return store, fmt.Errorf("error reading %s: %w", ChecksumFileName, err)
+	}
+
+	if err := json.Unmarshal(data, &store.checksums); err != nil {
+		return store, fmt.Errorf("error parsing %s: %w", ChecksumFileName, err)
+	}
+	if store.checksums == nil {
+		store.checksums = make(map[string]ChecksumEntry)
+	}
+
+	return store, nil
+}
+
+// Get returns the entry recorded for relPath and whether one was recorded
+func (cs *ChecksumStore) Get(relPath string) (ChecksumEntry, bool) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	entry, ok := cs.checksums[relPath]
+	return entry, ok
+}
+
+// Paths returns the relative paths of every recorded file, sorted
+func (cs *ChecksumStore) Paths() []string {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	paths := make([]string, 0, len(cs.checksums))
+	for relPath := range cs.checksums {
+		paths = append(paths, relPath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Set records the checksum and prompt version for relPath. The store is written to disk every
+// storeSaveInterval entries, so progress survives the run being interrupted; Flush writes the rest.
+func (cs *ChecksumStore) Set(relPath, checksum, promptVersion string) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	cs.checksums[relPath] = ChecksumEntry{Checksum: checksum, PromptVersion: promptVersion}
+	cs.unsaved++
+	if cs.unsaved < storeSaveInterval {
+		return nil
+	}
+	return cs.save()
+}
+
+// Flush writes the entries set since the store was last written to disk
+func (cs *ChecksumStore) Flush() error {
+	if cs == nil {
+		return nil
+	}
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if cs.unsaved == 0 {
+		return nil
+	}
+	return cs.save()
+}
+
+// save writes the store to disk. The caller must hold cs.mu.
+func (cs *ChecksumStore) save() error {
+	data, err := json.MarshalIndent(cs.checksums, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cs.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(cs.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", ChecksumFileName, err)
+	}
+	cs.unsaved = 0
+	return nil
+}
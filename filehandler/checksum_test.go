@@ -0,0 +1,83 @@
+package filehandler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChecksumStoreTwoVersions(t *testing.T) {
+	outputDir := t.TempDir()
+	v1 := "package main\n\nfunc main() {}\n"
+	v2 := "package main\n\nfunc main() { println(\"changed\") }\n"
+	if Checksum(v1) == Checksum(v2) {
+		t.Fatal("two versions of the file have the same checksum")
+	}
+
+	store, err := LoadChecksumStore(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("main.go", Checksum(v1), "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries are kept in memory until the store is flushed
+	path := filepath.Join(outputDir, ChecksumFileName)
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s written before Flush, error = %v", ChecksumFileName, err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	reloaded, err := LoadChecksumStore(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, ok := reloaded.Get("main.go")
+	if !ok {
+		t.Fatal("main.go not recorded after Flush")
+	}
+	if entry.Checksum != Checksum(v1) {
+		t.Error("the documented version does not match its recorded checksum")
+	}
+	if entry.Checksum == Checksum(v2) {
+		t.Error("the changed version matches the checksum of the documented one")
+	}
+
+	// Documenting the second version replaces the first
+	if err := reloaded.Set("main.go", Checksum(v2), "v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloaded.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	final, err := LoadChecksumStore(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := final.Get("main.go"); entry.Checksum != Checksum(v2) {
+		t.Error("the checksum of the second version was not recorded")
+	}
+}
+
+func TestChecksumStoreSavesEveryInterval(t *testing.T) {
+	outputDir := t.TempDir()
+	store, err := LoadChecksumStore(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < storeSaveInterval; i++ {
+		if err := store.Set(fmt.Sprintf("file%d.go", i), Checksum(fmt.Sprint(i)), "v1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An interrupted run keeps the progress written so far
+	if _, err := os.Stat(filepath.Join(outputDir, ChecksumFileName)); err != nil {
+		t.Errorf("%s not written after %d entries: %v", ChecksumFileName, storeSaveInterval, err)
+	}
+}
@@ -0,0 +1,114 @@
+package filehandler
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Character sets reported by DefaultEncodingDetector
+const (
+	CharsetUTF8        = "utf-8"
+	CharsetUTF16LE     = "utf-16le"
+	CharsetUTF16BE     = "utf-16be"
+	CharsetWindows1252 = "windows-1252"
+	CharsetISO88591    = "iso-8859-1"
+)
+
+// EncodingDetector guesses the character set of file contents. Charset names are WHATWG
+// encoding labels such as "utf-8", "windows-1252" or "shift_jis"; confidence is between 0 and 1.
+type EncodingDetector interface {
+	Detect(data []byte) (charset string, confidence float64)
+}
+
+// DefaultEncodingDetector recognises byte order marks and otherwise tells UTF-8 from the
+// single-byte encodings common in legacy Java and PHP sources, ISO-8859-1 and Windows-1252
+type DefaultEncodingDetector struct{}
+
+// Detect returns the character set of data
+func (DefaultEncodingDetector) Detect(data []byte) (string, float64) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return CharsetUTF8, 1
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return CharsetUTF16LE, 1
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return CharsetUTF16BE, 1
+	}
+
+	if utf8.Valid(data) {
+		return CharsetUTF8, 1
+	}
+
+	// Bytes 0x80-0x9F are control characters in ISO-8859-1 but punctuation such as curly
+	// quotes in Windows-1252; accented letters are 0xC0-0xFF in both
+	var high, letters, windowsOnly int
+	for _, b := range data {
+		switch {
+		case b >= 0xC0:
+			high++
+			letters++
+		case b >= 0xA0:
+			high++
+		case b >= 0x80:
+			high++
+			windowsOnly++
+		}
+	}
+
+	confidence := 0.5 + 0.5*float64(letters)/float64(high)
+	if windowsOnly > 0 {
+		return CharsetWindows1252, confidence
+	}
+	return CharsetISO88591, confidence
+}
+
+// decodeContent converts data in the character set detector reports to UTF-8, dropping any
+// byte order mark. Data in an unknown character set is returned unchanged.
+func decodeContent(data []byte, detector EncodingDetector) string {
+	if detector == nil {
+		detector = DefaultEncodingDetector{}
+	}
+
+	charset, _ := detector.Detect(data)
+	enc := charsetEncoding(charset)
+	if enc == nil {
+		return string(data)
+	}
+	if enc == encoding.Nop {
+		return string(bytes.TrimPrefix(data, []byte{0xEF, 0xBB, 0xBF}))
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return string(data)
+	}
+	return string(decoded)
+}
+
+// charsetEncoding returns the encoding named charset, encoding.Nop for UTF-8, or nil if
+// the name is unknown
+func charsetEncoding(charset string) encoding.Encoding {
+	switch strings.ToLower(charset) {
+	case CharsetUTF8, "utf8", "ascii", "us-ascii", "":
+		return encoding.Nop
+	case CharsetUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM)
+	case CharsetUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM)
+	case CharsetISO88591, "latin1", "latin-1":
+		// The WHATWG index maps this label to Windows-1252, which decodes 0x80-0x9F differently
+		return charmap.ISO8859_1
+	}
+
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil
+	}
+	return enc
+}
@@ -0,0 +1,92 @@
+package filehandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultEncodingDetector(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"ASCII", []byte("class Main {}\n"), CharsetUTF8},
+		{"UTF-8", []byte("// Café crème\n"), CharsetUTF8},
+		{"UTF-8 BOM", []byte("\xEF\xBB\xBFclass Main {}\n"), CharsetUTF8},
+		{"UTF-16LE BOM", []byte("\xFF\xFEc\x00"), CharsetUTF16LE},
+		{"UTF-16BE BOM", []byte("\xFE\xFF\x00c"), CharsetUTF16BE},
+		{"ISO-8859-1", []byte("// Caf\xE9 cr\xE8me\n"), CharsetISO88591},
+		{"Windows-1252 curly quotes", []byte("// \x93Caf\xE9\x94\n"), CharsetWindows1252},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := (DefaultEncodingDetector{}).Detect(tt.data); got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeContent(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"UTF-8 unchanged", []byte("// Café\n"), "// Café\n"},
+		{"UTF-8 BOM dropped", []byte("\xEF\xBB\xBF// Café\n"), "// Café\n"},
+		{"UTF-16LE", []byte("\xFF\xFEC\x00a\x00f\x00\xE9\x00"), "Café"},
+		{"ISO-8859-1", []byte("// Caf\xE9 cr\xE8me \xA9 2024\n"), "// Café crème © 2024\n"},
+		{"Windows-1252", []byte("// \x93Caf\xE9\x94 \x80\n"), "// “Café” €\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeContent(tt.data, nil); got != tt.want {
+				t.Errorf("decodeContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fixedDetector reports the same character set for all data
+type fixedDetector string
+
+func (d fixedDetector) Detect([]byte) (string, float64) { return string(d), 1 }
+
+func TestTraverseDirectoryDecodesISO88591(t *testing.T) {
+	root := t.TempDir()
+	source := []byte("// Gr\xFC\xDFe aus M\xFCnchen\npublic class Main {}\n")
+	if err := os.WriteFile(filepath.Join(root, "Main.java"), source, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		detector EncodingDetector
+		want     string
+	}{
+		{"default detector", nil, "// Grüße aus München\npublic class Main {}\n"},
+		{"injected detector", fixedDetector("macintosh"), "// Gr¸ﬂe aus M¸nchen\npublic class Main {}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fh := NewFileHandler()
+			fh.EncodingDetector = tt.detector
+			files, err := fh.TraverseDirectory(root)
+			if err != nil {
+				t.Fatalf("TraverseDirectory() error = %v", err)
+			}
+			if len(files) != 1 {
+				t.Fatalf("TraverseDirectory() returned %d files, want 1", len(files))
+			}
+			if files[0].Content != tt.want {
+				t.Errorf("Content = %q, want %q", files[0].Content, tt.want)
+			}
+		})
+	}
+}
@@ -2,40 +2,105 @@ package filehandler
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Abiggj/structura/config"
 )
 
 // ProjectType represents the type of project
 type ProjectType string
 
 const (
-	ProjectTypeGeneric ProjectType = "generic"
-	ProjectTypeReact   ProjectType = "react"
-	ProjectTypeNode    ProjectType = "node"
-	ProjectTypePython  ProjectType = "python"
-	ProjectTypeDjango  ProjectType = "django"
-	ProjectTypeGo      ProjectType = "go"
-	ProjectTypeJava    ProjectType = "java"
-	ProjectTypeRuby    ProjectType = "ruby"
-	ProjectTypeRails   ProjectType = "rails"
-	ProjectTypeFlutter ProjectType = "flutter"
+	ProjectTypeGeneric    ProjectType = "generic"
+	ProjectTypeReact      ProjectType = "react"
+	ProjectTypeNode       ProjectType = "node"
+	ProjectTypePython     ProjectType = "python"
+	ProjectTypeDjango     ProjectType = "django"
+	ProjectTypeGo         ProjectType = "go"
+	ProjectTypeJava       ProjectType = "java"
+	ProjectTypeRuby       ProjectType = "ruby"
+	ProjectTypeRails      ProjectType = "rails"
+	ProjectTypeFlutter    ProjectType = "flutter"
+	ProjectTypeRust       ProjectType = "rust"
+	ProjectTypeCSharp     ProjectType = "csharp"
+	ProjectTypeKotlin     ProjectType = "kotlin"
+	ProjectTypeSwift      ProjectType = "swift"
+	ProjectTypeTypeScript ProjectType = "typescript"
+	ProjectTypeProtobuf   ProjectType = "protobuf"
+	ProjectTypeGraphQL    ProjectType = "graphql"
+	ProjectTypePHP        ProjectType = "php"
+	ProjectTypeLaravel    ProjectType = "laravel"
+	ProjectTypeAndroid    ProjectType = "android"
 )
 
 // FileInfo represents information about a file
 type FileInfo struct {
-	Path    string
-	Content string
-	Size    int64
-	IsDir   bool
+	Path     string
+	Content  string
+	Size     int64
+	ModTime  time.Time
+	IsDir    bool
+	Language string
+	Checksum string // Hex-encoded SHA-256 of Content, used to detect changed files
+}
+
+// TooLarge reports whether the file is too large for its content to have been read
+func (f FileInfo) TooLarge() bool {
+	return !f.IsDir && f.Size >= maxContentSize
 }
 
 // FileHandler handles file operations
 type FileHandler struct {
-	IgnoreDirs  []string
-	IgnoreFiles []string
-	ProjectType ProjectType
+	IgnoreDirs      []string
+	IgnoreFiles     []string
+	IncludePatterns []string // When set, only files matching one of these patterns are collected
+	ProjectType     ProjectType
+	
+	// MaxDepth limits how deep the traversal descends, 0 meaning unlimited. The root
+	// directory is depth 0 and its immediate children are depth 1, so MaxDepth 2 returns
+	// root/a.go and root/pkg/b.go but not root/pkg/sub/c.go.
+	MaxDepth int
+	
+	// SortOrder is applied to the files returned by a traversal. PriorityPatterns
+	// select the files moved to the front by SortPriorityFirst.
+	SortOrder        SortOrder
+	PriorityPatterns []string
+	
+	// ShouldIgnoreCallback, when non-nil, is called for every path that passes the
+	// name and glob checks. Returning true ignores the path (and, for directories,
+	// everything below it). It is called from a single goroutine, the directory walk,
+	// even when directories are read concurrently, so it needs no locking.
+	ShouldIgnoreCallback func(path string, info os.FileInfo) bool
+	
+	// EncodingDetector guesses the character set of each file read, so contents in
+	// other encodings can be converted to UTF-8. Nil uses DefaultEncodingDetector.
+	EncodingDetector EncodingDetector
+	
+	// Normalizers rewrite each file's content in order after it is read, e.g. to strip
+	// license headers. Checksums are taken before, so changing them does not mark files changed.
+	Normalizers []ContentNormalizer
+	
+	// ModifiedAfter, when non-nil, skips files last modified before this time
+	ModifiedAfter *time.Time
+	
+	// ExcludePaths are files and directories left out by path rather than by name, so
+	// internal/generated can be excluded without excluding every directory named generated.
+	// Entries are absolute or relative to the directory being traversed.
+	ExcludePaths []string
+	
+	// Metadata describes the project, read from its manifest by the last traversal
+	Metadata ProjectMetadata
+	
+	ignored atomic.Int64 // Entries left out by the last traversal
 }
 
 // NewFileHandler creates a new file handler
@@ -60,7 +125,10 @@ func NewFileHandler() *FileHandler {
 			"*.suo", "*.user", "*.userosscache", "*.dbmdl", 
 			"*.sh", "*README*", "*readme*",
 		},
-		ProjectType: ProjectTypeGeneric,
+		ProjectType:      ProjectTypeGeneric,
+		SortOrder:        SortLexical,
+		EncodingDetector: DefaultEncodingDetector{},
+		Normalizers:      DefaultNormalizers(),
 	}
 }
 
@@ -109,18 +177,142 @@ func (fh *FileHandler) SetProjectType(projectType ProjectType) {
 	case ProjectTypeFlutter:
 		fh.IgnoreDirs = append(fh.IgnoreDirs, ".dart_tool", "build")
 		fh.IgnoreFiles = append(fh.IgnoreFiles, "pubspec.lock", "*.g.dart")
+	case ProjectTypeRust:
+		fh.IgnoreDirs = append(fh.IgnoreDirs, "target")
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.rlib", "Cargo.lock")
+	case ProjectTypeCSharp:
+		fh.IgnoreDirs = append(fh.IgnoreDirs, "bin", "obj")
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.csproj", "*.sln")
+	case ProjectTypeKotlin:
+		fh.IgnoreDirs = append(fh.IgnoreDirs, ".gradle", "build")
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.class", "*.apk", "gradlew", "local.properties")
+	case ProjectTypeAndroid:
+		// Raw resources, fonts and assets are binary; layouts and values under res/ are kept
+		fh.IgnoreDirs = append(fh.IgnoreDirs, ".gradle", "build", "assets", "res/raw", "res/font")
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.class", "*.apk", "*.aab", "*.dex", "*.jks", "*.keystore", "gradlew", "local.properties")
+	case ProjectTypeSwift:
+		fh.IgnoreDirs = append(fh.IgnoreDirs, ".build", "Packages")
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.xcworkspace")
+	case ProjectTypeTypeScript:
+		fh.IgnoreDirs = append(fh.IgnoreDirs, "dist")
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.js", "*.d.ts")
+	case ProjectTypeProtobuf:
+		// Code generated from the schemas documents nothing the schemas don't
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.pb.go", "*.pb_grpc.go", "*_grpc.pb.go", "*_pb2.py", "*_pb2_grpc.py")
+	case ProjectTypeGraphQL:
+		fh.IgnoreDirs = append(fh.IgnoreDirs, "__generated__")
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.generated.ts", "*.generated.js")
+	case ProjectTypePHP:
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.cache")
+	case ProjectTypeLaravel:
+		// Compiled views, sessions and service caches, and the CSS and JS built into public/
+		fh.IgnoreDirs = append(fh.IgnoreDirs, "bootstrap/cache", "storage/framework", "storage/logs", "public/build", "public/css", "public/js")
+		fh.IgnoreFiles = append(fh.IgnoreFiles, "*.cache", "mix-manifest.json", "hot")
+	}
+}
+
+// projectMarkers maps marker file patterns in the project root, or in a module directory just
+// below it, to project types, in priority order
+var projectMarkers = []struct {
+	pattern     string
+	projectType ProjectType
+}{
+	{"Cargo.toml", ProjectTypeRust},
+	{"*.csproj", ProjectTypeCSharp},
+	{"AndroidManifest.xml", ProjectTypeAndroid},
+	{"*/src/main/AndroidManifest.xml", ProjectTypeAndroid},
+	{"build.gradle.kts", ProjectTypeKotlin},
+	{"settings.gradle.kts", ProjectTypeKotlin},
+	{"Package.swift", ProjectTypeSwift},
+	{"artisan", ProjectTypeLaravel},
+	{"composer.json", ProjectTypePHP},
+	{"tsconfig.json", ProjectTypeTypeScript},
+	{"pubspec.yaml", ProjectTypeFlutter},
+	{"go.mod", ProjectTypeGo},
+	{"manage.py", ProjectTypeDjango},
+	{"requirements.txt", ProjectTypePython},
+	{"setup.py", ProjectTypePython},
+	{"pom.xml", ProjectTypeJava},
+	{"build.gradle", ProjectTypeJava},
+	{"config.ru", ProjectTypeRails},
+	{"Gemfile", ProjectTypeRuby},
+	{"package.json", ProjectTypeNode},
+	{"buf.yaml", ProjectTypeProtobuf},
+	{"*.proto", ProjectTypeProtobuf},
+	{".graphqlrc*", ProjectTypeGraphQL},
+	{"*.graphql", ProjectTypeGraphQL},
+}
+
+// DetectProjectType guesses the project type from marker files in the root directory
+func DetectProjectType(rootDir string) ProjectType {
+	for _, marker := range projectMarkers {
+		matches, err := filepath.Glob(filepath.Join(rootDir, marker.pattern))
+		if err == nil && len(matches) > 0 {
+			return marker.projectType
+		}
+	}
+	return ProjectTypeGeneric
+}
+
+// ApplyProjectConfig merges settings from .structura.yaml over the handler defaults.
+// An explicitly selected project type is kept; the file's project type only replaces the generic default.
+func (fh *FileHandler) ApplyProjectConfig(projectConfig *config.ProjectConfig) {
+	if projectConfig == nil {
+		return
+	}
+
+	if projectConfig.ProjectType != "" && fh.ProjectType == ProjectTypeGeneric {
+		fh.SetProjectType(ProjectType(projectConfig.ProjectType))
+	}
+
+	// Every traversal applies the file again, so patterns already present are not repeated
+	for _, pattern := range projectConfig.IgnorePatterns {
+		if !slices.Contains(fh.IgnoreFiles, pattern) {
+			fh.IgnoreFiles = append(fh.IgnoreFiles, pattern)
+		}
+	}
+	if len(projectConfig.IncludePatterns) > 0 {
+		fh.IncludePatterns = projectConfig.IncludePatterns
+	}
+	
+	// A depth set on the handler, e.g. from --max-depth, takes precedence
+	if projectConfig.MaxDepth > 0 && fh.MaxDepth == 0 {
+		fh.MaxDepth = projectConfig.MaxDepth
+	}
+}
+
+// depth returns the depth of path below rootDir, counting rootDir as depth 0
+func depth(rootDir, path string) int {
+	relPath, err := filepath.Rel(rootDir, path)
+	if err != nil || relPath == "." {
+		return 0
+	}
+	return strings.Count(relPath, string(filepath.Separator)) + 1
+}
+
+// ShouldInclude checks if a file matches the include patterns, matching either its
+// base name or its path relative to rootDir
+func (fh *FileHandler) ShouldInclude(rootDir, path string) bool {
+	if len(fh.IncludePatterns) == 0 {
+		return true
 	}
+
+	return matchesAnyPattern(fh.IncludePatterns, rootDir, path)
 }
 
 // ShouldIgnore checks if a file or directory should be ignored
 func (fh *FileHandler) ShouldIgnore(path string) bool {
 	basename := filepath.Base(path)
 
-	// Check if it's in the ignore dirs list
+	// Check if it's in the ignore dirs list. Entries with a slash, such as bootstrap/cache,
+	// match the end of the path.
 	for _, dir := range fh.IgnoreDirs {
 		if basename == dir {
 			return true
 		}
+		if strings.Contains(dir, "/") && strings.HasSuffix(filepath.ToSlash(path), "/"+dir) {
+			return true
+		}
 	}
 
 	// Check file patterns
@@ -133,61 +325,251 @@ func (fh *FileHandler) ShouldIgnore(path string) bool {
 	return false
 }
 
-// TraverseDirectory walks through the directory and collects file information
+// ShouldExclude checks if path is one of ExcludePaths or below one, resolving relative
+// entries against rootDir
+func (fh *FileHandler) ShouldExclude(rootDir, path string) bool {
+	if len(fh.ExcludePaths) == 0 {
+		return false
+	}
+	
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	for _, exclude := range fh.ExcludePaths {
+		if !filepath.IsAbs(exclude) {
+			exclude = filepath.Join(rootDir, exclude)
+		}
+		absExclude, err := filepath.Abs(exclude)
+		if err != nil {
+			continue
+		}
+		// Compare whole path elements, so internal/gen does not exclude internal/generated
+		if absPath == absExclude || strings.HasPrefix(absPath, strings.TrimSuffix(absExclude, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxContentSize is the largest file whose content is read during traversal
+const maxContentSize = 5 * 1024 * 1024 // 5MB
+
+// TraverseDirectory walks through the directory and collects file information. Files are
+// returned in walk order, which is by path, and then sorted by SortOrder.
 func (fh *FileHandler) TraverseDirectory(rootDir string) ([]FileInfo, error) {
+	rootDir, err := fh.prepareTraversal(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []FileInfo
+	err = fh.walkFiles(rootDir, func(path string, d fs.DirEntry) {
+		files = append(files, fh.newFileInfo(path, d))
+	})
+	if err != nil {
+		return files, err
+	}
+
+	fh.sortFiles(rootDir, files)
+
+	return files, nil
+}
+
+// TraverseDirectoryConcurrent is TraverseDirectory with one goroutine per directory and per
+// file, at most runtime.NumCPU() of which read from disk at once. ShouldIgnoreCallback is still
+// called from a single goroutine. The files are sorted by path, as TraverseDirectory returns
+// them, before SortOrder is applied.
+func (fh *FileHandler) TraverseDirectoryConcurrent(rootDir string) ([]FileInfo, error) {
+	rootDir, err := fh.prepareTraversal(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	sem := make(chan struct{}, runtime.NumCPU())
+	results := make(chan FileInfo)
 	var files []FileInfo
+	collected := make(chan struct{})
+	go func() {
+		for file := range results {
+			files = append(files, file)
+		}
+		close(collected)
+	}()
+
+	// Read sizes and contents while the walk goes on
+	var wg sync.WaitGroup
+	err = fh.walkFilesConcurrent(rootDir, sem, func(path string, d fs.DirEntry) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			file := fh.newFileInfo(path, d)
+			<-sem
+			results <- file
+		}()
+	})
+	wg.Wait()
+	close(results)
+	<-collected
+
+	// Restore path order, which the goroutines finishing in any order lost
+	sort.Slice(files, func(i, j int) bool { return walkOrderLess(files[i].Path, files[j].Path) })
+	if err != nil {
+		return files, err
+	}
+
+	fh.sortFiles(rootDir, files)
+
+	return files, nil
+}
+
+// prepareTraversal checks that rootDir is a directory and applies its .structura.yaml and
+// manifest before a walk. It returns the cleaned rootDir.
+func (fh *FileHandler) prepareTraversal(rootDir string) (string, error) {
+	fh.ignored.Store(0)
 
 	// Clean and normalize the path for cross-platform compatibility
 	rootDir = filepath.Clean(rootDir)
-	
+
 	// Check if directory exists before walking
 	info, err := os.Stat(rootDir)
 	if err != nil {
-		return nil, fmt.Errorf("error accessing directory: %w", err)
+		return "", fmt.Errorf("error accessing directory: %w", err)
 	}
-	
+
 	if !info.IsDir() {
-		return nil, fmt.Errorf("path is not a directory: %s", rootDir)
+		return "", fmt.Errorf("path is not a directory: %s", rootDir)
+	}
+
+	// Merge project-level settings from .structura.yaml
+	projectConfig, err := config.LoadProjectConfig(rootDir)
+	if err != nil {
+		return "", err
 	}
+	fh.ApplyProjectConfig(projectConfig)
 
-	err = filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
+	// A manifest that cannot be parsed still leaves the directory name as the project name
+	fh.Metadata, _ = ExtractProjectMetadata(rootDir)
+
+	return rootDir, nil
+}
+
+// newFileInfo collects the information of the file at path, found by a walk as d
+func (fh *FileHandler) newFileInfo(path string, d fs.DirEntry) FileInfo {
+	file := FileInfo{
+		Path:     path,
+		Language: DetectLanguage(path),
+	}
+	fh.readFileInfo(&file, d)
+	return file
+}
+
+// walkFiles walks rootDir and calls visit for every file passing the ignore rules,
+// ShouldIgnoreCallback, MaxDepth and the include patterns, in lexical order
+func (fh *FileHandler) walkFiles(rootDir string, visit func(path string, d fs.DirEntry)) error {
+	return filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip ignored files and directories
-		if fh.ShouldIgnore(path) {
-			if info.IsDir() {
+		action, err := fh.walkAction(rootDir, path, d)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case walkSkip:
+			if d.IsDir() {
 				return filepath.SkipDir
 			}
-			return nil
+		case walkVisit:
+			visit(path, d)
 		}
+		return nil
+	})
+}
 
-		// Create FileInfo struct
-		fileInfo := FileInfo{
-			Path:  path,
-			Size:  info.Size(),
-			IsDir: info.IsDir(),
-		}
+// walkAction is what a directory walk does with an entry
+type walkAction int
 
-		// Skip directories
-		if info.IsDir() {
-			return nil
+const (
+	walkSkip    walkAction = iota // Leave out the file, or the directory and everything below it
+	walkDescend                   // Walk the directory's entries
+	walkVisit                     // Collect the file
+)
+
+// walkAction decides whether a walk of rootDir collects the entry at path or, for a
+// directory, descends into it
+func (fh *FileHandler) walkAction(rootDir, path string, d fs.DirEntry) (walkAction, error) {
+	// Skip ignored files and directories
+	ignored := fh.ShouldIgnore(path) || fh.ShouldExclude(rootDir, path)
+	if !ignored && fh.ShouldIgnoreCallback != nil {
+		info, err := d.Info()
+		if err != nil {
+			return walkSkip, err
 		}
+		ignored = fh.ShouldIgnoreCallback(path, info)
+	}
+	if ignored {
+		fh.ignored.Add(1)
+		return walkSkip, nil
+	}
 
-		// Only read reasonable sized files
-		if info.Size() < 5*1024*1024 { // Less than 5MB
-			content, err := os.ReadFile(path)
-			if err == nil {
-				fileInfo.Content = string(content)
-			}
+	// Skip directories whose children would exceed MaxDepth
+	if d.IsDir() {
+		if fh.MaxDepth > 0 && depth(rootDir, path) >= fh.MaxDepth {
+			fh.ignored.Add(1)
+			return walkSkip, nil
+		}
+		return walkDescend, nil
+	}
+	
+	// Skip files not matched by the include patterns
+	if !fh.ShouldInclude(rootDir, path) {
+		fh.ignored.Add(1)
+		return walkSkip, nil
+	}
+	
+	// Skip files not modified recently enough
+	if fh.ModifiedAfter != nil {
+		info, err := d.Info()
+		if err != nil {
+			return walkSkip, err
 		}
+		if info.ModTime().Before(*fh.ModifiedAfter) {
+			fh.ignored.Add(1)
+			return walkSkip, nil
+		}
+	}
+	return walkVisit, nil
+}
 
-		files = append(files, fileInfo)
-		return nil
-	})
+// IgnoredCount returns the number of files and directories the last traversal left out
+// because of the ignore rules, ShouldIgnoreCallback, MaxDepth, the include patterns or
+// ModifiedAfter. A directory left out counts once, however many files it contains.
+func (fh *FileHandler) IgnoredCount() int {
+	return int(fh.ignored.Load())
+}
 
-	return files, err
+// readFileInfo fills in the size and, for reasonably sized files, the content of a file
+// converted to UTF-8
+func (fh *FileHandler) readFileInfo(fileInfo *FileInfo, entry fs.DirEntry) {
+	info, err := entry.Info()
+	if err != nil {
+		return
+	}
+	fileInfo.Size = info.Size()
+	fileInfo.ModTime = info.ModTime()
+
+	// Only read reasonable sized files
+	if info.Size() < maxContentSize {
+		content, err := os.ReadFile(fileInfo.Path)
+		if err == nil {
+			fileInfo.Content = decodeContent(content, fh.EncodingDetector)
+			fileInfo.Checksum = Checksum(fileInfo.Content)
+			fileInfo.Content = fh.normalize(fileInfo.Content, fileInfo.Path)
+		}
+	}
 }
 
 // GetFileExtension returns the file extension without the dot
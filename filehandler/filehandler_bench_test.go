@@ -0,0 +1,77 @@
+package filehandler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// syntheticSource is the content of each generated file, about 2 KB of Go
+var syntheticSource = "package synthetic\n\n" + strings.Repeat("// Add returns the sum of a and b.\nfunc Add(a, b int) int { return a + b }\n\n", 25)
+
+// writeSyntheticTree creates fanout directories per level, depth levels deep, each holding
+// filesPerDir Go files, and returns the number of files written
+func writeSyntheticTree(b *testing.B, dir string, depth, fanout, filesPerDir int) int {
+	b.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		b.Fatal(err)
+	}
+
+	written := 0
+	for i := 0; i < filesPerDir; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("file%03d.go", i))
+		if err := os.WriteFile(path, []byte(syntheticSource), 0644); err != nil {
+			b.Fatal(err)
+		}
+		written++
+	}
+	if depth > 0 {
+		for i := 0; i < fanout; i++ {
+			written += writeSyntheticTree(b, filepath.Join(dir, fmt.Sprintf("pkg%02d", i)), depth-1, fanout, filesPerDir)
+		}
+	}
+	return written
+}
+
+// benchmarkTraversal compares the sequential traversal with the concurrent one over the tree at root
+func benchmarkTraversal(b *testing.B, root string, files int) {
+	traversals := []struct {
+		name     string
+		traverse func(fh *FileHandler, root string) ([]FileInfo, error)
+	}{
+		{"sequential", (*FileHandler).TraverseDirectory},
+		{fmt.Sprintf("concurrent-%d", runtime.NumCPU()), (*FileHandler).TraverseDirectoryConcurrent},
+	}
+	for _, tt := range traversals {
+		b.Run(tt.name, func(b *testing.B) {
+			fh := NewFileHandler()
+			for i := 0; i < b.N; i++ {
+				result, err := tt.traverse(fh, root)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(result) != files {
+					b.Fatalf("got %d files, want %d", len(result), files)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkTraverseDirectory traverses 500 files in 25 directories
+func BenchmarkTraverseDirectory(b *testing.B) {
+	root := b.TempDir()
+	files := writeSyntheticTree(b, root, 1, 24, 20)
+	benchmarkTraversal(b, root, files)
+}
+
+// BenchmarkTraverseDirectoryDeep traverses 3,120 files in a tree of 156 directories, four
+// levels deep, where walking directories in parallel matters more than reading files
+func BenchmarkTraverseDirectoryDeep(b *testing.B) {
+	root := b.TempDir()
+	files := writeSyntheticTree(b, root, 3, 5, 20)
+	benchmarkTraversal(b, root, files)
+}
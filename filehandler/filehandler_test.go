@@ -0,0 +1,359 @@
+package filehandler
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSetProjectTypeIgnoreRules(t *testing.T) {
+	tests := []struct {
+		projectType ProjectType
+		ignored     []string
+		kept        []string
+	}{
+		{ProjectTypeRust, []string{"target", "libfoo.rlib", "Cargo.lock"}, []string{"main.rs", "lib.rs"}},
+		{ProjectTypeCSharp, []string{"bin", "obj", "App.csproj", "App.sln"}, []string{"Program.cs"}},
+		{ProjectTypeKotlin, []string{"build", "Main.class", "gradlew"}, []string{"Main.kt", "build.gradle.kts"}},
+		{ProjectTypeSwift, []string{".build", "App.xcworkspace", "Packages"}, []string{"main.swift", "Package.swift"}},
+		{ProjectTypeTypeScript, []string{"dist", "index.js", "index.d.ts"}, []string{"index.ts", "App.tsx"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.projectType), func(t *testing.T) {
+			fh := NewFileHandler()
+			fh.SetProjectType(tt.projectType)
+			if fh.ProjectType != tt.projectType {
+				t.Errorf("ProjectType = %q, want %q", fh.ProjectType, tt.projectType)
+			}
+			for _, name := range tt.ignored {
+				if !fh.ShouldIgnore(filepath.Join("project", name)) {
+					t.Errorf("ShouldIgnore(%q) = false, want true", name)
+				}
+			}
+			for _, name := range tt.kept {
+				if fh.ShouldIgnore(filepath.Join("project", name)) {
+					t.Errorf("ShouldIgnore(%q) = true, want false", name)
+				}
+			}
+		})
+	}
+}
+
+func TestSetProjectTypeResetsRules(t *testing.T) {
+	fh := NewFileHandler()
+	fh.SetProjectType(ProjectTypeTypeScript)
+	fh.SetProjectType(ProjectTypeRust)
+	if fh.ShouldIgnore(filepath.Join("project", "index.js")) {
+		t.Error("rules of the previous project type were kept: index.js is ignored")
+	}
+}
+
+func TestDetectProjectType(t *testing.T) {
+	tests := []struct {
+		marker string
+		want   ProjectType
+	}{
+		{"Cargo.toml", ProjectTypeRust},
+		{"App.csproj", ProjectTypeCSharp},
+		{"build.gradle.kts", ProjectTypeKotlin},
+		{"Package.swift", ProjectTypeSwift},
+		{"tsconfig.json", ProjectTypeTypeScript},
+		{"README", ProjectTypeGeneric},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.marker, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, tt.marker), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got := DetectProjectType(root); got != tt.want {
+				t.Errorf("DetectProjectType with %s = %q, want %q", tt.marker, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyProjectConfigIsIdempotent(t *testing.T) {
+	root := t.TempDir()
+	projectConfig := "ignore_patterns:\n  - \"*.gen.go\"\n  - testdata\n"
+	if err := os.WriteFile(filepath.Join(root, ".structura.yaml"), []byte(projectConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fh := NewFileHandler()
+	if _, err := fh.TraverseDirectory(root); err != nil {
+		t.Fatal(err)
+	}
+	want := len(fh.IgnoreFiles)
+	for i := 0; i < 3; i++ {
+		if _, err := fh.TraverseDirectory(root); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(fh.IgnoreFiles); got != want {
+		t.Errorf("after four traversals IgnoreFiles has %d patterns, want %d as after the first", got, want)
+	}
+}
+
+func TestExcludePaths(t *testing.T) {
+	root := t.TempDir()
+	files := []string{
+		"internal/api/handler.go",
+		"internal/api/v1/generated/types.go",
+		"internal/api/v1/generated/nested/client.go",
+		"internal/db/generated/models.go",
+		"internal/gen/tool.go",
+		"internal/generated/schema.go",
+		"main.go",
+		"scripts/build.go",
+	}
+	for _, name := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fh := NewFileHandler()
+	fh.ExcludePaths = []string{
+		"internal/api/v1/generated", // Deep directory; internal/db/generated stays
+		"internal/gen/",             // Whole path elements; internal/generated stays
+		filepath.Join(root, "scripts", "build.go"),
+	}
+	want := []string{
+		"internal/api/handler.go",
+		"internal/db/generated/models.go",
+		"internal/generated/schema.go",
+		"main.go",
+	}
+
+	traversals := map[string]func(string) ([]FileInfo, error){
+		"TraverseDirectory":           fh.TraverseDirectory,
+		"TraverseDirectoryConcurrent": fh.TraverseDirectoryConcurrent,
+	}
+	for name, traverse := range traversals {
+		found, err := traverse(root)
+		if err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		var got []string
+		for _, file := range found {
+			relPath, _ := filepath.Rel(root, file.Path)
+			got = append(got, filepath.ToSlash(relPath))
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s() found %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestTraverseDirectoryConcurrentMatchesSequential(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"a.go", "a-b.go", "a/b.go", "a/c/d.go", "b/e.go", "b/f/g/h.go", "z.go"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The callback is not synchronized: the race detector fails the test if the concurrent
+	// traversal calls it from more than one goroutine
+	var sequential, concurrent []string
+	for _, tt := range []struct {
+		traverse func(fh *FileHandler, root string) ([]FileInfo, error)
+		paths    *[]string
+	}{
+		{(*FileHandler).TraverseDirectory, &sequential},
+		{(*FileHandler).TraverseDirectoryConcurrent, &concurrent},
+	} {
+		seen := make(map[string]bool)
+		fh := NewFileHandler()
+		fh.ShouldIgnoreCallback = func(path string, info os.FileInfo) bool {
+			seen[path] = true
+			return filepath.Base(path) == "z.go"
+		}
+		files, err := tt.traverse(fh, root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(seen) == 0 {
+			t.Error("ShouldIgnoreCallback was not called")
+		}
+		for _, file := range files {
+			relPath, _ := filepath.Rel(root, file.Path)
+			*tt.paths = append(*tt.paths, filepath.ToSlash(relPath))
+		}
+	}
+
+	want := []string{"a/b.go", "a/c/d.go", "a-b.go", "a.go", "b/e.go", "b/f/g/h.go"}
+	if !reflect.DeepEqual(sequential, want) {
+		t.Errorf("TraverseDirectory() found %v, want %v", sequential, want)
+	}
+	if !reflect.DeepEqual(concurrent, sequential) {
+		t.Errorf("TraverseDirectoryConcurrent() found %v, want the order of TraverseDirectory, %v", concurrent, sequential)
+	}
+}
+
+func TestShouldIgnoreCallbackSkipsLargeFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"small.go":       "package x\n",
+		"large.go":       "package x\n\n// " + strings.Repeat("x", 2048) + "\n",
+		"pkg/small.go":   "package pkg\n",
+		"pkg/large.json": `{"data": "` + strings.Repeat("y", 4096) + `"}`,
+	})
+
+	fh := NewFileHandler()
+	fh.ShouldIgnoreCallback = func(path string, info os.FileInfo) bool {
+		return !info.IsDir() && info.Size() > 1024
+	}
+	files, err := fh.TraverseDirectory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, file := range files {
+		relPath, _ := filepath.Rel(root, file.Path)
+		got = append(got, filepath.ToSlash(relPath))
+	}
+	want := []string{"pkg/small.go", "small.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TraverseDirectory() found %v, want %v", got, want)
+	}
+}
+
+// writeFiles creates the files below root, keyed by slash-separated relative path
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTraverseDirectoryKeepsProtoFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                   "module example.com/app\n",
+		"main.go":                  "package main\n",
+		"proto/user/v1/user.proto": "syntax = \"proto3\";\n\nmessage User {}\n",
+	})
+
+	for _, projectType := range []ProjectType{ProjectTypeGeneric, ProjectTypeGo, ProjectTypeProtobuf} {
+		fh := NewFileHandler()
+		fh.SetProjectType(projectType)
+		files, err := fh.TraverseDirectory(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		found := false
+		for _, file := range files {
+			if filepath.Base(file.Path) == "user.proto" {
+				found = true
+				if file.Language != LanguageProtobuf {
+					t.Errorf("%s project: user.proto has language %q, want %q", projectType, file.Language, LanguageProtobuf)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s project: user.proto was filtered out", projectType)
+		}
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"root.go":              "package x\n",
+		"a/one.go":             "package a\n",
+		"a/b/two.go":           "package b\n",
+		"a/b/c/three.go":       "package c\n",
+		"a/b/c/d/four.go":      "package d\n",
+		"a/b/c/d/e/five.go":    "package e\n",
+		"other/one_sibling.go": "package other\n",
+	})
+
+	fh := NewFileHandler()
+	fh.MaxDepth = 2
+	want := []string{"a/one.go", "other/one_sibling.go", "root.go"}
+
+	traversals := map[string]func(string) ([]FileInfo, error){
+		"TraverseDirectory":           fh.TraverseDirectory,
+		"TraverseDirectoryConcurrent": fh.TraverseDirectoryConcurrent,
+	}
+	for name, traverse := range traversals {
+		files, err := traverse(root)
+		if err != nil {
+			t.Fatalf("%s() error = %v", name, err)
+		}
+		var got []string
+		for _, file := range files {
+			relPath, _ := filepath.Rel(root, file.Path)
+			got = append(got, filepath.ToSlash(relPath))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s() with MaxDepth 2 found %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestModifiedAfter(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"old.go":     "package x\n",
+		"new.go":     "package x\n",
+		"pkg/old.go": "package pkg\n",
+		"pkg/new.go": "package pkg\n",
+	})
+
+	cutoff := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for name, modTime := range map[string]time.Time{
+		"old.go":     cutoff.Add(-time.Hour),
+		"pkg/old.go": cutoff.Add(-24 * time.Hour),
+		"new.go":     cutoff.Add(time.Hour),
+		"pkg/new.go": cutoff.Add(time.Minute),
+	} {
+		if err := os.Chtimes(filepath.Join(root, filepath.FromSlash(name)), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fh := NewFileHandler()
+	fh.ModifiedAfter = &cutoff
+	files, err := fh.TraverseDirectory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, file := range files {
+		relPath, _ := filepath.Rel(root, file.Path)
+		got = append(got, filepath.ToSlash(relPath))
+	}
+	if want := []string{"new.go", "pkg/new.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TraverseDirectory() with ModifiedAfter found %v, want %v", got, want)
+	}
+	if ignored := fh.IgnoredCount(); ignored != 2 {
+		t.Errorf("IgnoredCount() = %d, want the 2 older files", ignored)
+	}
+}
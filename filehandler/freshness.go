@@ -0,0 +1,154 @@
+package filehandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FreshnessFileName is the name of the file in the output directory that records when
+// the documentation of each source file was last generated
+const FreshnessFileName = "freshness.json"
+
+// FreshnessStore maps absolute source file paths to the time their documentation was last
+// generated. It is safe for concurrent use.
+type FreshnessStore struct {
+	path      string
+	mu        sync.Mutex
+	generated map[string]time.Time
+	unsaved   int // Entries set since the store was last written
+}
+
+// StaleFile is a source file changed since its documentation was generated
+type StaleFile struct {
+	Path        string
+	ModTime     time.Time // Zero if the source file no longer exists
+	GeneratedAt time.Time
+}
+
+// LoadFreshnessStore reads the freshness store from outputDir.
+// A missing file yields an empty store.
+func LoadFreshnessStore(outputDir string) (*FreshnessStore, error) {
+	store := &FreshnessStore{
+		path:      filepath.Join(outputDir, FreshnessFileName),
+		generated: make(map[string]time.Time),
+	}
+
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return store, fmt.Errorf("error reading %s: %w", FreshnessFileName, err)
+	}
+
+	if err := json.Unmarshal(data, &store.generated); err != nil {
+		return store, fmt.Errorf("error parsing %s: %w", FreshnessFileName, err)
+	}
+	if store.generated == nil {
+		store.generated = make(map[string]time.Time)
+	}
+
+	return store, nil
+}
+
+// Get returns when the documentation of the file at path was last generated and whether
+// that was recorded
+func (fs *FreshnessStore) Get(path string) (time.Time, bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	generatedAt, ok := fs.generated[path]
+	return generatedAt, ok
+}
+
+// Set records that the documentation of the file at path was generated at generatedAt. The
+// store is written to disk every storeSaveInterval entries, so progress survives the run being
+// interrupted; Flush writes the rest.
+func (fs *FreshnessStore) Set(path string, generatedAt time.Time) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	fs.generated[path] = generatedAt
+	fs.unsaved++
+	if fs.unsaved < storeSaveInterval {
+		return nil
+	}
+	return fs.save()
+}
+
+// Flush writes the entries set since the store was last written to disk
+func (fs *FreshnessStore) Flush() error {
+	if fs == nil {
+		return nil
+	}
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.unsaved == 0 {
+		return nil
+	}
+	return fs.save()
+}
+
+// save writes the store to disk. The caller must hold fs.mu.
+func (fs *FreshnessStore) save() error {
+	data, err := json.MarshalIndent(fs.generated, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(fs.path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(fs.path, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", FreshnessFileName, err)
+	}
+	fs.unsaved = 0
+	return nil
+}
+
+// IsFresh reports whether the documentation at outputFile, for the source file at path last
+// modified at modTime, is up to date. The recorded generation time is used if there is one,
+// otherwise the modification time of outputFile. Missing documentation is never fresh.
+func (fs *FreshnessStore) IsFresh(path string, modTime time.Time, outputFile string) bool {
+	if generatedAt, ok := fs.Get(path); ok {
+		return !modTime.After(generatedAt)
+	}
+
+	info, err := os.Stat(outputFile)
+	return err == nil && !modTime.After(info.ModTime())
+}
+
+// StaleFiles returns the recorded source files modified after their documentation was
+// generated, or deleted since, sorted by path
+func (fs *FreshnessStore) StaleFiles() []StaleFile {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	var stale []StaleFile
+	for path, generatedAt := range fs.generated {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+			stale = append(stale, StaleFile{Path: path, GeneratedAt: generatedAt})
+		case info.ModTime().After(generatedAt):
+			stale = append(stale, StaleFile{Path: path, ModTime: info.ModTime(), GeneratedAt: generatedAt})
+		}
+	}
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Path < stale[j].Path })
+	return stale
+}
+
+// Len returns the number of recorded source files
+func (fs *FreshnessStore) Len() int {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	return len(fs.generated)
+}
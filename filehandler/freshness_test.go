@@ -0,0 +1,67 @@
+package filehandler
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFreshnessStoreFlush(t *testing.T) {
+	outputDir := t.TempDir()
+	source := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(source, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(source, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := LoadFreshnessStore(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(source, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries are kept in memory until the store is flushed
+	path := filepath.Join(outputDir, FreshnessFileName)
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("%s written before Flush, error = %v", FreshnessFileName, err)
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	reloaded, err := LoadFreshnessStore(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded.Len() != 1 {
+		t.Fatalf("%d files recorded after Flush, want 1", reloaded.Len())
+	}
+	if !reloaded.IsFresh(source, modTime, filepath.Join(outputDir, "main.go.md")) {
+		t.Error("documentation generated after the last change is not fresh")
+	}
+	if stale := reloaded.StaleFiles(); len(stale) != 0 {
+		t.Errorf("StaleFiles() = %v, want none", stale)
+	}
+}
+
+func TestFreshnessStoreFlushReportsErrors(t *testing.T) {
+	// The output directory cannot be created below a regular file
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	store, _ := LoadFreshnessStore(filepath.Join(parent, "docs"))
+	if err := store.Set("main.go", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Flush(); err == nil {
+		t.Error("Flush() succeeded without a writable output directory")
+	}
+}
@@ -0,0 +1,98 @@
+package filehandler
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitChangedFiles returns the files below repoDir changed between sinceRef and HEAD, together
+// with the files git does not track yet, so new files are documented before they are committed.
+// Paths are relative to repoDir, which may be a subdirectory of the repository.
+func GitChangedFiles(repoDir, sinceRef string) ([]string, error) {
+	if strings.HasPrefix(sinceRef, "-") {
+		return nil, fmt.Errorf("invalid git revision %q", sinceRef)
+	}
+
+	changed, err := gitPaths(repoDir, "diff", "--name-only", "--relative", "-z", sinceRef, "HEAD", "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := gitPaths(repoDir, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return append(changed, untracked...), nil
+}
+
+// GitStagedFiles returns the files below repoDir staged for the next commit, relative to repoDir
+func GitStagedFiles(repoDir string) ([]string, error) {
+	return gitPaths(repoDir, "diff", "--cached", "--name-only", "--relative", "-z", "--")
+}
+
+// GitHooksDir returns the directory git runs the hooks of the repository containing repoDir
+// from, taking core.hooksPath into account
+func GitHooksDir(repoDir string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = repoDir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git rev-parse failed: %s", message)
+		}
+		return "", fmt.Errorf("git rev-parse failed: %w", err)
+	}
+
+	hooksDir := filepath.FromSlash(strings.TrimSpace(string(out)))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(repoDir, hooksDir)
+	}
+	return hooksDir, nil
+}
+
+// gitPaths runs git with args in dir and returns the NUL-separated paths it prints
+func gitPaths(dir string, args ...string) ([]string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s failed: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			paths = append(paths, filepath.FromSlash(path))
+		}
+	}
+	return paths, nil
+}
+
+// FilterByChangedFiles returns the files of allFiles whose paths, relative to inputDir, are in
+// changedPaths, keeping their order
+func FilterByChangedFiles(allFiles []FileInfo, changedPaths []string, inputDir string) []FileInfo {
+	changed := make(map[string]bool, len(changedPaths))
+	for _, path := range changedPaths {
+		changed[filepath.Clean(path)] = true
+	}
+
+	var filtered []FileInfo
+	for _, file := range allFiles {
+		relPath, err := filepath.Rel(inputDir, file.Path)
+		if err == nil && changed[relPath] {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
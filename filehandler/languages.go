@@ -0,0 +1,130 @@
+package filehandler
+
+import (
+	"mime"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Languages that get schema-specific documentation prompts
+const (
+	LanguageProtobuf = "Protobuf"
+	LanguageGraphQL  = "GraphQL"
+)
+
+// LanguageText is the language of unknown files with a text/* MIME type
+const LanguageText = "Text"
+
+// languageByExtension maps lowercase file extensions (without the dot) to language names
+var languageByExtension = map[string]string{
+	"go":         "Go",
+	"py":         "Python",
+	"pyw":        "Python",
+	"js":         "JavaScript",
+	"mjs":        "JavaScript",
+	"cjs":        "JavaScript",
+	"jsx":        "JavaScript",
+	"ts":         "TypeScript",
+	"tsx":        "TypeScript",
+	"java":       "Java",
+	"kt":         "Kotlin",
+	"kts":        "Kotlin",
+	"scala":      "Scala",
+	"groovy":     "Groovy",
+	"rb":         "Ruby",
+	"erb":        "Ruby",
+	"php":        "PHP",
+	"rs":         "Rust",
+	"c":          "C",
+	"h":          "C",
+	"cpp":        "C++",
+	"cc":         "C++",
+	"cxx":        "C++",
+	"hpp":        "C++",
+	"hh":         "C++",
+	"cs":         "C#",
+	"fs":         "F#",
+	"swift":      "Swift",
+	"m":          "Objective-C",
+	"mm":         "Objective-C++",
+	"dart":       "Dart",
+	"lua":        "Lua",
+	"pl":         "Perl",
+	"pm":         "Perl",
+	"r":          "R",
+	"jl":         "Julia",
+	"ex":         "Elixir",
+	"exs":        "Elixir",
+	"erl":        "Erlang",
+	"hs":         "Haskell",
+	"clj":        "Clojure",
+	"ml":         "OCaml",
+	"zig":        "Zig",
+	"nim":        "Nim",
+	"vue":        "Vue",
+	"svelte":     "Svelte",
+	"html":       "HTML",
+	"htm":        "HTML",
+	"css":        "CSS",
+	"scss":       "SCSS",
+	"sass":       "Sass",
+	"less":       "Less",
+	"sql":        "SQL",
+	"sh":         "Shell",
+	"bash":       "Shell",
+	"zsh":        "Shell",
+	"ps1":        "PowerShell",
+	"bat":        "Batch",
+	"json":       "JSON",
+	"xml":        "XML",
+	"yaml":       "YAML",
+	"yml":        "YAML",
+	"toml":       "TOML",
+	"md":         "Markdown",
+	"tex":        "LaTeX",
+	"dockerfile": "Dockerfile",
+	"tf":         "HCL",
+	"sol":        "Solidity",
+	"proto":      LanguageProtobuf,
+	"graphql":    LanguageGraphQL,
+	"gql":        LanguageGraphQL,
+}
+
+// DetectLanguage returns the language name for a file based on its extension.
+// Unknown extensions with a registered MIME type map to "Text" for text/* types
+// and to "" for anything else; extensions without one fall back to the raw extension.
+func DetectLanguage(path string) string {
+	ext := strings.ToLower(GetFileExtension(path))
+	if ext == "" {
+		// Files like Dockerfile have no extension, so try the base name
+		ext = strings.ToLower(filepath.Base(path))
+	}
+
+	if language, ok := languageByExtension[ext]; ok {
+		return language
+	}
+
+	if mimeType := mime.TypeByExtension("." + ext); mimeType != "" {
+		if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil && strings.HasPrefix(mediaType, "text/") {
+			return LanguageText
+		}
+		return ""
+	}
+
+	return GetFileExtension(path)
+}
+
+// KnownLanguages returns the sorted list of distinct language names recognized by DetectLanguage
+func KnownLanguages() []string {
+	seen := make(map[string]bool)
+	var languages []string
+	for _, language := range languageByExtension {
+		if !seen[language] {
+			seen[language] = true
+			languages = append(languages, language)
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}
@@ -0,0 +1,71 @@
+package filehandler
+
+import (
+	"mime"
+	"slices"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", "Go"},
+		{"app/models.py", "Python"},
+		{"src/index.js", "JavaScript"},
+		{"src/App.tsx", "TypeScript"},
+		{"Main.java", "Java"},
+		{"lib.rs", "Rust"},
+		{"vector.cpp", "C++"},
+		{"Program.cs", "C#"},
+		{"script.sh", "Shell"},
+		{"config.yml", "YAML"},
+		{"UPPER.GO", "Go"},
+		{"api/service.proto", LanguageProtobuf},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.path); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLanguageMIMEFallback(t *testing.T) {
+	if err := mime.AddExtensionType(".structuranotes", "text/x-structura-notes; charset=utf-8"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		// Registered text/* types are plain text rather than a media type
+		{"notes.structuranotes", LanguageText},
+		// Other registered types have no language
+		{"manual.pdf", ""},
+		{"logo.png", ""},
+		// Unregistered extensions keep the raw extension
+		{"data.structuraunknown", "structuraunknown"},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.path); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestKnownLanguages(t *testing.T) {
+	languages := KnownLanguages()
+	if !slices.IsSorted(languages) {
+		t.Errorf("KnownLanguages() is not sorted: %v", languages)
+	}
+	if len(slices.Compact(slices.Clone(languages))) != len(languages) {
+		t.Errorf("KnownLanguages() has duplicates: %v", languages)
+	}
+	for _, language := range []string{"Go", "Python", LanguageProtobuf} {
+		if !slices.Contains(languages, language) {
+			t.Errorf("KnownLanguages() is missing %s", language)
+		}
+	}
+}
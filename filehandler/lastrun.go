@@ -0,0 +1,41 @@
+package filehandler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LastRunFileName is the name of the file in the output directory that records when the
+// last successful run documenting into it started
+const LastRunFileName = ".structura_last_run"
+
+// LoadLastRun returns when the last successful run documenting into outputDir started, and
+// whether one was recorded
+func LoadLastRun(outputDir string) (time.Time, bool, error) {
+	data, err := os.ReadFile(filepath.Join(outputDir, LastRunFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("error reading %s: %w", LastRunFileName, err)
+	}
+
+	startedAt, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("error parsing %s: %w", LastRunFileName, err)
+	}
+	return startedAt, true, nil
+}
+
+// SaveLastRun records that a successful run documenting into outputDir started at startedAt
+func SaveLastRun(outputDir string, startedAt time.Time) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	data := startedAt.UTC().Format(time.RFC3339Nano) + "\n"
+	return os.WriteFile(filepath.Join(outputDir, LastRunFileName), []byte(data), 0644)
+}
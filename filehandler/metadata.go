@@ -0,0 +1,265 @@
+package filehandler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Abiggj/structura/config"
+)
+
+// ProjectMetadata describes the project being documented, as declared in its manifest
+type ProjectMetadata = config.ProjectMetadata
+
+// metadataParsers read the manifests ExtractProjectMetadata looks for, in order
+var metadataParsers = []struct {
+	file  string
+	parse func(data []byte, metadata *ProjectMetadata) error
+}{
+	{"go.mod", parseGoMod},
+	{"package.json", parsePackageJSON},
+	{"pyproject.toml", parsePyproject},
+	{"setup.py", parseSetupPy},
+	{"pom.xml", parsePom},
+}
+
+// ExtractProjectMetadata reads the project's name, version, author, license, description and
+// repository from the first manifest found in rootDir: go.mod, package.json, pyproject.toml,
+// setup.py or pom.xml. Name falls back to the name of rootDir if no manifest declares it. If
+// a manifest cannot be read or parsed, the metadata found so far is returned with the error.
+func ExtractProjectMetadata(rootDir string) (ProjectMetadata, error) {
+	var metadata ProjectMetadata
+	var err error
+	for _, parser := range metadataParsers {
+		data, readErr := os.ReadFile(filepath.Join(rootDir, parser.file))
+		if errors.Is(readErr, os.ErrNotExist) {
+			continue
+		}
+		if readErr != nil {
+			err = readErr
+		} else if parseErr := parser.parse(data, &metadata); parseErr != nil {
+			err = fmt.Errorf("failed to parse %s: %w", parser.file, parseErr)
+		}
+		break
+	}
+
+	if metadata.Name == "" {
+		if absDir, absErr := filepath.Abs(rootDir); absErr == nil {
+			metadata.Name = filepath.Base(absDir)
+		} else {
+			metadata.Name = filepath.Base(rootDir)
+		}
+	}
+	return metadata, err
+}
+
+// majorVersionSuffix matches the /vN suffix of a Go module path
+var majorVersionSuffix = regexp.MustCompile(`/(v[0-9]+)$`)
+
+// parseGoMod reads the module path of go.mod as the name. The major version suffix of the
+// path, if any, is the version, and a path on a known code host gives the repository.
+func parseGoMod(data []byte, metadata *ProjectMetadata) error {
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "module" {
+			continue
+		}
+		modulePath := fields[1]
+		if unquoted, err := strconv.Unquote(modulePath); err == nil {
+			modulePath = unquoted
+		}
+
+		metadata.Name = modulePath
+		repository := modulePath
+		if match := majorVersionSuffix.FindStringSubmatch(modulePath); match != nil {
+			metadata.Version = match[1]
+			repository = strings.TrimSuffix(modulePath, "/"+match[1])
+		}
+		for _, host := range []string{"github.com/", "gitlab.com/", "bitbucket.org/"} {
+			if strings.HasPrefix(repository, host) {
+				metadata.Repository = "https://" + repository
+			}
+		}
+		return nil
+	}
+	return errors.New("no module directive")
+}
+
+// parsePackageJSON reads package.json. The author and repository may be strings or objects.
+func parsePackageJSON(data []byte, metadata *ProjectMetadata) error {
+	var manifest struct {
+		Name        string          `json:"name"`
+		Version     string          `json:"version"`
+		Description string          `json:"description"`
+		License     string          `json:"license"`
+		Author      json.RawMessage `json:"author"`
+		Repository  json.RawMessage `json:"repository"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return err
+	}
+
+	metadata.Name = manifest.Name
+	metadata.Version = manifest.Version
+	metadata.Description = manifest.Description
+	metadata.License = manifest.License
+	metadata.Author = stringOrField(manifest.Author, "name")
+	metadata.Repository = stringOrField(manifest.Repository, "url")
+	return nil
+}
+
+// stringOrField returns a JSON string, or the named string field of a JSON object
+func stringOrField(raw json.RawMessage, field string) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var object map[string]interface{}
+	if json.Unmarshal(raw, &object) == nil {
+		s, _ = object[field].(string)
+	}
+	return s
+}
+
+// Patterns for parsePyproject
+var (
+	tomlTable  = regexp.MustCompile(`^\[\s*([^\]]+?)\s*\]$`)
+	tomlString = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	tomlInline = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*=\s*[\[{].*?\b(?:name|text)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// parsePyproject reads the [project] table of pyproject.toml, or the [tool.poetry] table of
+// Poetry projects. Only string values, and the first name of an author list or the text of
+// a license table, are read, which is all the metadata needs.
+func parsePyproject(data []byte, metadata *ProjectMetadata) error {
+	table := ""
+	found := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if match := tomlTable.FindStringSubmatch(line); match != nil {
+			table = match[1]
+			continue
+		}
+		if table != "project" && table != "tool.poetry" && table != "project.urls" && table != "tool.poetry.urls" {
+			continue
+		}
+		found = found || table == "project" || table == "tool.poetry"
+
+		key, value := "", ""
+		if match := tomlString.FindStringSubmatch(line); match != nil {
+			key, value = match[1], match[2]+match[3]
+		} else if match := tomlInline.FindStringSubmatch(line); match != nil {
+			key, value = match[1], match[2]+match[3]
+		} else if strings.HasPrefix(line, "authors") {
+			// Poetry lists authors as "Name <email>" strings
+			if start := strings.IndexAny(line, `"'`); start >= 0 {
+				if end := strings.IndexAny(line[start+1:], `"'`); end >= 0 {
+					key, value = "authors", line[start+1:start+1+end]
+				}
+			}
+		}
+
+		switch {
+		case strings.HasSuffix(table, "urls"):
+			if metadata.Repository == "" && (strings.EqualFold(key, "repository") || strings.EqualFold(key, "source") || strings.EqualFold(key, "homepage")) {
+				metadata.Repository = value
+			}
+		case key == "name":
+			metadata.Name = value
+		case key == "version":
+			metadata.Version = value
+		case key == "description":
+			metadata.Description = value
+		case key == "license":
+			metadata.License = value
+		case key == "authors" && metadata.Author == "":
+			metadata.Author = strings.TrimSpace(strings.Split(value, "<")[0])
+		case key == "repository" || (key == "homepage" && metadata.Repository == ""):
+			metadata.Repository = value
+		}
+	}
+	if !found {
+		return errors.New("no [project] or [tool.poetry] table")
+	}
+	return nil
+}
+
+// setupArgument matches a keyword argument with a string literal value in setup.py
+var setupArgument = regexp.MustCompile(`\b(name|version|author|license|description|url)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// parseSetupPy reads the string literal arguments of the setup() call in setup.py. Values
+// computed at run time, such as a version read from another file, are left out.
+func parseSetupPy(data []byte, metadata *ProjectMetadata) error {
+	content := string(data)
+	start := strings.Index(content, "setup(")
+	if start < 0 {
+		return errors.New("no setup() call")
+	}
+
+	for _, match := range setupArgument.FindAllStringSubmatch(content[start:], -1) {
+		value := match[2] + match[3]
+		switch match[1] {
+		case "name":
+			metadata.Name = value
+		case "version":
+			metadata.Version = value
+		case "author":
+			metadata.Author = value
+		case "license":
+			metadata.License = value
+		case "description":
+			metadata.Description = value
+		case "url":
+			metadata.Repository = value
+		}
+	}
+	return nil
+}
+
+// parsePom reads pom.xml. The name falls back to the artifactId, and the version to the
+// parent's version, which modules inherit.
+func parsePom(data []byte, metadata *ProjectMetadata) error {
+	var pom struct {
+		ArtifactID  string `xml:"artifactId"`
+		Name        string `xml:"name"`
+		Version     string `xml:"version"`
+		Description string `xml:"description"`
+		URL         string `xml:"url"`
+		SCM         string `xml:"scm>url"`
+		Parent      struct {
+			Version string `xml:"version"`
+		} `xml:"parent"`
+		Licenses   []string `xml:"licenses>license>name"`
+		Developers []string `xml:"developers>developer>name"`
+	}
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return err
+	}
+
+	metadata.Name = strings.TrimSpace(pom.Name)
+	if metadata.Name == "" {
+		metadata.Name = strings.TrimSpace(pom.ArtifactID)
+	}
+	metadata.Version = strings.TrimSpace(pom.Version)
+	if metadata.Version == "" {
+		metadata.Version = strings.TrimSpace(pom.Parent.Version)
+	}
+	metadata.Description = strings.Join(strings.Fields(pom.Description), " ")
+	if len(pom.Licenses) > 0 {
+		metadata.License = strings.TrimSpace(pom.Licenses[0])
+	}
+	if len(pom.Developers) > 0 {
+		metadata.Author = strings.TrimSpace(pom.Developers[0])
+	}
+	metadata.Repository = strings.TrimSpace(pom.SCM)
+	if metadata.Repository == "" {
+		metadata.Repository = strings.TrimSpace(pom.URL)
+	}
+	return nil
+}
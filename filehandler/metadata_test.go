@@ -0,0 +1,97 @@
+package filehandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExtractProjectMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		want     ProjectMetadata
+		wantsErr bool
+	}{
+		{
+			name:  "go.mod",
+			files: map[string]string{"go.mod": "module github.com/acme/widget/v2\n\ngo 1.21\n"},
+			want:  ProjectMetadata{Name: "github.com/acme/widget/v2", Version: "v2", Repository: "https://github.com/acme/widget"},
+		},
+		{
+			name: "package.json",
+			files: map[string]string{"package.json": `{"name": "widget", "version": "1.4.0", "description": "Widgets for the web",
+				"license": "MIT", "author": {"name": "Ada Lovelace", "email": "ada@example.com"},
+				"repository": {"type": "git", "url": "https://github.com/acme/widget.git"}}`},
+			want: ProjectMetadata{Name: "widget", Version: "1.4.0", Description: "Widgets for the web", License: "MIT",
+				Author: "Ada Lovelace", Repository: "https://github.com/acme/widget.git"},
+		},
+		{
+			name: "pyproject.toml",
+			files: map[string]string{"pyproject.toml": "[build-system]\nrequires = [\"hatchling\"]\n\n[project]\nname = \"widget\"\nversion = \"0.3.1\"\n" +
+				"description = 'Widget toolkit'\nlicense = {text = \"Apache-2.0\"}\nauthors = [{name = \"Grace Hopper\", email = \"grace@example.com\"}]\n\n" +
+				"[project.urls]\nRepository = \"https://gitlab.com/acme/widget\"\n"},
+			want: ProjectMetadata{Name: "widget", Version: "0.3.1", Description: "Widget toolkit", License: "Apache-2.0",
+				Author: "Grace Hopper", Repository: "https://gitlab.com/acme/widget"},
+		},
+		{
+			name: "Poetry",
+			files: map[string]string{"pyproject.toml": "[tool.poetry]\nname = \"widget\"\nversion = \"2.0.0\"\n" +
+				"authors = [\"Alan Turing <alan@example.com>\"]\nrepository = \"https://github.com/acme/widget\"\n"},
+			want: ProjectMetadata{Name: "widget", Version: "2.0.0", Author: "Alan Turing", Repository: "https://github.com/acme/widget"},
+		},
+		{
+			name: "setup.py",
+			files: map[string]string{"setup.py": "from setuptools import setup\n\nsetup(\n    name='widget',\n    version=read_version(),\n" +
+				"    author=\"Guido\",\n    license='BSD',\n    url='https://example.com/widget',\n)\n"},
+			want: ProjectMetadata{Name: "widget", Author: "Guido", License: "BSD", Repository: "https://example.com/widget"},
+		},
+		{
+			name: "pom.xml",
+			files: map[string]string{"pom.xml": `<project><parent><version>3.1.0</version></parent><artifactId>widget-core</artifactId>
+				<description>Core
+				widgets</description><licenses><license><name>EPL-2.0</name></license></licenses>
+				<developers><developer><name>Barbara Liskov</name></developer></developers>
+				<scm><url>https://github.com/acme/widget</url></scm></project>`},
+			want: ProjectMetadata{Name: "widget-core", Version: "3.1.0", Description: "Core widgets", License: "EPL-2.0",
+				Author: "Barbara Liskov", Repository: "https://github.com/acme/widget"},
+		},
+		{
+			name:  "first manifest wins",
+			files: map[string]string{"go.mod": "module example.com/tool\n", "package.json": `{"name": "frontend"}`},
+			want:  ProjectMetadata{Name: "example.com/tool"},
+		},
+		{
+			name: "no manifest",
+			want: ProjectMetadata{Name: "project"},
+		},
+		{
+			name:     "invalid manifest",
+			files:    map[string]string{"package.json": "{"},
+			want:     ProjectMetadata{Name: "project"},
+			wantsErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := filepath.Join(t.TempDir(), "project")
+			if err := os.Mkdir(root, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := ExtractProjectMetadata(root)
+			if (err != nil) != tt.wantsErr {
+				t.Fatalf("ExtractProjectMetadata() error = %v, want error %v", err, tt.wantsErr)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractProjectMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package filehandler
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ContentNormalizer rewrites the content of a file before it is documented, to leave out
+// text that fills the context window without telling the API anything about the code
+type ContentNormalizer interface {
+	Normalize(content, path string) string
+}
+
+// DefaultNormalizers returns the normalizers a new FileHandler applies, in order
+func DefaultNormalizers() []ContentNormalizer {
+	return []ContentNormalizer{
+		LicenseHeaderStripper{},
+		MinifiedJSDetector{},
+		BlankLineCollapser{},
+	}
+}
+
+// maxLicenseHeaderLines is the longest comment block LicenseHeaderStripper removes
+const maxLicenseHeaderLines = 30
+
+// licenseMarker matches the wording of license and copyright headers
+var licenseMarker = regexp.MustCompile(`(?i)SPDX-License-Identifier|copyright|licensed under|all rights reserved|\(c\) [0-9]{4}`)
+
+// LicenseHeaderStripper removes a license or copyright header from the top of a file: a
+// comment block of up to 30 lines, ending at the first blank or code line, that mentions a
+// license or copyright. A shebang or <?php line above it is kept.
+type LicenseHeaderStripper struct{}
+
+// Normalize returns content without its license header
+func (LicenseHeaderStripper) Normalize(content, path string) string {
+	lines := strings.SplitAfter(content, "\n")
+	start := 0
+	if len(lines) > 0 && (strings.HasPrefix(lines[0], "#!") || strings.HasPrefix(strings.TrimSpace(lines[0]), "<?php")) {
+		start = 1
+	}
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+
+	end := start
+	inBlock := false
+	for end < len(lines) && end-start < maxLicenseHeaderLines {
+		line := strings.TrimSpace(lines[end])
+		if inBlock {
+			inBlock = !strings.Contains(line, "*/")
+		} else if strings.HasPrefix(line, "/*") {
+			inBlock = !strings.Contains(line[2:], "*/")
+		} else if !isLineComment(line) {
+			break
+		}
+		end++
+	}
+
+	// Leave headers that are too long, unterminated or not about licensing alone
+	if inBlock || end == start || (end < len(lines) && isLineComment(strings.TrimSpace(lines[end]))) {
+		return content
+	}
+	if !licenseMarker.MatchString(strings.Join(lines[start:end], "")) {
+		return content
+	}
+
+	for end < len(lines) && strings.TrimSpace(lines[end]) == "" {
+		end++
+	}
+	return strings.Join(lines[:start], "") + strings.Join(lines[end:], "")
+}
+
+// isLineComment reports whether a trimmed line is a //, #, -- or ; comment. Lines such as
+// #include are not comments.
+func isLineComment(line string) bool {
+	for _, prefix := range []string{"//", "# ", "-- ", ";"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return line == "#" || line == "--"
+}
+
+// minifiedSampleSize is how much of a JavaScript file MinifiedJSDetector looks at
+const minifiedSampleSize = 200
+
+// MinifiedMarker replaces the content of minified JavaScript files
+const MinifiedMarker = "// minified JavaScript, skipped"
+
+// MinifiedJSDetector replaces the content of minified JavaScript with MinifiedMarker. A
+// JavaScript file is taken to be minified if its first 200 bytes hold fewer than 3 newlines.
+type MinifiedJSDetector struct{}
+
+// Normalize returns MinifiedMarker for minified JavaScript and content otherwise
+func (MinifiedJSDetector) Normalize(content, path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".js", ".mjs", ".cjs":
+	default:
+		return content
+	}
+	if len(content) < minifiedSampleSize || strings.Count(content[:minifiedSampleSize], "\n") >= 3 {
+		return content
+	}
+	return MinifiedMarker
+}
+
+// blankLineRun matches three or more consecutive blank lines
+var blankLineRun = regexp.MustCompile(`\n(?:[ \t\r]*\n){3,}`)
+
+// BlankLineCollapser collapses runs of three or more blank lines into one
+type BlankLineCollapser struct{}
+
+// Normalize returns content with long runs of blank lines collapsed
+func (BlankLineCollapser) Normalize(content, path string) string {
+	return blankLineRun.ReplaceAllString(content, "\n\n")
+}
+
+// normalize applies the handler's normalizers to content in order
+func (fh *FileHandler) normalize(content, path string) string {
+	for _, normalizer := range fh.Normalizers {
+		content = normalizer.Normalize(content, path)
+	}
+	return content
+}
@@ -0,0 +1,122 @@
+package filehandler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// commentHeader returns a header of n lines, each starting with prefix, whose first line
+// is a copyright notice
+func commentHeader(prefix string, n int) string {
+	var sb strings.Builder
+	sb.WriteString(prefix + "Copyright 2024 Example Corp.\n")
+	for i := 1; i < n; i++ {
+		sb.WriteString(prefix + "Permission is granted under the terms of the license.\n")
+	}
+	return sb.String()
+}
+
+func TestLicenseHeaderStripper(t *testing.T) {
+	const code = "package main\n\nfunc main() {}\n"
+	blockHeader := "/*\n" + commentHeader(" * ", 23) + " */\n"
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{"25-line // header", "main.go", commentHeader("// ", 25) + "\n" + code, code},
+		{"25-line /* */ header", "main.go", blockHeader + "\n" + code, code},
+		{"30-line header", "main.go", commentHeader("// ", 30) + "\n" + code, code},
+		{"header followed by code", "main.go", commentHeader("// ", 3) + code, code},
+		{"# header after shebang", "run.py", "#!/usr/bin/env python3\n" + commentHeader("# ", 25) + "\nimport os\n", "#!/usr/bin/env python3\nimport os\n"},
+		{"SPDX identifier", "lib.rs", "// SPDX-License-Identifier: MIT\n\nfn main() {}\n", "fn main() {}\n"},
+		{"31-line header kept", "main.go", commentHeader("// ", 31) + "\n" + code, commentHeader("// ", 31) + "\n" + code},
+		{"doc comment kept", "main.go", "// Package main starts the server\n" + code, "// Package main starts the server\n" + code},
+		{"unterminated block kept", "main.c", "/* Copyright 2024 Example Corp.\n" + code, "/* Copyright 2024 Example Corp.\n" + code},
+		{"#include not a comment", "main.c", "#include <stdio.h> // (c) 2024\n", "#include <stdio.h> // (c) 2024\n"},
+		{"no header", "main.go", code, code},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (LicenseHeaderStripper{}).Normalize(tt.content, tt.path); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinifiedJSDetector(t *testing.T) {
+	minified := "!function(e){" + strings.Repeat("var a=e.b||{};a.c=function(d){return d*2};", 10) + "}(window);\n"
+	readable := strings.Repeat("function double(d) {\n  return d * 2;\n}\n\n", 10)
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		want    string
+	}{
+		{"minified", "dist/app.min.js", minified, MinifiedMarker},
+		{"minified module", "app.MJS", minified, MinifiedMarker},
+		{"readable", "src/app.js", readable, readable},
+		{"shorter than the sample", "tiny.js", "var a=1;", "var a=1;"},
+		{"not JavaScript", "style.css", minified, minified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (MinifiedJSDetector{}).Normalize(tt.content, tt.path); got != tt.want {
+				t.Errorf("Normalize() = %.40q, want %.40q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlankLineCollapser(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"a\n\nb\n", "a\n\nb\n"},
+		{"a\n\n\nb\n", "a\n\n\nb\n"},
+		{"a\n\n\n\nb\n", "a\n\nb\n"},
+		{"a\n \n\t\n\r\n\n\nb\n", "a\n\nb\n"},
+		{"a\n\n\n\n\nb\n\n\n\n\nc", "a\n\nb\n\nc"},
+	}
+
+	for _, tt := range tests {
+		if got := (BlankLineCollapser{}).Normalize(tt.content, "main.go"); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestTraverseDirectoryNormalizesContent(t *testing.T) {
+	root := t.TempDir()
+	source := commentHeader("// ", 25) + "\npackage main\n\n\n\n\nfunc main() {}\n"
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := NewFileHandler().TraverseDirectory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		if filepath.Base(file.Path) != "main.go" {
+			continue
+		}
+		if want := "package main\n\nfunc main() {}\n"; file.Content != want {
+			t.Errorf("Content = %q, want %q", file.Content, want)
+		}
+		if file.Checksum != Checksum(source) {
+			t.Errorf("Checksum = %q, want the checksum of the content on disk", file.Checksum)
+		}
+		return
+	}
+	t.Fatal("main.go not found")
+}
@@ -0,0 +1,131 @@
+package filehandler
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// RootPackage is the group of files that belong to no package or module, e.g. files
+// directly in the root of a Python project
+const RootPackage = "."
+
+// GetProjectFiles traverses rootDir and groups the files by the package or module they
+// belong to, as returned by GroupByPackage
+func GetProjectFiles(rootDir string, fh *FileHandler) (map[string][]FileInfo, error) {
+	files, err := fh.TraverseDirectory(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return GroupByPackage(rootDir, files, fh.ProjectType), nil
+}
+
+// GroupByPackage groups files by their package, keeping their order within each group.
+// See PackageNames for how packages are named.
+func GroupByPackage(rootDir string, files []FileInfo, projectType ProjectType) map[string][]FileInfo {
+	names := PackageNames(rootDir, files, projectType)
+
+	groups := make(map[string][]FileInfo)
+	for _, file := range files {
+		if !file.IsDir {
+			groups[names[file.Path]] = append(groups[names[file.Path]], file)
+		}
+	}
+	return groups
+}
+
+// PackageNames returns the package of each file, keyed by path:
+//   - Go: the package name from the package clause, without a _test suffix, prefixed by the directory
+//     below rootDir unless the name matches it, e.g. "util" for util/*.go and "cmd/tool/main" for cmd/tool/*.go
+//   - Python and Django: the top-level module directory, e.g. "app" for app/models/user.py
+//   - Node, React and TypeScript: the name in the nearest package.json above the file
+//   - Others, and files not matched above: the directory below rootDir
+//
+// Files directly in rootDir of a Python project, or in no npm package, are in RootPackage.
+func PackageNames(rootDir string, files []FileInfo, projectType ProjectType) map[string]string {
+	npmNames := make(map[string]string) // Cache of package.json names by directory
+
+	names := make(map[string]string, len(files))
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+
+		relDir := RootPackage
+		if rel, err := filepath.Rel(rootDir, filepath.Dir(file.Path)); err == nil {
+			relDir = filepath.ToSlash(rel)
+		}
+
+		name := ""
+		switch projectType {
+		case ProjectTypeGo:
+			if strings.HasSuffix(file.Path, ".go") {
+				name = goPackageName(file, relDir)
+			}
+		case ProjectTypePython, ProjectTypeDjango:
+			name = strings.Split(relDir, "/")[0]
+		case ProjectTypeNode, ProjectTypeReact, ProjectTypeTypeScript:
+			name = npmPackageName(rootDir, filepath.Dir(file.Path), npmNames)
+		}
+		if name == "" {
+			name = relDir
+		}
+		names[file.Path] = name
+	}
+	return names
+}
+
+// goPackageName returns the package key of a Go file in relDir, or "" if it cannot be parsed
+func goPackageName(file FileInfo, relDir string) string {
+	var src interface{}
+	if file.Content != "" {
+		src = file.Content
+	}
+
+	parsed, err := parser.ParseFile(token.NewFileSet(), file.Path, src, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+
+	// External test packages are documented with the package they test
+	name := strings.TrimSuffix(parsed.Name.Name, "_test")
+	switch {
+	case relDir == RootPackage:
+		return name
+	case path.Base(relDir) == name:
+		return relDir
+	default:
+		return relDir + "/" + name
+	}
+}
+
+// npmPackageName returns the name of the npm package containing dir, found in the nearest
+// package.json at or above dir but not above rootDir. Results are cached in names.
+func npmPackageName(rootDir, dir string, names map[string]string) string {
+	if name, ok := names[dir]; ok {
+		return name
+	}
+
+	name := RootPackage
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	switch {
+	case err == nil:
+		var manifest struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &manifest) == nil && manifest.Name != "" {
+			name = manifest.Name
+		} else if rel, err := filepath.Rel(rootDir, dir); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+	case filepath.Clean(dir) != filepath.Clean(rootDir) && filepath.Dir(dir) != dir:
+		name = npmPackageName(rootDir, filepath.Dir(dir), names)
+	}
+
+	names[dir] = name
+	return name
+}
@@ -0,0 +1,60 @@
+package filehandler
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGetProjectFiles(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                "module example.com/app\n",
+		"main.go":               "package main\n\nfunc main() {}\n",
+		"server.go":             "package main\n",
+		"util/strings.go":       "package util\n",
+		"util/strings_test.go":  "package util_test\n",
+		"util/internal_test.go": "package util\n",
+	})
+
+	fh := NewFileHandler()
+	fh.SetProjectType(ProjectTypeGo)
+	groups, err := GetProjectFiles(root, fh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string][]string)
+	for pkg, files := range groups {
+		for _, file := range files {
+			relPath, _ := filepath.Rel(root, file.Path)
+			got[pkg] = append(got[pkg], filepath.ToSlash(relPath))
+		}
+	}
+	want := map[string][]string{
+		RootPackage: {"go.mod"}, // Not Go source, so grouped by directory
+		"main":      {"main.go", "server.go"},
+		"util":      {"util/internal_test.go", "util/strings.go", "util/strings_test.go"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetProjectFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestPackageNamesPython(t *testing.T) {
+	root := filepath.FromSlash("/project")
+	files := []FileInfo{
+		{Path: filepath.Join(root, "manage.py")},
+		{Path: filepath.Join(root, "app", "models", "user.py")},
+		{Path: filepath.Join(root, "app", "views.py")},
+	}
+
+	want := map[string]string{
+		files[0].Path: RootPackage,
+		files[1].Path: "app",
+		files[2].Path: "app",
+	}
+	if got := PackageNames(root, files, ProjectTypePython); !reflect.DeepEqual(got, want) {
+		t.Errorf("PackageNames() = %v, want %v", got, want)
+	}
+}
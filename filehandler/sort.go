@@ -0,0 +1,105 @@
+package filehandler
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+// SortOrder controls the order in which traversed files are returned, and so processed
+type SortOrder string
+
+const (
+	SortLexical       SortOrder = "lexical"      // Path order, as walked
+	SortBySize        SortOrder = "size"         // Smallest files first, for quick feedback
+	SortBySizeDesc    SortOrder = "size-desc"    // Largest files first
+	SortByModTime     SortOrder = "modtime"      // Least recently modified first
+	SortByModTimeDesc SortOrder = "modtime-desc" // Most recently modified first
+	SortPriorityFirst SortOrder = "priority"     // Files matching PriorityPatterns first
+)
+
+// SortOrders returns all supported sort orders
+func SortOrders() []SortOrder {
+	return []SortOrder{
+		SortLexical,
+		SortBySize,
+		SortBySizeDesc,
+		SortByModTime,
+		SortByModTimeDesc,
+		SortPriorityFirst,
+	}
+}
+
+// ParseSortOrder returns the sort order with the given name
+func ParseSortOrder(name string) (SortOrder, error) {
+	for _, order := range SortOrders() {
+		if string(order) == name {
+			return order, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sort order: %s", name)
+}
+
+// sortFiles orders files according to fh.SortOrder. Files are expected in walk order, and
+// the sort is stable, so ties keep their path order and the result is the same on every run.
+func (fh *FileHandler) sortFiles(rootDir string, files []FileInfo) {
+	switch fh.SortOrder {
+	case SortBySize:
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].Size < files[j].Size
+		})
+	case SortBySizeDesc:
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].Size > files[j].Size
+		})
+	case SortByModTime:
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].ModTime.Before(files[j].ModTime)
+		})
+	case SortByModTimeDesc:
+		sort.SliceStable(files, func(i, j int) bool {
+			return files[i].ModTime.After(files[j].ModTime)
+		})
+	case SortPriorityFirst:
+		priority := make([]bool, len(files))
+		for i, file := range files {
+			priority[i] = matchesAnyPattern(fh.PriorityPatterns, rootDir, file.Path)
+		}
+		sort.Stable(&prioritySorter{files: files, priority: priority})
+	}
+}
+
+// prioritySorter moves priority files to the front, keeping both groups in their original order
+type prioritySorter struct {
+	files    []FileInfo
+	priority []bool
+}
+
+func (ps *prioritySorter) Len() int           { return len(ps.files) }
+func (ps *prioritySorter) Less(i, j int) bool { return ps.priority[i] && !ps.priority[j] }
+func (ps *prioritySorter) Swap(i, j int) {
+	ps.files[i], ps.files[j] = ps.files[j], ps.files[i]
+	ps.priority[i], ps.priority[j] = ps.priority[j], ps.priority[i]
+}
+
+// matchesAnyPattern reports whether path matches one of the glob patterns, by base name
+// or by its slash-separated path relative to rootDir
+func matchesAnyPattern(patterns []string, rootDir, path string) bool {
+	basename := filepath.Base(path)
+	relPath, err := filepath.Rel(rootDir, path)
+	if err != nil {
+		relPath = path
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	for _, pattern := range patterns {
+		if matched, _ := filepath.Match(pattern, basename); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, relPath); matched {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,62 @@
+package filehandler
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSortFiles(t *testing.T) {
+	root := filepath.FromSlash("/project")
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// In walk order. Files sharing a size or modification time must keep this order.
+	walked := []FileInfo{
+		{Path: filepath.Join(root, "a.go"), Size: 300, ModTime: base.Add(2 * time.Hour)},
+		{Path: filepath.Join(root, "b.go"), Size: 100, ModTime: base},
+		{Path: filepath.Join(root, "cmd", "main.go"), Size: 300, ModTime: base.Add(time.Hour)},
+		{Path: filepath.Join(root, "c.go"), Size: 100, ModTime: base.Add(2 * time.Hour)},
+		{Path: filepath.Join(root, "README.md"), Size: 200, ModTime: base},
+	}
+
+	tests := []struct {
+		order SortOrder
+		want  []string
+	}{
+		{SortLexical, []string{"a.go", "b.go", "cmd/main.go", "c.go", "README.md"}},
+		{"", []string{"a.go", "b.go", "cmd/main.go", "c.go", "README.md"}},
+		{SortBySize, []string{"b.go", "c.go", "README.md", "a.go", "cmd/main.go"}},
+		{SortBySizeDesc, []string{"a.go", "cmd/main.go", "README.md", "b.go", "c.go"}},
+		{SortByModTime, []string{"b.go", "README.md", "cmd/main.go", "a.go", "c.go"}},
+		{SortByModTimeDesc, []string{"a.go", "c.go", "cmd/main.go", "b.go", "README.md"}},
+		{SortPriorityFirst, []string{"cmd/main.go", "README.md", "a.go", "b.go", "c.go"}},
+	}
+	for _, tt := range tests {
+		fh := NewFileHandler()
+		fh.SortOrder = tt.order
+		fh.PriorityPatterns = []string{"*.md", "cmd/*"}
+
+		files := append([]FileInfo(nil), walked...)
+		fh.sortFiles(root, files)
+
+		var got []string
+		for _, file := range files {
+			relPath, _ := filepath.Rel(root, file.Path)
+			got = append(got, filepath.ToSlash(relPath))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("sortFiles(%q) = %v, want %v", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	for _, order := range SortOrders() {
+		if got, err := ParseSortOrder(string(order)); err != nil || got != order {
+			t.Errorf("ParseSortOrder(%q) = %q, %v, want %q", order, got, err, order)
+		}
+	}
+	if _, err := ParseSortOrder("random"); err == nil {
+		t.Error("ParseSortOrder(\"random\") succeeded, want an error")
+	}
+}
@@ -0,0 +1,102 @@
+package filehandler
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// walkFilesConcurrent is walkFiles with one goroutine per directory reading its entries, at
+// most cap(sem) of which run at once. The entries are checked with walkAction, and so
+// ShouldIgnoreCallback, and passed to visit on the calling goroutine only, in no particular
+// order. If reading any directory fails, the error for the first such directory in walk order
+// is returned.
+func (fh *FileHandler) walkFilesConcurrent(rootDir string, sem chan struct{}, visit func(path string, d fs.DirEntry)) error {
+	// Like filepath.WalkDir, the root is not followed if it is a symlink
+	info, err := os.Lstat(rootDir)
+	if err != nil {
+		return err
+	}
+	root := fs.FileInfoToDirEntry(info)
+	action, err := fh.walkAction(rootDir, rootDir, root)
+	if err != nil || action == walkSkip {
+		return err
+	}
+	if action == walkVisit {
+		visit(rootDir, root)
+		return nil
+	}
+
+	// dirListing is the result of reading one directory
+	type dirListing struct {
+		dir     string
+		entries []fs.DirEntry
+		err     error
+	}
+	listings := make(chan dirListing)
+	pending := 0
+	readDir := func(dir string) {
+		pending++
+		go func() {
+			sem <- struct{}{}
+			entries, err := os.ReadDir(dir)
+			<-sem
+			listings <- dirListing{dir, entries, err}
+		}()
+	}
+
+	var errPath string
+	var firstErr error
+	fail := func(path string, err error) {
+		if firstErr == nil || walkOrderLess(path, errPath) {
+			errPath, firstErr = path, err
+		}
+	}
+
+	readDir(rootDir)
+	for pending > 0 {
+		listing := <-listings
+		pending--
+		if listing.err != nil {
+			fail(listing.dir, listing.err)
+			continue
+		}
+
+		for _, d := range listing.entries {
+			path := filepath.Join(listing.dir, d.Name())
+			action, err := fh.walkAction(rootDir, path, d)
+			if err != nil {
+				fail(path, err)
+				continue
+			}
+			switch action {
+			case walkDescend:
+				readDir(path)
+			case walkVisit:
+				visit(path, d)
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// walkOrderLess reports whether filepath.WalkDir visits path a before path b: paths are
+// compared element by element, so a directory's contents come before a sibling sorting after
+// the directory's name, e.g. "a/b.go" before "a.go"
+func walkOrderLess(a, b string) bool {
+	sep := string(filepath.Separator)
+	for {
+		aElem, aRest, aMore := strings.Cut(a, sep)
+		bElem, bRest, bMore := strings.Cut(b, sep)
+		if aElem != bElem {
+			return aElem < bElem
+		}
+		if !aMore || !bMore {
+			// A directory comes before its contents
+			return !aMore && bMore
+		}
+		a, b = aRest, bRest
+	}
+}
@@ -0,0 +1,252 @@
+package filehandler
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchDebounce is how long a file must stay unchanged before a FileWatcher reports it
+const DefaultWatchDebounce = 500 * time.Millisecond
+
+// FileWatcher reports files created or modified under a directory after it was started.
+// Changes are received from the operating system through fsnotify; as its watches are not
+// recursive, every directory passing the ignore rules is watched, including ones created
+// later. A file is reported once it has stayed unchanged for Debounce, so a file still being
+// written is reported once rather than on every write.
+type FileWatcher struct {
+	Debounce time.Duration // Time a file must stay unchanged before it is reported
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewFileWatcher creates a file watcher reporting files once they have stayed unchanged for debounce
+func NewFileWatcher(debounce time.Duration) *FileWatcher {
+	return &FileWatcher{Debounce: debounce}
+}
+
+// Start watches the directories under rootDir that pass fh's ignore rules, sending a FileInfo
+// with its Content read for every file passing them that is created or modified afterwards.
+// Errors while watching are sent on the error channel without stopping the watcher. Both
+// channels are closed by Stop.
+func (w *FileWatcher) Start(rootDir string, fh *FileHandler) (<-chan FileInfo, <-chan error, error) {
+	if w.stop != nil {
+		return nil, nil, errors.New("file watcher already started")
+	}
+
+	rootDir = filepath.Clean(rootDir)
+	info, err := os.Stat(rootDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error accessing directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, nil, fmt.Errorf("path is not a directory: %s", rootDir)
+	}
+
+	notifier, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating file watcher: %w", err)
+	}
+	// Files present now are the baseline; only later changes are reported
+	if _, err := w.watchTree(notifier, rootDir, rootDir, fh); err != nil {
+		notifier.Close()
+		return nil, nil, err
+	}
+
+	files := make(chan FileInfo)
+	errs := make(chan error)
+	w.stop = make(chan struct{})
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		defer close(errs)
+		defer close(files)
+		defer notifier.Close()
+
+		// Files waiting out the debounce period, by the time of their last change
+		pending := make(map[string]time.Time)
+		timer := time.NewTimer(w.Debounce)
+		timer.Stop()
+
+		sendErr := func(err error) bool {
+			select {
+			case errs <- err:
+				return true
+			case <-w.stop:
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-w.stop:
+				return
+
+			case err, ok := <-notifier.Errors:
+				if !ok {
+					return
+				}
+				if !sendErr(err) {
+					return
+				}
+
+			case event, ok := <-notifier.Events:
+				if !ok {
+					return
+				}
+
+				// Forget removed files, so they are reported again if they are recreated.
+				// Watches on removed directories are dropped by fsnotify.
+				if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+					delete(pending, event.Name)
+					continue
+				}
+				if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+					continue
+				}
+
+				changed, err := w.changedFiles(notifier, rootDir, event.Name, fh)
+				if err != nil && !sendErr(err) {
+					return
+				}
+				if len(changed) == 0 {
+					continue
+				}
+
+				// Restart the debounce period whenever a file changes again
+				now := time.Now()
+				for _, path := range changed {
+					pending[path] = now
+				}
+				timer.Reset(w.Debounce)
+
+			case <-timer.C:
+				now := time.Now()
+				var next time.Duration
+				for path, changedAt := range pending {
+					if wait := w.Debounce - now.Sub(changedAt); wait > 0 {
+						if next == 0 || wait < next {
+							next = wait
+						}
+						continue
+					}
+
+					delete(pending, path)
+					file, err := fh.readWatchedFile(path)
+					if err != nil {
+						// Removed again before it could be read
+						continue
+					}
+					select {
+					case files <- file:
+					case <-w.stop:
+						return
+					}
+				}
+				if next > 0 {
+					timer.Reset(next)
+				}
+			}
+		}
+	}()
+
+	return files, errs, nil
+}
+
+// Stop stops watching and closes the channels returned by Start. It is safe to call more than once.
+func (w *FileWatcher) Stop() {
+	if w.stop == nil {
+		return
+	}
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	<-w.done
+}
+
+// changedFiles returns the files under rootDir to report for a create or write event on path.
+// A new directory is watched, and the files already in it are returned, as they may have been
+// created before the watch was added. Paths removed since the event are left out.
+func (w *FileWatcher) changedFiles(notifier *fsnotify.Watcher, rootDir, path string, fh *FileHandler) ([]string, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil, nil
+	}
+
+	d := fs.FileInfoToDirEntry(info)
+	if d.IsDir() {
+		files, err := w.watchTree(notifier, rootDir, path, fh)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+		return files, err
+	}
+	if !d.Type().IsRegular() {
+		return nil, nil
+	}
+
+	action, err := fh.walkAction(rootDir, path, d)
+	if err != nil || action != walkVisit {
+		return nil, nil
+	}
+	return []string{path}, nil
+}
+
+// watchTree adds a watch for dir and every directory below it passing fh's rules, and returns
+// the files in them passing the rules
+func (w *FileWatcher) watchTree(notifier *fsnotify.Watcher, rootDir, dir string, fh *FileHandler) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		action, err := fh.walkAction(rootDir, path, d)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case walkSkip:
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+		case walkDescend:
+			if err := notifier.Add(path); err != nil {
+				return fmt.Errorf("error watching %s: %w", path, err)
+			}
+		case walkVisit:
+			if d.Type().IsRegular() {
+				files = append(files, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error watching %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// readWatchedFile returns the FileInfo of the file at path with its content read
+func (fh *FileHandler) readWatchedFile(path string) (FileInfo, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	file := FileInfo{
+		Path:     path,
+		Language: DetectLanguage(path),
+	}
+	fh.readFileInfo(&file, fs.FileInfoToDirEntry(info))
+	return file, nil
+}
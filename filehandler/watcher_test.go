@@ -0,0 +1,78 @@
+package filehandler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileWatcher(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"pkg", "node_modules"} {
+		if err := os.Mkdir(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "old.go"), []byte("package pkg\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	watcher := NewFileWatcher(50 * time.Millisecond)
+	files, errs, err := watcher.Start(root, NewFileHandler())
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer watcher.Stop()
+
+	// A file in a watched directory, written twice, one in a directory created after the start,
+	// and one in an ignored directory
+	writes := []struct {
+		path    string
+		content string
+	}{
+		{"pkg/new.go", "package pkg\n"},
+		{"pkg/new.go", "package pkg\n\nfunc New() {}\n"},
+		{"cmd/tool/main.go", "package main\n"},
+		{"node_modules/dep/index.js", "module.exports = {}\n"},
+	}
+	for _, w := range writes {
+		path := filepath.Join(root, filepath.FromSlash(w.path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(w.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := map[string]string{
+		filepath.Join(root, "pkg", "new.go"):          "package pkg\n\nfunc New() {}\n",
+		filepath.Join(root, "cmd", "tool", "main.go"): "package main\n",
+	}
+	timeout := time.After(5 * time.Second)
+	for len(want) > 0 {
+		select {
+		case file := <-files:
+			content, ok := want[file.Path]
+			if !ok {
+				t.Fatalf("unexpected file %s reported", file.Path)
+			}
+			if file.Content != content {
+				t.Errorf("%s reported with content %q, want the last write %q", file.Path, file.Content, content)
+			}
+			delete(want, file.Path)
+		case err := <-errs:
+			t.Fatalf("watcher error: %v", err)
+		case <-timeout:
+			t.Fatalf("files not reported: %v", want)
+		}
+	}
+
+	// Each file is reported once, and nothing from the ignored directory
+	select {
+	case file := <-files:
+		t.Errorf("unexpected file %s reported", file.Path)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
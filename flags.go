@@ -0,0 +1,233 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/spf13/pflag"
+)
+
+// documentationFlags are the flags shared by run and generate. They are applied over the
+// settings of .structura.yaml and the selected profile, so only flags given explicitly are applied.
+type documentationFlags struct {
+	fs *pflag.FlagSet
+
+	profile                 *string
+	noKeyring               *bool
+	dryRun                  *bool
+	force                   *bool
+	maxConcurrent           *int
+	cacheTTL                *time.Duration
+	batchSize               *int
+	tokenBudget             *int
+	sortOrder               *string
+	priority                *string
+	maxDepth                *int
+	excludePaths            stringList
+	outputFormat            *string
+	format                  *string
+	style                   *string
+	naming                  *string
+	outputTemplate          *string
+	readme                  *bool
+	overview                *bool
+	architecture            *bool
+	glossary                *bool
+	glossaryTerms           *int
+	techDebt                *bool
+	mkdocs                  *bool
+	redocumentPromptVersion *string
+	addFrontmatter          *bool
+	maxOutputBytes          *int64
+	minOutputBytes          *int64
+	minDocScore             *float64
+	lintOutput              *bool
+	crossReferences         *bool
+	symbolLinks             *bool
+	webhookURL              *string
+	outputBucket            *string
+	outputBucketPrefix      *string
+	outputBucketEndpoint    *string
+}
+
+// addDocumentationFlags registers the flags shared by run and generate on fs. Their defaults
+// are those of config.NewConfig.
+func addDocumentationFlags(fs *pflag.FlagSet) *documentationFlags {
+	f := &documentationFlags{fs: fs}
+
+	f.profile = fs.String("profile", "", "load the named profile from ~/.config/structura/"+config.ProfilesFileName)
+	f.noKeyring = fs.Bool("no-keyring", false, "do not use the OS keychain for API keys")
+	f.dryRun = fs.Bool("dry-run", false, "list the files that would be documented in [dir] with their size, estimated tokens and cost, without calling the API")
+	f.force = fs.Bool("force", false, "regenerate documentation for every file, even if its source is unchanged")
+	f.maxConcurrent = fs.Int("max-concurrent", 1, "maximum number of API requests in flight at once")
+	f.cacheTTL = fs.Duration("cache-ttl", 7*24*time.Hour, cacheTTLUsage)
+	f.batchSize = fs.Int("batch-size", 1, "maximum number of small files documented per API call (ChatGPT and DeepSeek only)")
+	f.tokenBudget = fs.Int("token-budget", 0, tokenBudgetUsage)
+	f.sortOrder = fs.String("sort", string(filehandler.SortLexical), "order files are processed in: lexical, size, size-desc, modtime, modtime-desc or priority")
+	f.priority = fs.String("priority", "", "comma-separated glob patterns of files processed first with --sort=priority")
+	f.maxDepth = fs.Int("max-depth", 0, "maximum directory depth to document, where files in [dir] are depth 1 (0 = unlimited)")
+	fs.Var(&f.excludePaths, "exclude-path", excludePathUsage)
+	f.outputFormat = fs.String("output-format", config.OutputFormatMarkdown, outputFormatUsage)
+	f.format = fs.String("format", config.OutputFormatMarkdown, formatUsage)
+	f.style = fs.String("style", "", styleUsage)
+	f.naming = fs.String("naming", "", namingUsage)
+	f.outputTemplate = fs.String("output-template", "", outputTemplateUsage)
+	f.readme = fs.Bool("readme", false, "also generate a README.md for the project in the output directory")
+	f.overview = fs.Bool("overview", true, "also generate a PROJECT_OVERVIEW.md describing the project's architecture (use --overview=false to skip)")
+	f.architecture = fs.Bool("architecture", false, "also generate an ARCHITECTURE.md from the directory tree and a summary of each file's documentation")
+	f.glossary = fs.Bool("glossary", false, "also generate a GLOSSARY.md defining the project's domain terms")
+	f.glossaryTerms = fs.Int("glossary-terms", 30, "maximum number of terms defined in GLOSSARY.md")
+	f.techDebt = fs.Bool("tech-debt", false, techDebtUsage)
+	f.mkdocs = fs.Bool("mkdocs", false, mkdocsUsage)
+	f.redocumentPromptVersion = fs.String("re-document-prompt-version", "", redocumentPromptVersionUsage)
+	f.addFrontmatter = fs.Bool("frontmatter", false, frontmatterUsage)
+	f.maxOutputBytes = fs.Int64("max-output-bytes", 0, maxOutputBytesUsage)
+	f.minOutputBytes = fs.Int64("min-output-bytes", 0, minOutputBytesUsage)
+	f.minDocScore = fs.Float64("min-doc-score", 0.6, minDocScoreUsage)
+	f.lintOutput = fs.Bool("lint-output", false, lintOutputUsage)
+	f.crossReferences = fs.Bool("cross-references", false, crossReferencesUsage)
+	f.symbolLinks = fs.Bool("symbol-links", false, symbolLinksUsage)
+	f.webhookURL = fs.String("webhook-url", "", webhookURLUsage)
+	f.outputBucket = fs.String("output-bucket", "", outputBucketUsage)
+	f.outputBucketPrefix = fs.String("output-bucket-prefix", "", outputBucketPrefixUsage)
+	f.outputBucketEndpoint = fs.String("output-bucket-endpoint", "", outputBucketEndpointUsage)
+	return f
+}
+
+// check validates the flags whose values are parsed, so mistakes are reported before
+// anything else is done
+func (f *documentationFlags) check() error {
+	if err := config.ValidateOutputFormat(*f.outputFormat); err != nil {
+		return err
+	}
+	if _, err := outputFormats(*f.format); err != nil {
+		return err
+	}
+	if *f.style != "" {
+		if _, err := config.ParseDocumentationStyle(*f.style); err != nil {
+			return err
+		}
+	}
+	if _, err := outputNaming(*f.naming, *f.outputTemplate); err != nil {
+		return err
+	}
+	_, err := filehandler.ParseSortOrder(*f.sortOrder)
+	return err
+}
+
+// apply sets the fields of cfg selected by the flags given on the command line
+func (f *documentationFlags) apply(cfg *config.Config) error {
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	if f.fs.Changed("force") {
+		cfg.ForceRegenerate = *f.force
+	}
+	if f.fs.Changed("max-concurrent") && *f.maxConcurrent > 0 {
+		cfg.MaxConcurrentRequests = *f.maxConcurrent
+	}
+	if f.fs.Changed("cache-ttl") {
+		cfg.CacheTTL = *f.cacheTTL
+	}
+	if f.fs.Changed("batch-size") {
+		cfg.BatchSize = *f.batchSize
+	}
+	if f.fs.Changed("token-budget") {
+		cfg.SessionTokenBudget = *f.tokenBudget
+	}
+	if f.fs.Changed("output-format") {
+		cfg.OutputFormat = *f.outputFormat
+	}
+	if f.fs.Changed("format") {
+		cfg.OutputFormats, _ = outputFormats(*f.format)
+	}
+	if *f.style != "" {
+		cfg.DocumentationStyle, _ = config.ParseDocumentationStyle(*f.style)
+	}
+	if f.fs.Changed("naming") || f.fs.Changed("output-template") {
+		cfg.OutputFileNaming, _ = outputNaming(*f.naming, *f.outputTemplate)
+		cfg.OutputFileTemplate = *f.outputTemplate
+	}
+	if f.fs.Changed("readme") {
+		cfg.GenerateReadme = *f.readme
+	}
+	if f.fs.Changed("overview") {
+		cfg.GenerateProjectOverview = *f.overview
+	}
+	if f.fs.Changed("architecture") {
+		cfg.GenerateArchitectureDocs = *f.architecture
+	}
+	if f.fs.Changed("glossary") {
+		cfg.GenerateGlossary = *f.glossary
+	}
+	if f.fs.Changed("glossary-terms") {
+		cfg.GlossaryTerms = *f.glossaryTerms
+	}
+	if f.fs.Changed("tech-debt") {
+		cfg.GenerateTechDebt = *f.techDebt
+	}
+	if f.fs.Changed("mkdocs") {
+		cfg.GenerateMkdocsConfig = *f.mkdocs
+	}
+	if f.fs.Changed("re-document-prompt-version") {
+		cfg.RedocumentPromptVersion = *f.redocumentPromptVersion
+	}
+	if f.fs.Changed("frontmatter") {
+		cfg.AddFrontmatter = *f.addFrontmatter
+	}
+	if f.fs.Changed("max-output-bytes") {
+		cfg.MaxOutputFileBytes = *f.maxOutputBytes
+	}
+	if f.fs.Changed("min-output-bytes") {
+		cfg.MinOutputFileBytes = *f.minOutputBytes
+	}
+	if f.fs.Changed("min-doc-score") {
+		cfg.MinDocScore = *f.minDocScore
+	}
+	if f.fs.Changed("lint-output") {
+		cfg.LintOutput = *f.lintOutput
+	}
+	if f.fs.Changed("cross-references") {
+		cfg.CrossReferenceLinks = *f.crossReferences
+	}
+	if f.fs.Changed("symbol-links") {
+		cfg.InjectCrossReferences = *f.symbolLinks
+	}
+	if f.fs.Changed("webhook-url") {
+		cfg.WebhookURL = *f.webhookURL
+	}
+	if f.fs.Changed("output-bucket") {
+		cfg.OutputBucket = *f.outputBucket
+	}
+	if f.fs.Changed("output-bucket-prefix") {
+		cfg.OutputBucketPrefix = *f.outputBucketPrefix
+	}
+	if f.fs.Changed("output-bucket-endpoint") {
+		cfg.OutputBucketEndpoint = *f.outputBucketEndpoint
+	}
+	return nil
+}
+
+// applyTraversal sets how fh selects and orders the files to document
+func (f *documentationFlags) applyTraversal(fh *filehandler.FileHandler) error {
+	order, err := filehandler.ParseSortOrder(*f.sortOrder)
+	if err != nil {
+		return err
+	}
+	fh.SortOrder = order
+	fh.PriorityPatterns = f.priorityPatterns()
+	fh.MaxDepth = *f.maxDepth
+	fh.ExcludePaths = f.excludePaths
+	return nil
+}
+
+// priorityPatterns returns the glob patterns given with --priority
+func (f *documentationFlags) priorityPatterns() []string {
+	if *f.priority == "" {
+		return nil
+	}
+	return strings.Split(*f.priority, ",")
+}
@@ -0,0 +1,49 @@
+package frontmatter
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// delimiter opens and closes a frontmatter block
+const delimiter = "---"
+
+// Meta is the frontmatter written at the top of a generated Markdown file
+type Meta struct {
+	SourceFile    string    `yaml:"source_file"` // Path relative to the project root
+	GeneratedAt   time.Time `yaml:"generated_at"`
+	APIProvider   string    `yaml:"api_provider"`
+	Model         string    `yaml:"model"`
+	ProjectType   string    `yaml:"project_type"`
+	FileSizeBytes int64     `yaml:"file_size_bytes"`
+}
+
+// Prepend returns doc with a YAML frontmatter block for meta, as read by static site
+// generators such as Hugo, Jekyll and Gatsby
+func Prepend(doc string, meta Meta) string {
+	meta.GeneratedAt = meta.GeneratedAt.UTC().Truncate(time.Second)
+
+	// Marshaling a struct of strings, numbers and a time cannot fail
+	data, _ := yaml.Marshal(meta)
+	return delimiter + "\n" + string(data) + delimiter + "\n\n" + doc
+}
+
+// Strip returns doc without its frontmatter block, if it starts with one
+func Strip(doc string) string {
+	normalized := strings.ReplaceAll(doc, "\r\n", "\n")
+	if !strings.HasPrefix(normalized, delimiter+"\n") {
+		return doc
+	}
+
+	rest := normalized[len(delimiter)+1:]
+	end := strings.Index(rest, "\n"+delimiter+"\n")
+	if end < 0 {
+		if !strings.HasSuffix(rest, "\n"+delimiter) {
+			return doc
+		}
+		return ""
+	}
+	return strings.TrimLeft(rest[end+len(delimiter)+2:], "\n")
+}
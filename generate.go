@@ -0,0 +1,853 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/ci"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/docs"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/frontmatter"
+	"github.com/Abiggj/structura/jsonoutput"
+	"github.com/Abiggj/structura/linker"
+	"github.com/Abiggj/structura/linter"
+	"github.com/Abiggj/structura/notification"
+	"github.com/Abiggj/structura/output"
+	"github.com/Abiggj/structura/output/s3"
+	"github.com/Abiggj/structura/search"
+	"github.com/Abiggj/structura/techdebt"
+	"github.com/Abiggj/structura/types"
+	"github.com/spf13/cobra"
+)
+
+// newGenerateCommand creates the command documenting a project without the TUI
+func newGenerateCommand() *cobra.Command {
+	cmd := newCommand("generate", "[dir]", "Document a project without the TUI, e.g. in CI pipelines")
+	cmd.Args = cobra.MaximumNArgs(1)
+	fs := cmd.Flags()
+	output := fs.String("output", "", "directory the documentation is written to (default: output_dir from .structura.yaml)")
+	apiType := fs.String("api", "", "API type: deepseek, chatgpt, groq, custom or bedrock (default: $STRUCTURA_API_TYPE, or deepseek)")
+	model := fs.String("model", "", "model name (default: $STRUCTURA_MODEL, or the first model of the API type)")
+	projectType := fs.String("type", "", "project type (default: detected from the project)")
+	awsRegion := fs.String("aws-region", "", "AWS region of Bedrock (default: $AWS_REGION or the AWS profile's region)")
+	awsProfile := fs.String("aws-profile", "", "AWS profile the Bedrock credentials are read from (default: $AWS_PROFILE or default)")
+	flags := addDocumentationFlags(fs)
+	sinceCommit := fs.String("since-commit", "", "only document files changed between this git revision and HEAD, plus files git does not track")
+	since := fs.String("since", "", "only document files modified after this time, an RFC 3339 timestamp such as 2024-01-15T10:00:00Z or a duration ago such as 24h or 7d; with --since-commit, files matching either are documented")
+	sinceLastRun := fs.Bool("since-last-run", false, "like --since, with the start of the last successful run into the output directory")
+	watch := fs.Bool("watch", false, "after documenting the project, keep documenting files created or modified in [dir] until interrupted")
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		rootDir := "."
+		if len(args) > 0 {
+			rootDir = args[0]
+		}
+		rootDir, err := filepath.Abs(rootDir)
+		if err != nil {
+			return err
+		}
+
+		config.KeyringEnabled = !*flags.noKeyring
+		if err := flags.check(); err != nil {
+			return err
+		}
+		cfg := config.NewConfig()
+
+		projectConfig, err := config.LoadProjectConfig(rootDir)
+		if err != nil {
+			return err
+		}
+		config.MergeProjectConfig(cfg, projectConfig)
+
+		// Settings are applied from least to most specific: project file, profile, flags
+		selectedType := ""
+		if projectConfig != nil {
+			selectedType = projectConfig.ProjectType
+		}
+		if *flags.profile != "" {
+			profiles, err := config.LoadProfiles()
+			if err != nil {
+				return err
+			}
+			p, ok := profiles[*flags.profile]
+			if !ok {
+				return fmt.Errorf("profile %q not found", *flags.profile)
+			}
+			p.Apply(cfg)
+			if p.ProjectType != "" {
+				selectedType = p.ProjectType
+			}
+		}
+		envAPIType, envModel, err := config.EnvironmentAPI()
+		if err != nil {
+			return err
+		}
+		if *apiType == "" {
+			*apiType = string(envAPIType)
+		}
+		if *model == "" {
+			*model = envModel
+		}
+		if *apiType != "" {
+			cfg.APIType = types.APIType(*apiType)
+			if models := types.APIModelMap[cfg.APIType]; len(models) > 0 && *model == "" {
+				cfg.APIModel = models[0]
+			}
+		}
+		if *model != "" {
+			cfg.APIModel = *model
+			if cfg.APIType == types.APITypeCustom {
+				cfg.CustomModelName = *model
+			}
+		}
+		if *projectType != "" {
+			selectedType = *projectType
+		}
+		if *awsRegion != "" {
+			cfg.AWSRegion = *awsRegion
+		}
+		if *awsProfile != "" {
+			cfg.AWSProfile = *awsProfile
+		}
+		if err := flags.apply(cfg); err != nil {
+			return err
+		}
+
+		outputDir := *output
+		if outputDir == "" {
+			outputDir = cfg.OutputDir
+		}
+		if outputDir == "" {
+			return fmt.Errorf("no output directory: pass --output or set output_dir in %s", config.ProjectConfigFileName)
+		}
+		if outputDir, err = filepath.Abs(outputDir); err != nil {
+			return err
+		}
+
+		fileHandler := filehandler.NewFileHandler()
+		if selectedType == "" {
+			fileHandler.SetProjectType(filehandler.DetectProjectType(rootDir))
+		} else {
+			fileHandler.SetProjectType(filehandler.ProjectType(selectedType))
+		}
+		if err := flags.applyTraversal(fileHandler); err != nil {
+			return err
+		}
+		cfg.FileHandler = fileHandler
+
+		var modifiedAfter *time.Time
+		switch {
+		case *since != "" && *sinceLastRun:
+			return fmt.Errorf("--since and --since-last-run cannot be used together")
+		case *since != "":
+			t, err := parseSince(*since, time.Now())
+			if err != nil {
+				return err
+			}
+			modifiedAfter = &t
+		case *sinceLastRun:
+			t, ok, err := filehandler.LoadLastRun(outputDir)
+			if err != nil {
+				return err
+			}
+			if ok {
+				modifiedAfter = &t
+			} else {
+				fmt.Printf("No previous run recorded in %s, documenting every file\n", outputDir)
+			}
+		}
+
+		var changed []string
+		if *sinceCommit != "" {
+			if changed, err = filehandler.GitChangedFiles(rootDir, *sinceCommit); err != nil {
+				return err
+			}
+			if changed == nil {
+				changed = []string{} // Nothing changed, rather than no filter
+			}
+		}
+		if changed == nil {
+			fileHandler.ModifiedAfter = modifiedAfter
+		}
+
+		if *flags.dryRun {
+			files, err := fileHandler.TraverseDirectoryConcurrent(rootDir)
+			if err != nil {
+				return fmt.Errorf("failed to traverse directory: %w", err)
+			}
+			files = filesToDocument(files, rootDir, changed, modifiedAfter)
+			fmt.Print(api.FormatDryRunTable(rootDir, api.DryRun(context.Background(), cfg, files)))
+			return nil
+		}
+
+		if config.RequiresAPIKey(cfg.APIType) && cfg.GetActiveAPIKey() == "" {
+			return fmt.Errorf("no API key for %s: set it in the environment or the OS keychain", cfg.APIType)
+		}
+		client, err := api.CreateDocumentationClient(cfg, false)
+		if err != nil {
+			return err
+		}
+
+		// Check the bucket's credentials before spending any API calls
+		var uploader *s3.S3Uploader
+		if cfg.OutputBucket != "" {
+			if uploader, err = s3.NewS3Uploader(cfg); err != nil {
+				return err
+			}
+		}
+
+		// Stop starting new files on SIGINT/SIGTERM and cancel the requests in flight
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		g := &headlessGenerator{
+			cfg:           cfg,
+			client:        client,
+			projectType:   string(fileHandler.ProjectType),
+			rootDir:       rootDir,
+			outputDir:     outputDir,
+			changed:       changed,
+			modifiedAfter: modifiedAfter,
+			reporter:      ci.DetectReporter(),
+			uploader:      uploader,
+		}
+		start := time.Now()
+		err = g.run(ctx, fileHandler)
+		if flushErr := cfg.Usage.Flush(); flushErr != nil {
+			fmt.Println("Warning: failed to save API usage:", flushErr)
+		}
+		if err == nil {
+			if saveErr := filehandler.SaveLastRun(outputDir, start); saveErr != nil {
+				fmt.Println("Warning:", saveErr)
+			}
+		}
+		if cfg.WebhookURL != "" {
+			// A receiver that is down does not fail the run
+			if notifyErr := g.notifyWebhook(time.Since(start)); notifyErr != nil {
+				fmt.Println("Warning:", notifyErr)
+			}
+		}
+		if g.reporter != nil {
+			// Report failed runs too, so their errors show up in the pull request
+			if reportErr := g.reporter.Finish(ci.Summary{OutputDir: outputDir, IndexFile: g.indexPath}); reportErr != nil {
+				fmt.Println("Warning:", reportErr)
+			}
+		}
+		if !*watch || ctx.Err() != nil || err == errBudgetExceeded {
+			return err
+		}
+		if err != nil {
+			fmt.Println("Warning:", err)
+		}
+		return g.watch(ctx, fileHandler)
+	}
+	return cmd
+}
+
+// headlessGenerator documents every file of a project, printing one line per file
+type headlessGenerator struct {
+	cfg                       *config.Config
+	client                    api.DocumentationClient
+	projectType               string
+	metadata                  filehandler.ProjectMetadata // Read from the project's manifest by the traversal
+	rootDir                   string
+	outputDir                 string
+	changed                   []string       // When non-nil, only these files, relative to rootDir, are documented
+	modifiedAfter             *time.Time     // With changed, files modified after this time are documented too
+	reporter                  ci.Reporter    // Set when running in CI
+	indexPath                 string         // Set once the documentation index is written
+	uploader                  *s3.S3Uploader // Set when the documentation is uploaded to a bucket
+	results                   []notification.FileResult
+	checksums                 *filehandler.ChecksumStore
+	freshness                 *filehandler.FreshnessStore
+	lintFailures              int        // Files whose documentation failed the Markdown checks
+	promptVersionRedocumented int        // Unchanged files documented again for an outdated prompt version
+	mu                        sync.Mutex // Serializes output lines
+}
+
+// Statuses documentFile returns for skipped files
+const (
+	statusUnchanged  = "unchanged, skipped"
+	statusDocumented = "already documented, skipped"
+	statusTooLarge   = "too large, skipped"
+)
+
+// run documents the files in the project root with up to MaxConcurrentRequests files at a time.
+// It returns an error if any file failed.
+func (g *headlessGenerator) run(ctx context.Context, fileHandler *filehandler.FileHandler) error {
+	files, err := fileHandler.TraverseDirectoryConcurrent(g.rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to traverse directory: %w", err)
+	}
+	g.metadata = fileHandler.Metadata
+
+	g.checksums, err = filehandler.LoadChecksumStore(g.outputDir)
+	if err != nil {
+		fmt.Println("Warning:", err)
+	}
+	g.freshness, err = filehandler.LoadFreshnessStore(g.outputDir)
+	if err != nil {
+		fmt.Println("Warning:", err)
+	}
+
+	fmt.Printf("Documenting %s with %s / %s into %s\n", g.rootDir, g.cfg.APIType, g.cfg.APIModel, g.outputDir)
+
+	if g.cfg.GenerateTechDebt {
+		annotations := techdebt.Extract(files)
+		if reportPath, err := techdebt.WriteReport(g.outputDir, g.rootDir, annotations); err != nil {
+			fmt.Println("Warning: failed to write technical debt report:", err)
+		} else {
+			fmt.Printf("Technical debt: %s (%d annotations)\n", reportPath, techdebt.Count(annotations))
+		}
+	}
+
+	toDocument := filesToDocument(files, g.rootDir, g.changed, g.modifiedAfter)
+	if g.changed != nil {
+		fmt.Printf("%d of %d files changed\n", len(toDocument), len(files))
+	}
+
+	workers := g.cfg.MaxConcurrentRequests
+	if workers < 1 {
+		workers = 1
+	}
+
+	stats := docs.ProcessingStats{SkippedIgnored: fileHandler.IgnoredCount(), MinScore: g.cfg.MinDocScore}
+	for _, file := range toDocument {
+		if !file.IsDir {
+			stats.Discovered++
+		}
+	}
+
+	var documented, skipped, failed int
+	report := func(file filehandler.FileInfo, status string, err error) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		relPath, _ := filepath.Rel(g.rootDir, file.Path)
+		switch {
+		case err != nil:
+			failed++
+			g.results = append(g.results, notification.FileResult{Path: relPath, Status: notification.FileFailed})
+			stats.Failures = append(stats.Failures, docs.FileFailure{Path: filepath.ToSlash(relPath), Error: err.Error()})
+			fmt.Printf("✗ %s: %s\n", relPath, err)
+			if g.reporter != nil {
+				g.reporter.FileFailed(file.Path, err)
+			}
+		case status != "":
+			skipped++
+			if status == statusTooLarge {
+				stats.SkippedTooLarge++
+			} else {
+				stats.SkippedUnchanged++
+			}
+			fmt.Printf("- %s (%s)\n", relPath, status)
+		default:
+			documented++
+			g.results = append(g.results, notification.FileResult{Path: relPath, Status: notification.FileDocumented})
+			fmt.Printf("✓ %s\n", relPath)
+			if g.reporter != nil {
+				g.reporter.FileProcessed(file.Path)
+			}
+		}
+	}
+
+	// Each worker documents a single file, or a batch of small files of one package
+	var wg sync.WaitGroup
+	queue := make(chan []filehandler.FileInfo)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range queue {
+				if len(group) == 1 {
+					status, err := g.documentFile(ctx, group[0])
+					report(group[0], status, err)
+					continue
+				}
+				for j, result := range g.documentBatch(ctx, group) {
+					report(group[j], result.status, result.err)
+				}
+			}
+		}()
+	}
+
+	var sourceFiles []filehandler.FileInfo
+	for _, file := range toDocument {
+		if !file.IsDir {
+			sourceFiles = append(sourceFiles, file)
+		}
+	}
+	packages := filehandler.PackageNames(g.rootDir, sourceFiles, filehandler.ProjectType(g.projectType))
+
+	notStarted := 0
+	for i := 0; i < len(sourceFiles); {
+		n := max(api.NextBatch(g.cfg, g.client, sourceFiles[i:], packages), 1)
+		group := sourceFiles[i : i+n]
+		i += n
+		if ctx.Err() != nil {
+			break
+		}
+		if notStarted > 0 || g.overBudget() {
+			// Files already sent may still take the run a little over the budget
+			notStarted += len(group)
+			continue
+		}
+		queue <- group
+	}
+	close(queue)
+	wg.Wait()
+	g.flushStores()
+
+	fmt.Printf("\nDocumented %d files (%d from the response cache), skipped %d, failed %d\n", documented, api.CacheHits(), skipped, failed)
+	if usage := g.cfg.Usage.Run(); usage.TotalAPICallsMade > 0 {
+		fmt.Printf("API usage: %d calls, ~%d tokens, ~$%.4f\n", usage.TotalAPICallsMade, usage.TotalTokensEstimated, usage.TotalCostEstimated)
+	}
+	if g.cfg.LintOutput {
+		fmt.Printf("%d files failed the Markdown checks and end with quality notes\n", g.lintFailures)
+	}
+	if g.promptVersionRedocumented > 0 {
+		fmt.Printf("Re-documented %d unchanged files for an outdated prompt version\n", g.promptVersionRedocumented)
+	}
+	if notStarted > 0 {
+		fmt.Printf("Token budget of %d reached: %d files were not documented\n", g.cfg.SessionTokenBudget, notStarted)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted")
+	}
+
+	g.writeProjectDocs(ctx, files, notStarted > 0 || g.overBudget())
+
+	if g.cfg.CrossReferenceLinks {
+		if err := linker.AddCrossReferences(g.outputDir); err != nil {
+			return fmt.Errorf("failed to add cross references: %w", err)
+		}
+	}
+
+	if g.cfg.InjectCrossReferences {
+		var symbolFiles []filehandler.FileInfo
+		for _, file := range files {
+			if relPath, err := filepath.Rel(g.rootDir, file.Path); err == nil && !file.IsDir {
+				symbolFiles = append(symbolFiles, filehandler.FileInfo{Path: filepath.ToSlash(relPath)})
+			}
+		}
+		docPath := func(relPath string) string { return config.OutputRelPath(g.cfg, relPath) }
+		if err := docs.LinkCrossReferences(g.outputDir, symbolFiles, docPath); err != nil {
+			return fmt.Errorf("failed to link symbols: %w", err)
+		}
+	}
+
+	docPath := func(relPath string) string { return config.OutputRelPath(g.cfg, relPath) }
+	stats.Documented = documented
+	stats.Scores = linter.ScoreFiles(files, g.rootDir, g.outputDir, docPath)
+	if err := docs.GenerateCoverageReport(g.outputDir, stats); err != nil {
+		return fmt.Errorf("failed to write %s: %w", docs.CoverageFileName, err)
+	}
+	fmt.Printf("Coverage report: %s (%.1f%% documented, %d files to review)\n", filepath.Join(g.outputDir, docs.CoverageFileName),
+		stats.Percentage(), len(linter.BelowMinimum(stats.Scores, g.cfg.MinDocScore)))
+
+	indexPath, err := docs.GenerateIndex(g.outputDir)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", docs.IndexFileName, err)
+	}
+	fmt.Println("Documentation index:", indexPath)
+	g.indexPath = indexPath
+
+	if _, err := search.WriteIndex(g.outputDir); err != nil {
+		return fmt.Errorf("failed to write %s: %w", search.IndexFileName, err)
+	}
+
+	if g.cfg.GenerateMkdocsConfig {
+		if err := docs.GenerateMkdocsConfig(g.outputDir, g.metadata.Name); err != nil {
+			return fmt.Errorf("failed to write %s: %w", docs.MkdocsFileName, err)
+		}
+		fmt.Println("MkDocs configuration:", filepath.Join(g.outputDir, docs.MkdocsFileName))
+	}
+
+	if g.cfg.OutputFormat == config.OutputFormatHTML {
+		indexPath, err := docs.WriteHTMLSite(g.outputDir, filepath.Base(g.rootDir))
+		if err != nil {
+			return fmt.Errorf("failed to write HTML documentation: %w", err)
+		}
+		fmt.Println("HTML documentation:", indexPath)
+	}
+
+	if g.uploader != nil {
+		uploaded, err := output.SyncDir(g.uploader, g.outputDir)
+		if err != nil {
+			return fmt.Errorf("failed to upload the documentation: %w", err)
+		}
+		fmt.Printf("Uploaded %d files to %s\n", uploaded, g.uploader.URI())
+	}
+	if notStarted > 0 {
+		return errBudgetExceeded
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d files failed", failed)
+	}
+	return nil
+}
+
+// writeProjectDocs writes the documents describing the project as a whole, as the TUI does once
+// every file is done. A document that cannot be written is reported without failing the run, and
+// the documents generated by the API are skipped once the token budget is used up.
+func (g *headlessGenerator) writeProjectDocs(ctx context.Context, files []filehandler.FileInfo, overBudget bool) {
+	projectDocs := &docs.ProjectDocs{
+		Client:      g.client,
+		Config:      g.cfg,
+		RootDir:     g.rootDir,
+		OutputDir:   g.outputDir,
+		ProjectType: filehandler.ProjectType(g.projectType),
+		Files:       files,
+	}
+
+	steps := []struct {
+		name    string
+		enabled bool
+		write   func() (string, error)
+	}{
+		{"Project structure", true, projectDocs.WriteStructure},
+		{"Setup guide", !overBudget, func() (string, error) { return projectDocs.WriteSetup(ctx) }},
+		{"README", g.cfg.GenerateReadme && !overBudget, func() (string, error) { return projectDocs.WriteReadme(ctx) }},
+		{"Project overview", g.cfg.GenerateProjectOverview && !overBudget, func() (string, error) { return projectDocs.WriteOverview(ctx) }},
+		{"Architecture", g.cfg.GenerateArchitectureDocs && !overBudget, func() (string, error) { return projectDocs.WriteArchitecture(ctx) }},
+		{"Glossary", g.cfg.GenerateGlossary && !overBudget, func() (string, error) { return projectDocs.WriteGlossary(ctx) }},
+	}
+	for _, step := range steps {
+		if !step.enabled || ctx.Err() != nil {
+			continue
+		}
+		path, err := step.write()
+		if err != nil {
+			fmt.Println("Warning:", err)
+		}
+		if path != "" {
+			fmt.Printf("%s: %s\n", step.name, path)
+		}
+	}
+}
+
+// flushStores writes the files recorded as documented since the stores were last written. If
+// this fails the files are only documented again next run.
+func (g *headlessGenerator) flushStores() {
+	if err := g.checksums.Flush(); err != nil {
+		fmt.Println("Warning:", err)
+	}
+	if err := g.freshness.Flush(); err != nil {
+		fmt.Println("Warning:", err)
+	}
+}
+
+// warn prints a warning from a worker
+func (g *headlessGenerator) warn(err error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	fmt.Println("Warning:", err)
+}
+
+// overBudget reports whether the run has used up its session token budget
+func (g *headlessGenerator) overBudget() bool {
+	return g.cfg.Usage.Run().BudgetUsed(g.cfg.SessionTokenBudget) >= 1
+}
+
+// parseSince returns the time selected by --since: an RFC 3339 timestamp, or a duration
+// before now such as 30m, 24h or 7d
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: expected an RFC 3339 timestamp such as 2024-01-15T10:00:00Z or a duration such as 24h or 7d", value)
+}
+
+// notifyWebhook posts the results of the run to the configured webhook
+func (g *headlessGenerator) notifyWebhook(duration time.Duration) error {
+	var processed, failed int
+	for _, result := range g.results {
+		if result.Status == notification.FileFailed {
+			failed++
+		} else {
+			processed++
+		}
+	}
+
+	notifier := notification.NewWebhookNotifier(g.cfg.WebhookURL, g.cfg.WebhookSecret)
+	return notifier.Notify(context.Background(), notification.RunResult{
+		Status:         notification.RunStatus(processed, failed),
+		ProcessedCount: processed,
+		ErrorCount:     failed,
+		OutputDir:      g.outputDir,
+		Duration:       duration,
+		GeneratedAt:    time.Now(),
+		Files:          g.results,
+	})
+}
+
+// watch documents files created or modified in the project root, one at a time, until ctx is
+// cancelled. The documentation index is rewritten after each file.
+func (g *headlessGenerator) watch(ctx context.Context, fileHandler *filehandler.FileHandler) error {
+	// Documentation written inside the project must not be picked up as new source files
+	fileHandler.ShouldIgnoreCallback = func(path string, info os.FileInfo) bool {
+		absPath, err := filepath.Abs(path)
+		return err == nil && absPath == g.outputDir
+	}
+
+	watcher := filehandler.NewFileWatcher(filehandler.DefaultWatchDebounce)
+	files, errs, err := watcher.Start(g.rootDir, fileHandler)
+	if err != nil {
+		return fmt.Errorf("failed to watch directory: %w", err)
+	}
+	defer watcher.Stop()
+
+	fmt.Printf("\nWatching %s for changes, press Ctrl+C to stop\n", g.rootDir)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			fmt.Println("Warning:", err)
+		case file := <-files:
+			relPath, _ := filepath.Rel(g.rootDir, file.Path)
+			status, err := g.documentFile(ctx, file)
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case err != nil:
+				fmt.Printf("✗ %s: %s\n", relPath, err)
+				continue
+			case status != "":
+				fmt.Printf("- %s (%s)\n", relPath, status)
+				continue
+			}
+			fmt.Printf("✓ %s\n", relPath)
+			g.flushStores()
+
+			if _, err := docs.GenerateIndex(g.outputDir); err != nil {
+				fmt.Printf("Warning: failed to write %s: %s\n", docs.IndexFileName, err)
+			}
+			if err := g.cfg.Usage.Flush(); err != nil {
+				fmt.Println("Warning: failed to save API usage:", err)
+			}
+			if g.overBudget() {
+				fmt.Printf("Token budget of %d reached, no longer watching\n", g.cfg.SessionTokenBudget)
+				return errBudgetExceeded
+			}
+		}
+	}
+}
+
+// filesToDocument returns the files selected by --since-commit, whose changed files relative to
+// rootDir are given, together with those modified after modifiedAfter. Without --since-commit
+// the traversal has already left out files modified earlier, so every file is returned.
+func filesToDocument(files []filehandler.FileInfo, rootDir string, changed []string, modifiedAfter *time.Time) []filehandler.FileInfo {
+	if changed == nil {
+		return files
+	}
+
+	changed = append([]string(nil), changed...)
+	if modifiedAfter != nil {
+		// A file changed in git or modified recently is documented
+		for _, file := range files {
+			if relPath, err := filepath.Rel(rootDir, file.Path); err == nil && !file.ModTime.Before(*modifiedAfter) {
+				changed = append(changed, relPath)
+			}
+		}
+	}
+	return filehandler.FilterByChangedFiles(files, changed, rootDir)
+}
+
+// documentFile writes the documentation for one file. It returns a non-empty status
+// instead if the file was skipped.
+func (g *headlessGenerator) documentFile(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	p, status, err := g.checkFile(file)
+	if p == nil {
+		return status, err
+	}
+
+	doc, err := g.generate(ctx, file)
+	if err != nil {
+		return "", err
+	}
+	return "", g.saveDocumentation(p, doc)
+}
+
+// batchResult is the outcome of documenting one file of a batch, as returned by documentFile
+type batchResult struct {
+	status string
+	err    error
+}
+
+// documentBatch writes the documentation for several small files with as few API calls as
+// possible. Files the batch response leaves out are documented one at a time.
+func (g *headlessGenerator) documentBatch(ctx context.Context, files []filehandler.FileInfo) []batchResult {
+	results := make([]batchResult, len(files))
+
+	// Check every file first so skipped files are not sent to the API
+	pending := make([]*pendingFile, len(files))
+	var batch []filehandler.FileInfo
+	for i, file := range files {
+		pending[i], results[i].status, results[i].err = g.checkFile(file)
+		if pending[i] != nil {
+			batch = append(batch, file)
+		}
+	}
+
+	var batchDocs map[string]string
+	if client, ok := g.client.(api.BatchDocumentationClient); ok && len(batch) > 0 {
+		// A failed batch is not fatal: its files are documented one at a time below
+		batchDocs, _ = client.BatchGenerate(ctx, batch, g.cfg.MaxInputTokens)
+	}
+
+	for i, p := range pending {
+		if p == nil {
+			continue
+		}
+		doc, ok := batchDocs[p.file.Path]
+		if !ok || output.CheckMinLength(doc, g.cfg.MinOutputFileBytes) != nil {
+			var err error
+			if doc, err = g.generate(ctx, p.file); err != nil {
+				results[i].err = err
+				continue
+			}
+		}
+		results[i].err = g.saveDocumentation(p, doc)
+	}
+	return results
+}
+
+// pendingFile is a file checkFile found needs documenting
+type pendingFile struct {
+	file                 filehandler.FileInfo
+	relPath              string
+	outputFile           string
+	promptVersionChanged bool // Unchanged, but documented with an outdated prompt version
+}
+
+// checkFile prepares the output location of file. It returns nil and a non-empty status
+// instead if the file is skipped.
+func (g *headlessGenerator) checkFile(file filehandler.FileInfo) (*pendingFile, string, error) {
+	relPath, err := filepath.Rel(g.rootDir, file.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	outputFile := filepath.Join(g.outputDir, config.ComputeOutputPath(g.cfg, g.rootDir, file.Path))
+
+	if file.TooLarge() {
+		return nil, statusTooLarge, nil
+	}
+
+	// Skip files whose documentation is up to date
+	promptVersionChanged := false
+	if !g.cfg.ForceRegenerate {
+		if entry, ok := g.checksums.Get(relPath); ok {
+			if entry.Checksum == file.Checksum {
+				if !g.cfg.OutdatedPromptVersion(entry.PromptVersion) {
+					return nil, statusUnchanged, nil
+				}
+				promptVersionChanged = true
+			}
+		} else if g.freshness.IsFresh(file.Path, file.ModTime, outputFile) {
+			return nil, statusDocumented, nil
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return nil, "", err
+	}
+	return &pendingFile{file: file, relPath: relPath, outputFile: outputFile, promptVersionChanged: promptVersionChanged}, "", nil
+}
+
+// generate asks the API for the documentation of file, asking again, up to MaxRetries times,
+// while the response is trivially short
+func (g *headlessGenerator) generate(ctx context.Context, file filehandler.FileInfo) (string, error) {
+	for attempt := 0; ; attempt++ {
+		doc, err := g.client.GenerateDocumentation(ctx, file)
+		if err != nil {
+			return "", err
+		}
+		if err = output.CheckMinLength(doc, g.cfg.MinOutputFileBytes); err == nil {
+			return doc, nil
+		}
+		if attempt >= g.cfg.MaxRetries {
+			return "", err
+		}
+	}
+}
+
+// saveDocumentation writes the documentation generated for p in every selected format and
+// records that the file is documented
+func (g *headlessGenerator) saveDocumentation(p *pendingFile, doc string) error {
+	doc = output.TruncateAtHeading(doc, g.cfg.MaxOutputFileBytes)
+	if g.cfg.LintOutput {
+		if lintErrors := linter.ValidateFile(doc, p.outputFile); len(lintErrors) > 0 {
+			doc = linter.AppendQualityNotes(doc, lintErrors)
+			g.mu.Lock()
+			g.lintFailures++
+			g.mu.Unlock()
+		}
+	}
+
+	content := doc
+	if g.cfg.AddFrontmatter {
+		content = frontmatter.Prepend(doc, frontmatter.Meta{
+			SourceFile:    filepath.ToSlash(p.relPath),
+			GeneratedAt:   time.Now(),
+			APIProvider:   string(g.cfg.APIType),
+			Model:         g.cfg.GetActiveModel(),
+			ProjectType:   g.projectType,
+			FileSizeBytes: p.file.Size,
+		})
+	}
+	renderers, err := output.Renderers.New(g.cfg.OutputFormats)
+	if err != nil {
+		return err
+	}
+	if err := output.WriteRendered(p.outputFile, content, renderers); err != nil {
+		return err
+	}
+	if g.cfg.OutputFormat == config.OutputFormatJSON {
+		meta := jsonoutput.Metadata{
+			SourcePath:    filepath.ToSlash(p.relPath),
+			GeneratedAt:   time.Now(),
+			Model:         g.cfg.GetActiveModel(),
+			APIType:       string(g.cfg.APIType),
+			ProjectType:   g.projectType,
+			FileExtension: filehandler.GetFileExtension(p.file.Path),
+			FileSizeBytes: p.file.Size,
+		}
+		if err := jsonoutput.WriteFile(p.outputFile, doc, meta); err != nil {
+			return err
+		}
+	}
+
+	// Remember which version was documented. If this fails the file is only documented again next run.
+	if err := g.checksums.Set(p.relPath, p.file.Checksum, g.cfg.PromptVersion); err != nil {
+		g.warn(err)
+	}
+	if err := g.freshness.Set(p.file.Path, time.Now()); err != nil {
+		g.warn(err)
+	}
+	if p.promptVersionChanged {
+		g.mu.Lock()
+		g.promptVersionRedocumented++
+		g.mu.Unlock()
+	}
+	return nil
+}
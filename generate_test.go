@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/docs"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/spf13/pflag"
+)
+
+// batchMockClient documents every file of a batch with the mock client's placeholder
+type batchMockClient struct {
+	*api.MockDocumentationClient
+
+	mu      sync.Mutex
+	batches [][]string // Paths sent in each batch call
+}
+
+func (c *batchMockClient) BatchGenerate(ctx context.Context, files []filehandler.FileInfo, maxBatchTokens int) (map[string]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var paths []string
+	results := make(map[string]string)
+	for _, file := range files {
+		paths = append(paths, file.Path)
+		results[file.Path] = "# " + filepath.Base(file.Path) + "\n\nDocumented in a batch.\n"
+	}
+	c.batches = append(c.batches, paths)
+	return results, nil
+}
+
+func TestGenerateWritesProjectDocs(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rootDir := t.TempDir()
+	outputDir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":  "module example.com/app\n\ngo 1.22\n",
+		"main.go": "package main\n\nfunc main() {}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(rootDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewConfig()
+	cfg.CacheTTL = 0
+	cfg.GenerateSetupDoc = false
+	cfg.GenerateProjectOverview = true
+	cfg.GenerateReadme = true
+	g := &headlessGenerator{cfg: cfg, client: api.NewMockClient(nil), rootDir: rootDir, outputDir: outputDir}
+	if err := g.run(context.Background(), filehandler.NewFileHandler()); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	for _, name := range []string{docs.StructureFileName, docs.SetupFileName, docs.OverviewFileName, docs.ReadmeFileName} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+	for _, name := range []string{docs.ArchitectureFileName, docs.GlossaryFileName} {
+		if _, err := os.Stat(filepath.Join(outputDir, name)); err == nil {
+			t.Errorf("%s written, but it is not enabled", name)
+		}
+	}
+}
+
+func TestGenerateBatchesSmallFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rootDir := t.TempDir()
+	outputDir := t.TempDir()
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if err := os.WriteFile(filepath.Join(rootDir, name), []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.NewConfig()
+	cfg.CacheTTL = 0
+	cfg.BatchSize = 2
+	cfg.DocumentationStyle = config.StyleStructured
+	cfg.GenerateProjectOverview = false
+	client := &batchMockClient{MockDocumentationClient: api.NewMockClient(nil)}
+	fileHandler := filehandler.NewFileHandler()
+	fileHandler.SetProjectType(filehandler.ProjectTypeGo)
+	g := &headlessGenerator{cfg: cfg, client: client, projectType: string(filehandler.ProjectTypeGo), rootDir: rootDir, outputDir: outputDir}
+	if err := g.run(context.Background(), fileHandler); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	var batched []string
+	for _, batch := range client.batches {
+		if len(batch) > cfg.BatchSize {
+			t.Errorf("batch of %d files, want at most %d", len(batch), cfg.BatchSize)
+		}
+		batched = append(batched, batch...)
+	}
+	if len(batched) != 2 {
+		t.Errorf("%d files documented in batches, want 2 of 3", len(batched))
+	}
+	for _, call := range client.CallLog() {
+		if filepath.Ext(call) == ".go" {
+			batched = append(batched, call)
+		}
+	}
+	sort.Strings(batched)
+	if len(batched) != 3 {
+		t.Fatalf("documented %v, want each of the 3 files once", batched)
+	}
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		if _, err := os.Stat(filepath.Join(outputDir, config.ComputeOutputPath(cfg, rootDir, filepath.Join(rootDir, name)))); err != nil {
+			t.Errorf("documentation of %s not written: %v", name, err)
+		}
+	}
+}
+
+func TestDocumentationFlagsApplyOnlyChanged(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags := addDocumentationFlags(fs)
+	if err := fs.Parse([]string{"--batch-size=4", "--glossary"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Settings from a profile or .structura.yaml stay unless the flag is given
+	cfg := &config.Config{MaxConcurrentRequests: 8, GenerateProjectOverview: false, MinDocScore: 0.9}
+	if err := flags.apply(cfg); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if cfg.BatchSize != 4 || !cfg.GenerateGlossary {
+		t.Errorf("BatchSize = %d, GenerateGlossary = %v, want the flags' 4 and true", cfg.BatchSize, cfg.GenerateGlossary)
+	}
+	if cfg.MaxConcurrentRequests != 8 || cfg.GenerateProjectOverview || cfg.MinDocScore != 0.9 {
+		t.Errorf("MaxConcurrentRequests = %d, GenerateProjectOverview = %v, MinDocScore = %v, want the configured 8, false and 0.9",
+			cfg.MaxConcurrentRequests, cfg.GenerateProjectOverview, cfg.MinDocScore)
+	}
+}
+
+func TestGenerateSkipsUnchangedFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	rootDir := t.TempDir()
+	outputDir := t.TempDir()
+	source := filepath.Join(rootDir, "main.go")
+
+	run := func(content string) int {
+		t.Helper()
+		if err := os.WriteFile(source, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.NewConfig()
+		cfg.CacheTTL = 0
+		cfg.GenerateProjectOverview = false
+		client := api.NewMockClient(nil)
+		g := &headlessGenerator{cfg: cfg, client: client, rootDir: rootDir, outputDir: outputDir}
+		if err := g.run(context.Background(), filehandler.NewFileHandler()); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		return len(client.CallLog())
+	}
+
+	v1 := "package main\n\nfunc main() {}\n"
+	v2 := "package main\n\nfunc main() { println(\"changed\") }\n"
+	if calls := run(v1); calls != 1 {
+		t.Fatalf("first run documented %d files, want 1", calls)
+	}
+	if calls := run(v1); calls != 0 {
+		t.Errorf("run with the same version documented %d files, want it skipped", calls)
+	}
+	if calls := run(v2); calls != 1 {
+		t.Errorf("run with a changed version documented %d files, want 1", calls)
+	}
+}
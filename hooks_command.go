@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies pre-commit hooks written by structura, so others are never replaced
+const hookMarker = "# Installed by structura hooks install"
+
+// preCommitHook is the pre-commit script. %s is the audit command line, the first %d the exit
+// status audit reports stale documentation with and the second the hook's exit status then.
+// Any other audit failure is reported without blocking the commit.
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `: warns when staged files have stale documentation.
+# Remove it with structura hooks uninstall.
+
+command -v structura >/dev/null 2>&1 || exit 0
+
+stale=$(%s 2>&1)
+status=$?
+if [ $status -eq %d ]; then
+	echo "structura: the documentation of these staged files is out of date:" >&2
+	echo "$stale" | sed 's/^/  /' >&2
+	echo "structura: run structura generate to update it" >&2
+	exit %d
+elif [ $status -ne 0 ]; then
+	echo "structura: could not check the documentation:" >&2
+	echo "$stale" | sed 's/^/  /' >&2
+fi
+exit 0
+`
+
+// newHooksCommand creates the command installing and removing the git pre-commit hook
+func newHooksCommand() *cobra.Command {
+	cmd := newCommand("hooks", "install|uninstall [dir]", "Install or remove a git pre-commit hook warning about stale documentation")
+	cmd.ValidArgs = []string{"install", "uninstall"}
+	cmd.Args = cobra.RangeArgs(1, 2)
+	fs := cmd.Flags()
+	failOnStale := fs.Bool("fail-on-stale", false, "block commits of staged files with stale documentation instead of warning")
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		action := args[0]
+		args = args[1:]
+
+		hooksDir, err := filehandler.GitHooksDir(".")
+		if err != nil {
+			return err
+		}
+		hookPath := filepath.Join(hooksDir, "pre-commit")
+
+		switch action {
+		case "install":
+			return installHook(hookPath, args, *failOnStale)
+		case "uninstall":
+			return uninstallHook(hookPath)
+		}
+		return fmt.Errorf("unknown action %q: expected install or uninstall", action)
+	}
+	return cmd
+}
+
+// installHook writes the pre-commit hook to hookPath, auditing the output directory in args,
+// if any. A hook written by structura before is replaced; any other hook is left alone.
+func installHook(hookPath string, args []string, failOnStale bool) error {
+	if existing, err := os.ReadFile(hookPath); err == nil && !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("%s already exists and was not installed by structura: remove it first", hookPath)
+	}
+
+	audit := "structura audit --freshness-check --staged --quiet"
+	if len(args) > 0 {
+		audit += " '" + strings.ReplaceAll(args[0], "'", `'\''`) + "'"
+	}
+	exitStatus := 0
+	if failOnStale {
+		exitStatus = 1
+	}
+
+	if err := os.MkdirAll(filepath.Dir(hookPath), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(hookPath, []byte(fmt.Sprintf(preCommitHook, audit, staleExitStatus, exitStatus)), 0755); err != nil {
+		return err
+	}
+	fmt.Println("Installed", hookPath)
+	return nil
+}
+
+// uninstallHook removes the pre-commit hook at hookPath if structura installed it
+func uninstallHook(hookPath string) error {
+	existing, err := os.ReadFile(hookPath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Println("No pre-commit hook installed")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("%s was not installed by structura: leaving it in place", hookPath)
+	}
+
+	if err := os.Remove(hookPath); err != nil {
+		return err
+	}
+	fmt.Println("Removed", hookPath)
+	return nil
+}
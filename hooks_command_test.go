@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestPreCommitHookOnlyBlocksStaleDocumentation(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh to run the hook with")
+	}
+
+	tests := []struct {
+		name        string
+		auditStatus int
+		failOnStale bool
+		want        int
+	}{
+		{"up to date", 0, true, 0},
+		{"stale", staleExitStatus, true, 1},
+		{"stale without --fail-on-stale", staleExitStatus, false, 0},
+		{"audit failed", 1, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A fake structura on PATH stands in for the audit
+			bin := t.TempDir()
+			fake := "#!/bin/sh\necho main.go\nexit " + strconv.Itoa(tt.auditStatus) + "\n"
+			if err := os.WriteFile(filepath.Join(bin, "structura"), []byte(fake), 0755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+			hookPath := filepath.Join(t.TempDir(), "hooks", "pre-commit")
+			if err := installHook(hookPath, nil, tt.failOnStale); err != nil {
+				t.Fatal(err)
+			}
+			err := exec.Command("sh", hookPath).Run()
+			status := 0
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				status = exitErr.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.want {
+				t.Errorf("hook exited with status %d, want %d", status, tt.want)
+			}
+		})
+	}
+}
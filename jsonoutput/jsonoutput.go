@@ -0,0 +1,105 @@
+package jsonoutput
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// headingLine matches a Markdown ATX heading, capturing its level markers and text
+var headingLine = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+
+// Metadata describes the source file and the run a documentation file was generated in
+type Metadata struct {
+	SourcePath    string // Path relative to the project root
+	GeneratedAt   time.Time
+	Model         string
+	APIType       string
+	ProjectType   string
+	FileExtension string // Without the dot
+	FileSizeBytes int64
+}
+
+// Section is the text below one Markdown heading, up to the next heading
+type Section struct {
+	Heading string `json:"heading"`
+	Level   int    `json:"level"` // 1 for "#", 0 for text before the first heading
+	Body    string `json:"body"`
+}
+
+// FileDoc is the JSON document written for one source file
+type FileDoc struct {
+	SourcePath      string    `json:"source_path"`
+	GeneratedAt     time.Time `json:"generated_at"`
+	Model           string    `json:"model"`
+	APIType         string    `json:"api_type"`
+	ProjectType     string    `json:"project_type"`
+	ContentMarkdown string    `json:"content_markdown"`
+	Sections        []Section `json:"sections"`
+	FileExtension   string    `json:"file_extension"`
+	FileSizeBytes   int64     `json:"file_size_bytes"`
+}
+
+// Marshal returns the indented JSON document for the Markdown documentation doc
+func Marshal(doc string, meta Metadata) ([]byte, error) {
+	fileDoc := FileDoc{
+		SourcePath:      meta.SourcePath,
+		GeneratedAt:     meta.GeneratedAt.UTC(),
+		Model:           meta.Model,
+		APIType:         meta.APIType,
+		ProjectType:     meta.ProjectType,
+		ContentMarkdown: doc,
+		Sections:        ParseSections(doc),
+		FileExtension:   meta.FileExtension,
+		FileSizeBytes:   meta.FileSizeBytes,
+	}
+	return json.MarshalIndent(fileDoc, "", "  ")
+}
+
+// ParseSections splits Markdown into sections at its heading lines. Text before the first
+// heading becomes a section without a heading, and lines inside code fences are never headings.
+func ParseSections(markdown string) []Section {
+	sections := []Section{}
+	current := Section{}
+	var body []string
+	inFence := false
+
+	flush := func() {
+		current.Body = strings.TrimSpace(strings.Join(body, "\n"))
+		if current.Heading != "" || current.Body != "" {
+			sections = append(sections, current)
+		}
+		body = nil
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+
+		if !inFence {
+			if match := headingLine.FindStringSubmatch(trimmed); match != nil {
+				flush()
+				current = Section{Heading: match[2], Level: len(match[1])}
+				continue
+			}
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	return sections
+}
+
+// WriteFile writes the JSON document for doc next to its Markdown file, replacing the .md
+// extension with .json, e.g. main.go.md -> main.go.json
+func WriteFile(markdownFile, doc string, meta Metadata) error {
+	data, err := Marshal(doc, meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(strings.TrimSuffix(markdownFile, ".md")+".json", data, 0644)
+}
@@ -0,0 +1,162 @@
+// Package linker adds links between the generated documentation files of a project.
+package linker
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Abiggj/structura/docs"
+	"github.com/Abiggj/structura/techdebt"
+)
+
+// codeSpan matches a backtick-quoted span on a single line, with the character before it
+// and the opening bracket of a link target after it, if any
+var codeSpan = regexp.MustCompile("(^|.)`([^`\n]+)`(\\]\\()?")
+
+// AddCrossReferences links every file's documentation in outputDir to the documentation
+// of the files it mentions. A backtick-quoted name such as `client.go` or `api/client.go`
+// that is the base name or path of another documented file becomes a relative link to its
+// documentation. Only the first mention of each file is linked, mentions that are already
+// links are left alone, and code blocks are not changed. Base names shared by several
+// files are ambiguous and only their paths are linked.
+func AddCrossReferences(outputDir string) error {
+	targets, err := documentedFiles(outputDir)
+	if err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".md") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(outputDir, p)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		linked := link(string(content), filepath.ToSlash(relPath), targets)
+		if linked == string(content) {
+			return nil
+		}
+		if err := os.WriteFile(p, []byte(linked), 0644); err != nil {
+			return fmt.Errorf("error writing cross references to %s: %w", p, err)
+		}
+		return nil
+	})
+}
+
+// documentedFiles maps the base names and paths of the documented source files in outputDir
+// to the slash-separated paths of their documentation, relative to outputDir
+func documentedFiles(outputDir string) (map[string]string, error) {
+	targets := make(map[string]string)
+	bases := make(map[string][]string) // Documentation paths by source base name
+
+	err := filepath.WalkDir(outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".md") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(outputDir, p)
+		if err != nil {
+			return err
+		}
+		docPath := filepath.ToSlash(relPath)
+		if !isFileDoc(docPath) {
+			return nil
+		}
+
+		source := strings.TrimSuffix(docPath, ".md")
+		targets[source] = docPath
+		bases[path.Base(source)] = append(bases[path.Base(source)], docPath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing documentation in %s: %w", outputDir, err)
+	}
+
+	for base, docPaths := range bases {
+		if _, ok := targets[base]; !ok && len(docPaths) == 1 {
+			targets[base] = docPaths[0]
+		}
+	}
+	return targets, nil
+}
+
+// isFileDoc reports whether docPath, relative to the output directory, is the documentation
+// of a source file rather than a project-level document or directory summary
+func isFileDoc(docPath string) bool {
+	switch docPath {
+	case docs.ReadmeFileName, docs.StructureFileName, docs.OverviewFileName, docs.ArchitectureFileName, docs.SetupFileName, docs.IndexFileName, docs.MkdocsHomePage, techdebt.FileName:
+		return false
+	}
+	return path.Base(docPath) != docs.SummaryFileName
+}
+
+// link replaces the first unlinked mention of each target in content, the documentation at
+// docPath, with a link relative to docPath
+func link(content, docPath string, targets map[string]string) string {
+	linked := make(map[string]bool) // Documentation already linked to, by path
+	linked[docPath] = true          // A file never links to itself
+
+	// Mentions that are already links count as the first mention
+	for _, match := range codeSpan.FindAllStringSubmatch(content, -1) {
+		if target, ok := targets[match[2]]; ok && match[1] == "[" && match[3] != "" {
+			linked[target] = true
+		}
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		lines[i] = codeSpan.ReplaceAllStringFunc(line, func(span string) string {
+			match := codeSpan.FindStringSubmatch(span)
+			target, ok := targets[match[2]]
+			if !ok || linked[target] || match[1] == "[" || match[3] != "" {
+				return span
+			}
+			linked[target] = true
+			return fmt.Sprintf("%s[`%s`](%s)", match[1], match[2], relativeLink(docPath, target))
+		})
+	}
+	return strings.Join(lines, "")
+}
+
+// relativeLink returns the escaped link from the documentation at docPath to target, both
+// slash-separated paths relative to the output directory
+func relativeLink(docPath, target string) string {
+	rel, err := filepath.Rel(path.Dir(docPath), target)
+	if err != nil {
+		rel = target
+	}
+
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
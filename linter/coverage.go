@@ -0,0 +1,80 @@
+package linter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// FileScore is the documentation score of a source file
+type FileScore struct {
+	Path  string // Slash-separated path relative to the project root
+	Score float64
+}
+
+// ScoreFiles scores the documentation of every file in files that has been documented.
+// docPath returns the path of a file's documentation relative to outputDir, given the file's
+// path relative to rootDir.
+func ScoreFiles(files []filehandler.FileInfo, rootDir, outputDir string, docPath func(relPath string) string) []FileScore {
+	var scores []FileScore
+	for _, file := range files {
+		if file.IsDir {
+			continue
+		}
+		relPath, err := filepath.Rel(rootDir, file.Path)
+		if err != nil {
+			continue
+		}
+		doc, err := os.ReadFile(filepath.Join(outputDir, docPath(relPath)))
+		if err != nil {
+			continue
+		}
+		scores = append(scores, FileScore{Path: filepath.ToSlash(relPath), Score: Score(string(doc), file)})
+	}
+	return scores
+}
+
+// BelowMinimum returns the scores lower than minScore, which should be reviewed
+func BelowMinimum(scores []FileScore, minScore float64) []FileScore {
+	var flagged []FileScore
+	for _, score := range scores {
+		if score.Score < minScore {
+			flagged = append(flagged, score)
+		}
+	}
+	return flagged
+}
+
+// ScoreReport renders the documentation scores section of the coverage report, listing every
+// file's score from the lowest to the highest and flagging those below minScore for review
+func ScoreReport(scores []FileScore, minScore float64) string {
+	sorted := append([]FileScore(nil), scores...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Score != sorted[j].Score {
+			return sorted[i].Score < sorted[j].Score
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	var sb strings.Builder
+	sb.WriteString("## Documentation Scores\n\n")
+	sb.WriteString(fmt.Sprintf("Scores from 0 to 1 for the documentation of %d files: 0.2 for a title and summary, "+
+		"0.4 for mentioning every function, 0.2 for a code example and 0.2 for no placeholder text. ", len(sorted)))
+	sb.WriteString(fmt.Sprintf("%d files score below %.2f and should be reviewed.\n\n", len(BelowMinimum(sorted, minScore)), minScore))
+
+	if len(sorted) > 0 {
+		sb.WriteString("| File | Score | Status |\n|------|-------|--------|\n")
+		for _, score := range sorted {
+			status := "OK"
+			if score.Score < minScore {
+				status = "⚠️ Review"
+			}
+			sb.WriteString(fmt.Sprintf("| `%s` | %.2f | %s |\n", strings.ReplaceAll(score.Path, "|", "\\|"), score.Score, status))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+// Package linter checks the quality of generated documentation
+package linter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/frontmatter"
+)
+
+// Weights of the checks making up a documentation score, adding up to 1
+const (
+	summaryWeight     = 0.2 // A top-level heading followed by a summary
+	functionsWeight   = 0.4 // Share of the source file's functions the documentation mentions
+	exampleWeight     = 0.2 // A code block
+	placeholderWeight = 0.2 // No placeholder text left in
+)
+
+// placeholderText matches text a model leaves in when it did not finish the documentation
+var placeholderText = regexp.MustCompile(`\b(?:TODO|TBD|FIXME)\b|\[\.\.\.\]|\[…\]|(?i:lorem ipsum|insert (?:description|details) here)`)
+
+// functionPatterns find the function and method names defined in source files, by language.
+// The first submatch of each pattern is the name.
+var functionPatterns = map[string][]*regexp.Regexp{
+	"Go":         {regexp.MustCompile(`(?m)^func\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w*)`)},
+	"Python":     {regexp.MustCompile(`(?m)^\s*(?:async\s+)?def\s+([A-Za-z_]\w*)`)},
+	"JavaScript": jsFunctionPatterns,
+	"TypeScript": jsFunctionPatterns,
+	"Vue":        jsFunctionPatterns,
+	"Svelte":     jsFunctionPatterns,
+	"Java":       {regexp.MustCompile(`(?m)^\s*(?:(?:public|private|protected|static|final|abstract|synchronized|native|default)\s+)+[\w<>\[\],.? ]+?\s+([A-Za-z_]\w*)\s*\(`)},
+	"C#":         {regexp.MustCompile(`(?m)^\s*(?:(?:public|private|protected|internal|static|virtual|override|abstract|async|sealed)\s+)+[\w<>\[\],.? ]+?\s+([A-Za-z_]\w*)\s*\(`)},
+	"Kotlin":     {regexp.MustCompile(`\bfun\s+(?:<[^>]*>\s*)?(?:[\w.]+\.)?([A-Za-z_]\w*)\s*\(`)},
+	"Scala":      {regexp.MustCompile(`\bdef\s+([A-Za-z_]\w*)`)},
+	"Swift":      {regexp.MustCompile(`\bfunc\s+([A-Za-z_]\w*)`)},
+	"Rust":       {regexp.MustCompile(`\bfn\s+([A-Za-z_]\w*)`)},
+	"Ruby":       {regexp.MustCompile(`(?m)^\s*def\s+(?:self\.)?([A-Za-z_]\w*[?!]?)`)},
+	"PHP":        {regexp.MustCompile(`\bfunction\s+&?([A-Za-z_]\w*)`)},
+	"Elixir":     {regexp.MustCompile(`(?m)^\s*defp?\s+([a-z_]\w*[?!]?)`)},
+	"Dart":       {regexp.MustCompile(`(?m)^\s*(?:static\s+)?(?:Future<[^>]*>|void|[A-Z]\w*(?:<[^>]*>)?|int|double|bool|String)\s+([a-z_]\w*)\s*\(`)},
+}
+
+// jsFunctionPatterns find function declarations and arrow functions assigned to constants
+var jsFunctionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bfunction\s*\*?\s+([A-Za-z_$][\w$]*)`),
+	regexp.MustCompile(`(?m)^\s*(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:async\s*)?(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*=>`),
+}
+
+// Score rates the documentation content generated for file from 0 to 1: 0.2 for a top-level
+// heading followed by a summary, 0.4 times the share of the file's functions the documentation
+// mentions, 0.2 for a code example and 0.2 for having no placeholder text such as TODO or [...].
+// Files in languages whose functions are not recognised, or without functions, get the full
+// function share.
+func Score(content string, file filehandler.FileInfo) float64 {
+	content = frontmatter.Strip(content)
+
+	score := 0.0
+	if hasSummary(content) {
+		score += summaryWeight
+	}
+	score += functionsWeight * documentedShare(content, file)
+	if strings.Contains(content, "```") || strings.Contains(content, "~~~") {
+		score += exampleWeight
+	}
+	if !placeholderText.MatchString(content) {
+		score += placeholderWeight
+	}
+	return score
+}
+
+// hasSummary reports whether content has a top-level heading whose first following text,
+// outside code blocks, is a paragraph rather than another heading or a code block
+func hasSummary(content string) bool {
+	afterTitle := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case strings.HasPrefix(trimmed, "# "):
+			afterTitle = true
+		case afterTitle:
+			return !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "```")
+		}
+	}
+	return false
+}
+
+// documentedShare returns the share of the functions defined in file whose names occur in
+// content, or 1 if none are found
+func documentedShare(content string, file filehandler.FileInfo) float64 {
+	names := functionNames(file)
+	if len(names) == 0 {
+		return 1
+	}
+
+	documented := 0
+	for _, name := range names {
+		if containsWord(content, name) {
+			documented++
+		}
+	}
+	return float64(documented) / float64(len(names))
+}
+
+// functionNames returns the distinct names of the functions and methods defined in file
+func functionNames(file filehandler.FileInfo) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, pattern := range functionPatterns[file.Language] {
+		for _, match := range pattern.FindAllStringSubmatch(file.Content, -1) {
+			name := match[1]
+			// Constructors and operators such as __init__ are rarely documented by name
+			if seen[name] || strings.HasPrefix(name, "__") {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// containsWord reports whether word occurs in text, not as part of a longer identifier
+func containsWord(text, word string) bool {
+	for start := 0; ; {
+		i := strings.Index(text[start:], word)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(word)
+		if (i == 0 || !isIdentByte(text[i-1])) && (end == len(text) || !isIdentByte(text[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+// isIdentByte reports whether b can be part of an identifier
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
@@ -0,0 +1,198 @@
+package linter
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// qualityNotePrefix starts the notes appended to documentation that fails validation
+const qualityNotePrefix = "> ⚠️ Documentation quality note: "
+
+// LintError is a problem found in generated Markdown
+type LintError struct {
+	Line    int // 1-based line of the problem, or 0 if it concerns the whole document
+	Message string
+}
+
+func (e LintError) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Patterns for Validate
+var (
+	rawHTMLTag   = regexp.MustCompile(`(?i)<\s*(script|style)\b`)
+	inlineCode   = regexp.MustCompile("`+[^`]*`+")
+	markdownLink = regexp.MustCompile(`\[[^\]]*\]\(\s*<?([^)\s>]*)>?(?:\s+"[^"]*")?\s*\)`)
+	urlScheme    = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*:`)
+)
+
+// proseLine is a line of Markdown outside fenced code blocks
+type proseLine struct {
+	number int
+	raw    string
+	text   string // raw without inline code
+}
+
+// proseLines returns the lines of content outside fenced code blocks. If a code block is never
+// closed, it also returns the fence that opened it and its line.
+func proseLines(content string) (lines []proseLine, openFence string, openLine int) {
+	fence, fenceLine := "", 0
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if closesFence(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			fence, fenceLine = marker, i+1
+			continue
+		}
+		lines = append(lines, proseLine{number: i + 1, raw: line, text: inlineCode.ReplaceAllString(line, "")})
+	}
+	return lines, fence, fenceLine
+}
+
+// Validate checks generated Markdown for output that renders badly: fenced code blocks that
+// are never closed, raw <script> or <style> tags, more than one top-level heading, and links
+// to anchors within the document that match no heading. Code blocks and inline code are not
+// checked for tags, headings or links.
+func Validate(content string) []LintError {
+	lines, openFence, openLine := proseLines(content)
+
+	var errs []LintError
+	anchors := make(map[string]bool)
+	type anchorLink struct {
+		line   int
+		anchor string
+	}
+	var anchorLinks []anchorLink // Checked once all headings are known
+	firstH1 := 0
+	for _, line := range lines {
+		if match := rawHTMLTag.FindStringSubmatch(line.text); match != nil {
+			errs = append(errs, LintError{Line: line.number, Message: fmt.Sprintf("raw <%s> tag", strings.ToLower(match[1]))})
+		}
+
+		if level, heading := atxHeading(strings.TrimSpace(line.raw)); level > 0 {
+			anchors[anchorKey(heading)] = true
+			if level == 1 {
+				if firstH1 == 0 {
+					firstH1 = line.number
+				} else {
+					errs = append(errs, LintError{Line: line.number, Message: fmt.Sprintf("another top-level heading %q after the one on line %d", heading, firstH1)})
+				}
+			}
+			continue
+		}
+
+		for _, match := range markdownLink.FindAllStringSubmatch(line.text, -1) {
+			switch target := match[1]; {
+			case target == "":
+				errs = append(errs, LintError{Line: line.number, Message: fmt.Sprintf("link %s has no target", match[0])})
+			case strings.HasPrefix(target, "#"):
+				anchorLinks = append(anchorLinks, anchorLink{line: line.number, anchor: target})
+			}
+		}
+	}
+
+	for _, link := range anchorLinks {
+		if !anchors[anchorKey(link.anchor)] {
+			errs = append(errs, LintError{Line: link.line, Message: fmt.Sprintf("link to %s matches no heading", link.anchor)})
+		}
+	}
+	if openFence != "" {
+		errs = append(errs, LintError{Line: openLine, Message: fmt.Sprintf("code block opened with %s is never closed", openFence)})
+	}
+	return errs
+}
+
+// ValidateFile validates content with Validate and also reports relative links to files that
+// do not exist, resolved against the directory of path, where the content is written
+func ValidateFile(content, path string) []LintError {
+	errs := Validate(content)
+
+	lines, _, _ := proseLines(content)
+	for _, line := range lines {
+		for _, match := range markdownLink.FindAllStringSubmatch(line.text, -1) {
+			target := match[1]
+			if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") || urlScheme.MatchString(target) {
+				continue
+			}
+			if cut := strings.IndexAny(target, "#?"); cut >= 0 {
+				target = target[:cut]
+			}
+			if unescaped, err := url.PathUnescape(target); err == nil {
+				target = unescaped
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(path), filepath.FromSlash(target))); err != nil {
+				errs = append(errs, LintError{Line: line.number, Message: fmt.Sprintf("link to %s, which does not exist", match[1])})
+			}
+		}
+	}
+	return errs
+}
+
+// AppendQualityNotes appends a quality note quoting each error to the end of content
+func AppendQualityNotes(content string, errs []LintError) string {
+	if len(errs) == 0 {
+		return content
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimRight(content, "\n"))
+	sb.WriteString("\n\n")
+	for _, err := range errs {
+		sb.WriteString(qualityNotePrefix + err.Error() + "\n")
+	}
+	return sb.String()
+}
+
+// fenceMarker returns the backticks or tildes opening a fenced code block on line, or "" if
+// line does not open one
+func fenceMarker(line string) string {
+	for _, char := range []string{"`", "~"} {
+		if strings.HasPrefix(line, strings.Repeat(char, 3)) {
+			return line[:len(line)-len(strings.TrimLeft(line, char))]
+		}
+	}
+	return ""
+}
+
+// closesFence reports whether line closes the code block opened with fence: a run of at least
+// as many of the same characters, with nothing after it
+func closesFence(line, fence string) bool {
+	marker := fenceMarker(line)
+	return marker != "" && marker[0] == fence[0] && len(marker) >= len(fence) && marker == line
+}
+
+// atxHeading returns the level and text of an ATX heading line, or level 0 if line is not one
+func atxHeading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+}
+
+// anchorKey reduces a heading, or an anchor without its #, to its lower-case letters and
+// digits, so that anchors match headings whichever renderer generated the ids
+func anchorKey(text string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(strings.TrimPrefix(text, "#")) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
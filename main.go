@@ -1,23 +1,296 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
 
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/output"
 	"github.com/Abiggj/structura/tui"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
 )
 
 func main() {
-	// Create a new model
-	m := tui.NewModel()
+	err := execute(os.Args[1:])
+	if err != nil && err != errUsage && err != errStale && err != errChanged {
+		fmt.Println("Error:", err)
+	}
+	os.Exit(exitCode(err))
+}
+
+// exitCode returns the exit status for the error a command returned: 2 for invalid usage,
+// 3 when the token budget ran out, staleExitStatus for stale documentation and 1 for any other
+// error. errUsage, errStale and errChanged are returned after the problem was already printed.
+func exitCode(err error) int {
+	switch err {
+	case nil:
+		return 0
+	case errUsage:
+		return 2
+	case errBudgetExceeded:
+		return 3
+	case errStale:
+		return staleExitStatus
+	default:
+		return 1
+	}
+}
+
+// outputFormatUsage describes the --output-format flag shared by run and generate
+const outputFormatUsage = "documentation format: markdown; html for HTML pages with a navigation sidebar, or json for a JSON file per source file, alongside the Markdown"
+
+// frontmatterUsage describes the --frontmatter flag shared by run and generate
+const frontmatterUsage = "start every file's documentation with YAML frontmatter for static site generators such as Hugo and Jekyll"
+
+// crossReferencesUsage describes the --cross-references flag shared by run and generate
+const crossReferencesUsage = "link backtick-quoted names of other documented files, such as client.go, to their documentation"
+
+// cacheTTLUsage describes the --cache-ttl flag shared by run and generate
+const cacheTTLUsage = "how long API responses for unchanged files are cached in the user cache directory, e.g. 24h (0 disables the cache)"
 
-	// Initialize the program
-	p := tea.NewProgram(m, tea.WithAltScreen())
+// symbolLinksUsage describes the --symbol-links flag shared by run and generate
+const symbolLinksUsage = "link mentions of functions and types to the documentation of the file defining them"
+
+// excludePathUsage describes the --exclude-path flag shared by run and generate
+const excludePathUsage = "file or directory left out of the documentation, absolute or relative to [dir], e.g. internal/generated (repeatable)"
+
+// webhookURLUsage describes the --webhook-url flag shared by run and generate
+const webhookURLUsage = "URL receiving a JSON POST with the results when processing completes, signed with $STRUCTURA_WEBHOOK_SECRET if it is set"
+
+// Usage of the output bucket flags shared by run and generate
+const (
+	outputBucketUsage         = "S3 bucket the output directory is uploaded to after each run, using the AWS credentials of --aws-profile or the environment"
+	outputBucketPrefixUsage   = "key prefix the documentation is uploaded under in --output-bucket, e.g. docs/main"
+	outputBucketEndpointUsage = "endpoint of an S3-compatible service holding --output-bucket, such as MinIO or https://storage.googleapis.com (default: AWS S3)"
+)
+
+// Usage of the response size flags shared by run and generate
+const (
+	maxOutputBytesUsage = "truncate longer documentation at the last heading before this many bytes (0 = unlimited)"
+	minOutputBytesUsage = "treat documentation shorter than this many bytes as a failed request and retry it (0 = no minimum)"
+)
 
-	// Start the program
-	if _, err := p.Run(); err != nil {
-		fmt.Println("Error running program:", err)
-		os.Exit(1)
+// minDocScoreUsage describes the --min-doc-score flag shared by run and generate
+const minDocScoreUsage = "flag documentation scoring below this, from 0 to 1, for review in COVERAGE.md"
+
+// styleUsage describes the --style flag shared by run and generate
+const styleUsage = "documentation style: structured (sections and lists), jsdoc (/** */ comments with @param and @returns), terse (one sentence per function) or narrative (prose without lists) (default structured, or documentation_style in .structura.yaml)"
+
+// formatUsage describes the --format flag shared by run and generate
+const formatUsage = "comma-separated formats each file's documentation is written in side by side, from one API response: markdown, html (Markdown is always written)"
+
+// outputFormats returns the formats listed in the --format flag, checking that each is registered
+func outputFormats(value string) ([]string, error) {
+	var formats []string
+	for _, format := range strings.Split(value, ",") {
+		if format = strings.TrimSpace(format); format != "" {
+			formats = append(formats, format)
+		}
 	}
-}
\ No newline at end of file
+	if _, err := output.Renderers.New(formats); err != nil {
+		return nil, err
+	}
+	return formats, nil
+}
+
+// techDebtUsage describes the --tech-debt flag shared by run and generate
+const techDebtUsage = "list TODO, FIXME, HACK, XXX and NOSONAR comments of the source files in TECH_DEBT.md"
+
+// mkdocsUsage describes the --mkdocs flag shared by run and generate
+const mkdocsUsage = "write mkdocs.yml to the output directory, with a nav of every documentation file, for building a site with MkDocs and the Material theme"
+
+// tokenBudgetUsage describes the --token-budget flag shared by run and generate
+const tokenBudgetUsage = "stop once a run has used about this many prompt and response tokens, exiting with status 3 (0 = unlimited)"
+
+// redocumentPromptVersionUsage describes the --re-document-prompt-version flag shared by run and generate
+const redocumentPromptVersionUsage = "document again every file documented with this prompt version, e.g. v1, even if its source is unchanged"
+
+// errBudgetExceeded is returned by run and generate when the run stopped at its token budget.
+// Files documented before the budget ran out are skipped when the next run resumes.
+var errBudgetExceeded = errors.New("token budget exceeded; run again to document the remaining files")
+
+// lintOutputUsage describes the --lint-output flag shared by run and generate
+const lintOutputUsage = "check generated Markdown for unclosed code blocks, <script> and <style> tags, several top-level headings and broken relative links, appending a quality note for each problem"
+
+// Usage of the output file naming flags shared by run and generate
+const (
+	namingUsage         = "how documentation files are named: mirrored (src/parser.go.md), flat (src_parser.go.md), strip-ext (src/parser.md) or template (default mirrored, or template with --output-template)"
+	outputTemplateUsage = "Go template of documentation paths in the output directory for --naming=template, with .RelPath, .Dir, .Base and .Ext, e.g. {{.Dir}}/{{.Base}}.mdx"
+)
+
+// outputNaming returns the naming strategy selected by the --naming and --output-template flags.
+// A template without a naming strategy selects the template strategy.
+func outputNaming(naming, outputTemplate string) (config.OutputFileNaming, error) {
+	if naming == "" {
+		if outputTemplate == "" {
+			return config.NamingMirrored, nil
+		}
+		naming = string(config.NamingCustomTemplate)
+	}
+
+	parsed, err := config.ParseOutputFileNaming(naming)
+	if err != nil {
+		return "", err
+	}
+	if parsed == config.NamingCustomTemplate {
+		if err := config.ValidateOutputFileTemplate(outputTemplate); err != nil {
+			return "", err
+		}
+	} else if outputTemplate != "" {
+		return "", fmt.Errorf("--output-template requires --naming=template")
+	}
+	return parsed, nil
+}
+
+// newRunCommand creates the default command, which launches the TUI
+func newRunCommand() *cobra.Command {
+	cmd := newCommand("run", "[dir]", "Launch the interactive TUI (default)")
+	cmd.Args = cobra.MaximumNArgs(1)
+	fs := cmd.Flags()
+	flags := addDocumentationFlags(fs)
+	useKeyring := fs.Bool("keyring", true, "load API keys from and save them to the OS keychain")
+	noPreview := fs.Bool("no-preview", false, "do not show the source of the last documented file beside the progress on wide terminals")
+	verbose := fs.Bool("verbose", false, "list the last files finished with their timings while processing (toggle with v)")
+	logFile := fs.String("log-file", "", "append each finished file with its timing or error to this file")
+	notify := fs.Bool("notify", false, "show a desktop notification when processing completes")
+	saveProfile := fs.String("save-profile", "", "save the configuration chosen in this run as the named profile")
+	listProfiles := fs.Bool("list-profiles", false, "print the names of the saved profiles and exit")
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		config.KeyringEnabled = *useKeyring && !*flags.noKeyring
+		if err := flags.check(); err != nil {
+			return err
+		}
+
+		if *flags.dryRun {
+			rootDir := "."
+			if len(args) > 0 {
+				rootDir = args[0]
+			}
+			return runDryRun(rootDir, flags)
+		}
+
+		if *listProfiles {
+			profiles, err := config.LoadProfiles()
+			if err != nil {
+				return err
+			}
+			for _, name := range profiles.Names() {
+				fmt.Println(name)
+			}
+			return nil
+		}
+
+		// Create a new model. Flags only override a profile's settings when given explicitly.
+		m := tui.NewModel()
+		if len(args) > 0 {
+			if err := m.SetInputDir(args[0]); err != nil {
+				return err
+			}
+		}
+		if *flags.profile != "" {
+			if err := m.UseProfile(*flags.profile); err != nil {
+				return err
+			}
+		}
+		if err := flags.apply(m.Config()); err != nil {
+			return err
+		}
+		if err := flags.applyTraversal(m.FileHandler()); err != nil {
+			return err
+		}
+		if fs.Changed("notify") {
+			m.Config().DesktopNotification = *notify
+		}
+		if fs.Changed("no-preview") {
+			m.Config().FilePreview = !*noPreview
+		}
+		if fs.Changed("verbose") {
+			m.Config().VerboseProgress = *verbose
+		}
+		if fs.Changed("log-file") {
+			m.Config().LogFile = *logFile
+		}
+
+		// Initialize the program
+		p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+
+		// Shut down gracefully on SIGINT/SIGTERM so in-flight API calls are cancelled
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			for range signals {
+				p.Send(tui.ShutdownMsg{})
+			}
+		}()
+
+		// Start the program
+		finalModel, err := p.Run()
+		if flushErr := m.Config().Usage.Flush(); flushErr != nil {
+			fmt.Println("Warning: failed to save API usage:", flushErr)
+		}
+		if final, ok := finalModel.(tui.Model); ok {
+			if flushErr := final.FlushProgress(); flushErr != nil {
+				fmt.Println("Warning:", flushErr)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("error running program: %w", err)
+		}
+
+		if *saveProfile != "" {
+			if err := finalModel.(tui.Model).SaveProfile(*saveProfile); err != nil {
+				return fmt.Errorf("error saving profile: %w", err)
+			}
+			fmt.Printf("Saved profile %q\n", *saveProfile)
+		}
+		if finalModel.(tui.Model).BudgetExceeded() {
+			if resume := finalModel.(tui.Model).ResumeCommand(); resume != "" {
+				fmt.Println("Settings saved. To resume, run", resume)
+			}
+			return errBudgetExceeded
+		}
+		return nil
+	}
+	return cmd
+}
+
+// runDryRun prints a table of the files in rootDir that would be documented and their estimated cost
+func runDryRun(rootDir string, flags *documentationFlags) error {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.NewConfig()
+	projectConfig, err := config.LoadProjectConfig(rootDir)
+	if err != nil {
+		return err
+	}
+	config.MergeProjectConfig(cfg, projectConfig)
+
+	fileHandler := filehandler.NewFileHandler()
+	fileHandler.SetProjectType(filehandler.DetectProjectType(rootDir))
+	if err := flags.applyTraversal(fileHandler); err != nil {
+		return err
+	}
+	cfg.FileHandler = fileHandler
+
+	files, err := fileHandler.TraverseDirectoryConcurrent(rootDir)
+	if err != nil {
+		return fmt.Errorf("failed to traverse directory: %w", err)
+	}
+
+	results := api.DryRun(context.Background(), cfg, files)
+	fmt.Print(api.FormatDryRunTable(rootDir, results))
+	return nil
+}
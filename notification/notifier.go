@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Run statuses reported to notifiers
+const (
+	StatusSuccess = "success" // Every file was documented
+	StatusPartial = "partial" // Some files failed
+	StatusFailed  = "failed"  // No file was documented
+)
+
+// File statuses reported to notifiers
+const (
+	FileDocumented = "documented"
+	FileFailed     = "failed"
+)
+
+// Notifier is told when a documentation run completes
+type Notifier interface {
+	Notify(ctx context.Context, result RunResult) error
+}
+
+// RunResult describes a completed documentation run
+type RunResult struct {
+	Status         string
+	ProcessedCount int // Files documented
+	ErrorCount     int // Files that failed
+	OutputDir      string
+	Duration       time.Duration
+	GeneratedAt    time.Time
+	Files          []FileResult
+}
+
+// FileResult is the outcome of documenting one file
+type FileResult struct {
+	Path   string
+	Status string
+}
+
+// RunStatus returns the status of a run with the given numbers of documented and failed files
+func RunStatus(processed, failed int) string {
+	switch {
+	case failed == 0:
+		return StatusSuccess
+	case processed == 0:
+		return StatusFailed
+	}
+	return StatusPartial
+}
+
+// DesktopNotifier shows a desktop notification when a run completes
+type DesktopNotifier struct {
+	Title string
+}
+
+// Notify shows the number of documented and failed files in a desktop notification
+func (n DesktopNotifier) Notify(ctx context.Context, result RunResult) error {
+	body := fmt.Sprintf("Documented %d files in %s", result.ProcessedCount, result.OutputDir)
+	if result.ErrorCount > 0 {
+		body += fmt.Sprintf(" (%d failed)", result.ErrorCount)
+	}
+	return SendDesktop(n.Title, body)
+}
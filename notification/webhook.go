@@ -0,0 +1,109 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the request body, as "sha256=<hex>"
+const WebhookSignatureHeader = "X-Structura-Signature-256"
+
+// webhookTimeout bounds a webhook request, so a slow receiver cannot hold up the program
+const webhookTimeout = 10 * time.Second
+
+// WebhookNotifier posts the result of a run as JSON to a URL. When Secret is set, the body
+// is signed with it in the X-Structura-Signature-256 header, like GitHub's webhooks, so the
+// receiver can check the request came from this program.
+type WebhookNotifier struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// webhookPayload is the JSON body of a webhook request
+type webhookPayload struct {
+	Status          string        `json:"status"`
+	ProcessedCount  int           `json:"processed_count"`
+	ErrorCount      int           `json:"error_count"`
+	OutputDir       string        `json:"output_dir"`
+	DurationSeconds float64       `json:"duration_seconds"`
+	GeneratedAt     time.Time     `json:"generated_at"`
+	Files           []webhookFile `json:"files"`
+}
+
+// webhookFile is a file listed in a webhook payload
+type webhookFile struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// NewWebhookNotifier creates a notifier posting to url, signing requests with secret if it is not empty
+func NewWebhookNotifier(url, secret string) *WebhookNotifier {
+	return &WebhookNotifier{
+		URL:    url,
+		Secret: secret,
+		Client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Notify posts result to the webhook URL. Any response other than 2xx is an error.
+func (n *WebhookNotifier) Notify(ctx context.Context, result RunResult) error {
+	payload := webhookPayload{
+		Status:          result.Status,
+		ProcessedCount:  result.ProcessedCount,
+		ErrorCount:      result.ErrorCount,
+		OutputDir:       result.OutputDir,
+		DurationSeconds: result.Duration.Seconds(),
+		GeneratedAt:     result.GeneratedAt.UTC(),
+		Files:           make([]webhookFile, len(result.Files)),
+	}
+	for i, file := range result.Files {
+		payload.Files[i] = webhookFile{Path: file.Path, Status: file.Status}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "structura")
+	if n.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(n.Secret, body))
+	}
+
+	client := n.Client
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the X-Structura-Signature-256 header value of body signed with secret
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
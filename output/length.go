@@ -0,0 +1,23 @@
+package output
+
+import "fmt"
+
+// ShortResponseError reports documentation shorter than the configured minimum, which
+// usually means the API returned an empty or trivial response worth retrying
+type ShortResponseError struct {
+	Size     int
+	MinBytes int64
+}
+
+func (e *ShortResponseError) Error() string {
+	return fmt.Sprintf("documentation is only %d bytes, below the minimum of %d", e.Size, e.MinBytes)
+}
+
+// CheckMinLength returns a *ShortResponseError if doc is shorter than minBytes.
+// minBytes <= 0 disables the check.
+func CheckMinLength(doc string, minBytes int64) error {
+	if minBytes > 0 && int64(len(doc)) < minBytes {
+		return &ShortResponseError{Size: len(doc), MinBytes: minBytes}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RendererFactory creates a Renderer
+type RendererFactory func() Renderer
+
+// RendererRegistry maps output format names to the factories of their renderers
+type RendererRegistry map[string]RendererFactory
+
+// Renderers is the registry the output formats add themselves to when their package is loaded
+var Renderers = RendererRegistry{}
+
+// Register adds the renderer of the named format. It panics if the name is already taken,
+// since two packages registering the same format is a programming error.
+func (r RendererRegistry) Register(name string, factory RendererFactory) {
+	if _, ok := r[name]; ok {
+		panic(fmt.Sprintf("output format %s registered twice", name))
+	}
+	r[name] = factory
+}
+
+// Names returns the names of the registered formats, sorted
+func (r RendererRegistry) Names() []string {
+	names := make([]string, 0, len(r))
+	for name := range r {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the renderers of the named formats, in order. Markdown is added first if it is
+// not named, since the index, search and reports are built from the Markdown files.
+func (r RendererRegistry) New(names []string) ([]Renderer, error) {
+	renderers := []Renderer{MarkdownRenderer{}}
+	seen := map[string]bool{MarkdownFormat: true}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		factory, ok := r[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q: expected %s", name, strings.Join(r.Names(), ", "))
+		}
+		renderers = append(renderers, factory())
+	}
+	return renderers, nil
+}
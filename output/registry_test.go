@@ -0,0 +1,105 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// upperRenderer is a test format writing the documentation in upper case
+type upperRenderer struct{}
+
+func (upperRenderer) Extension() string { return ".txt" }
+
+func (upperRenderer) Render(content string) ([]byte, error) {
+	return []byte(strings.ToUpper(content)), nil
+}
+
+func newTestRegistry() RendererRegistry {
+	registry := RendererRegistry{}
+	registry.Register(MarkdownFormat, func() Renderer { return MarkdownRenderer{} })
+	registry.Register("upper", func() Renderer { return upperRenderer{} })
+	return registry
+}
+
+func TestRendererRegistryNew(t *testing.T) {
+	registry := newTestRegistry()
+
+	tests := []struct {
+		names []string
+		want  []Renderer
+	}{
+		{nil, []Renderer{MarkdownRenderer{}}},
+		{[]string{"upper"}, []Renderer{MarkdownRenderer{}, upperRenderer{}}},
+		{[]string{"upper", MarkdownFormat, "upper"}, []Renderer{MarkdownRenderer{}, upperRenderer{}}},
+	}
+	for _, tt := range tests {
+		got, err := registry.New(tt.names)
+		if err != nil {
+			t.Fatalf("New(%v) error = %v", tt.names, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("New(%v) = %#v, want %#v", tt.names, got, tt.want)
+		}
+	}
+
+	if _, err := registry.New([]string{"pdf"}); err == nil || !strings.Contains(err.Error(), "markdown, upper") {
+		t.Errorf("New([pdf]) error = %v, want one listing the registered formats", err)
+	}
+}
+
+func TestRendererRegistryRegisterTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a format twice did not panic")
+		}
+	}()
+	newTestRegistry().Register("upper", func() Renderer { return upperRenderer{} })
+}
+
+func TestWriteRenderedFromOneResponse(t *testing.T) {
+	renderers, err := newTestRegistry().New([]string{"upper"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	response := "# parser.go\n\nParses input.\n"
+	markdownPath := filepath.Join(dir, "parser.go.md")
+	if err := WriteRendered(markdownPath, response, renderers); err != nil {
+		t.Fatalf("WriteRendered() error = %v", err)
+	}
+
+	want := map[string]string{
+		"parser.go.md":  response,
+		"parser.go.txt": strings.ToUpper(response),
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("wrote %d files, want %d", len(entries), len(want))
+	}
+	for name, content := range want {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Errorf("%s not written: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+}
+
+func TestRenderedPath(t *testing.T) {
+	if got := RenderedPath(filepath.Join("docs", "App.tsx.mdx"), upperRenderer{}); got != filepath.Join("docs", "App.tsx.txt") {
+		t.Errorf("RenderedPath() = %q, want the extension replaced", got)
+	}
+	if got := RenderedPath(filepath.Join("docs", "App.tsx.mdx"), MarkdownRenderer{}); got != filepath.Join("docs", "App.tsx.mdx") {
+		t.Errorf("RenderedPath() for Markdown = %q, want the path unchanged", got)
+	}
+}
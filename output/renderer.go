@@ -0,0 +1,60 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Renderer converts a file's generated Markdown documentation into an output format
+type Renderer interface {
+	// Extension returns the file extension of the format, such as ".html"
+	Extension() string
+	// Render returns the documentation in the format. content may start with YAML frontmatter.
+	Render(content string) ([]byte, error)
+}
+
+// MarkdownFormat is the name of the Markdown output format, which is always written
+const MarkdownFormat = "markdown"
+
+// MarkdownRenderer writes the documentation as generated
+type MarkdownRenderer struct{}
+
+func init() {
+	Renderers.Register(MarkdownFormat, func() Renderer { return MarkdownRenderer{} })
+}
+
+// Extension returns ".md"
+func (MarkdownRenderer) Extension() string {
+	return ".md"
+}
+
+// Render returns content unchanged
+func (MarkdownRenderer) Render(content string) ([]byte, error) {
+	return []byte(content), nil
+}
+
+// RenderedPath returns the path a renderer writes the documentation at markdownPath to. The
+// Markdown keeps the path chosen by the naming strategy, which may have another extension such
+// as .mdx; other formats replace its extension with theirs.
+func RenderedPath(markdownPath string, renderer Renderer) string {
+	if _, ok := renderer.(MarkdownRenderer); ok {
+		return markdownPath
+	}
+	return strings.TrimSuffix(markdownPath, filepath.Ext(markdownPath)) + renderer.Extension()
+}
+
+// WriteRendered renders content with each renderer and writes the results side by side,
+// named after markdownPath
+func WriteRendered(markdownPath, content string, renderers []Renderer) error {
+	for _, renderer := range renderers {
+		data, err := renderer.Render(content)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(RenderedPath(markdownPath, renderer), data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
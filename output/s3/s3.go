@@ -0,0 +1,125 @@
+// Package s3 uploads documentation to Amazon S3 and S3-compatible storage such as MinIO
+// and Google Cloud Storage
+package s3
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Abiggj/structura/awsauth"
+	"github.com/Abiggj/structura/config"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// uploadTimeout bounds the upload of a single file, and loading the AWS credentials
+const uploadTimeout = 60 * time.Second
+
+// defaultRegion is signed with by S3-compatible services when no region is configured;
+// MinIO and GCS accept it
+const defaultRegion = "us-east-1"
+
+// S3Uploader uploads files to a bucket with the AWS SDK. On a custom endpoint the bucket is
+// the first path segment, as MinIO and GCS expect.
+type S3Uploader struct {
+	Bucket string
+	Prefix string // Key prefix, without leading or trailing slashes
+	Client *s3.Client
+}
+
+// NewS3Uploader creates an uploader for cfg.OutputBucket. The keys are cfg.AWSAccessKeyID and
+// cfg.AWSSecretAccessKey if set, otherwise those of the default AWS credential chain.
+func NewS3Uploader(cfg *config.Config) (*S3Uploader, error) {
+	if cfg.OutputBucket == "" {
+		return nil, fmt.Errorf("no output bucket configured")
+	}
+
+	// S3-compatible services do not need a region, but requests are still signed with one
+	region := ""
+	endpoint := strings.TrimSuffix(cfg.OutputBucketEndpoint, "/")
+	if endpoint != "" {
+		region = defaultRegion
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+	awsCfg, err := awsauth.LoadConfig(ctx, cfg, region)
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+			// Not every S3-compatible service accepts the checksums the SDK adds by default
+			o.RequestChecksumCalculation = aws.RequestChecksumCalculationWhenRequired
+		}
+	})
+
+	return &S3Uploader{
+		Bucket: strings.TrimPrefix(cfg.OutputBucket, "s3://"),
+		Prefix: strings.Trim(cfg.OutputBucketPrefix, "/"),
+		Client: client,
+	}, nil
+}
+
+// URI returns the s3:// URI of the uploaded documentation
+func (u *S3Uploader) URI() string {
+	if u.Prefix == "" {
+		return "s3://" + u.Bucket + "/"
+	}
+	return "s3://" + u.Bucket + "/" + u.Prefix + "/"
+}
+
+// Upload copies the file at localPath to remotePath below the prefix
+func (u *S3Uploader) Upload(localPath, remotePath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+	_, err = u.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(u.Bucket),
+		Key:         aws.String(u.key(remotePath)),
+		Body:        f,
+		ContentType: aws.String(contentType(remotePath)),
+	})
+	if err != nil {
+		return fmt.Errorf("upload to %s failed: %w", u.Bucket, err)
+	}
+	return nil
+}
+
+// key returns the object key of remotePath
+func (u *S3Uploader) key(remotePath string) string {
+	remotePath = strings.TrimPrefix(remotePath, "/")
+	if u.Prefix == "" {
+		return remotePath
+	}
+	return u.Prefix + "/" + remotePath
+}
+
+// contentType returns the MIME type of the file at remotePath, so browsers render the
+// uploaded HTML pages and Markdown as text
+func contentType(remotePath string) string {
+	switch ext := path.Ext(remotePath); ext {
+	case ".md":
+		return "text/markdown; charset=utf-8"
+	case "":
+		return "application/octet-stream"
+	default:
+		if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+			return mimeType
+		}
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,129 @@
+package s3
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Abiggj/structura/config"
+)
+
+// isolateAWSEnvironment keeps the AWS files and variables of the machine out of the test
+func isolateAWSEnvironment(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	for _, name := range []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+		t.Setenv(name, "")
+	}
+}
+
+// putRequest is an upload received by the test server
+type putRequest struct {
+	path          string
+	contentType   string
+	authorization string
+	body          string
+}
+
+func TestS3UploaderUpload(t *testing.T) {
+	isolateAWSEnvironment(t)
+
+	var mu sync.Mutex
+	var requests []putRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPut {
+			t.Errorf("%s request, want PUT", r.Method)
+		}
+		mu.Lock()
+		requests = append(requests, putRequest{r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization"), string(body)})
+		mu.Unlock()
+		if strings.Contains(r.URL.Path, "denied") {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+		}
+	}))
+	defer server.Close()
+
+	cfg := config.NewConfig()
+	cfg.OutputBucket = "s3://docs-bucket"
+	cfg.OutputBucketPrefix = "/main/"
+	cfg.OutputBucketEndpoint = server.URL + "/"
+	cfg.AWSAccessKeyID = "AKIDTEST"
+	cfg.AWSSecretAccessKey = "test-secret"
+
+	uploader, err := NewS3Uploader(cfg)
+	if err != nil {
+		t.Fatalf("NewS3Uploader() error = %v", err)
+	}
+	if got := uploader.URI(); got != "s3://docs-bucket/main/" {
+		t.Errorf("URI() = %q, want s3://docs-bucket/main/", got)
+	}
+
+	localPath := filepath.Join(t.TempDir(), "parser.go.md")
+	if err := os.WriteFile(localPath, []byte("# parser.go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := uploader.Upload(localPath, "src/utils/parser.go.md"); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if err := uploader.Upload(localPath, "denied.md"); err == nil || !strings.Contains(err.Error(), "docs-bucket") {
+		t.Errorf("Upload() rejected by the server = %v, want an error naming the bucket", err)
+	}
+
+	if len(requests) == 0 {
+		t.Fatal("no upload request received")
+	}
+	got := requests[0]
+	if got.path != "/docs-bucket/main/src/utils/parser.go.md" {
+		t.Errorf("uploaded to %s, want the bucket in the path and the key below the prefix", got.path)
+	}
+	if got.contentType != "text/markdown; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want Markdown", got.contentType)
+	}
+	// The explicit keys are used, with the default region as none is configured
+	if !strings.HasPrefix(got.authorization, "AWS4-HMAC-SHA256 Credential=AKIDTEST/") || !strings.Contains(got.authorization, "/"+defaultRegion+"/s3/aws4_request") {
+		t.Errorf("Authorization header %q is not signed with the configured keys in %s", got.authorization, defaultRegion)
+	}
+	if got.body != "# parser.go\n" {
+		t.Errorf("uploaded body %q, want the file content", got.body)
+	}
+}
+
+func TestNewS3UploaderErrors(t *testing.T) {
+	isolateAWSEnvironment(t)
+
+	cfg := config.NewConfig()
+	if _, err := NewS3Uploader(cfg); err == nil {
+		t.Error("NewS3Uploader() without a bucket succeeded")
+	}
+
+	// AWS S3 itself needs a region
+	cfg.OutputBucket = "docs-bucket"
+	cfg.AWSAccessKeyID = "AKIDTEST"
+	cfg.AWSSecretAccessKey = "test-secret"
+	if _, err := NewS3Uploader(cfg); err == nil || !strings.Contains(err.Error(), "region") {
+		t.Errorf("NewS3Uploader() without a region = %v, want an error naming the region", err)
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := map[string]string{
+		"docs/main.go.md": "text/markdown; charset=utf-8",
+		"index.html":      "text/html; charset=utf-8",
+		"LICENSE":         "application/octet-stream",
+	}
+	for remotePath, want := range tests {
+		if got := contentType(remotePath); got != want {
+			t.Errorf("contentType(%q) = %q, want %q", remotePath, got, want)
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package output
+
+import "embed"
+
+// PromptTemplates holds the text/template documentation prompts, one per documentation style
+// named after it, such as templates/terse.tmpl. templates/source.tmpl defines the "source"
+// template that ends every prompt with the project's metadata and the file's path and content.
+//
+//go:embed templates/*.tmpl
+var PromptTemplates embed.FS
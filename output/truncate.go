@@ -0,0 +1,59 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncateAtHeading shortens content to at most maxBytes, cutting before the last Markdown
+// heading that starts within the limit so no section is left half-written, and appends a
+// note saying where it was cut. Without such a heading it cuts at the last line break, or
+// at the last whole character. maxBytes <= 0 means unlimited.
+func TruncateAtHeading(content string, maxBytes int64) string {
+	if maxBytes <= 0 || int64(len(content)) <= maxBytes {
+		return content
+	}
+
+	cut := lastHeadingBefore(content, int(maxBytes))
+	if cut <= 0 {
+		cut = strings.LastIndex(content[:maxBytes], "\n")
+	}
+	if cut <= 0 {
+		cut = int(maxBytes)
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+	}
+
+	kept := strings.TrimRight(content[:cut], "\n ")
+	return fmt.Sprintf("%s\n\n> *Documentation truncated at %d bytes*\n", kept, len(kept))
+}
+
+// lastHeadingBefore returns the offset of the last heading line starting at or before limit,
+// ignoring lines in code fences, or -1 if there is none
+func lastHeadingBefore(content string, limit int) int {
+	last := -1
+	inFence := false
+	for offset := 0; offset <= limit && offset < len(content); {
+		end := strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content) - offset
+		}
+		line := strings.TrimSpace(content[offset : offset+end])
+
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inFence = !inFence
+		} else if !inFence && isHeading(line) {
+			last = offset
+		}
+		offset += end + 1
+	}
+	return last
+}
+
+// isHeading reports whether a trimmed line is an ATX heading such as "## Functions"
+func isHeading(line string) bool {
+	level := len(line) - len(strings.TrimLeft(line, "#"))
+	return level >= 1 && level <= 6 && (len(line) == level || line[level] == ' ')
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTruncateAtHeading(t *testing.T) {
+	doc := "# main.go\n\nIntro.\n\n## Functions\n\nLong text about functions.\n\n## Types\n\nMore text.\n"
+	fenced := "# a.go\n\n## Usage\n\n```sh\n# not a heading\necho hello world\n```\n\nTrailing text here.\n"
+
+	tests := []struct {
+		name     string
+		content  string
+		maxBytes int64
+		want     string // Content kept before the truncation note
+	}{
+		{"unlimited", doc, 0, doc},
+		{"within limit", doc, int64(len(doc)), doc},
+		{"cut before last heading", doc, int64(strings.Index(doc, "More")), "# main.go\n\nIntro.\n\n## Functions\n\nLong text about functions."},
+		{"heading right at the limit", doc, int64(strings.Index(doc, "## Types")), "# main.go\n\nIntro.\n\n## Functions\n\nLong text about functions."},
+		{"heading in code fence ignored", fenced, int64(strings.Index(fenced, "Trailing")), "# a.go"},
+		{"no heading, line break", "line one\nline two\nline three", 12, "line one"},
+		{"no line break, whole character", "ééééé", 5, "éé"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateAtHeading(tt.content, tt.maxBytes)
+			if tt.want == tt.content {
+				if got != tt.content {
+					t.Errorf("TruncateAtHeading() = %q, want the content unchanged", got)
+				}
+				return
+			}
+
+			kept, note, found := strings.Cut(got, "\n\n> *Documentation truncated at ")
+			if !found {
+				t.Fatalf("TruncateAtHeading() = %q, want a truncation note", got)
+			}
+			if kept != tt.want {
+				t.Errorf("kept %q, want %q", kept, tt.want)
+			}
+			if !strings.HasPrefix(note, strconv.Itoa(len(kept))+" bytes*") {
+				t.Errorf("note %q does not give the %d bytes kept", note, len(kept))
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Uploader copies documentation files to remote storage
+type Uploader interface {
+	// Upload copies the file at localPath to remotePath, a slash-separated path in the storage
+	Upload(localPath, remotePath string) error
+}
+
+// SyncDir uploads every file below localDir with uploader, under its slash-separated path
+// relative to localDir. Hidden files and directories, such as the checksum store, are skipped.
+// It returns the number of files uploaded.
+func SyncDir(uploader Uploader, localDir string) (int, error) {
+	uploaded := 0
+	err := filepath.WalkDir(localDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != localDir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		if err := uploader.Upload(path, filepath.ToSlash(relPath)); err != nil {
+			return fmt.Errorf("error uploading %s: %w", relPath, err)
+		}
+		uploaded++
+		return nil
+	})
+	return uploaded, err
+}
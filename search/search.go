@@ -0,0 +1,272 @@
+// Package search builds a search index of generated documentation and queries it
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Abiggj/structura/frontmatter"
+)
+
+// IndexFileName is the name of the search index written to the output directory
+const IndexFileName = "search-index.json"
+
+// maxPreviewLength is the number of characters of the first paragraph kept as a body preview
+const maxPreviewLength = 300
+
+// contextRadius is the number of characters shown on each side of a match in a preview
+const contextRadius = 40
+
+// indexExclusions are Markdown files in the output directory that only link to other pages
+var indexExclusions = map[string]bool{
+	"INDEX.md": true,
+}
+
+// SearchEntry is the search index entry of one Markdown file
+type SearchEntry struct {
+	File        string   `json:"file"`  // Slash-separated path relative to the output directory
+	Title       string   `json:"title"` // First top-level heading, or the file name
+	Headings    []string `json:"headings"`
+	BodyPreview string   `json:"body_preview"` // Start of the first paragraph
+}
+
+// Match is an index entry matching a query, with the matching parts of it
+type Match struct {
+	Entry   SearchEntry
+	Context []string
+}
+
+// BuildIndex returns an entry for every Markdown file in outputDir, sorted by path. The title
+// is the first top-level heading, headings are the other headings, and the body preview is the
+// start of the first paragraph that is not a heading, list or code block.
+func BuildIndex(outputDir string) ([]SearchEntry, error) {
+	var entries []SearchEntry
+	err := filepath.WalkDir(outputDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".md") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(outputDir, p)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if indexExclusions[relPath] {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, parseEntry(relPath, frontmatter.Strip(string(content))))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error indexing documentation in %s: %w", outputDir, err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].File < entries[j].File })
+	return entries, nil
+}
+
+// parseEntry extracts the title, headings and body preview of a Markdown document
+func parseEntry(file, markdown string) SearchEntry {
+	entry := SearchEntry{File: file, Headings: []string{}}
+
+	var paragraph []string
+	inCode := false
+	for _, line := range strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+
+		if level, text := heading(trimmed); level > 0 {
+			if level == 1 && entry.Title == "" {
+				entry.Title = text
+			} else {
+				entry.Headings = append(entry.Headings, text)
+			}
+			continue
+		}
+
+		if entry.BodyPreview != "" {
+			continue
+		}
+		switch {
+		case trimmed == "":
+			if len(paragraph) > 0 {
+				entry.BodyPreview = truncate(strings.Join(paragraph, " "), maxPreviewLength)
+			}
+			paragraph = nil
+		case isBlockMarkup(trimmed):
+			paragraph = nil
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	if entry.BodyPreview == "" && len(paragraph) > 0 {
+		entry.BodyPreview = truncate(strings.Join(paragraph, " "), maxPreviewLength)
+	}
+
+	if entry.Title == "" {
+		entry.Title = strings.TrimSuffix(path.Base(file), ".md")
+	}
+	return entry
+}
+
+// heading returns the level and text of an ATX heading line, or level 0 if line is not one
+func heading(line string) (int, string) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ') {
+		return 0, ""
+	}
+	text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[level:]), "#"))
+	return level, strings.NewReplacer("**", "", "`", "").Replace(text)
+}
+
+// isBlockMarkup reports whether line starts a list item, quote, table row or rule, which are
+// not used as a body preview
+func isBlockMarkup(line string) bool {
+	for _, prefix := range []string{"- ", "* ", "+ ", ">", "|", "---", "***", "<"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	if dot := strings.Index(line, ". "); dot > 0 {
+		return strings.Trim(line[:dot], "0123456789") == ""
+	}
+	return false
+}
+
+// truncate shortens s to at most max characters, ending it with an ellipsis if it was cut
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max-1])) + "…"
+}
+
+// WriteIndex builds the search index of outputDir and writes it to search-index.json there.
+// It returns the path of the index.
+func WriteIndex(outputDir string) (string, error) {
+	entries, err := BuildIndex(outputDir)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	indexPath := filepath.Join(outputDir, IndexFileName)
+	if err := os.WriteFile(indexPath, data, 0644); err != nil {
+		return "", err
+	}
+	return indexPath, nil
+}
+
+// LoadIndex reads the search index written to outputDir
+func LoadIndex(outputDir string) ([]SearchEntry, error) {
+	data, err := os.ReadFile(filepath.Join(outputDir, IndexFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []SearchEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", IndexFileName, err)
+	}
+	return entries, nil
+}
+
+// Search returns the entries whose path, title, headings or body preview contain query,
+// ignoring case, in index order. The context of a match lists the title, headings and
+// surroundings of the match in the preview that contain the query.
+func Search(entries []SearchEntry, query string) []Match {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	var matches []Match
+	for _, entry := range entries {
+		var context []string
+		if strings.Contains(strings.ToLower(entry.Title), query) {
+			context = append(context, entry.Title)
+		}
+		for _, h := range entry.Headings {
+			if strings.Contains(strings.ToLower(h), query) {
+				context = append(context, "# "+h)
+			}
+		}
+		if snippet := matchContext(entry.BodyPreview, query); snippet != "" {
+			context = append(context, snippet)
+		}
+
+		if len(context) > 0 || strings.Contains(strings.ToLower(entry.File), query) {
+			matches = append(matches, Match{Entry: entry, Context: context})
+		}
+	}
+	return matches
+}
+
+// matchContext returns the first occurrence of the lower-case query in text with up to
+// contextRadius characters on each side, or "" if text does not contain it
+func matchContext(text, query string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	needle := []rune(query)
+	if len(lower) != len(runes) {
+		// Lower-casing changed the length, so positions cannot be mapped back
+		if !strings.Contains(strings.ToLower(text), query) {
+			return ""
+		}
+		return truncate(text, 2*contextRadius)
+	}
+
+	at := runeIndex(lower, needle)
+	if at < 0 {
+		return ""
+	}
+	start, end := max(at-contextRadius, 0), min(at+len(needle)+contextRadius, len(runes))
+
+	snippet := strings.TrimSpace(string(runes[start:end]))
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// runeIndex returns the index of the first occurrence of needle in haystack, or -1
+func runeIndex(haystack, needle []rune) int {
+	for i := 0; i+len(needle) <= len(haystack); i++ {
+		if string(haystack[i:i+len(needle)]) == string(needle) {
+			return i
+		}
+	}
+	return -1
+}
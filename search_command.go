@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/search"
+	"github.com/spf13/cobra"
+)
+
+// newSearchCommand creates the command searching generated documentation
+func newSearchCommand() *cobra.Command {
+	cmd := newCommand("search", "<query>", "Search the documentation in an output directory")
+	fs := cmd.Flags()
+	output := fs.String("output", "", "directory holding the documentation (default: output_dir from .structura.yaml)")
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		query := strings.TrimSpace(strings.Join(args, " "))
+		if query == "" {
+			return fmt.Errorf("no query: pass the text to search for")
+		}
+
+		outputDir := *output
+		if outputDir == "" {
+			projectConfig, err := config.LoadProjectConfig(".")
+			if err != nil {
+				return err
+			}
+			if projectConfig != nil {
+				outputDir = projectConfig.OutputDir
+			}
+		}
+		if outputDir == "" {
+			return fmt.Errorf("no output directory: pass --output or set output_dir in %s", config.ProjectConfigFileName)
+		}
+
+		entries, err := search.LoadIndex(outputDir)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no %s in %s: generate the documentation first", search.IndexFileName, outputDir)
+		}
+		if err != nil {
+			return err
+		}
+
+		matches := search.Search(entries, query)
+		if len(matches) == 0 {
+			fmt.Printf("No documentation matches %q\n", query)
+			return nil
+		}
+		for _, match := range matches {
+			fmt.Println(filepath.Join(outputDir, filepath.FromSlash(match.Entry.File)))
+			for _, context := range match.Context {
+				fmt.Println("    " + context)
+			}
+		}
+		return nil
+	}
+	return cmd
+}
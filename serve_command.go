@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/docs"
+	"github.com/spf13/cobra"
+)
+
+// newServeCommand creates the command serving generated documentation over HTTP
+func newServeCommand() *cobra.Command {
+	cmd := newCommand("serve", "", "Serve the documentation in an output directory as HTML, reloading pages when it changes")
+	cmd.Args = cobra.NoArgs
+	fs := cmd.Flags()
+	output := fs.String("output", "", "directory holding the documentation (default: output_dir from .structura.yaml)")
+	host := fs.String("host", "localhost", "address to listen on; use 0.0.0.0 to serve other machines")
+	port := fs.Int("port", 8080, "port to listen on")
+	title := fs.String("title", "", "project name shown in the sidebar (default: name of the directory containing the output directory)")
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		outputDir := *output
+		if outputDir == "" {
+			projectConfig, err := config.LoadProjectConfig(".")
+			if err != nil {
+				return err
+			}
+			if projectConfig != nil {
+				outputDir = projectConfig.OutputDir
+			}
+		}
+		if outputDir == "" {
+			return fmt.Errorf("no output directory: pass --output or set output_dir in %s", config.ProjectConfigFileName)
+		}
+		outputDir, err := filepath.Abs(outputDir)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(outputDir); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory: generate the documentation first", outputDir)
+		}
+
+		projectName := *title
+		if projectName == "" {
+			projectName = filepath.Base(filepath.Dir(outputDir))
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		defer stop()
+
+		server := docs.NewServer(outputDir, projectName)
+		go func() {
+			if err := server.Watch(ctx); err != nil {
+				fmt.Println("Warning: pages will not reload on changes:", err)
+			}
+		}()
+
+		httpServer := &http.Server{
+			Addr:              net.JoinHostPort(*host, strconv.Itoa(*port)),
+			Handler:           server,
+			ReadHeaderTimeout: 10 * time.Second,
+			// Cancel the event streams on shutdown, which would otherwise stay open
+			BaseContext: func(net.Listener) context.Context { return ctx },
+		}
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			httpServer.Shutdown(shutdownCtx)
+		}()
+
+		fmt.Printf("Serving %s at http://%s/ (press Ctrl+C to stop)\n", outputDir, httpServer.Addr)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+	return cmd
+}
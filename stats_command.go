@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/spf13/cobra"
+)
+
+// newStatsCommand creates the command summarizing API usage across runs
+func newStatsCommand() *cobra.Command {
+	cmd := newCommand("stats", "", "Show the API calls, tokens and cost of every run so far")
+	cmd.Args = cobra.NoArgs
+
+	cmd.RunE = func(_ *cobra.Command, args []string) error {
+		usage, err := config.LoadUsage()
+		if err != nil {
+			return err
+		}
+		if usage.TotalAPICallsMade == 0 {
+			fmt.Println("No API calls recorded yet")
+			return nil
+		}
+
+		path, err := config.UsagePath()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("API usage since %s, from %s\n\n", usage.Since.Format(time.DateOnly), path)
+		fmt.Printf("API calls:         %d\n", usage.TotalAPICallsMade)
+		fmt.Printf("Estimated tokens:  ~%d\n", usage.TotalTokensEstimated)
+		fmt.Printf("Estimated cost:    ~$%.4f\n\n", usage.TotalCostEstimated)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Provider\tAPI calls\tTokens\tCost")
+		for _, name := range usage.ProviderNames() {
+			provider := usage.Providers[name]
+			fmt.Fprintf(w, "%s\t%d\t~%d\t~$%.4f\n", name, provider.APICalls, provider.TokensEstimated, provider.CostEstimated)
+		}
+		return w.Flush()
+	}
+	return cmd
+}
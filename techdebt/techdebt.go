@@ -0,0 +1,216 @@
+// Package techdebt collects TODO, FIXME and similar comments from source files
+package techdebt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// FileName is the name of the technical debt report written to the output directory
+const FileName = "TECH_DEBT.md"
+
+// Tags are the comment tags collected, in the order they are reported
+var Tags = []string{"TODO", "FIXME", "HACK", "XXX", "NOSONAR"}
+
+// Annotation is a tagged comment in a source file
+type Annotation struct {
+	File       string // Path of the source file
+	LineNumber int    // 1-based
+	Tag        string // One of Tags
+	Message    string // Text following the tag, if any
+}
+
+// tagPattern matches comment text starting with a tag, with an optional (author) and colon,
+// followed by its message. Leading asterisks continue block comments.
+var tagPattern = regexp.MustCompile(`^[\s*!-]*(TODO|FIXME|HACK|XXX|NOSONAR)\b(?:\([^)]*\))?:?(.*)`)
+
+// Block comment delimiters
+var blockComments = []struct{ open, close string }{
+	{"/*", "*/"},
+	{"<!--", "-->"},
+}
+
+// Extract scans the content of files for comments tagged TODO, FIXME, HACK, XXX or NOSONAR
+// and returns them by file path, in line order. Line comments starting with // or #, and the
+// lines of block comments in /* */ or <!-- -->, are collected when they start with a tag, so
+// comments merely mentioning one are not. Files without annotations are left out.
+func Extract(files []filehandler.FileInfo) map[string][]Annotation {
+	annotations := make(map[string][]Annotation)
+	for _, file := range files {
+		if file.IsDir || file.Content == "" {
+			continue
+		}
+		for _, comment := range comments(file.Content) {
+			match := tagPattern.FindStringSubmatch(comment.text)
+			if match == nil {
+				continue
+			}
+			annotations[file.Path] = append(annotations[file.Path], Annotation{
+				File:       file.Path,
+				LineNumber: comment.line,
+				Tag:        match[1],
+				Message:    cleanMessage(match[2]),
+			})
+		}
+	}
+	return annotations
+}
+
+// comment is the comment text on one line of a file
+type comment struct {
+	line int
+	text string
+}
+
+// comments returns the comment text of each line of content that has any
+func comments(content string) []comment {
+	var found []comment
+	blockClose := "" // Set while inside a block comment
+	for i, line := range strings.Split(content, "\n") {
+		if blockClose != "" {
+			end := strings.Index(line, blockClose)
+			if end < 0 {
+				found = append(found, comment{line: i + 1, text: line})
+				continue
+			}
+			found = append(found, comment{line: i + 1, text: line[:end]})
+			line = line[end+len(blockClose):]
+			blockClose = ""
+		}
+
+		start, marker := commentStart(line)
+		if start < 0 {
+			continue
+		}
+		text := line[start+len(marker):]
+		for _, block := range blockComments {
+			if marker != block.open {
+				continue
+			}
+			if end := strings.Index(text, block.close); end >= 0 {
+				text = text[:end]
+			} else {
+				blockClose = block.close
+			}
+		}
+		found = append(found, comment{line: i + 1, text: text})
+	}
+	return found
+}
+
+// commentStart returns the position and marker of the first comment on line, or -1. A //
+// preceded by a colon, as in a URL, and a # inside a word, as in a color, do not start one.
+func commentStart(line string) (int, string) {
+	start, marker := -1, ""
+	consider := func(i int, m string) {
+		if i >= 0 && (start < 0 || i < start) {
+			start, marker = i, m
+		}
+	}
+
+	for offset := 0; ; {
+		i := strings.Index(line[offset:], "//")
+		if i < 0 {
+			break
+		}
+		i += offset
+		if i == 0 || line[i-1] != ':' {
+			consider(i, "//")
+			break
+		}
+		offset = i + 2
+	}
+	for i, r := range line {
+		if r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			consider(i, "#")
+			break
+		}
+	}
+	for _, block := range blockComments {
+		consider(strings.Index(line, block.open), block.open)
+	}
+	return start, marker
+}
+
+// cleanMessage trims the separators and comment markers around a tag's message
+func cleanMessage(message string) string {
+	message = strings.TrimSpace(message)
+	message = strings.TrimSuffix(message, "*/")
+	message = strings.TrimSuffix(message, "-->")
+	return strings.TrimSpace(strings.TrimLeft(message, ":-– "))
+}
+
+// WriteReport writes TECH_DEBT.md to outputDir, with a count of each tag at the top and the
+// annotations grouped by tag, each referenced by its path relative to rootDir and line number.
+// It returns the path of the report.
+func WriteReport(outputDir, rootDir string, annotations map[string][]Annotation) (string, error) {
+	byTag := make(map[string][]Annotation)
+	for _, fileAnnotations := range annotations {
+		for _, annotation := range fileAnnotations {
+			byTag[annotation.Tag] = append(byTag[annotation.Tag], annotation)
+		}
+	}
+	total := Count(annotations)
+
+	var sb strings.Builder
+	sb.WriteString("# Technical Debt\n\n")
+	sb.WriteString(fmt.Sprintf("%d annotations in %d files.\n\n", total, len(annotations)))
+	if total > 0 {
+		sb.WriteString("| Tag | Count |\n|-----|-------|\n")
+		for _, tag := range Tags {
+			if len(byTag[tag]) > 0 {
+				sb.WriteString(fmt.Sprintf("| %s | %d |\n", tag, len(byTag[tag])))
+			}
+		}
+	}
+
+	for _, tag := range Tags {
+		tagged := byTag[tag]
+		if len(tagged) == 0 {
+			continue
+		}
+		sort.Slice(tagged, func(i, j int) bool {
+			if tagged[i].File != tagged[j].File {
+				return tagged[i].File < tagged[j].File
+			}
+			return tagged[i].LineNumber < tagged[j].LineNumber
+		})
+
+		sb.WriteString(fmt.Sprintf("\n## %s (%d)\n\n", tag, len(tagged)))
+		for _, annotation := range tagged {
+			relPath, err := filepath.Rel(rootDir, annotation.File)
+			if err != nil {
+				relPath = annotation.File
+			}
+			sb.WriteString(fmt.Sprintf("- `%s:%d`", filepath.ToSlash(relPath), annotation.LineNumber))
+			if annotation.Message != "" {
+				sb.WriteString(" " + annotation.Message)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", err
+	}
+	reportPath := filepath.Join(outputDir, FileName)
+	if err := os.WriteFile(reportPath, []byte(sb.String()), 0644); err != nil {
+		return "", err
+	}
+	return reportPath, nil
+}
+
+// Count returns the total number of annotations
+func Count(annotations map[string][]Annotation) int {
+	total := 0
+	for _, fileAnnotations := range annotations {
+		total += len(fileAnnotations)
+	}
+	return total
+}
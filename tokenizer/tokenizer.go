@@ -0,0 +1,36 @@
+package tokenizer
+
+import (
+	"unicode/utf8"
+)
+
+// charsPerToken is the average number of characters per token for typical source code
+// and English text across the supported models
+const charsPerToken = 4
+
+// Estimate returns an approximate token count for the given text
+func Estimate(text string) int {
+	chars := utf8.RuneCountInString(text)
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// Truncate shortens text from the end so that its estimated token count does not exceed maxTokens
+func Truncate(text string, maxTokens int) string {
+	if maxTokens <= 0 {
+		return ""
+	}
+	if Estimate(text) <= maxTokens {
+		return text
+	}
+
+	// Cut on a rune boundary so multi-byte characters are never split
+	maxChars := maxTokens * charsPerToken
+	chars := 0
+	for i := range text {
+		if chars == maxChars {
+			return text[:i]
+		}
+		chars++
+	}
+	return text
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// malformedBatchClient answers every batch with JSON that does not parse
+type malformedBatchClient struct {
+	*api.MockDocumentationClient
+	batches int
+}
+
+func (c *malformedBatchClient) BatchGenerate(ctx context.Context, files []filehandler.FileInfo, maxBatchTokens int) (map[string]string, error) {
+	c.batches++
+	return api.ParseBatchResponse(`[{"path": "` + files[0].Path + `", "documentation": `)
+}
+
+func TestProcessBatchFallsBackOnMalformedJSON(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	m := NewModel()
+	m.inputDir = inputDir
+	m.outputDir = outputDir
+	var err error
+	if m.checksums, err = filehandler.LoadChecksumStore(outputDir); err != nil {
+		t.Fatal(err)
+	}
+	if m.freshness, err = filehandler.LoadFreshnessStore(outputDir); err != nil {
+		t.Fatal(err)
+	}
+	client := &malformedBatchClient{MockDocumentationClient: api.NewMockClient(nil)}
+	m.apiClient = client
+
+	for _, name := range []string{"a.go", "b.go"} {
+		path := filepath.Join(inputDir, name)
+		if err := os.WriteFile(path, []byte("package main\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		m.files = append(m.files, filehandler.FileInfo{Path: path, Content: "package main\n", Checksum: filehandler.Checksum("package main\n")})
+	}
+
+	msg := processBatchAt(m.files, []int{0, 1}, m)()
+	batch, ok := msg.(batchProcessedMsg)
+	if !ok {
+		t.Fatalf("processBatchAt returned %T, want batchProcessedMsg", msg)
+	}
+	if client.batches != 1 {
+		t.Errorf("%d batch calls, want 1", client.batches)
+	}
+
+	// Both files are documented one at a time after the batch failed
+	if got, want := client.CallLog(), []string{m.files[0].Path, m.files[1].Path}; !reflect.DeepEqual(got, want) {
+		t.Errorf("files documented individually = %v, want %v", got, want)
+	}
+	for i, result := range batch.results {
+		if _, ok := result.(fileProcessedMsg); !ok {
+			t.Errorf("result %d = %#v, want fileProcessedMsg", i, result)
+		}
+	}
+	for _, file := range m.files {
+		rel, _ := filepath.Rel(inputDir, file.Path)
+		if _, err := os.Stat(filepath.Join(outputDir, rel+".md")); err != nil {
+			t.Errorf("documentation of %s not written: %v", rel, err)
+		}
+	}
+}
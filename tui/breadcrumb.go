@@ -0,0 +1,40 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// pathSegments splits a path into its directories, starting with the volume name on Windows
+func pathSegments(path string) []string {
+	path = filepath.Clean(path)
+	volume := filepath.VolumeName(path)
+	rest := strings.Trim(path[len(volume):], string(filepath.Separator))
+
+	var segments []string
+	if volume != "" {
+		segments = append(segments, volume)
+	}
+	if rest != "" {
+		segments = append(segments, strings.Split(rest, string(filepath.Separator))...)
+	}
+	return segments
+}
+
+// renderBreadcrumb renders a path as "home › user › project" with the last segment highlighted
+func renderBreadcrumb(path string) string {
+	segments := pathSegments(path)
+	if len(segments) == 0 {
+		return selectedStyle.Render(string(filepath.Separator))
+	}
+
+	parts := make([]string, len(segments))
+	for i, segment := range segments {
+		if i == len(segments)-1 {
+			parts[i] = selectedStyle.Render(segment)
+		} else {
+			parts[i] = infoStyle.Render(segment)
+		}
+	}
+	return strings.Join(parts, infoStyle.Render(" › "))
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestDirectoryQuickFilter(t *testing.T) {
+	entries, err := fs.ReadDir(fstest.MapFS{
+		"README.md":       {},
+		"cmd/main.go":     {},
+		"Config.go":       {},
+		"config_test.go":  {},
+		"internal/api.go": {},
+	}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"Config.go", "README.md", "cmd", "config_test.go", "internal"}},
+		{"config", []string{"Config.go", "config_test.go"}},
+		{"CONFIG", []string{"Config.go", "config_test.go"}},
+		{".go", []string{"Config.go", "config_test.go"}},
+		{"m", []string{"README.md", "cmd"}},
+		{"nothing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			var got []string
+			for _, entry := range DirectoryQuickFilter(entries, tt.filter) {
+				got = append(got, entry.Name())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DirectoryQuickFilter(%q) = %q, want %q", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetDirFilterKeepsSelectionInRange(t *testing.T) {
+	entries, err := fs.ReadDir(fstest.MapFS{"a.go": {}, "b.go": {}, "c.txt": {}}, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel()
+	m.allDirEntries = entries
+	m.dirEntries = entries
+	m.selectedDir = 2
+
+	m.setDirFilter(".go")
+	if m.selectedDir != 1 {
+		t.Errorf("selectedDir = %d with 2 entries shown, want 1", m.selectedDir)
+	}
+	m.setDirFilter("none")
+	if m.selectedDir != 0 {
+		t.Errorf("selectedDir = %d with no entries shown, want 0", m.selectedDir)
+	}
+	m.setDirFilter("")
+	if len(m.dirEntries) != 3 {
+		t.Errorf("%d entries shown with the filter cleared, want 3", len(m.dirEntries))
+	}
+}
+
+func TestSetInputDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/widget\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewModel()
+	if err := m.SetInputDir(dir); err != nil {
+		t.Fatalf("SetInputDir() error = %v", err)
+	}
+	if m.inputDir != dir || len(m.dirHistory) != 1 || m.dirHistory[0] != dir {
+		t.Errorf("inputDir %q, dirHistory %q, want %q", m.inputDir, m.dirHistory, dir)
+	}
+	if got := m.projectTypes[m.selectedType]; got != filehandler.ProjectTypeGo {
+		t.Errorf("selected project type %q, want %q detected from go.mod", got, filehandler.ProjectTypeGo)
+	}
+
+	for _, path := range []string{filepath.Join(dir, "missing"), filepath.Join(dir, "go.mod")} {
+		if err := m.SetInputDir(path); err == nil {
+			t.Errorf("SetInputDir(%q) succeeded, want an error", path)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/types"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// setEnvironment sets the given variables, clearing the other API variables, with no
+// keychain or saved profiles
+func setEnvironment(t *testing.T, vars map[string]string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	for _, apiType := range types.APITypes() {
+		if name := config.APIKeyEnvVar(apiType); name != "" {
+			t.Setenv(name, "")
+		}
+	}
+	t.Setenv(config.APITypeEnvVar, "")
+	t.Setenv(config.APIModelEnvVar, "")
+	for name, value := range vars {
+		t.Setenv(name, value)
+	}
+
+	keyringEnabled := config.KeyringEnabled
+	config.KeyringEnabled = false
+	t.Cleanup(func() { config.KeyringEnabled = keyringEnabled })
+}
+
+func TestNewModelEnvironment(t *testing.T) {
+	tests := []struct {
+		name      string
+		vars      map[string]string
+		wantState State
+	}{
+		{"nothing set", nil, StateSelectAPIType},
+		{"API type", map[string]string{config.APITypeEnvVar: "chatgpt"}, StateSelectAPIModel},
+		{"API type and model", map[string]string{config.APITypeEnvVar: "chatgpt", config.APIModelEnvVar: "gpt-4o"}, StateEnterAPIKey},
+		{"API type, model and key", map[string]string{config.APITypeEnvVar: "chatgpt", config.APIModelEnvVar: "gpt-4o", "OPENAI_API_KEY": "sk-test"}, StateSelectProjectType},
+		{"custom API type", map[string]string{config.APITypeEnvVar: "custom"}, StateEnterCustomEndpoint},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setEnvironment(t, tt.vars)
+
+			m := NewModel()
+			model, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			m = model.(Model)
+			if m.state != tt.wantState {
+				t.Fatalf("state after the start screen = %v, want %v", m.state, tt.wantState)
+			}
+
+			if tt.wantState == StateSelectProjectType {
+				if m.config.APIModel != "gpt-4o" {
+					t.Errorf("APIModel = %q, want gpt-4o from %s", m.config.APIModel, config.APIModelEnvVar)
+				}
+				if view := m.View(); !strings.Contains(view, "(from environment)") {
+					t.Errorf("project type screen does not say the key is from the environment:\n%s", view)
+				}
+			}
+		})
+	}
+}
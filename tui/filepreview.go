@@ -0,0 +1,205 @@
+package tui
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// filePreviewMinWidth is the terminal width from which the file preview pane is shown
+// beside the progress
+const filePreviewMinWidth = 120
+
+// filePreviewLines is the number of source lines shown in the file preview pane
+const filePreviewLines = 40
+
+// FilePreviewPane shows the highlighted source of the last documented file. It only
+// scrolls when told to, so it stays put while files are being processed.
+type FilePreviewPane struct {
+	Path     string
+	source   string // Highlighted source lines
+	viewport viewport.Model
+}
+
+// NewFilePreviewPane creates an empty file preview pane
+func NewFilePreviewPane() FilePreviewPane {
+	return FilePreviewPane{viewport: viewport.New(0, 0)}
+}
+
+// SetFile shows the first filePreviewLines lines of a file, scrolled to the top
+func (p *FilePreviewPane) SetFile(path, content string) {
+	lines := strings.Split(strings.ReplaceAll(content, "\t", "    "), "\n")
+	if len(lines) > filePreviewLines {
+		lines = lines[:filePreviewLines]
+	}
+
+	p.Path = path
+	p.source = highlightSource(path, strings.Join(lines, "\n"))
+	p.viewport.SetContent(p.source)
+	p.viewport.GotoTop()
+}
+
+// SetSize fits the pane, border included, to width and height. The pane is no taller
+// than filePreviewLines of source.
+func (p *FilePreviewPane) SetSize(width, height int) {
+	frameWidth, frameHeight := filePreviewStyle.GetFrameSize()
+	p.viewport.Width = max(width-frameWidth, 1)
+	p.viewport.Height = max(min(height-frameHeight-1, filePreviewLines), 1) // One line for the file name
+}
+
+// ScrollUp scrolls the pane up by one page
+func (p *FilePreviewPane) ScrollUp() {
+	p.viewport.ViewUp()
+}
+
+// ScrollDown scrolls the pane down by one page
+func (p *FilePreviewPane) ScrollDown() {
+	p.viewport.ViewDown()
+}
+
+// View renders the pane with the file name above the source. Lines wider than the pane are cut.
+func (p FilePreviewPane) View() string {
+	lines := strings.Split(p.source, "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, p.viewport.Width, "…")
+	}
+	p.viewport.SetContent(strings.Join(lines, "\n"))
+
+	title := fileStyle.Render(truncatePath(filepath.Base(p.Path), p.viewport.Width))
+	return filePreviewStyle.Render(title + "\n" + p.viewport.View())
+}
+
+// truncatePath shortens path to width columns, keeping its end
+func truncatePath(path string, width int) string {
+	runes := []rune(path)
+	if len(runes) <= width || width < 2 {
+		return path
+	}
+	return "…" + string(runes[len(runes)-width+1:])
+}
+
+// Token patterns for highlightSource, tried in order at each position
+var (
+	sourceString     = regexp.MustCompile("^(\"(?:[^\"\\\\]|\\\\.)*\"|'(?:[^'\\\\]|\\\\.)*'|`[^`]*`)")
+	sourceNumber     = regexp.MustCompile(`^\d+(\.\d+)?`)
+	sourceIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+)
+
+// sourceKeywords are highlighted in every language; words that are keywords in only some
+// languages are rarely used as names in the others
+var sourceKeywords = map[string]bool{
+	"func": true, "function": true, "def": true, "fn": true, "class": true, "struct": true,
+	"interface": true, "type": true, "enum": true, "impl": true, "trait": true, "return": true,
+	"if": true, "else": true, "elif": true, "for": true, "while": true, "switch": true,
+	"case": true, "break": true, "continue": true, "import": true, "from": true,
+	"package": true, "const": true, "let": true, "var": true, "val": true, "new": true,
+	"public": true, "private": true, "protected": true, "static": true, "async": true,
+	"await": true, "try": true, "catch": true, "except": true, "finally": true,
+	"throw": true, "raise": true, "go": true, "defer": true, "select": true, "range": true,
+	"nil": true, "null": true, "None": true, "true": true, "false": true, "True": true,
+	"False": true, "this": true, "self": true, "extends": true, "implements": true,
+	"pub": true, "use": true, "mod": true, "mut": true, "match": true, "with": true,
+}
+
+// hashCommentExtensions are the file extensions of languages whose line comments start with #
+var hashCommentExtensions = map[string]bool{
+	".py": true, ".rb": true, ".sh": true, ".bash": true, ".zsh": true, ".yaml": true,
+	".yml": true, ".toml": true, ".r": true, ".pl": true, ".ex": true, ".exs": true,
+	".dockerfile": true, ".mk": true, ".cmake": true,
+}
+
+// highlightSource colors the comments, strings, numbers and keywords of source. The comment
+// syntax follows the language detected from path's extension: # for scripting languages,
+// // and /* */ for the C family.
+func highlightSource(path, source string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	base := filepath.Base(path)
+	hashComments := hashCommentExtensions[ext] || base == "Makefile" || base == "Dockerfile"
+
+	var sb strings.Builder
+	inBlockComment := false
+	for i, line := range strings.Split(source, "\n") {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		for rest := line; rest != ""; {
+			if inBlockComment {
+				end := strings.Index(rest, "*/")
+				if end < 0 {
+					sb.WriteString(codeCommentStyle.Render(rest))
+					break
+				}
+				sb.WriteString(codeCommentStyle.Render(rest[:end+2]))
+				rest = rest[end+2:]
+				inBlockComment = false
+				continue
+			}
+
+			switch {
+			case !hashComments && strings.HasPrefix(rest, "/*"):
+				inBlockComment = true
+				sb.WriteString(codeCommentStyle.Render("/*"))
+				rest = rest[2:]
+				continue
+			case !hashComments && strings.HasPrefix(rest, "//"),
+				hashComments && strings.HasPrefix(rest, "#"):
+				sb.WriteString(codeCommentStyle.Render(rest))
+				rest = ""
+				continue
+			}
+
+			if token := sourceString.FindString(rest); token != "" {
+				sb.WriteString(codeStringStyle.Render(token))
+				rest = rest[len(token):]
+			} else if token := sourceIdentifier.FindString(rest); token != "" {
+				if sourceKeywords[token] {
+					sb.WriteString(codeKeywordStyle.Render(token))
+				} else {
+					sb.WriteString(token)
+				}
+				rest = rest[len(token):]
+			} else if token := sourceNumber.FindString(rest); token != "" {
+				sb.WriteString(codeNumberStyle.Render(token))
+				rest = rest[len(token):]
+			} else {
+				_, size := utf8.DecodeRuneInString(rest)
+				sb.WriteString(rest[:size])
+				rest = rest[size:]
+			}
+		}
+	}
+	return sb.String()
+}
+
+// filePreviewLayout renders left with the file preview pane to its right when the terminal is
+// wide enough and a file has been documented; otherwise it returns left unchanged
+func (m Model) filePreviewLayout(left string) string {
+	if !m.showFilePreview() {
+		return left
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		lipgloss.NewStyle().Width(m.terminalWidth()-m.filePreviewWidth()).Render(left),
+		m.filePreview.View())
+}
+
+// filePreviewWidth returns the width of the file preview pane, the right half of the terminal
+func (m Model) filePreviewWidth() int {
+	return m.terminalWidth() - m.terminalWidth()/2
+}
+
+// resizeFilePreview fits the file preview pane to the terminal
+func (m *Model) resizeFilePreview() {
+	m.filePreview.SetSize(m.filePreviewWidth(), m.terminalHeight()-statusBarHeight)
+}
+
+// showFilePreview reports whether the file preview pane is shown beside the progress
+func (m Model) showFilePreview() bool {
+	return m.config.FilePreview && m.width >= filePreviewMinWidth && m.currentFileContent != ""
+}
@@ -0,0 +1,163 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// HelpEntry is one keybinding listed in the help overlay
+type HelpEntry struct {
+	Key         string
+	Description string
+}
+
+// helpEntries converts key bindings to help entries
+func helpEntries(bindings ...key.Binding) []HelpEntry {
+	entries := make([]HelpEntry, len(bindings))
+	for i, binding := range bindings {
+		help := binding.Help()
+		entries[i] = HelpEntry{Key: help.Key, Description: help.Desc}
+	}
+	return entries
+}
+
+// stateHelp returns the keybindings available in each state, without the global ones
+func (k KeyMap) stateHelp() map[State][]HelpEntry {
+	list := helpEntries(k.Up, k.Down, k.Enter)
+	textEntry := helpEntries(k.Enter, k.Backspace)
+	scroll := helpEntries(k.Up, k.Down, k.Back)
+
+	return map[State][]HelpEntry{
+		StateInit:                append([]HelpEntry{{Key: "any key", Description: "start"}}, helpEntries(k.Theme)...),
+		StateSelectTheme:         list,
+		StateSelectProfile:       helpEntries(k.Up, k.Down, k.Enter, k.Delete),
+		StateSelectAPIType:       list,
+		StateSelectAPIModel:      list,
+		StateEnterCustomEndpoint: textEntry,
+		StateEnterCustomModel:    textEntry,
+		StateEnterAPIKey:         textEntry,
+		StateSaveKeyPrompt:       helpEntries(k.Yes, k.No),
+		StateAdvancedSettings:    helpEntries(k.Up, k.Down, k.NextField, k.Backspace, k.Enter),
+		StateSelectProjectType:   list,
+		StateSelectDocStyle:      list,
+		StateSelectInputDir:      helpEntries(k.Up, k.Down, k.Enter, k.ParentDir, k.UseDir, k.ToggleFile, k.ToggleAll, k.Filter, k.Preview, k.DryRun, k.ManualEntry),
+		StateEnterInputDir:       textEntry,
+		StateEnterOutputDir:      textEntry,
+		StateEnterExcludePaths:   textEntry,
+		StateProcessing:          helpEntries(k.Pause, k.Verbose),
+		StatePaused:              helpEntries(k.Pause, k.Verbose),
+		StateDone:                helpEntries(k.Up, k.Down, k.Retry, k.Stats, k.ScrollUp, k.ScrollDown),
+		StateStats:               helpEntries(k.Back),
+		StateStopping:            helpEntries(k.ForceQuit),
+		StatePreview:             scroll,
+		StateDryRunPreview:       append(helpEntries(k.Proceed), scroll...),
+		StateBudgetExceeded:      helpEntries(k.IgnoreBudget, k.StopBudget, k.ResumeLater),
+	}
+}
+
+// helpContent lists the keybindings for the current state followed by the global ones
+func (m Model) helpContent() string {
+	entries := m.keys.stateHelp()[m.state]
+	if m.state == StateSelectInputDir && m.filteringDirs {
+		entries = helpEntries(m.keys.Up, m.keys.Down, m.keys.Enter, m.keys.Backspace, m.keys.ClearFilter)
+	}
+
+	var sb strings.Builder
+	for _, entry := range entries {
+		sb.WriteString(fmt.Sprintf("%-12s %s\n", entry.Key, entry.Description))
+	}
+	sb.WriteString("\n" + selectedStyle.Render("Global") + "\n")
+	for _, entry := range helpEntries(m.keys.Help, m.keys.Quit) {
+		sb.WriteString(fmt.Sprintf("%-12s %s\n", entry.Key, entry.Description))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// toggleHelp opens or closes the help overlay
+func (m *Model) toggleHelp() {
+	m.showHelp = !m.showHelp
+	if m.showHelp {
+		m.help = viewport.New(0, 0)
+		m.resizeHelp()
+	}
+}
+
+// resizeHelp fits the help overlay's viewport to its content and the terminal
+func (m *Model) resizeHelp() {
+	content := m.helpContent()
+	width, height := lipgloss.Width(content), lipgloss.Height(content)
+
+	// Leave room for the box border, title and hint, and the status bar
+	if maxHeight := m.terminalHeight() - 8 - statusBarHeight; height > maxHeight {
+		height = max(maxHeight, 3)
+	}
+	m.help.Width = width
+	m.help.Height = height
+	m.help.SetContent(content)
+}
+
+// terminalHeight returns the terminal height, assuming 24 rows before the first resize
+func (m Model) terminalHeight() int {
+	if m.height > 0 {
+		return m.height
+	}
+	return 24
+}
+
+// terminalWidth returns the terminal width, assuming 80 columns before the first resize
+func (m Model) terminalWidth() int {
+	if m.width > 0 {
+		return m.width
+	}
+	return 80
+}
+
+// renderHelpOverlay draws the help box centered over background, which is dimmed
+func (m Model) renderHelpOverlay(background string) string {
+	// Refresh the content in case the state changed while the overlay was open
+	help := m.help
+	help.SetContent(m.helpContent())
+
+	hint := "? or esc to close"
+	if help.TotalLineCount() > help.Height {
+		hint = "↑/↓ to scroll, " + hint
+	}
+	box := helpBoxStyle.Render(
+		selectedStyle.Render("Keyboard shortcuts") + "\n\n" +
+			help.View() + "\n\n" +
+			dimStyle.Render(hint))
+
+	width, height := m.terminalWidth(), m.terminalHeight()
+	boxLines := strings.Split(box, "\n")
+	boxWidth := lipgloss.Width(box)
+	left := max((width-boxWidth)/2, 0)
+	top := max((height-len(boxLines))/2, 0)
+
+	// The background loses its colors and is redrawn dimmed around the box
+	lines := strings.Split(ansi.Strip(background), "\n")
+	for len(lines) < max(height, top+len(boxLines)) {
+		lines = append(lines, "")
+	}
+
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		if i < top || i >= top+len(boxLines) {
+			sb.WriteString(dimStyle.Render(line))
+			continue
+		}
+
+		line += strings.Repeat(" ", max(left+boxWidth-ansi.StringWidth(line), 0))
+		sb.WriteString(dimStyle.Render(ansi.Truncate(line, left, "")))
+		sb.WriteString(boxLines[i-top])
+		sb.WriteString(dimStyle.Render(ansi.TruncateLeft(line, left+boxWidth, "")))
+	}
+	return sb.String()
+}
@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// KeyMap defines the keybindings used by the TUI. Update matches keys against it and
+// the help overlay is rendered from it, so the two cannot drift apart.
+type KeyMap struct {
+	Up           key.Binding
+	Down         key.Binding
+	NextField    key.Binding
+	Enter        key.Binding
+	Backspace    key.Binding
+	UseDir       key.Binding
+	ParentDir    key.Binding
+	ToggleFile   key.Binding
+	ToggleAll    key.Binding
+	ManualEntry  key.Binding
+	Filter       key.Binding
+	Preview      key.Binding
+	Pause        key.Binding
+	Verbose      key.Binding
+	DryRun       key.Binding
+	Proceed      key.Binding
+	Retry        key.Binding
+	Stats        key.Binding
+	ScrollUp     key.Binding
+	ScrollDown   key.Binding
+	IgnoreBudget key.Binding
+	StopBudget   key.Binding
+	ResumeLater  key.Binding
+	ClearFilter  key.Binding
+	Back         key.Binding
+	Yes          key.Binding
+	No           key.Binding
+	Theme        key.Binding
+	Delete       key.Binding
+	Help         key.Binding
+	Quit         key.Binding
+	ForceQuit    key.Binding
+}
+
+// DefaultKeyMap returns the default keybindings
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Up: key.NewBinding(
+			key.WithKeys("up", "k"),
+			key.WithHelp("↑/k", "move up"),
+		),
+		Down: key.NewBinding(
+			key.WithKeys("down", "j"),
+			key.WithHelp("↓/j", "move down"),
+		),
+		NextField: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "next field"),
+		),
+		Enter: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "select / confirm"),
+		),
+		Backspace: key.NewBinding(
+			key.WithKeys("backspace"),
+			key.WithHelp("backspace", "delete character"),
+		),
+		UseDir: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "use current directory (on a directory entry)"),
+		),
+		ParentDir: key.NewBinding(
+			key.WithKeys("left"),
+			key.WithHelp("←", "go to parent directory"),
+		),
+		ToggleFile: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "select file (on a file entry)"),
+		),
+		ToggleAll: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "select / deselect all files"),
+		),
+		ManualEntry: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "type path manually"),
+		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter entries"),
+		),
+		Preview: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "preview file"),
+		),
+		DryRun: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "dry run: list files and estimated cost"),
+		),
+		Proceed: key.NewBinding(
+			key.WithKeys("p", "P"),
+			key.WithHelp("p", "proceed: choose the output directory and document these files"),
+		),
+		Retry: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "retry selected failed file"),
+		),
+		Stats: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "show statistics"),
+		),
+		ScrollUp: key.NewBinding(
+			key.WithKeys("pgup"),
+			key.WithHelp("pgup", "scroll the last file's source up"),
+		),
+		ScrollDown: key.NewBinding(
+			key.WithKeys("pgdown"),
+			key.WithHelp("pgdown", "scroll the last file's source down"),
+		),
+		Pause: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "pause / resume"),
+		),
+		Verbose: key.NewBinding(
+			key.WithKeys("v"),
+			key.WithHelp("v", "show / hide the last files with timings"),
+		),
+		IgnoreBudget: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "continue, ignoring the token budget"),
+		),
+		StopBudget: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "stop and save checkpoint"),
+		),
+		ResumeLater: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", "save the settings and resume in a new session"),
+		),
+		ClearFilter: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "clear filter"),
+		),
+		Back: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "go back"),
+		),
+		Yes: key.NewBinding(
+			key.WithKeys("y", "Y"),
+			key.WithHelp("y", "yes"),
+		),
+		No: key.NewBinding(
+			key.WithKeys("n", "N", "esc"),
+			key.WithHelp("n/esc", "no"),
+		),
+		Theme: key.NewBinding(
+			key.WithKeys("t"),
+			key.WithHelp("t", "change color theme (start screen)"),
+		),
+		Delete: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "delete selected profile"),
+		),
+		Help: key.NewBinding(
+			key.WithKeys("?"),
+			key.WithHelp("?", "toggle help"),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("ctrl+c", "q"),
+			key.WithHelp("q/ctrl+c", "quit (q only outside text fields)"),
+		),
+		ForceQuit: key.NewBinding(
+			key.WithKeys("ctrl+c"),
+			key.WithHelp("ctrl+c", "quit immediately while stopping"),
+		),
+	}
+}
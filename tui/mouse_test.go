@@ -0,0 +1,26 @@
+package tui
+
+import "testing"
+
+func TestEntryAtRow(t *testing.T) {
+	tests := []struct {
+		name                                string
+		y, headerRows, startIndex, endIndex int
+		want                                int
+	}{
+		{"first entry", 3, 3, 0, 10, 0},
+		{"middle entry", 7, 3, 0, 10, 4},
+		{"last entry", 12, 3, 0, 10, 9},
+		{"scrolled to the first visible entry", 3, 3, 20, 30, 20},
+		{"scrolled", 8, 3, 20, 30, 25},
+		{"header row", 2, 3, 20, 30, -1},
+		{"top of the screen", 0, 3, 0, 10, -1},
+		{"below the last entry", 13, 3, 0, 10, -1},
+		{"below a short list", 5, 3, 0, 2, -1},
+	}
+	for _, tt := range tests {
+		if got := entryAtRow(tt.y, tt.headerRows, tt.startIndex, tt.endIndex); got != tt.want {
+			t.Errorf("%s: entryAtRow(%d, %d, %d, %d) = %d, want %d", tt.name, tt.y, tt.headerRows, tt.startIndex, tt.endIndex, got, tt.want)
+		}
+	}
+}
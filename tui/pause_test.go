@@ -0,0 +1,76 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/filehandler"
+)
+
+func TestPauseAndResumeUntilDone(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	m := NewModel()
+	m.config.APIModel = "gpt-4o"
+	m.inputDir = inputDir
+	m.outputDir = outputDir
+	m.apiClient = api.NewMockClient(nil)
+	var err error
+	if m.checksums, err = filehandler.LoadChecksumStore(outputDir); err != nil {
+		t.Fatal(err)
+	}
+	if m.freshness, err = filehandler.LoadFreshnessStore(outputDir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a", "b"} {
+		path := filepath.Join(inputDir, name+".go")
+		content := strings.Repeat("func "+name+"() {}\n", 200)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		m.files = append(m.files, filehandler.FileInfo{Path: path, Content: content, Checksum: filehandler.Checksum(content)})
+	}
+	m.packages = filehandler.PackageNames(inputDir, m.files, m.projectType)
+	m.packageSizes = map[string]int{m.packages[m.files[0].Path]: len(m.files)}
+	m.packageLeft = map[string]int{m.packages[m.files[0].Path]: len(m.files)}
+	m.state = StateProcessing
+
+	model, _ := m.Pause()
+	paused := model.(Model)
+	if paused.state != StatePaused {
+		t.Fatalf("state after Pause = %v, want StatePaused", paused.state)
+	}
+	if paused.pausedRemainingTokens != paused.remainingTokens() || paused.pausedRemainingTokens == 0 {
+		t.Errorf("pausedRemainingTokens = %d, want the %d tokens of both prompts", paused.pausedRemainingTokens, paused.remainingTokens())
+	}
+
+	cost := fmt.Sprintf("(~$%.4f)", api.EstimateCost("gpt-4o", paused.pausedRemainingTokens))
+	if view := paused.View(); !strings.Contains(view, cost) {
+		t.Errorf("paused view does not show the estimated cost %s:\n%s", cost, view)
+	}
+
+	model, _ = paused.Resume()
+	if state := model.(Model).state; state != StateProcessing {
+		t.Fatalf("state after Resume = %v, want StateProcessing", state)
+	}
+
+	// Resume started the files again; finish them as their commands would
+	for i := range m.files {
+		if state := model.(Model).state; state != StateProcessing {
+			t.Fatalf("state before file %d finished = %v, want StateProcessing", i, state)
+		}
+		model, _ = model.Update(continueProcessingAt(m.files, i, model.(Model))())
+	}
+	done := model.(Model)
+	if done.state != StateDone {
+		t.Errorf("state after every file finished = %v, want StateDone", done.state)
+	}
+	if done.processedFiles != len(m.files) || len(done.errors) != 0 {
+		t.Errorf("processed %d files with errors %v, want %d without errors", done.processedFiles, done.errors, len(m.files))
+	}
+}
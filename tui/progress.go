@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxRecentFiles is the number of finished files listed in verbose mode
+const maxRecentFiles = 10
+
+// maxStatusLength is the number of characters of an error kept in a progress line
+const maxStatusLength = 60
+
+// Statuses of a finished file other than an error
+const (
+	statusDocumented = "documented"
+	statusUnchanged  = "unchanged"
+	statusTooLarge   = "too large"
+)
+
+// ProgressDetail records how a finished file went, for the verbose progress log
+type ProgressDetail struct {
+	Path       string
+	Duration   time.Duration
+	Status     string // "documented", "unchanged", "too large", or "error: " and the reason
+	TokensUsed int    // Estimated prompt and response tokens, 0 when no API call was made
+}
+
+// Failed reports whether the file could not be documented
+func (d ProgressDetail) Failed() bool {
+	return strings.HasPrefix(d.Status, "error")
+}
+
+// String renders the detail as a progress line such as "✓ main.go (1.2s)" or
+// "✗ utils.go (error: rate limit)"
+func (d ProgressDetail) String() string {
+	switch {
+	case d.Failed():
+		return fmt.Sprintf("✗ %s (%s)", d.Path, d.Status)
+	case d.Status == statusUnchanged || d.Status == statusTooLarge:
+		return fmt.Sprintf("- %s (%s)", d.Path, d.Status)
+	case d.TokensUsed > 0:
+		return fmt.Sprintf("✓ %s (%s, ~%d tokens)", d.Path, formatSeconds(d.Duration), d.TokensUsed)
+	default:
+		return fmt.Sprintf("✓ %s (%s)", d.Path, formatSeconds(d.Duration))
+	}
+}
+
+// formatSeconds formats d in seconds to one decimal place
+func formatSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// errorReason returns the reason a file failed with message, leaving out the
+// "Failed to ... for <path>: " prefix the error messages start with
+func errorReason(path, message string) string {
+	if i := strings.Index(message, path+": "); i >= 0 {
+		message = message[i+len(path)+2:]
+	}
+	return message
+}
+
+// errorStatus returns the status of a file that failed with message, shortened to
+// maxStatusLength characters
+func errorStatus(path, message string) string {
+	message = errorReason(path, message)
+	runes := []rune(message)
+	if len(runes) > maxStatusLength {
+		message = string(runes[:maxStatusLength-1]) + "…"
+	}
+	return "error: " + message
+}
+
+// progressLogErrorMsg reports that the log file could not be written
+type progressLogErrorMsg struct {
+	err error
+}
+
+// recordProgress adds detail to the recently finished files, with its path made relative to
+// the input directory, dropping the oldest beyond maxRecentFiles. It returns a command
+// appending the detail to the log file if one is set.
+func (m *Model) recordProgress(detail ProgressDetail) tea.Cmd {
+	if relPath, err := filepath.Rel(m.inputDir, detail.Path); err == nil {
+		detail.Path = relPath
+	}
+	m.recentFiles = append(m.recentFiles, detail)
+	if len(m.recentFiles) > maxRecentFiles {
+		m.recentFiles = m.recentFiles[len(m.recentFiles)-maxRecentFiles:]
+	}
+	// The next file's time runs from here when several are in flight
+	m.fileStartTime = time.Now()
+
+	logFile := m.config.LogFile
+	if logFile == "" || m.logFileFailed {
+		return nil
+	}
+	line := time.Now().Format(time.RFC3339) + " " + detail.String() + "\n"
+	return func() tea.Msg {
+		f, err := os.OpenFile(logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return progressLogErrorMsg{err: err}
+		}
+		defer f.Close()
+		if _, err := f.WriteString(line); err != nil {
+			return progressLogErrorMsg{err: err}
+		}
+		return nil
+	}
+}
+
+// renderRecentFiles renders the progress lines of the recently finished files, oldest first,
+// followed by the file currently being documented
+func (m Model) renderRecentFiles() string {
+	var sb strings.Builder
+	sb.WriteString(fileStyle.Render("Recent files:") + "\n")
+	if len(m.recentFiles) == 0 {
+		sb.WriteString(dimStyle.Render("  none finished yet") + "\n")
+	}
+	for _, detail := range m.recentFiles {
+		line := "  " + detail.String()
+		if detail.Failed() {
+			sb.WriteString(errorStyle.Render(line) + "\n")
+		} else {
+			sb.WriteString(line + "\n")
+		}
+	}
+	if m.inFlight > 0 {
+		sb.WriteString(dimStyle.Render(fmt.Sprintf("  … %d in progress (%s)", m.inFlight, formatSeconds(time.Since(m.fileStartTime)))) + "\n")
+	}
+	return sb.String() + "\n"
+}
+
+// fileProgress renders the file being documented, or the recently finished files in
+// verbose mode
+func (m Model) fileProgress() string {
+	if m.config.VerboseProgress {
+		return m.renderRecentFiles()
+	}
+	return fileStyle.Render("Current file: "+m.currentFile) + "\n\n"
+}
@@ -0,0 +1,135 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/types"
+	"github.com/charmbracelet/bubbletea"
+)
+
+// Backoff for files that failed with a transient API error
+const (
+	retryBaseDelay = time.Second
+	retryMaxDelay  = 60 * time.Second
+)
+
+// retryEntry is a file waiting to be retried after a transient error
+type retryEntry struct {
+	file    filehandler.FileInfo
+	index   int       // Index of the file in Model.files
+	retries int       // Retries made so far, including the one this entry is waiting for
+	retryAt time.Time // When the file is dispatched again
+}
+
+// retryDueMsg is sent when the earliest queued retry is due
+type retryDueMsg struct{}
+
+// isTransient reports whether err is worth retrying automatically: a rate limit or network error
+func isTransient(err error) bool {
+	return errors.Is(err, types.ErrRateLimit) || errors.Is(err, types.ErrNetworkError)
+}
+
+// retryDelay returns the backoff before the given retry: 1s, 2s, 4s, ... up to a minute
+func retryDelay(retries int) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < retries; i++ {
+		delay *= 2
+		if delay >= retryMaxDelay {
+			return retryMaxDelay
+		}
+	}
+	return delay
+}
+
+// now returns the current time from the model's clock
+func (m Model) now() time.Time {
+	if m.clock != nil {
+		return m.clock()
+	}
+	return time.Now()
+}
+
+// queueRetry schedules the file at index to be processed again. It returns false once the
+// file has used up config.MaxRetries, in which case the error should be treated as permanent.
+func (m *Model) queueRetry(index int) bool {
+	if m.retryCounts == nil {
+		m.retryCounts = make(map[int]int)
+	}
+	retries := m.retryCounts[index] + 1
+	if retries > m.config.MaxRetries {
+		return false
+	}
+	m.retryCounts[index] = retries
+
+	m.errorRetryQueue = append(m.errorRetryQueue, retryEntry{
+		file:    m.files[index],
+		index:   index,
+		retries: retries,
+		retryAt: m.now().Add(retryDelay(retries)),
+	})
+	return true
+}
+
+// scheduleRetryTick returns a command that fires when the earliest queued retry is due
+func (m Model) scheduleRetryTick() tea.Cmd {
+	if len(m.errorRetryQueue) == 0 {
+		return nil
+	}
+
+	earliest := m.errorRetryQueue[0].retryAt
+	for _, entry := range m.errorRetryQueue[1:] {
+		if entry.retryAt.Before(earliest) {
+			earliest = entry.retryAt
+		}
+	}
+
+	return tea.Tick(earliest.Sub(m.now()), func(time.Time) tea.Msg {
+		return retryDueMsg{}
+	})
+}
+
+// dispatchDueRetries starts every queued file whose retry is due. Nothing is started while paused.
+func (m *Model) dispatchDueRetries() []tea.Cmd {
+	if m.state == StatePaused || m.state == StateBudgetExceeded {
+		return nil
+	}
+
+	now := m.now()
+	var cmds []tea.Cmd
+	var waiting []retryEntry
+	for _, entry := range m.errorRetryQueue {
+		if entry.retryAt.After(now) {
+			waiting = append(waiting, entry)
+			continue
+		}
+		cmds = append(cmds, continueProcessingAt(m.files, entry.index, *m))
+		m.inFlight++
+	}
+	m.errorRetryQueue = waiting
+	return cmds
+}
+
+// renderRetryQueue renders a countdown for each file waiting to be retried
+func (m Model) renderRetryQueue() string {
+	if len(m.errorRetryQueue) == 0 {
+		return ""
+	}
+
+	result := ""
+	for _, entry := range m.errorRetryQueue {
+		wait := entry.retryAt.Sub(m.now()).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		name, err := filepath.Rel(m.inputDir, entry.file.Path)
+		if err != nil {
+			name = entry.file.Path
+		}
+		result += infoStyle.Render(fmt.Sprintf("↻ Retrying %s in %ds (attempt %d of %d)", name, int(wait.Seconds()), entry.retries, m.config.MaxRetries)) + "\n"
+	}
+	return result + "\n"
+}
@@ -0,0 +1,72 @@
+package tui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Abiggj/structura/filehandler"
+)
+
+// fakeClock is a clock that only moves when told to
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestRetryDelay(t *testing.T) {
+	want := []time.Duration{1, 2, 4, 8, 16, 32, 60, 60}
+	for i, w := range want {
+		if got := retryDelay(i + 1); got != w*time.Second {
+			t.Errorf("retryDelay(%d) = %v, want %v", i+1, got, w*time.Second)
+		}
+	}
+}
+
+func TestRetryBackoffProgression(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	m := NewModel()
+	m.clock = clock.Now
+	m.config.MaxRetries = 3
+	m.outputDir = t.TempDir()
+	m.files = []filehandler.FileInfo{{Path: "a.go"}}
+	m.packageLeft = map[string]int{"": 1}
+	m.state = StateProcessing
+	m.inFlight = 1
+
+	for retry, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		model, cmd := m.Update(fileErrorMsg{index: 0, err: "rate limited", transient: true})
+		m = model.(Model)
+		if cmd == nil || len(m.errorRetryQueue) != 1 {
+			t.Fatalf("retry %d: transient error was not queued", retry+1)
+		}
+		if len(m.errors) != 0 {
+			t.Fatalf("retry %d: transient error recorded as permanent: %v", retry+1, m.errors)
+		}
+		if got := m.errorRetryQueue[0].retryAt.Sub(clock.Now()); got != delay {
+			t.Errorf("retry %d: due in %v, want %v", retry+1, got, delay)
+		}
+
+		// Nothing is dispatched until the backoff has passed
+		clock.Advance(delay - time.Millisecond)
+		if cmds := m.dispatchDueRetries(); len(cmds) != 0 {
+			t.Fatalf("retry %d: dispatched %v before it was due", retry+1, delay-time.Millisecond)
+		}
+		clock.Advance(time.Millisecond)
+		if cmds := m.dispatchDueRetries(); len(cmds) != 1 || len(m.errorRetryQueue) != 0 {
+			t.Fatalf("retry %d: not dispatched once due", retry+1)
+		}
+	}
+
+	// With MaxRetries used up the error is permanent
+	model, _ := m.Update(fileErrorMsg{index: 0, err: "rate limited", transient: true})
+	m = model.(Model)
+	if len(m.errorRetryQueue) != 0 {
+		t.Error("file queued again after MaxRetries retries")
+	}
+	if len(m.failedFiles) != 1 || len(m.errors) != 1 {
+		t.Errorf("failed files %d, errors %d after MaxRetries retries, want 1 each", len(m.failedFiles), len(m.errors))
+	}
+}
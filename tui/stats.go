@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/docs"
+)
+
+// fileDuration is the time taken to document one file
+type fileDuration struct {
+	path     string
+	duration time.Duration
+}
+
+// processingStats accumulates the figures shown on the statistics screen
+type processingStats struct {
+	apiCalls      int
+	tokens        int // Estimated prompt and response tokens
+	lintFailures  int // Files whose documentation failed the Markdown checks
+	unchanged     int // Files skipped because their documentation was up to date
+	tooLarge      int // Files skipped for being too large to document
+	promptVersion int // Unchanged files documented again for an outdated prompt version
+	durations     []fileDuration
+}
+
+// record adds a documented file to the statistics. Skipped files made no API call.
+func (s *processingStats) record(msg fileProcessedMsg) {
+	if !msg.apiCall {
+		return
+	}
+	s.apiCalls++
+	s.tokens += msg.tokens
+	if msg.lintErrors > 0 {
+		s.lintFailures++
+	}
+	if msg.promptVersionChanged {
+		s.promptVersion++
+	}
+	s.durations = append(s.durations, fileDuration{path: msg.path, duration: msg.duration})
+}
+
+// averageDuration returns the mean time taken per documented file
+func (s processingStats) averageDuration() time.Duration {
+	if len(s.durations) == 0 {
+		return 0
+	}
+
+	var total time.Duration
+	for _, d := range s.durations {
+		total += d.duration
+	}
+	return total / time.Duration(len(s.durations))
+}
+
+// slowest returns up to n files that took the longest, slowest first
+func (s processingStats) slowest(n int) []fileDuration {
+	sorted := append([]fileDuration(nil), s.durations...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].duration > sorted[j].duration
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// renderStats renders the statistics screen body
+func (m Model) renderStats() string {
+	result := fmt.Sprintf("Files processed:        %d\n", m.processedFiles)
+	result += fmt.Sprintf("API calls (files):      %d\n", m.stats.apiCalls)
+	result += fmt.Sprintf("Cache hits:             %d\n", api.CacheHits())
+	result += fmt.Sprintf("Estimated tokens:       ~%d\n", m.stats.tokens)
+	result += fmt.Sprintf("Estimated cost:         ~$%.4f\n", api.EstimateCost(m.config.GetActiveModel(), m.stats.tokens))
+	result += fmt.Sprintf("Average time per file:  %s\n", m.stats.averageDuration().Round(time.Millisecond))
+	if m.config.LintOutput {
+		result += fmt.Sprintf("Failed Markdown checks: %d files\n", m.stats.lintFailures)
+	}
+	if m.config.Usage != nil {
+		result += "\n" + renderUsage(m.config.Usage.Run(), m.config.Usage.AllTime())
+	}
+
+	if slowest := m.stats.slowest(5); len(slowest) > 0 {
+		result += "\nSlowest files:\n"
+		for _, d := range slowest {
+			relPath, err := filepath.Rel(m.inputDir, d.path)
+			if err != nil {
+				relPath = d.path
+			}
+			result += fmt.Sprintf("  %8s  %s\n", d.duration.Round(time.Millisecond), fileStyle.Render(relPath))
+		}
+	}
+
+	return result
+}
+
+// coverageStats returns the outcomes of the run so far, for the coverage report
+func (m Model) coverageStats() docs.ProcessingStats {
+	stats := docs.ProcessingStats{
+		Documented:       m.stats.apiCalls,
+		SkippedUnchanged: m.stats.unchanged,
+		SkippedTooLarge:  m.stats.tooLarge,
+		MinScore:         m.config.MinDocScore,
+	}
+	if m.fileHandler != nil {
+		stats.SkippedIgnored = m.fileHandler.IgnoredCount()
+	}
+	for _, file := range m.files {
+		if !file.IsDir {
+			stats.Discovered++
+		}
+	}
+	for i, file := range m.failedFiles {
+		relPath, err := filepath.Rel(m.inputDir, file.Path)
+		if err != nil {
+			relPath = file.Path
+		}
+		stats.Failures = append(stats.Failures, docs.FileFailure{
+			Path:  filepath.ToSlash(relPath),
+			Error: errorReason(file.Path, m.failedFileErrors[i]),
+		})
+	}
+	return stats
+}
+
+// renderUsage renders the API usage of this run beside the usage accumulated across runs
+func renderUsage(run, allTime config.UsageStats) string {
+	result := fmt.Sprintf("%-24s%-16s%s\n", "API usage:", "This run", "All time")
+	result += fmt.Sprintf("%-24s%-16d%d\n", "  API calls", run.TotalAPICallsMade, allTime.TotalAPICallsMade)
+	result += fmt.Sprintf("%-24s%-16s%s\n", "  Estimated tokens", fmt.Sprintf("~%d", run.TotalTokensEstimated), fmt.Sprintf("~%d", allTime.TotalTokensEstimated))
+	result += fmt.Sprintf("%-24s%-16s%s\n", "  Estimated cost", fmt.Sprintf("~$%.4f", run.TotalCostEstimated), fmt.Sprintf("~$%.4f", allTime.TotalCostEstimated))
+	for _, name := range allTime.ProviderNames() {
+		result += fmt.Sprintf("%-24s%-16d%d\n", "  "+name+" calls", run.Providers[name].APICalls, allTime.Providers[name].APICalls)
+	}
+	return result
+}
@@ -0,0 +1,106 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Abiggj/structura/api"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// statusBarHeight is the number of lines the status bar takes at the bottom of the screen
+const statusBarHeight = 1
+
+// stateNames are the names shown in the status bar for each state
+var stateNames = map[State]string{
+	StateInit:                "Start",
+	StateSelectTheme:         "Theme",
+	StateSelectProfile:       "Profile",
+	StateSelectAPIType:       "API type",
+	StateSelectAPIModel:      "Model",
+	StateEnterCustomEndpoint: "Custom endpoint",
+	StateEnterCustomModel:    "Custom model",
+	StateEnterAPIKey:         "API key",
+	StateSaveKeyPrompt:       "Save API key",
+	StateAdvancedSettings:    "Advanced settings",
+	StateSelectProjectType:   "Project type",
+	StateSelectDocStyle:      "Documentation style",
+	StateSelectInputDir:      "Input directory",
+	StateEnterInputDir:       "Input directory",
+	StateEnterOutputDir:      "Output directory",
+	StateEnterExcludePaths:   "Exclude paths",
+	StateProcessing:          "Processing",
+	StatePaused:              "Paused",
+	StateDone:                "Done",
+	StateStats:               "Statistics",
+	StateStopping:            "Stopping",
+	StatePreview:             "Preview",
+	StateDryRunPreview:       "Dry run",
+	StateBudgetExceeded:      "Token budget exceeded",
+}
+
+// String returns the name of the state shown in the status bar
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// StatusBar is the line at the bottom of every screen, with text on the left, center and right
+type StatusBar struct {
+	Left   string
+	Center string
+	Right  string
+	Width  int // Width of the terminal; the bar spans all of it
+}
+
+// View renders the status bar. Sections that do not fit the width are cut, the center first.
+func (s StatusBar) View() string {
+	width := s.Width
+	if width <= 0 {
+		width = 80
+	}
+
+	// The right section is kept whole if it fits a third of the width, the left one if it fits
+	// half of the rest, and the center gets what is left
+	right := ansi.Truncate(" "+s.Right+" ", width/3, "…")
+	left := ansi.Truncate(" "+s.Left+" ", (width-lipgloss.Width(right))/2, "…")
+	centerWidth := width - lipgloss.Width(left) - lipgloss.Width(right)
+	center := ansi.Truncate(s.Center, centerWidth, "…")
+
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		statusBarStyle.Render(left),
+		statusBarStyle.Width(centerWidth).Align(lipgloss.Center).Render(center),
+		statusBarStyle.Render(right),
+	)
+}
+
+// statusBar returns the status bar for the current state
+func (m Model) statusBar() StatusBar {
+	var right []string
+	if limiter := api.ClientRateLimiter(m.apiClient); limiter != nil {
+		right = append(right, fmt.Sprintf("rate %d%%", int(limiter.CurrentFill()*100)))
+	}
+	if !m.processingStartTime.IsZero() {
+		right = append(right, m.processingElapsed().Round(time.Second).String())
+	}
+
+	return StatusBar{
+		Left:   fmt.Sprintf("%s / %s", m.config.APIType, m.config.GetActiveModel()),
+		Center: m.state.String(),
+		Right:  strings.Join(right, " · "),
+		Width:  m.terminalWidth(),
+	}
+}
+
+// withStatusBar appends the status bar to a screen, on the last line of the terminal
+// when the screen is shorter than it
+func (m Model) withStatusBar(view string) string {
+	if gap := m.terminalHeight() - statusBarHeight - lipgloss.Height(view); gap > 0 && m.height > 0 {
+		view += strings.Repeat("\n", gap)
+	}
+	return view + "\n" + m.statusBar().View()
+}
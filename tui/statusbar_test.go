@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestStatusBarView(t *testing.T) {
+	bar := StatusBar{
+		Left:   "openai / gpt-4o-mini",
+		Center: "Processing",
+		Right:  "rate 40% · 1m30s",
+	}
+
+	tests := []struct {
+		width     int
+		contains  []string
+		truncated bool
+	}{
+		{40, []string{"rate 40%"}, true},
+		{80, []string{"openai / gpt-4o-mini", "Processing", "rate 40% · 1m30s"}, false},
+		{120, []string{"openai / gpt-4o-mini", "Processing", "rate 40% · 1m30s"}, false},
+	}
+	for _, tt := range tests {
+		bar.Width = tt.width
+		view := bar.View()
+		text := ansi.Strip(view)
+
+		if lines := strings.Count(view, "\n") + 1; lines != statusBarHeight {
+			t.Errorf("width %d: status bar has %d lines, want %d:\n%s", tt.width, lines, statusBarHeight, text)
+		}
+		if got := lipgloss.Width(view); got != tt.width {
+			t.Errorf("width %d: status bar is %d columns wide:\n%s", tt.width, got, text)
+		}
+		for _, want := range tt.contains {
+			if !strings.Contains(text, want) {
+				t.Errorf("width %d: status bar %q is missing %q", tt.width, text, want)
+			}
+		}
+		if truncated := strings.Contains(text, "…"); truncated != tt.truncated {
+			t.Errorf("width %d: status bar %q truncated = %v, want %v", tt.width, text, truncated, tt.truncated)
+		}
+	}
+}
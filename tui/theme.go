@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme defines the colors used by the TUI
+type Theme struct {
+	Name          string
+	Primary       lipgloss.Color // Accents such as the spinner and progress bar
+	Success       lipgloss.Color // Informational and success messages
+	Error         lipgloss.Color // Error messages
+	FileHighlight lipgloss.Color // File paths and file contents
+	Background    lipgloss.Color // Background of the title and selected items
+	Text          lipgloss.Color // Text drawn on the Background color
+}
+
+var (
+	// ThemePurple is the default theme
+	ThemePurple = Theme{
+		Name:          "Purple",
+		Primary:       lipgloss.Color("#7D56F4"),
+		Success:       lipgloss.Color("#43BF6D"),
+		Error:         lipgloss.Color("#FF5555"),
+		FileHighlight: lipgloss.Color("#61AFEF"),
+		Background:    lipgloss.Color("#7D56F4"),
+		Text:          lipgloss.Color("#FAFAFA"),
+	}
+
+	// ThemeOcean uses blues and teals
+	ThemeOcean = Theme{
+		Name:          "Ocean",
+		Primary:       lipgloss.Color("#0EA5E9"),
+		Success:       lipgloss.Color("#2DD4BF"),
+		Error:         lipgloss.Color("#F87171"),
+		FileHighlight: lipgloss.Color("#7DD3FC"),
+		Background:    lipgloss.Color("#0F766E"),
+		Text:          lipgloss.Color("#F0FDFA"),
+	}
+
+	// ThemeDracula follows the Dracula color palette
+	ThemeDracula = Theme{
+		Name:          "Dracula",
+		Primary:       lipgloss.Color("#BD93F9"),
+		Success:       lipgloss.Color("#50FA7B"),
+		Error:         lipgloss.Color("#FF5555"),
+		FileHighlight: lipgloss.Color("#8BE9FD"),
+		Background:    lipgloss.Color("#44475A"),
+		Text:          lipgloss.Color("#F8F8F2"),
+	}
+)
+
+// Themes returns the built-in themes in the order they are offered
+func Themes() []Theme {
+	return []Theme{ThemePurple, ThemeOcean, ThemeDracula}
+}
+
+// ApplyTheme rebuilds the TUI styles from the theme's colors
+func ApplyTheme(theme Theme) {
+	titleStyle = lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Background(theme.Background).
+		Bold(true).
+		Padding(0, 1)
+
+	infoStyle = lipgloss.NewStyle().
+		Foreground(theme.Success).
+		Padding(1, 0)
+
+	errorStyle = lipgloss.NewStyle().
+		Foreground(theme.Error)
+
+	fileStyle = lipgloss.NewStyle().
+		Foreground(theme.FileHighlight)
+
+	progressBarStyle = lipgloss.NewStyle().
+		Foreground(theme.Primary).
+		Padding(0, 1)
+
+	selectedStyle = lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Background(theme.Background).
+		Bold(true).
+		Padding(0, 1)
+
+	spinnerStyle = lipgloss.NewStyle().
+		Foreground(theme.Primary)
+
+	dimStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6C6C6C"))
+
+	statusBarStyle = lipgloss.NewStyle().
+		Foreground(theme.Text).
+		Background(theme.Background)
+
+	helpBoxStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Primary).
+		Padding(1, 2)
+
+	filePreviewStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Primary).
+		Padding(0, 1)
+
+	codeKeywordStyle = lipgloss.NewStyle().
+		Foreground(theme.Primary).
+		Bold(true)
+
+	codeStringStyle = lipgloss.NewStyle().
+		Foreground(theme.Success)
+
+	codeNumberStyle = lipgloss.NewStyle().
+		Foreground(theme.FileHighlight)
+
+	codeCommentStyle = lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#6C6C6C")).
+		Italic(true)
+}
@@ -1,48 +1,55 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Abiggj/structura/api"
 	"github.com/Abiggj/structura/config"
+	"github.com/Abiggj/structura/docs"
 	"github.com/Abiggj/structura/filehandler"
+	"github.com/Abiggj/structura/frontmatter"
+	"github.com/Abiggj/structura/jsonoutput"
+	"github.com/Abiggj/structura/linker"
+	"github.com/Abiggj/structura/linter"
+	"github.com/Abiggj/structura/output"
+	"github.com/Abiggj/structura/output/s3"
+	"github.com/Abiggj/structura/notification"
+	"github.com/Abiggj/structura/search"
+	"github.com/Abiggj/structura/techdebt"
+	"github.com/Abiggj/structura/tokenizer"
 	"github.com/Abiggj/structura/types"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	"github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-// Styling
+// Styling, set by ApplyTheme
 var (
-	titleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
-			Bold(true).
-			Padding(0, 1)
-
-	infoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#43BF6D")).
-			Padding(1, 0)
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FF5555"))
-
-	fileStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#61AFEF"))
-
-	progressBarStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#7D56F4")).
-			Padding(0, 1)
-			
-	selectedStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("#FAFAFA")).
-			Background(lipgloss.Color("#7D56F4")).
-			Bold(true).
-			Padding(0, 1)
+	titleStyle       lipgloss.Style
+	infoStyle        lipgloss.Style
+	errorStyle       lipgloss.Style
+	fileStyle        lipgloss.Style
+	progressBarStyle lipgloss.Style
+	selectedStyle    lipgloss.Style
+	spinnerStyle     lipgloss.Style
+	dimStyle         lipgloss.Style
+	statusBarStyle   lipgloss.Style
+	helpBoxStyle     lipgloss.Style
+	filePreviewStyle lipgloss.Style
+	codeKeywordStyle lipgloss.Style
+	codeStringStyle  lipgloss.Style
+	codeNumberStyle  lipgloss.Style
+	codeCommentStyle lipgloss.Style
 )
 
 // Model represents the state of the TUI
@@ -53,33 +60,145 @@ type Model struct {
 	state         State
 	inputDir      string
 	outputDir     string
+	excludePaths  string // Comma-separated paths typed on the exclude paths screen
 	apiKey        string
+	validatingKey bool
+	keyError      string
+	keyFromEnv    bool // The API key was read from the environment, so it was not entered
+	
+	// Saved profiles; index 0 of the profile list starts a new configuration
+	profiles        config.Profiles
+	profileNames    []string
+	selectedProfile int
+	profileName     string // Profile in use, if any
 	
 	// API Selection
 	apiTypes        []types.APIType
 	selectedAPIType int
 	apiModels       []string
 	selectedModel   int
+	envAPIType      bool // API type set in STRUCTURA_API_TYPE; the start screen skips its selection
+	envModel        bool // Model also set in STRUCTURA_MODEL; the start screen skips its selection too
+	
+	// Custom OpenAI-compatible API
+	customEndpoint  string
+	customModelName string
+	
+	// Advanced settings (per-provider rate limits in milliseconds)
+	rateLimitProviders []types.APIType
+	rateLimitInputs    []string
+	selectedRateLimit  int
 	
 	// Project type selection
 	projectType   filehandler.ProjectType
 	projectTypes  []filehandler.ProjectType
 	selectedType  int
 	
+	// Documentation style selection
+	docStyles     []config.DocumentationStyle
+	selectedStyle int
+	
 	// Directory Selection
-	dirEntries     []os.DirEntry
+	dirEntries     []os.DirEntry // Entries shown, narrowed by dirFilter
+	allDirEntries  []os.DirEntry
+	dirFilter      string
+	filteringDirs  bool // Keypresses edit dirFilter
 	selectedDir    int
 	dirHistory     []string // For navigation history
+	lastClickTime  time.Time // For detecting double clicks in the directory browser
+	lastClickY     int
+	selectedFiles  map[string]bool // Files picked in the browser; when non-empty only these are processed
+	preview        viewport.Model // File preview opened from the directory browser
+	previewPath    string
+	dryRunTotal    api.DryRunResult // Totals of the dry run shown in the preview
+	dryRunFiles    int
 	
 	// Processing
 	files         []filehandler.FileInfo
+	packages      map[string]string // Package or module of each file, keyed by path
+	packageSizes  map[string]int    // Files per package
+	packageLeft   map[string]int    // Unfinished files per package
+	lastPackage   string            // Package of the most recently finished file
+	nextFile      int            // Index of the next file to start
+	inFlight      int            // Files started but not yet finished
+	retrying      bool           // Processing a single failed file from the done screen
+	setupPending  bool           // PROJECT_SETUP.md is still being generated
+	readmePending bool           // README.md is still being generated
+	readmePath    string         // Set once README.md has been written
+	overviewPending bool         // PROJECT_OVERVIEW.md is still being generated
+	overviewPath  string         // Set once PROJECT_OVERVIEW.md has been written
+	architecturePending bool     // ARCHITECTURE.md is still being generated
+	architecturePath string      // Set once ARCHITECTURE.md has been written
+	glossaryPending bool         // GLOSSARY.md is still being generated
+	glossaryPath  string         // Set once GLOSSARY.md has been written
+	indexPending  bool           // INDEX.md and the HTML pages are still being written
+	indexPath     string         // Set once INDEX.md has been written
+	htmlPath      string         // Set once index.html has been written
+	projectName   string         // Name from the project's manifest, set once the files are read
+	coveragePath  string         // Set once COVERAGE.md has been written
+	coveragePercent float64      // Files with up-to-date documentation, from 0 to 100
+	reviewCount   int            // Files whose documentation scored below MinDocScore
+	techDebtPath  string         // Set once TECH_DEBT.md has been written
+	techDebtCount int            // Annotations listed in TECH_DEBT.md
+	uploadPending bool           // The output directory is still being uploaded to the output bucket
+	uploadURI     string         // Set once the output directory has been uploaded
 	processedFiles int
 	currentFile   string
+	currentFileContent string // Source of the most recently finished file
+	filePreview   FilePreviewPane // Shows currentFileContent beside the progress on wide terminals
+	recentFiles   []ProgressDetail // Last files finished, shown instead of currentFile in verbose mode
+	fileStartTime time.Time        // When the file being documented started, or the last one finished
+	logFileFailed bool             // Writing the log file failed, so no more lines are written
 	errors        []string
 	spinner       spinner.Model
 	progress      progress.Model
 	width         int
 	height        int
+	
+	// Failed files, retryable from the done screen
+	failedFiles      []filehandler.FileInfo
+	failedFileErrors []string // Error message for each failed file
+	selectedFailed   int
+	
+	// Checksums of documented source files, persisted in the output directory
+	checksums *filehandler.ChecksumStore
+	
+	// When each source file was last documented, persisted in the output directory
+	freshness *filehandler.FreshnessStore
+	
+	// Files waiting to be retried after a transient API error
+	errorRetryQueue []retryEntry
+	retryCounts     map[int]int      // Automatic retries made per file index
+	clock           func() time.Time // Time source for retry backoff, time.Now when nil
+	
+	// Statistics for the stats screen
+	stats processingStats
+	
+	// Prompt tokens of the files not yet started, estimated when processing is paused
+	pausedRemainingTokens int
+	
+	// Session token budget
+	ignoreBudget   bool   // Continue past the budget, as chosen on the budget screen
+	budgetExceeded bool   // Stopped at the budget, so the program exits with status 3
+	resumeCommand  string // How to resume in a new session, once the settings are saved
+	
+	// Progress estimate
+	processingStartTime time.Time
+	processingEndTime   time.Time     // Set once every file is processed
+	eta                 time.Duration // Estimated time until all files are processed
+	
+	// Theme
+	themes        []Theme
+	selectedTheme int
+	
+	// Keybindings and help
+	keys     KeyMap
+	showHelp bool           // The help overlay is drawn over the current screen
+	help     viewport.Model // Scrollable content of the help overlay
+	
+	// Shutdown
+	ctx           context.Context
+	cancelFunc    context.CancelFunc
 }
 
 // State represents the current state of the application
@@ -87,22 +206,68 @@ type State int
 
 const (
 	StateInit State = iota
+	StateSelectTheme
+	StateSelectProfile
 	StateSelectAPIType
 	StateSelectAPIModel
+	StateEnterCustomEndpoint
+	StateEnterCustomModel
 	StateEnterAPIKey
+	StateSaveKeyPrompt
+	StateAdvancedSettings
 	StateSelectProjectType
+	StateSelectDocStyle
 	StateSelectInputDir
 	StateEnterInputDir  // Fallback if selecting fails
 	StateEnterOutputDir
+	StateEnterExcludePaths // Optional paths left out of the documentation
 	StateProcessing
+	StatePaused
 	StateDone
+	StateStats
+	StateStopping
+	StatePreview
+	StateDryRunPreview
+	StateBudgetExceeded // The session token budget ran out; nothing new is started
 )
 
+// appTitle is shown at the top of every screen
+const appTitle = "Structura - Documentation Generator"
+
+// title returns the title shown at the top of the screen, followed by the project name once
+// the project has been read
+func (m Model) title() string {
+	if m.projectName == "" {
+		return appTitle
+	}
+	return appTitle + " | " + m.projectName
+}
+
+// previewLines is the number of lines of a file shown in the preview pane
+const previewLines = 50
+
+// minFilesForETA is the number of processed files needed before an ETA is shown
+const minFilesForETA = 3
+
+// maxDirEntries is the number of entries shown at once in the directory browser
+const maxDirEntries = 15
+
+// doubleClickInterval is the maximum time between two clicks on the same row to open it
+const doubleClickInterval = 300 * time.Millisecond
+
+// stopTimeout is how long to wait for an in-flight API request when shutting down
+const stopTimeout = 5 * time.Second
+
+// ShutdownMsg asks the model to shut down gracefully, e.g. when the process receives a signal
+type ShutdownMsg struct{}
+
 // NewModel creates a new TUI model
 func NewModel() Model {
+	ApplyTheme(ThemePurple)
+	
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("#7D56F4"))
+	s.Style = spinnerStyle
 
 	p := progress.New(progress.WithDefaultGradient())
 
@@ -118,6 +283,16 @@ func NewModel() Model {
 		filehandler.ProjectTypeRuby,
 		filehandler.ProjectTypeRails,
 		filehandler.ProjectTypeFlutter,
+		filehandler.ProjectTypeRust,
+		filehandler.ProjectTypeCSharp,
+		filehandler.ProjectTypeKotlin,
+		filehandler.ProjectTypeSwift,
+		filehandler.ProjectTypeTypeScript,
+		filehandler.ProjectTypeProtobuf,
+		filehandler.ProjectTypeGraphQL,
+		filehandler.ProjectTypePHP,
+		filehandler.ProjectTypeLaravel,
+		filehandler.ProjectTypeAndroid,
 	}
 	
 	// Set up API types
@@ -129,9 +304,60 @@ func NewModel() Model {
 		cwd = "/"
 	}
 	
+	// Preselect the project type detected in the working directory
+	selectedType := 0
+	detectedType := filehandler.DetectProjectType(cwd)
+	for i, projectType := range projectTypes {
+		if projectType == detectedType {
+			selectedType = i
+			break
+		}
+	}
+	
 	// Create initial config
 	cfg := config.NewConfig()
 	
+	// Load the saved profiles offered on the start screen
+	var errors []string
+	profiles, err := config.LoadProfiles()
+	if err != nil {
+		errors = append(errors, fmt.Sprintf("Failed to load profiles: %s", err))
+		profiles = config.Profiles{}
+	}
+	
+	// Preselect the API type and model set in the environment
+	envAPIType, envModel, err := config.EnvironmentAPI()
+	if err != nil {
+		errors = append(errors, err.Error())
+	}
+	selectedAPIType, selectedModel := 0, 0
+	if envAPIType != "" {
+		cfg.APIType = envAPIType
+		for i, apiType := range apiTypes {
+			if apiType == envAPIType {
+				selectedAPIType = i
+			}
+		}
+		cfg.APIModel = ""
+		if models := types.APIModelMap[envAPIType]; len(models) > 0 {
+			cfg.APIModel = models[0]
+		}
+		if envModel != "" {
+			cfg.APIModel = envModel
+			if envAPIType == types.APITypeCustom {
+				cfg.CustomModelName = envModel
+			}
+			for i, model := range types.APIModelMap[envAPIType] {
+				if model == envModel {
+					selectedModel = i
+				}
+			}
+		}
+	}
+	
+	// Context cancelled on shutdown to abort in-flight API calls
+	ctx, cancel := context.WithCancel(context.Background())
+	
 	return Model{
 		config:          cfg,
 		fileHandler:     filehandler.NewFileHandler(),
@@ -140,13 +366,25 @@ func NewModel() Model {
 		progress:        p,
 		projectTypes:    projectTypes,
 		projectType:     filehandler.ProjectTypeGeneric,
-		selectedType:    0,
+		selectedType:    selectedType,
 		apiTypes:        apiTypes,
-		selectedAPIType: 0,
-		apiModels:       types.APIModelMap[apiTypes[0]], // Default to first API type models
-		selectedModel:   0,
+		selectedAPIType: selectedAPIType,
+		apiModels:       types.APIModelMap[apiTypes[selectedAPIType]],
+		selectedModel:   selectedModel,
+		envAPIType:      envAPIType != "",
+		envModel:        envAPIType != "" && envModel != "",
+		customModelName: cfg.CustomModelName,
+		profiles:        profiles,
+		profileNames:    profiles.Names(),
 		inputDir:        cwd,
 		dirHistory:      []string{cwd},
+		selectedFiles:   make(map[string]bool),
+		keys:            DefaultKeyMap(),
+		themes:          Themes(),
+		filePreview:     NewFilePreviewPane(),
+		errors:          errors,
+		ctx:             ctx,
+		cancelFunc:      cancel,
 	}
 }
 
@@ -159,20 +397,102 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "ctrl+c", "q":
+		// Characters typed into text fields must not trigger shortcuts
+		typing := m.isTextEntry() && msg.Type == tea.KeyRunes
+		
+		switch {
+		case key.Matches(msg, m.keys.ForceQuit) && m.state == StateStopping:
+			// Second interrupt: stop waiting and quit immediately
 			return m, tea.Quit
+		case key.Matches(msg, m.keys.Quit) && !typing:
+			return m.shutdown()
+		case key.Matches(msg, m.keys.Help) && !typing:
+			m.toggleHelp()
+			return m, nil
+		}
+		
+		// While the help overlay is open, keys scroll it instead of reaching the screen below
+		if m.showHelp {
+			if msg.Type == tea.KeyEsc {
+				m.showHelp = false
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.help, cmd = m.help.Update(msg)
+			return m, cmd
 		}
 
 		// Handle different states
 		switch m.state {
 		case StateInit:
-			m.state = StateSelectAPIType
+			if key.Matches(msg, m.keys.Theme) {
+				m.state = StateSelectTheme
+				return m, nil
+			}
+			switch {
+			case m.profileName != "":
+				// A profile chosen on the command line only needs its API key confirmed
+				m = m.enterAPIKey()
+			case m.envAPIType && m.config.APIType == types.APITypeCustom:
+				m.state = StateEnterCustomEndpoint
+			case m.envModel:
+				m = m.enterAPIKey()
+			case m.envAPIType:
+				m.state = StateSelectAPIModel
+			case len(m.profileNames) > 0:
+				m.selectedProfile = 0
+				m.state = StateSelectProfile
+			default:
+				m.state = StateSelectAPIType
+			}
+			return m, nil
+			
+		case StateSelectProfile:
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.selectedProfile > 0 {
+					m.selectedProfile--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.selectedProfile < len(m.profileNames) {
+					m.selectedProfile++
+				}
+			case key.Matches(msg, m.keys.Enter):
+				if m.selectedProfile == 0 {
+					m.state = StateSelectAPIType
+					return m, nil
+				}
+				m.applyProfile(m.profileNames[m.selectedProfile-1])
+				m = m.enterAPIKey()
+			case key.Matches(msg, m.keys.Delete):
+				if m.selectedProfile > 0 {
+					m.deleteProfile(m.profileNames[m.selectedProfile-1])
+				}
+			}
+			return m, nil
+			
+		case StateSelectTheme:
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.selectedTheme > 0 {
+					m.selectedTheme--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.selectedTheme < len(m.themes)-1 {
+					m.selectedTheme++
+				}
+			case key.Matches(msg, m.keys.Enter):
+				ApplyTheme(m.themes[m.selectedTheme])
+				m.spinner.Style = spinnerStyle
+				m.state = StateInit
+			case key.Matches(msg, m.keys.Back):
+				m.state = StateInit
+			}
 			return m, nil
 			
 		case StateSelectAPIType:
-			switch msg.String() {
-			case "up", "k":
+			switch {
+			case key.Matches(msg, m.keys.Up):
 				if m.selectedAPIType > 0 {
 					m.selectedAPIType--
 					// Update available models when API type changes
@@ -180,7 +500,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.selectedModel = 0
 				}
 				return m, nil
-			case "down", "j":
+			case key.Matches(msg, m.keys.Down):
 				if m.selectedAPIType < len(m.apiTypes)-1 {
 					m.selectedAPIType++
 					// Update available models when API type changes
@@ -188,58 +508,115 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.selectedModel = 0
 				}
 				return m, nil
-			case "enter":
+			case key.Matches(msg, m.keys.Enter):
 				m.config.APIType = m.apiTypes[m.selectedAPIType]
-				m.state = StateSelectAPIModel
+				if m.config.APIType == types.APITypeCustom {
+					// Custom endpoints have no predefined models
+					m.state = StateEnterCustomEndpoint
+				} else {
+					m.state = StateSelectAPIModel
+				}
 				return m, nil
 			}
 			return m, nil
 			
 		case StateSelectAPIModel:
-			switch msg.String() {
-			case "up", "k":
+			switch {
+			case key.Matches(msg, m.keys.Up):
 				if m.selectedModel > 0 {
 					m.selectedModel--
 				}
 				return m, nil
-			case "down", "j":
+			case key.Matches(msg, m.keys.Down):
 				if m.selectedModel < len(m.apiModels)-1 {
 					m.selectedModel++
 				}
 				return m, nil
-			case "enter":
+			case key.Matches(msg, m.keys.Enter):
 				m.config.APIModel = m.apiModels[m.selectedModel]
-				m.state = StateEnterAPIKey
+				m = m.enterAPIKey()
+				return m, nil
+			}
+			return m, nil
+
+		case StateEnterCustomEndpoint:
+			if key.Matches(msg, m.keys.Enter) {
+				endpoint := strings.TrimSpace(m.customEndpoint)
+				if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+					m.errors = append(m.errors, fmt.Sprintf("Invalid endpoint URL: %s", m.customEndpoint))
+					return m, nil
+				}
+				
+				m.config.CustomEndpoint = endpoint
+				if m.envModel {
+					m = m.enterAPIKey()
+				} else {
+					m.state = StateEnterCustomModel
+				}
+				return m, nil
+			}
+			
+			// Handle backspace
+			if key.Matches(msg, m.keys.Backspace) && len(m.customEndpoint) > 0 {
+				m.customEndpoint = m.customEndpoint[:len(m.customEndpoint)-1]
+				return m, nil
+			}
+			
+			if msg.Type == tea.KeyRunes {
+				m.customEndpoint += string(msg.Runes)
+			}
+			return m, nil
+			
+		case StateEnterCustomModel:
+			if key.Matches(msg, m.keys.Enter) {
+				modelName := strings.TrimSpace(m.customModelName)
+				if modelName == "" {
+					m.errors = append(m.errors, "Model name cannot be empty")
+					return m, nil
+				}
+				
+				m.config.CustomModelName = modelName
+				m.config.APIModel = modelName
+				m = m.enterAPIKey()
+				return m, nil
+			}
+			
+			// Handle backspace
+			if key.Matches(msg, m.keys.Backspace) && len(m.customModelName) > 0 {
+				m.customModelName = m.customModelName[:len(m.customModelName)-1]
 				return m, nil
 			}
+			
+			if msg.Type == tea.KeyRunes {
+				m.customModelName += string(msg.Runes)
+			}
 			return m, nil
 
 		case StateEnterAPIKey:
-			if msg.Type == tea.KeyEnter {
+			// Ignore input while the key is being validated
+			if m.validatingKey {
+				return m, nil
+			}
+			
+			if key.Matches(msg, m.keys.Enter) {
 				// Set the appropriate API key based on the selected API type
-				switch m.config.APIType {
-				case types.APITypeChatGPT:
-					m.config.OpenAIAPIKey = m.apiKey
-				case types.APITypeGemini:
-					m.config.GeminiAPIKey = m.apiKey
-				default:
-					m.config.DeepseekAPIKey = m.apiKey
-				}
+				m.config.SetAPIKey(m.config.APIType, m.apiKey)
+				m.keyFromEnv = false
 				
 				// Create the appropriate API client
-				var err error
-				m.apiClient, err = api.CreateDocumentationClient(m.config)
-				if err != nil {
+				if err := m.createAPIClient(); err != nil {
 					m.errors = append(m.errors, fmt.Sprintf("Error creating API client: %s", err))
 					return m, nil
 				}
 				
-				m.state = StateSelectProjectType
-				return m, nil
+				// Check the key before going any further
+				m.validatingKey = true
+				m.keyError = ""
+				return m, m.validateKey()
 			}
 			
 			// Handle backspace
-			if msg.Type == tea.KeyBackspace && len(m.apiKey) > 0 {
+			if key.Matches(msg, m.keys.Backspace) && len(m.apiKey) > 0 {
 				m.apiKey = m.apiKey[:len(m.apiKey)-1]
 				return m, nil
 			}
@@ -249,25 +626,121 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 			
+		case StateSaveKeyPrompt:
+			switch {
+			case key.Matches(msg, m.keys.Yes):
+				store := config.NewKeyringStore()
+				keyName := config.KeyringKeyName(m.config.APIType)
+				if err := store.Set(config.KeyringService, keyName, m.apiKey); err != nil {
+					m.errors = append(m.errors, fmt.Sprintf("Failed to save API key to keychain: %s", err))
+				}
+				return m.continueAfterAPIKey(), nil
+			case key.Matches(msg, m.keys.No):
+				return m.continueAfterAPIKey(), nil
+			}
+			return m, nil
+			
+		case StateAdvancedSettings:
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.selectedRateLimit > 0 {
+					m.selectedRateLimit--
+				}
+				return m, nil
+			case key.Matches(msg, m.keys.Down), key.Matches(msg, m.keys.NextField):
+				if m.selectedRateLimit < len(m.rateLimitInputs)-1 {
+					m.selectedRateLimit++
+				}
+				return m, nil
+			case key.Matches(msg, m.keys.Backspace):
+				input := m.rateLimitInputs[m.selectedRateLimit]
+				if len(input) > 0 {
+					m.rateLimitInputs[m.selectedRateLimit] = input[:len(input)-1]
+				}
+				return m, nil
+			case msg.Type == tea.KeyRunes:
+				for _, r := range msg.Runes {
+					if r >= '0' && r <= '9' {
+						m.rateLimitInputs[m.selectedRateLimit] += string(r)
+					}
+				}
+				return m, nil
+			case key.Matches(msg, m.keys.Enter):
+				rateLimits := make(map[types.APIType]time.Duration, len(m.config.PerProviderRateLimits))
+				for provider, rateLimit := range m.config.PerProviderRateLimits {
+					rateLimits[provider] = rateLimit
+				}
+				for i, provider := range m.rateLimitProviders {
+					millis, err := strconv.Atoi(m.rateLimitInputs[i])
+					if err != nil {
+						m.errors = append(m.errors, fmt.Sprintf("Invalid rate limit for %s: %q", provider, m.rateLimitInputs[i]))
+						return m, nil
+					}
+					rateLimits[provider] = time.Duration(millis) * time.Millisecond
+				}
+				m.config.PerProviderRateLimits = rateLimits
+				
+				// Create the API client with the updated rate limits
+				if err := m.createAPIClient(); err != nil {
+					m.errors = append(m.errors, fmt.Sprintf("Error creating API client: %s", err))
+					return m, nil
+				}
+				
+				m.state = StateSelectProjectType
+				return m, nil
+			}
+			return m, nil
+			
 		case StateSelectProjectType:
-			switch msg.String() {
-			case "up", "k":
+			switch {
+			case key.Matches(msg, m.keys.Up):
 				if m.selectedType > 0 {
 					m.selectedType--
 				}
 				return m, nil
-			case "down", "j":
+			case key.Matches(msg, m.keys.Down):
 				if m.selectedType < len(m.projectTypes)-1 {
 					m.selectedType++
 				}
 				return m, nil
-			case "enter":
+			case key.Matches(msg, m.keys.Enter):
 				m.projectType = m.projectTypes[m.selectedType]
 				m.fileHandler.SetProjectType(m.projectType)
 				
+				// Go imports are parsed precisely, so the dependency graph is on by default there
+				m.config.GenerateDependencyGraph = m.projectType == filehandler.ProjectTypeGo
+				
 				// Store the fileHandler in the config for the API client to access
 				m.config.FileHandler = m.fileHandler
 				
+				// Preselect the style given with --style or in a profile
+				m.docStyles = config.DocumentationStyles()
+				m.selectedStyle = 0
+				for i, style := range m.docStyles {
+					if style == m.config.DocumentationStyle {
+						m.selectedStyle = i
+					}
+				}
+				m.state = StateSelectDocStyle
+				return m, nil
+			}
+			return m, nil
+			
+		case StateSelectDocStyle:
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.selectedStyle > 0 {
+					m.selectedStyle--
+				}
+				return m, nil
+			case key.Matches(msg, m.keys.Down):
+				if m.selectedStyle < len(m.docStyles)-1 {
+					m.selectedStyle++
+				}
+				return m, nil
+			case key.Matches(msg, m.keys.Enter):
+				m.config.DocumentationStyle = m.docStyles[m.selectedStyle]
+				
 				// Load the directory entries for input directory selection
 				if err := m.loadDirectoryEntries(m.inputDir); err != nil {
 					m.errors = append(m.errors, fmt.Sprintf("Error loading directory: %s", err))
@@ -280,57 +753,176 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 			
 		case StateSelectInputDir:
-			switch msg.String() {
-			case "up", "k":
+			if m.filteringDirs {
+				switch {
+				case msg.Type == tea.KeyRunes:
+					m.setDirFilter(m.dirFilter + string(msg.Runes))
+					return m, nil
+				case key.Matches(msg, m.keys.Backspace):
+					if len(m.dirFilter) > 0 {
+						m.setDirFilter(m.dirFilter[:len(m.dirFilter)-1])
+					}
+					return m, nil
+				case key.Matches(msg, m.keys.ClearFilter):
+					m.filteringDirs = false
+					m.setDirFilter("")
+					return m, nil
+				}
+			}
+			
+			switch {
+			case key.Matches(msg, m.keys.Filter):
+				m.filteringDirs = true
+				return m, nil
+			case key.Matches(msg, m.keys.Up):
 				if m.selectedDir > 0 {
 					m.selectedDir--
 				}
 				return m, nil
-			case "down", "j":
+			case key.Matches(msg, m.keys.Down):
 				if m.selectedDir < len(m.dirEntries)-1 {
 					m.selectedDir++
 				}
 				return m, nil
-			case "enter":
-				// Navigate into the selected directory
-				if m.selectedDir < len(m.dirEntries) && m.dirEntries[m.selectedDir].IsDir() {
-					entry := m.dirEntries[m.selectedDir]
-					
-					if entry.Name() == ".." {
-						// Go up one directory
-						if len(m.dirHistory) > 1 {
-							m.dirHistory = m.dirHistory[:len(m.dirHistory)-1]
-							m.inputDir = m.dirHistory[len(m.dirHistory)-1]
-						}
-					} else {
-						// Go into the selected directory
-						newPath := filepath.Join(m.inputDir, entry.Name())
-						m.inputDir = newPath
-						m.dirHistory = append(m.dirHistory, newPath)
-					}
-			
-					// Reload directory entries
-					if err := m.loadDirectoryEntries(m.inputDir); err != nil {
-						m.errors = append(m.errors, fmt.Sprintf("Error loading directory: %s", err))
-					}
+			case key.Matches(msg, m.keys.Enter):
+				if m.filteringDirs && m.selectedDir < len(m.dirEntries) && !m.dirEntries[m.selectedDir].IsDir() {
+					// A file found by filtering is selected instead of entered
+					m.toggleFileSelection(filepath.Join(m.inputDir, m.dirEntries[m.selectedDir].Name()))
 					return m, nil
 				}
+				m.openSelectedDir()
+				return m, nil
+			case key.Matches(msg, m.keys.ParentDir):
+				m.openParentDir()
+				return m, nil
+			
+			case key.Matches(msg, m.keys.ToggleFile) && m.selectedDir < len(m.dirEntries) && !m.dirEntries[m.selectedDir].IsDir():
+				path := filepath.Join(m.inputDir, m.dirEntries[m.selectedDir].Name())
+				m.toggleFileSelection(path)
+				return m, nil
+			
+			case key.Matches(msg, m.keys.ToggleAll):
+				m.toggleAllFiles()
+				return m, nil
+			
+			case key.Matches(msg, m.keys.Preview):
+				// Show the start of the selected file
+				if m.selectedDir < len(m.dirEntries) && !m.dirEntries[m.selectedDir].IsDir() {
+					m.openPreview(filepath.Join(m.inputDir, m.dirEntries[m.selectedDir].Name()))
+				}
 				return m, nil
 			
-			case " ":
+			case key.Matches(msg, m.keys.DryRun):
+				// List what would be documented without calling the API
+				return m, m.dryRun()
+			
+			case key.Matches(msg, m.keys.UseDir):
 				// Select the current directory
+				m.applyProjectConfig()
 				m.state = StateEnterOutputDir
 				return m, nil
 			
-			case "escape":
+			case key.Matches(msg, m.keys.ManualEntry):
 				// Switch to manual entry mode
 				m.state = StateEnterInputDir
 				return m, nil
+			
+			case msg.Type == tea.KeyRunes:
+				// Typing a character without a shortcut starts filtering with it
+				m.filteringDirs = true
+				m.setDirFilter(string(msg.Runes))
+				return m, nil
 			}
 			return m, nil
 
+		case StatePreview, StateDryRunPreview:
+			if key.Matches(msg, m.keys.Back) {
+				m.state = StateSelectInputDir
+				return m, nil
+			}
+			if m.state == StateDryRunPreview && key.Matches(msg, m.keys.Proceed) {
+				// Document the listed files, as if the directory had been selected
+				m.applyProjectConfig()
+				m.state = StateEnterOutputDir
+				return m, nil
+			}
+			
+			// Scroll the preview
+			var cmd tea.Cmd
+			m.preview, cmd = m.preview.Update(msg)
+			return m, cmd
+			
+		case StateDone:
+			switch {
+			case key.Matches(msg, m.keys.Up):
+				if m.selectedFailed > 0 {
+					m.selectedFailed--
+				}
+			case key.Matches(msg, m.keys.Down):
+				if m.selectedFailed < len(m.failedFiles)-1 {
+					m.selectedFailed++
+				}
+			case key.Matches(msg, m.keys.Retry):
+				if m.selectedFailed < len(m.failedFiles) {
+					return m.retryFailedFile(m.selectedFailed)
+				}
+			case key.Matches(msg, m.keys.Stats):
+				m.state = StateStats
+			case key.Matches(msg, m.keys.ScrollUp):
+				m.filePreview.ScrollUp()
+			case key.Matches(msg, m.keys.ScrollDown):
+				m.filePreview.ScrollDown()
+			}
+			return m, nil
+			
+		case StateStats:
+			if key.Matches(msg, m.keys.Back) {
+				m.state = StateDone
+			}
+			return m, nil
+			
+		case StateProcessing:
+			switch {
+			case key.Matches(msg, m.keys.Pause):
+				return m.Pause()
+			case key.Matches(msg, m.keys.Verbose):
+				m.config.VerboseProgress = !m.config.VerboseProgress
+			}
+			return m, nil
+			
+		case StateBudgetExceeded:
+			switch {
+			case key.Matches(msg, m.keys.IgnoreBudget):
+				// Carry on as if resuming after a pause
+				m.ignoreBudget = true
+				m.state = StatePaused
+				return m.Resume()
+			case key.Matches(msg, m.keys.StopBudget):
+				// Documented files are skipped on the next run, so quitting saves the progress made
+				m.budgetExceeded = true
+				return m.shutdown()
+			case key.Matches(msg, m.keys.ResumeLater):
+				m.budgetExceeded = true
+				if err := m.SaveProfile(resumeProfileName); err != nil {
+					m.errors = append(m.errors, fmt.Sprintf("Failed to save the settings: %s", err))
+					return m, nil
+				}
+				m.resumeCommand = fmt.Sprintf("structura run --profile %s, then select %s", resumeProfileName, m.inputDir)
+				return m.shutdown()
+			}
+			return m, nil
+			
+		case StatePaused:
+			switch {
+			case key.Matches(msg, m.keys.Pause):
+				return m.Resume()
+			case key.Matches(msg, m.keys.Verbose):
+				m.config.VerboseProgress = !m.config.VerboseProgress
+			}
+			return m, nil
+			
 		case StateEnterInputDir:
-			if msg.Type == tea.KeyEnter {
+			if key.Matches(msg, m.keys.Enter) {
 				// Clean and normalize the path
 				cleanPath := filepath.Clean(m.inputDir)
 				m.inputDir = cleanPath
@@ -347,12 +939,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				
+				m.applyProjectConfig()
 				m.state = StateEnterOutputDir
 				return m, nil
 			}
 			
 			// Handle backspace
-			if msg.Type == tea.KeyBackspace && len(m.inputDir) > 0 {
+			if key.Matches(msg, m.keys.Backspace) && len(m.inputDir) > 0 {
 				m.inputDir = m.inputDir[:len(m.inputDir)-1]
 				return m, nil
 			}
@@ -363,7 +956,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case StateEnterOutputDir:
-			if msg.Type == tea.KeyEnter {
+			if key.Matches(msg, m.keys.Enter) {
 				// Clean the path
 				cleanPath := filepath.Clean(m.outputDir)
 				m.outputDir = cleanPath
@@ -374,8 +967,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				
+				// Start from the paths excluded with --exclude-path
+				m.excludePaths = strings.Join(m.fileHandler.ExcludePaths, ", ")
+				m.state = StateEnterExcludePaths
+				return m, nil
+			}
+			
+			// Handle backspace
+			if key.Matches(msg, m.keys.Backspace) && len(m.outputDir) > 0 {
+				m.outputDir = m.outputDir[:len(m.outputDir)-1]
+				return m, nil
+			}
+			
+			if msg.Type == tea.KeyRunes {
+				m.outputDir += string(msg.Runes)
+			}
+			return m, nil
+			
+		case StateEnterExcludePaths:
+			if key.Matches(msg, m.keys.Enter) {
+				var excludePaths []string
+				for _, path := range strings.Split(m.excludePaths, ",") {
+					if path = strings.TrimSpace(path); path != "" {
+						excludePaths = append(excludePaths, path)
+					}
+				}
+				m.fileHandler.ExcludePaths = excludePaths
+				
 				// Start processing
 				m.state = StateProcessing
+				m.processingStartTime = time.Now()
+				m.processingEndTime = time.Time{}
 				return m, tea.Batch(
 					m.processFiles,
 					m.spinner.Tick,
@@ -383,21 +1005,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			
 			// Handle backspace
-			if msg.Type == tea.KeyBackspace && len(m.outputDir) > 0 {
-				m.outputDir = m.outputDir[:len(m.outputDir)-1]
+			if key.Matches(msg, m.keys.Backspace) && len(m.excludePaths) > 0 {
+				m.excludePaths = m.excludePaths[:len(m.excludePaths)-1]
 				return m, nil
 			}
 			
 			if msg.Type == tea.KeyRunes {
-				m.outputDir += string(msg.Runes)
+				m.excludePaths += string(msg.Runes)
 			}
 			return m, nil
 		}
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+		
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.progress.Width = msg.Width - 10
+		m.resizeFilePreview()
+		if m.showHelp {
+			m.resizeHelp()
+		}
 		return m, nil
 		
 	case spinner.TickMsg:
@@ -410,75 +1039,374 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmd := m.progress.SetPercent(float64(m.processedFiles) / float64(len(m.files)))
 		return m, cmd
 		
-	case fileProcessedMsg:
-		m.processedFiles++
-		m.currentFile = string(msg)
+	case keyValidatedMsg:
+		m.validatingKey = false
+		if msg.err != nil {
+			// Stay on the key screen so the user can correct it
+			m.keyError = msg.err.Error()
+			return m, nil
+		}
 		
-		progress := float64(m.processedFiles) / float64(len(m.files))
-		if m.processedFiles >= len(m.files) {
-			// Generate and save project structure and setup documentation
-			m.generateStructureDocumentation()
-			
-			m.state = StateDone
-			return m, m.progress.SetPercent(progress)
+		// Offer to remember a new key in the OS keychain
+		if m.config.UseKeyring && m.apiKey != "" && !msg.savedInKeyring {
+			m.state = StateSaveKeyPrompt
+			return m, nil
 		}
 		
-		// Continue processing the next file
-		return m, tea.Batch(
-			m.progress.SetPercent(progress),
-			continueProcessing(filesLoadedMsg{files: m.files}, m),
-		)
+		return m.continueAfterAPIKey(), nil
 		
-	case fileErrorMsg:
-		m.errors = append(m.errors, string(msg))
-		m.processedFiles++
+	case setupDocMsg:
+		m.setupPending = false
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		}
+		cmds := []tea.Cmd{m.generateReadmeIfReady(), m.generateOverviewIfReady(), m.generateArchitectureIfReady(), m.generateGlossaryIfReady(), m.writeIndexIfReady()}
+		return m, tea.Batch(cmds...)
 		
-		progress := float64(m.processedFiles) / float64(len(m.files))
-		if m.processedFiles >= len(m.files) {
-			// Generate and save project structure and setup documentation
-			m.generateStructureDocumentation()
-			
-			m.state = StateDone
-			return m, m.progress.SetPercent(progress)
+	case readmeMsg:
+		m.readmePending = false
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		} else {
+			m.readmePath = msg.path
 		}
+		cmd := m.writeIndexIfReady()
+		return m, cmd
 		
-		// Continue processing the next file
-		return m, tea.Batch(
-			m.progress.SetPercent(progress),
-			continueProcessing(filesLoadedMsg{files: m.files}, m),
-		)
+	case overviewMsg:
+		m.overviewPending = false
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		} else {
+			m.overviewPath = msg.path
+		}
+		cmd := m.writeIndexIfReady()
+		return m, cmd
 		
-	case filesLoadedMsg:
-		m.files = msg.files
-		// Start processing files
-		return m, continueProcessing(msg, m)
-	}
-
-	return m, nil
-}
-
-// View renders the current state of the application
-func (m Model) View() string {
-	title := "Structura - Documentation Generator"
-	
-	switch m.state {
-	case StateInit:
-		return titleStyle.Render(title) + "\n\n" +
-			"Press any key to start"
-			
-	case StateSelectAPIType:
-		var options string
-		for i, apiType := range m.apiTypes {
-			option := string(apiType)
-			if i == m.selectedAPIType {
-				options += selectedStyle.Render("› " + option) + "\n"
-			} else {
-				options += "  " + option + "\n"
-			}
+	case architectureMsg:
+		m.architecturePending = false
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		} else {
+			m.architecturePath = msg.path
 		}
+		cmd := m.writeIndexIfReady()
+		return m, cmd
 		
-		return titleStyle.Render(title) + "\n\n" +
-			"Select API type (use arrow keys and enter):\n\n" +
+	case glossaryMsg:
+		m.glossaryPending = false
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		} else {
+			m.glossaryPath = msg.path
+		}
+		cmd := m.writeIndexIfReady()
+		return m, cmd
+		
+	case indexMsg:
+		m.indexPending = false
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		}
+		m.indexPath = msg.indexPath
+		m.htmlPath = msg.htmlPath
+		m.coveragePath = msg.coveragePath
+		m.coveragePercent = msg.coveragePercent
+		m.reviewCount = msg.reviewCount
+		cmd := m.uploadOutput()
+		return m, cmd
+		
+	case uploadMsg:
+		m.uploadPending = false
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		} else {
+			m.uploadURI = msg.uri
+		}
+		return m, nil
+		
+	case dryRunMsg:
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+			return m, nil
+		}
+		if m.state != StateSelectInputDir {
+			return m, nil
+		}
+		
+		m.preview = m.newViewport()
+		m.preview.SetContent(msg.table)
+		m.dryRunTotal = msg.total
+		m.dryRunFiles = msg.files
+		m.projectName = m.fileHandler.Metadata.Name
+		m.state = StateDryRunPreview
+		return m, nil
+		
+	case notificationMsg:
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		}
+		return m, nil
+		
+	case dirSummaryMsg:
+		if msg.err != "" {
+			m.errors = append(m.errors, msg.err)
+		}
+		return m, nil
+		
+	case ShutdownMsg:
+		if m.state == StateStopping {
+			return m, nil
+		}
+		return m.shutdown()
+		
+	case stopTimeoutMsg:
+		return m, tea.Quit
+		
+	case fileProcessedMsg:
+		m.processedFiles++
+		if msg.saveErr != "" {
+			m.errors = append(m.errors, msg.saveErr)
+		}
+		if !msg.moreInBatch {
+			m.inFlight--
+		}
+		m.currentFile = msg.path
+		if msg.index >= 0 && msg.index < len(m.files) && !m.files[msg.index].IsDir {
+			m.currentFileContent = m.files[msg.index].Content
+			m.filePreview.SetFile(m.files[msg.index].Path, m.currentFileContent)
+		}
+		m.stats.record(msg)
+		m.updateETA()
+		
+		var logCmd tea.Cmd
+		if msg.index >= 0 && msg.index < len(m.files) && !m.files[msg.index].IsDir {
+			detail := ProgressDetail{Path: m.files[msg.index].Path, Duration: msg.duration, Status: statusDocumented, TokensUsed: msg.tokens}
+			switch {
+			case msg.tooLarge:
+				detail.Status = statusTooLarge
+				m.stats.tooLarge++
+			case !msg.apiCall:
+				detail.Status = statusUnchanged
+				m.stats.unchanged++
+			}
+			logCmd = m.recordProgress(detail)
+		}
+		
+		if m.state == StateStopping {
+			// The in-flight request finished and its output is already on disk
+			return m, tea.Sequence(logCmd, tea.Quit)
+		}
+		
+		model, cmd := m.fileFinished(msg.index, !msg.moreInBatch)
+		return model, tea.Batch(cmd, logCmd)
+		
+	case fileErrorMsg:
+		if msg.transient && m.state != StateStopping && m.queueRetry(msg.index) {
+			// Try the file again later and give its worker slot to the next file meanwhile
+			if msg.moreInBatch {
+				return m, m.scheduleRetryTick()
+			}
+			m.inFlight--
+			cmds := []tea.Cmd{m.dispatchNextFile(), m.scheduleRetryTick()}
+			return m, tea.Batch(cmds...)
+		}
+		
+		m.errors = append(m.errors, msg.err)
+		m.processedFiles++
+		var logCmd tea.Cmd
+		if msg.index >= 0 {
+			if !msg.moreInBatch {
+				m.inFlight--
+			}
+			
+			// Remember the file so it can be retried from the done screen
+			m.failedFiles = append(m.failedFiles, m.files[msg.index])
+			m.failedFileErrors = append(m.failedFileErrors, msg.err)
+			
+			// Failed requests report no duration, so time them from the last finished file
+			logCmd = m.recordProgress(ProgressDetail{
+				Path:     m.files[msg.index].Path,
+				Duration: time.Since(m.fileStartTime),
+				Status:   errorStatus(m.files[msg.index].Path, msg.err),
+			})
+		}
+		m.updateETA()
+		
+		if m.state == StateStopping {
+			return m, tea.Sequence(logCmd, tea.Quit)
+		}
+		
+		model, cmd := m.fileFinished(msg.index, !msg.moreInBatch)
+		return model, tea.Batch(cmd, logCmd)
+		
+	case progressLogErrorMsg:
+		m.logFileFailed = true
+		m.errors = append(m.errors, fmt.Sprintf("Failed to write log file %s: %s", m.config.LogFile, msg.err))
+		return m, nil
+		
+	case batchProcessedMsg:
+		// Handle each file in turn; only the last one frees the batch's worker slot
+		var model tea.Model = m
+		var cmds []tea.Cmd
+		for i, result := range msg.results {
+			more := i < len(msg.results)-1
+			switch r := result.(type) {
+			case fileProcessedMsg:
+				r.moreInBatch = more
+				result = r
+			case fileErrorMsg:
+				r.moreInBatch = more
+				result = r
+			}
+			
+			var cmd tea.Cmd
+			model, cmd = model.Update(result)
+			cmds = append(cmds, cmd)
+		}
+		return model, tea.Batch(cmds...)
+		
+	case retryDueMsg:
+		if m.state == StateStopping {
+			if m.inFlight == 0 {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if m.state == StatePaused || m.state == StateBudgetExceeded {
+			// Resume dispatches the retries that fell due in the meantime
+			return m, nil
+		}
+		
+		cmds := m.dispatchDueRetries()
+		cmds = append(cmds, m.scheduleRetryTick())
+		return m, tea.Batch(cmds...)
+		
+	case filesLoadedMsg:
+		if m.state == StateStopping {
+			return m, tea.Quit
+		}
+		
+		m.files = msg.files
+		m.fileStartTime = time.Now()
+		m.projectName = m.fileHandler.Metadata.Name
+		
+		// Load the checksums of previously documented files to detect changed sources
+		checksums, err := filehandler.LoadChecksumStore(m.outputDir)
+		if err != nil {
+			m.errors = append(m.errors, err.Error())
+		}
+		m.checksums = checksums
+		
+		// Collect tagged comments from the sources before any API call is made
+		if m.config.GenerateTechDebt {
+			annotations := techdebt.Extract(m.files)
+			if reportPath, err := techdebt.WriteReport(m.outputDir, m.inputDir, annotations); err != nil {
+				m.errors = append(m.errors, fmt.Sprintf("Failed to write technical debt report: %s", err))
+			} else {
+				m.techDebtPath, m.techDebtCount = reportPath, techdebt.Count(annotations)
+			}
+		}
+		
+		// Load when files were last documented, to detect stale documentation without checksums
+		freshness, err := filehandler.LoadFreshnessStore(m.outputDir)
+		if err != nil {
+			m.errors = append(m.errors, err.Error())
+		}
+		m.freshness = freshness
+		
+		// Count files per package so summaries run once a package is complete
+		m.packages = filehandler.PackageNames(m.inputDir, m.files, m.projectType)
+		m.packageSizes = make(map[string]int)
+		m.packageLeft = make(map[string]int)
+		for _, file := range m.files {
+			if !file.IsDir {
+				m.packageSizes[m.packages[file.Path]]++
+				m.packageLeft[m.packages[file.Path]]++
+			}
+		}
+		
+		// Start up to MaxConcurrentRequests files, writing the setup guide alongside
+		m.setupPending = true
+		cmds := []tea.Cmd{m.generateSetupDocumentation()}
+		for i := 0; i < m.workerCount(); i++ {
+			cmds = append(cmds, m.dispatchNextFile())
+		}
+		return m, tea.Batch(cmds...)
+	}
+
+	return m, nil
+}
+
+// View renders the current state of the application
+func (m Model) View() string {
+	view := m.withStatusBar(m.screenView())
+	if m.showHelp {
+		return m.renderHelpOverlay(view)
+	}
+	return view
+}
+
+// screenView renders the screen of the current state
+func (m Model) screenView() string {
+	title := m.title()
+	
+	switch m.state {
+	case StateInit:
+		return titleStyle.Render(title) + "\n\n" +
+			"Press any key to start, t to change the color theme, or ? for keyboard shortcuts"
+			
+	case StateSelectTheme:
+		var options string
+		for i, theme := range m.themes {
+			// Show a swatch of the theme's colors next to its name
+			swatch := lipgloss.NewStyle().Foreground(theme.Text).Background(theme.Background).Render(" Aa ") +
+				lipgloss.NewStyle().Foreground(theme.Primary).Render(" ■") +
+				lipgloss.NewStyle().Foreground(theme.Success).Render("■") +
+				lipgloss.NewStyle().Foreground(theme.Error).Render("■") +
+				lipgloss.NewStyle().Foreground(theme.FileHighlight).Render("■")
+			
+			if i == m.selectedTheme {
+				options += selectedStyle.Render("› " + theme.Name) + " " + swatch + "\n"
+			} else {
+				options += "  " + theme.Name + " " + swatch + "\n"
+			}
+		}
+		
+		return m.listHeader() +
+			options
+			
+	case StateSelectProfile:
+		options := "  (new configuration)\n"
+		if m.selectedProfile == 0 {
+			options = selectedStyle.Render("› (new configuration)") + "\n"
+		}
+		for i, name := range m.profileNames {
+			option := fmt.Sprintf("%s (%s)", name, m.profiles[name].Summary())
+			if i+1 == m.selectedProfile {
+				options += selectedStyle.Render("› " + option) + "\n"
+			} else {
+				options += "  " + option + "\n"
+			}
+		}
+		
+		return m.listHeader() +
+			options + "\n" +
+			infoStyle.Render("Press d to delete the selected profile") + "\n\n" +
+			renderErrors(m.errors)
+		
+	case StateSelectAPIType:
+		var options string
+		for i, apiType := range m.apiTypes {
+			option := string(apiType)
+			if i == m.selectedAPIType {
+				options += selectedStyle.Render("› " + option) + "\n"
+			} else {
+				options += "  " + option + "\n"
+			}
+		}
+		
+		return m.listHeader() +
 			options + "\n" +
 			renderErrors(m.errors)
 	
@@ -492,19 +1420,60 @@ func (m Model) View() string {
 			}
 		}
 		
-		apiTypeStr := string(m.config.APIType)
-		return titleStyle.Render(title) + "\n\n" +
-			fmt.Sprintf("Selected API: %s\n\n", apiTypeStr) +
-			"Select model (use arrow keys and enter):\n\n" +
+		return m.listHeader() +
 			options + "\n" +
 			renderErrors(m.errors)
 			
+	case StateEnterCustomEndpoint:
+		return titleStyle.Render(title) + "\n\n" +
+			"Selected API: custom (OpenAI-compatible)\n\n" +
+			"Enter the endpoint URL: " + m.customEndpoint + "\n" +
+			infoStyle.Render("e.g. http://localhost:8000/v1/chat/completions") + "\n\n" +
+			renderErrors(m.errors)
+			
+	case StateEnterCustomModel:
+		return titleStyle.Render(title) + "\n\n" +
+			fmt.Sprintf("Endpoint: %s\n\n", m.config.CustomEndpoint) +
+			"Enter the model name: " + m.customModelName + "\n\n" +
+			renderErrors(m.errors)
+			
 	case StateEnterAPIKey:
+		keyStatus := ""
+		if m.validatingKey {
+			keyStatus = m.spinner.View() + " Validating API key…\n\n"
+		} else if m.keyError != "" {
+			keyStatus = errorStyle.Render("✗ " + m.keyError) + "\n\n"
+		}
+		
 		apiTypeStr := string(m.config.APIType)
 		return titleStyle.Render(title) + "\n\n" +
 			fmt.Sprintf("Selected API: %s\n", apiTypeStr) + 
 			fmt.Sprintf("Selected model: %s\n\n", m.config.APIModel) +
 			fmt.Sprintf("Enter your %s API Key: %s\n\n", apiTypeStr, strings.Repeat("*", len(m.apiKey))) +
+			keyStatus +
+			renderErrors(m.errors)
+			
+	case StateSaveKeyPrompt:
+		return titleStyle.Render(title) + "\n\n" +
+			infoStyle.Render("✓ API key is valid") + "\n" +
+			fmt.Sprintf("Save your %s API key to the OS keychain for next time? (y/n)\n\n", m.config.APIType) +
+			renderErrors(m.errors)
+			
+	case StateAdvancedSettings:
+		var fields string
+		for i, provider := range m.rateLimitProviders {
+			field := fmt.Sprintf("%-10s %s ms", string(provider)+":", m.rateLimitInputs[i])
+			if i == m.selectedRateLimit {
+				fields += selectedStyle.Render("› " + field) + "\n"
+			} else {
+				fields += "  " + field + "\n"
+			}
+		}
+		
+		return titleStyle.Render(title) + "\n\n" +
+			"Advanced settings: minimum delay between API calls per provider\n\n" +
+			fields + "\n" +
+			infoStyle.Render("Use arrow keys to select, type to edit, Enter to confirm") + "\n\n" +
 			renderErrors(m.errors)
 			
 	case StateSelectProjectType:
@@ -518,45 +1487,42 @@ func (m Model) View() string {
 			}
 		}
 		
-		apiTypeStr := string(m.config.APIType)
-		return titleStyle.Render(title) + "\n\n" +
-			fmt.Sprintf("Using: %s / %s\n\n", apiTypeStr, m.config.APIModel) +
-			"Select project type (use arrow keys and enter):\n\n" +
+		return m.listHeader() +
 			options + "\n" +
 			renderErrors(m.errors)
 			
-	case StateSelectInputDir:
-		var dirList string
-		maxEntries := 15 // Maximum number of entries to show
-		startIndex := 0
-		
-		// If there are many entries, center the selected one
-		if len(m.dirEntries) > maxEntries && m.selectedDir > maxEntries/2 {
-			startIndex = m.selectedDir - maxEntries/2
-			if startIndex + maxEntries > len(m.dirEntries) {
-				startIndex = len(m.dirEntries) - maxEntries
-			}
-			if startIndex < 0 {
-				startIndex = 0
+	case StateSelectDocStyle:
+		var options string
+		for i, style := range m.docStyles {
+			option := fmt.Sprintf("%-10s", style)
+			description := " " + dimStyle.Render(style.Description())
+			if i == m.selectedStyle {
+				options += selectedStyle.Render("› " + option) + description + "\n"
+			} else {
+				options += "  " + option + description + "\n"
 			}
 		}
 		
-		endIndex := startIndex + maxEntries
-		if endIndex > len(m.dirEntries) {
-			endIndex = len(m.dirEntries)
-		}
-		
-		// Add current path information
-		dirList += infoStyle.Render("Current directory: " + m.inputDir) + "\n\n"
+		return m.listHeader() +
+			options + "\n" +
+			renderErrors(m.errors)
+			
+	case StateSelectInputDir:
+		var dirList string
+		startIndex, endIndex := m.dirWindow()
 		
 		// Add directory entries
 		for i := startIndex; i < endIndex; i++ {
 			entry := m.dirEntries[i]
 			name := entry.Name()
 			
-			// Add indicator for directories
+			// Add indicator for directories and checkboxes for files
 			if entry.IsDir() {
-				name += "/"
+				name = "    " + name + "/"
+			} else if m.selectedFiles[filepath.Join(m.inputDir, entry.Name())] {
+				name = "[x] " + name
+			} else {
+				name = "[ ] " + name
 			}
 			
 			if i == m.selectedDir {
@@ -571,11 +1537,23 @@ func (m Model) View() string {
 			dirList += "  ... " + fmt.Sprintf("(%d more)", len(m.dirEntries) - endIndex) + "\n"
 		}
 		
-		// Add instructions
-		dirList += "\n" + infoStyle.Render("Navigate with arrow keys, press Enter to select or enter a directory, Esc for manual input")
+		if len(m.dirEntries) == 0 {
+			dirList += "  (no matching entries)\n"
+		}
 		
-		return titleStyle.Render(title) + "\n\n" +
-			"Select input directory:\n\n" +
+		if len(m.selectedFiles) > 0 {
+			dirList += "\n" + infoStyle.Render(fmt.Sprintf("%d files selected; only these will be documented", len(m.selectedFiles))) + "\n"
+		}
+		
+		// Add the search bar
+		if m.filteringDirs {
+			dirList += "\n" + selectedStyle.Render("Filter: " + m.dirFilter) + "\n"
+			dirList += "\n" + infoStyle.Render("Type to filter, Enter to enter a directory or select a file, Esc to clear the filter")
+		} else {
+			dirList += "\n" + infoStyle.Render("Navigate with arrow keys or the mouse, press Enter or double-click to enter a directory, ← to go up, p to preview a file, d for a dry run, type or / to filter, Esc for manual input")
+		}
+		
+		return m.listHeader() +
 			dirList + "\n\n" +
 			renderErrors(m.errors)
 			
@@ -589,29 +1567,159 @@ func (m Model) View() string {
 			"Enter the output directory path: " + m.outputDir + "\n\n" +
 			renderErrors(m.errors)
 			
+	case StateEnterExcludePaths:
+		return titleStyle.Render(title) + "\n\n" +
+			"Paths to exclude, comma-separated and relative to the input directory (optional): " + m.excludePaths + "\n" +
+			dimStyle.Render("e.g. internal/generated, docs/legacy - press enter to continue") + "\n\n" +
+			renderErrors(m.errors)
+			
 	case StateProcessing:
 		progress := fmt.Sprintf("Processing %d/%d files", m.processedFiles, len(m.files))
 		
 		apiTypeStr := string(m.config.APIType)
-		return titleStyle.Render(title) + "\n\n" +
+		return m.filePreviewLayout(titleStyle.Render(title) + "\n\n" +
 			infoStyle.Render(fmt.Sprintf("API: %s / %s", apiTypeStr, m.config.APIModel)) + "\n" +
 			infoStyle.Render("Processing files from: " + m.inputDir) + "\n" +
 			infoStyle.Render("Saving documentation to: " + m.outputDir) + "\n" +
-			infoStyle.Render("Project type: " + string(m.projectType)) + "\n\n" +
+			infoStyle.Render("Project type: " + string(m.projectType)) + "\n" +
+			infoStyle.Render("Sort order: " + string(m.fileHandler.SortOrder)) + "\n\n" +
+			m.packageProgress() +
 			m.spinner.View() + " " + progress + "\n" +
+			progressBarStyle.Render(m.progress.View()) + "\n" +
+			// Rendered again on every spinner tick, which keeps the elapsed time current
+			infoStyle.Render(m.etaText() + " · Elapsed: " + formatElapsed(m.processingElapsed())) + "\n\n" +
+			m.fileProgress() +
+			m.renderRetryQueue() +
+			m.budgetWarning() +
+			renderErrors(m.errors) + "\n\n" +
+			infoStyle.Render("Press p to pause, v for verbose progress"))
+			
+	case StateBudgetExceeded:
+		usage := m.config.Usage.Run()
+		status := fmt.Sprintf("Token budget exceeded: ~%d of %d tokens used (%d/%d files processed", usage.TotalTokensEstimated, m.config.SessionTokenBudget, m.processedFiles, len(m.files))
+		if m.inFlight > 0 {
+			status += fmt.Sprintf(", %d still finishing", m.inFlight)
+		}
+		status += ")"
+		
+		return titleStyle.Render(title) + "\n\n" +
+			errorStyle.Render(status) + "\n\n" +
+			m.renderRemainingFiles() + "\n" +
+			renderErrors(m.errors) + "\n\n" +
+			"Press c to continue ignoring the budget, s to stop and save checkpoint, or r to save the settings and resume in a new session."
+			
+	case StatePaused:
+		// The paused symbol takes the spinner's place on the status line
+		status := fmt.Sprintf("⏸ Paused – press p to resume (%d/%d files processed", m.processedFiles, len(m.files))
+		if m.inFlight > 0 {
+			status += fmt.Sprintf(", %d still finishing", m.inFlight)
+		}
+		status += ")"
+		
+		return titleStyle.Render(title) + "\n\n" +
+			infoStyle.Render(fmt.Sprintf("API: %s / %s", string(m.config.APIType), m.config.APIModel)) + "\n" +
+			infoStyle.Render("Processing files from: " + m.inputDir) + "\n\n" +
+			status + "\n" +
+			infoStyle.Render(fmt.Sprintf("Estimated remaining input: ~%d tokens (~$%.4f) for %d files", m.pausedRemainingTokens, api.EstimateCost(m.config.GetActiveModel(), m.pausedRemainingTokens), len(m.files)-m.nextFile)) + "\n" +
 			progressBarStyle.Render(m.progress.View()) + "\n\n" +
-			fileStyle.Render("Current file: " + m.currentFile) + "\n\n" +
-			renderErrors(m.errors)
+			renderErrors(m.errors) + "\n\n" +
+			// Documented files are skipped on the next run, so quitting keeps the progress made
+			"Press p to resume or q to quit and save checkpoint."
 			
 	case StateDone:
 		apiTypeStr := string(m.config.APIType)
-		return titleStyle.Render(title) + "\n\n" +
+		setupStatus := infoStyle.Render("Project setup documentation: " + filepath.Join(m.outputDir, docs.SetupFileName))
+		if m.setupPending {
+			setupStatus = m.spinner.View() + " Writing project setup documentation..."
+		}
+		if m.readmePending {
+			setupStatus += "\n" + m.spinner.View() + " Writing README..."
+		} else if m.readmePath != "" {
+			setupStatus += "\n" + infoStyle.Render("README: " + m.readmePath)
+		}
+		if m.overviewPending {
+			setupStatus += "\n" + m.spinner.View() + " Writing the project overview..."
+		} else if m.overviewPath != "" {
+			setupStatus += "\n" + infoStyle.Render("Project overview: " + m.overviewPath)
+		}
+		if m.architecturePending {
+			setupStatus += "\n" + m.spinner.View() + " Writing the architecture documentation..."
+		} else if m.architecturePath != "" {
+			setupStatus += "\n" + infoStyle.Render("Architecture: " + m.architecturePath)
+		}
+		if m.glossaryPending {
+			setupStatus += "\n" + m.spinner.View() + " Writing the glossary..."
+		} else if m.glossaryPath != "" {
+			setupStatus += "\n" + infoStyle.Render("Glossary: " + m.glossaryPath)
+		}
+		if m.indexPending {
+			setupStatus += "\n" + m.spinner.View() + " Writing the documentation index..."
+		}
+		if m.indexPath != "" {
+			setupStatus += "\n" + infoStyle.Render("Documentation index: " + m.indexPath)
+		}
+		if m.htmlPath != "" {
+			setupStatus += "\n" + infoStyle.Render("HTML documentation: " + m.htmlPath)
+		}
+		if m.techDebtPath != "" {
+			setupStatus += "\n" + infoStyle.Render(fmt.Sprintf("Technical debt: %s (%d annotations)", m.techDebtPath, m.techDebtCount))
+		}
+		if m.coveragePath != "" {
+			coverage := fmt.Sprintf("Coverage report: %s (%.1f%% documented, %d files to review)", m.coveragePath, m.coveragePercent, m.reviewCount)
+			if m.reviewCount > 0 {
+				setupStatus += "\n" + errorStyle.Render(coverage)
+			} else {
+				setupStatus += "\n" + infoStyle.Render(coverage)
+			}
+		}
+		if m.uploadPending {
+			setupStatus += "\n" + m.spinner.View() + " Uploading to " + m.config.OutputBucket + "..."
+		} else if m.uploadURI != "" {
+			setupStatus += "\n" + infoStyle.Render("Uploaded to: " + m.uploadURI)
+		}
+		promptVersionStatus := ""
+		if m.stats.promptVersion > 0 {
+			promptVersionStatus = infoStyle.Render(fmt.Sprintf("Re-documented %d unchanged files for an outdated prompt version", m.stats.promptVersion)) + "\n"
+		}
+		hint := "Press s for statistics or q to quit"
+		if m.showFilePreview() {
+			hint = "Press s for statistics, pgup/pgdown to scroll the last file, or q to quit"
+		}
+		return m.filePreviewLayout(titleStyle.Render(title) + "\n\n" +
 			infoStyle.Render(fmt.Sprintf("✓ Done! Processed %d files using %s", m.processedFiles, apiTypeStr)) + "\n" +
+			promptVersionStatus +
+			infoStyle.Render("Completed in " + formatElapsed(m.processingElapsed())) + "\n" +
 			infoStyle.Render("Documentation saved to: " + m.outputDir) + "\n" +
-			infoStyle.Render("Project structure documentation: " + filepath.Join(m.outputDir, "PROJECT_STRUCTURE.md")) + "\n" +
-			infoStyle.Render("Project setup documentation: " + filepath.Join(m.outputDir, "PROJECT_SETUP.md")) + "\n\n" +
-			renderErrors(m.errors) + "\n\n" +
-			"Press q to quit"
+			infoStyle.Render("Project structure documentation: " + filepath.Join(m.outputDir, docs.StructureFileName)) + "\n" +
+			setupStatus + "\n\n" +
+			m.renderFailedFiles() + "\n" +
+			renderErrors(m.generalErrors()) + "\n\n" +
+			hint)
+			
+	case StateStats:
+		return titleStyle.Render(title) + "\n\n" +
+			"Statistics\n\n" +
+			m.renderStats() + "\n" +
+			infoStyle.Render("Press esc to go back")
+			
+	case StatePreview:
+		return titleStyle.Render(title) + "\n\n" +
+			infoStyle.Render(fmt.Sprintf("Preview: %s (first %d lines)", m.previewPath, previewLines)) + "\n\n" +
+			m.preview.View() + "\n\n" +
+			infoStyle.Render("Scroll with arrow keys, Esc to go back")
+			
+	case StateDryRunPreview:
+		return titleStyle.Render(title) + "\n\n" +
+			infoStyle.Render(fmt.Sprintf("Dry run: files in %s that would be documented with %s", m.inputDir, m.config.GetActiveModel())) + "\n\n" +
+			m.preview.View() + "\n\n" +
+			selectedStyle.Render(fmt.Sprintf("Total: %d files, %d bytes, ~%d tokens, ~$%.4f", m.dryRunFiles, m.dryRunTotal.Size, m.dryRunTotal.EstTokens, m.dryRunTotal.EstCost)) + "\n" +
+			infoStyle.Render("No API calls were made. Proceed (p) or quit (q); scroll with arrow keys, Esc to go back")
+			
+	case StateStopping:
+		return titleStyle.Render(title) + "\n\n" +
+			m.spinner.View() + " Stopping... waiting for the current request to finish\n\n" +
+			fileStyle.Render("Current file: " + m.currentFile) + "\n\n" +
+			infoStyle.Render("Press ctrl+c again to quit immediately")
 	}
 	
 	return ""
@@ -620,98 +1728,1229 @@ func (m Model) View() string {
 // processFiles processes all files in the input directory
 func (m Model) processFiles() tea.Msg {
 	// Traverse the directory
-	files, err := m.fileHandler.TraverseDirectory(m.inputDir)
+	files, err := m.fileHandler.TraverseDirectoryConcurrent(m.inputDir)
 	if err != nil {
-		return fileErrorMsg(fmt.Sprintf("Failed to traverse directory: %s", err))
+		return fileErrorMsg{index: -1, err: fmt.Sprintf("Failed to traverse directory: %s", err)}
+	}
+	
+	// Keep only the explicitly selected files, if any
+	if len(m.selectedFiles) > 0 {
+		files = filterSelected(files, m.selectedFiles)
 	}
 	
 	// Return the files loaded message first
 	return filesLoadedMsg{files: files}
 }
 
-// continueProcessing continues processing after files are loaded
-func continueProcessing(msg tea.Msg, m Model) tea.Cmd {
-	filesMsg, ok := msg.(filesLoadedMsg)
-	if !ok {
+// filterSelected keeps directories and the files present in selected
+func filterSelected(files []filehandler.FileInfo, selected map[string]bool) []filehandler.FileInfo {
+	var filtered []filehandler.FileInfo
+	for _, file := range files {
+		if file.IsDir || selected[file.Path] {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// continueProcessingAt processes the file at nextIndex, one file per command so the UI can update.
+// The index is fixed when the command is created, so concurrent commands cannot skip or repeat a file.
+func continueProcessingAt(files []filehandler.FileInfo, nextIndex int, m Model) tea.Cmd {
+	if nextIndex < 0 || nextIndex >= len(files) {
 		return nil
 	}
 	
-	files := filesMsg.files
-	
-	// Process only one file at a time, so we can update the UI
 	return func() tea.Msg {
-		// Find the next file to process
-		for i, file := range files {
-			if i < m.processedFiles {
-				continue // Skip already processed files
-			}
-			
-			if file.IsDir {
-				m.processedFiles++
-				continue
-			}
-			
-			// Update current file
-			currentFile := file.Path
-			
-			// Create relative path for output
-			relPath, err := filepath.Rel(m.inputDir, file.Path)
-			if err != nil {
-				return fileErrorMsg(fmt.Sprintf("Failed to get relative path for %s: %s", file.Path, err))
-			}
-			
-			// Create output directory with the same structure as input
-			outputPath := filepath.Join(m.outputDir, filepath.Dir(relPath))
-			if err := os.MkdirAll(outputPath, 0755); err != nil {
-				return fileErrorMsg(fmt.Sprintf("Failed to create directory %s: %s", outputPath, err))
-			}
-			
-			// Output file path
-			outputFile := filepath.Join(outputPath, filepath.Base(file.Path)+".md")
-			
-			// Check if the file has already been documented
-			if _, err := os.Stat(outputFile); err == nil {
-				// File already exists in the output directory, skip processing
-				m.processedFiles++
-				return fileProcessedMsg(currentFile + " (already documented, skipped)")
-			}
-			
-			// Generate documentation
-			doc, err := m.apiClient.GenerateDocumentation(file)
-			if err != nil {
-				return fileErrorMsg(fmt.Sprintf("Failed to generate documentation for %s: %s", file.Path, err))
-			}
-			
-			// Write documentation to file
-			if err := os.WriteFile(outputFile, []byte(doc), 0644); err != nil {
-				return fileErrorMsg(fmt.Sprintf("Failed to write documentation to %s: %s", outputFile, err))
+		file := files[nextIndex]
+		start := time.Now()
+		
+		outputFile, relPath, msg := m.checkFile(files, nextIndex)
+		if msg != nil {
+			return msg
+		}
+		
+		// Generate documentation
+		doc, err := m.apiClient.GenerateDocumentation(m.ctx, file)
+		if err != nil {
+			return fileErrorMsg{
+				index:     nextIndex,
+				err:       fmt.Sprintf("Failed to generate documentation for %s: %s", file.Path, err),
+				transient: isTransient(err),
 			}
-			
-			// Return a file processed message
-			return fileProcessedMsg(currentFile)
 		}
 		
-		// If we've processed all files, return nil
-		if m.processedFiles >= len(files) {
-			return nil
+		// A trivially short response is retried like a transient error
+		if err := output.CheckMinLength(doc, m.config.MinOutputFileBytes); err != nil {
+			return fileErrorMsg{index: nextIndex, err: fmt.Sprintf("Failed to generate documentation for %s: %s", file.Path, err), transient: true}
 		}
 		
-		return nil
+		prompt := api.BuildDocumentationPrompt(file, string(m.projectType), m.fileHandler.Metadata, m.config.DocumentationStyle)
+		return m.saveDocumentation(file, outputFile, relPath, doc, fileProcessedMsg{
+			index:    nextIndex,
+			path:     file.Path,
+			apiCall:  true,
+			duration: time.Since(start),
+			tokens:   tokenizer.Estimate(prompt) + tokenizer.Estimate(doc),
+		})
 	}
 }
 
-// Message types
-type progressMsg float64
-type fileProcessedMsg string
-type fileErrorMsg string
-type filesLoadedMsg struct {
-	files []filehandler.FileInfo
-}
-
-// renderErrors renders the error messages
-func renderErrors(errors []string) string {
-	if len(errors) == 0 {
-		return ""
+// processBatchAt documents the files at indices with as few API calls as possible. Files the
+// batch response leaves out are documented one at a time. The results are reported together
+// in a batchProcessedMsg.
+func processBatchAt(files []filehandler.FileInfo, indices []int, m Model) tea.Cmd {
+	client, ok := m.apiClient.(api.BatchDocumentationClient)
+	if !ok || len(indices) == 0 {
+		return nil
+	}
+	
+	return func() tea.Msg {
+		start := time.Now()
+		results := make([]tea.Msg, len(indices))
+		
+		// Check every file first so skipped files are not sent to the API
+		var pending []int // Positions in indices still to document
+		var batch []filehandler.FileInfo
+		outputFiles := make([]string, len(indices))
+		relPaths := make([]string, len(indices))
+		for i, index := range indices {
+			var msg tea.Msg
+			outputFiles[i], relPaths[i], msg = m.checkFile(files, index)
+			if msg != nil {
+				results[i] = msg
+				continue
+			}
+			pending = append(pending, i)
+			batch = append(batch, files[index])
+		}
+		
+		var docs map[string]string
+		if len(batch) > 0 {
+			// A failed batch is not fatal: its files are documented one at a time below
+			docs, _ = client.BatchGenerate(m.ctx, batch, m.config.MaxInputTokens)
+		}
+		
+		for _, i := range pending {
+			file := files[indices[i]]
+			doc, ok := docs[file.Path]
+			if !ok {
+				var err error
+				doc, err = client.GenerateDocumentation(m.ctx, file)
+				if err != nil {
+					results[i] = fileErrorMsg{
+						index:     indices[i],
+						err:       fmt.Sprintf("Failed to generate documentation for %s: %s", file.Path, err),
+						transient: isTransient(err),
+					}
+					continue
+				}
+			}
+			if err := output.CheckMinLength(doc, m.config.MinOutputFileBytes); err != nil {
+				results[i] = fileErrorMsg{index: indices[i], err: fmt.Sprintf("Failed to generate documentation for %s: %s", file.Path, err), transient: true}
+				continue
+			}
+			
+			results[i] = m.saveDocumentation(file, outputFiles[i], relPaths[i], doc, fileProcessedMsg{
+				index:    indices[i],
+				path:     file.Path,
+				apiCall:  true,
+				duration: time.Since(start) / time.Duration(len(pending)),
+				tokens:   tokenizer.Estimate(file.Content) + tokenizer.Estimate(doc),
+			})
+		}
+		
+		return batchProcessedMsg{results: results}
+	}
+}
+
+// checkFile prepares the output location of the file at index. It returns a message instead
+// if the file is skipped or cannot be documented.
+func (m Model) checkFile(files []filehandler.FileInfo, index int) (outputFile string, relPath string, msg tea.Msg) {
+	file := files[index]
+	
+	if file.IsDir {
+		return "", "", fileProcessedMsg{index: index, path: file.Path + " (directory, skipped)"}
+	}
+	if file.TooLarge() {
+		return "", "", fileProcessedMsg{index: index, path: file.Path + " (too large, skipped)", tooLarge: true}
+	}
+	
+	// Output file path with the same structure as input
+	outputFile, err := m.outputFileFor(file.Path)
+	if err != nil {
+		return "", "", fileErrorMsg{index: index, err: fmt.Sprintf("Failed to get relative path for %s: %s", file.Path, err)}
+	}
+	
+	// Create output directory
+	outputPath := filepath.Dir(outputFile)
+	if err := os.MkdirAll(outputPath, 0755); err != nil {
+		return "", "", fileErrorMsg{index: index, err: fmt.Sprintf("Failed to create directory %s: %s", outputPath, err)}
+	}
+	
+	// Check if the file has already been documented
+	relPath, _ = filepath.Rel(m.inputDir, file.Path)
+	if !m.config.ForceRegenerate {
+		if entry, ok := m.checksums.Get(relPath); ok {
+			// Regenerate stale documentation when the source or the prompts have changed since
+			if entry.Checksum == file.Checksum && !m.config.OutdatedPromptVersion(entry.PromptVersion) {
+				return "", "", fileProcessedMsg{index: index, path: file.Path + " (unchanged, skipped)"}
+			}
+		} else if m.freshness.IsFresh(file.Path, file.ModTime, outputFile) {
+			// Documented before checksums were recorded, and not modified since
+			return "", "", fileProcessedMsg{index: index, path: file.Path + " (already documented, skipped)"}
+		}
+	}
+	
+	return outputFile, relPath, nil
+}
+
+// saveDocumentation writes doc for file and returns done, or an error message if writing failed
+func (m Model) saveDocumentation(file filehandler.FileInfo, outputFile, relPath, doc string, done fileProcessedMsg) tea.Msg {
+	doc = output.TruncateAtHeading(doc, m.config.MaxOutputFileBytes)
+	if m.config.LintOutput {
+		lintErrors := linter.ValidateFile(doc, outputFile)
+		doc = linter.AppendQualityNotes(doc, lintErrors)
+		done.lintErrors = len(lintErrors)
+	}
+	
+	content := doc
+	if m.config.AddFrontmatter {
+		content = frontmatter.Prepend(doc, frontmatter.Meta{
+			SourceFile:    filepath.ToSlash(relPath),
+			GeneratedAt:   time.Now(),
+			APIProvider:   string(m.config.APIType),
+			Model:         m.config.GetActiveModel(),
+			ProjectType:   string(m.projectType),
+			FileSizeBytes: file.Size,
+		})
+	}
+	// Every format is rendered from the same response
+	renderers, err := output.Renderers.New(m.config.OutputFormats)
+	if err == nil {
+		err = output.WriteRendered(outputFile, content, renderers)
+	}
+	if err != nil {
+		return fileErrorMsg{index: done.index, err: fmt.Sprintf("Failed to write documentation to %s: %s", outputFile, err)}
+	}
+	
+	if m.config.OutputFormat == config.OutputFormatJSON {
+		meta := jsonoutput.Metadata{
+			SourcePath:    filepath.ToSlash(relPath),
+			GeneratedAt:   time.Now(),
+			Model:         m.config.GetActiveModel(),
+			APIType:       string(m.config.APIType),
+			ProjectType:   string(m.projectType),
+			FileExtension: filehandler.GetFileExtension(file.Path),
+			FileSizeBytes: file.Size,
+		}
+		if err := jsonoutput.WriteFile(outputFile, doc, meta); err != nil {
+			return fileErrorMsg{index: done.index, err: fmt.Sprintf("Failed to write JSON documentation for %s: %s", file.Path, err)}
+		}
+	}
+	
+	// An unchanged file is only documented again when its prompt version is outdated
+	if entry, ok := m.checksums.Get(relPath); ok && !m.config.ForceRegenerate && entry.Checksum == file.Checksum {
+		done.promptVersionChanged = true
+	}
+	
+	// Remember which version was documented. If this fails the file is only documented again next run.
+	if err := m.checksums.Set(relPath, file.Checksum, m.config.PromptVersion); err != nil {
+		done.saveErr = err.Error()
+	}
+	if err := m.freshness.Set(file.Path, time.Now()); err != nil && done.saveErr == "" {
+		done.saveErr = err.Error()
+	}
+	return done
+}
+
+// FlushProgress writes the files recorded as documented since the stores in the output
+// directory were last written, so the next run skips them
+func (m Model) FlushProgress() error {
+	return errors.Join(m.checksums.Flush(), m.freshness.Flush())
+}
+
+// nextBatch returns the indices of the files to document in one batch starting at nextFile,
+// or nil if batching is off or the next file is too large to batch
+func (m Model) nextBatch() []int {
+	n := api.NextBatch(m.config, m.apiClient, m.files[m.nextFile:], m.packages)
+	if n == 0 {
+		return nil
+	}
+	
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = m.nextFile + i
+	}
+	return indices
+}
+
+// fileFinished updates progress after a file is processed or fails. It starts the next
+// file, if dispatch is set, or, after the last one, finishes the run.
+func (m Model) fileFinished(index int, dispatch bool) (tea.Model, tea.Cmd) {
+	progress := float64(m.processedFiles) / float64(len(m.files))
+	cmds := []tea.Cmd{
+		m.progress.SetPercent(progress),
+		m.completeFile(index),
+	}
+	
+	if m.processedFiles >= len(m.files) {
+		// Generate and save project structure documentation, unless only retrying a failed file
+		if !m.retrying {
+			if _, err := m.projectDocs().WriteStructure(); err != nil {
+				m.errors = append(m.errors, fmt.Sprintf("Failed to write project structure: %s", err))
+			}
+		}
+		
+		m.state = StateDone
+		m.processingEndTime = time.Now()
+		if err := m.FlushProgress(); err != nil {
+			m.errors = append(m.errors, err.Error())
+		}
+		cmds = append(cmds, m.generateReadmeIfReady(), m.generateOverviewIfReady(), m.generateArchitectureIfReady(), m.generateGlossaryIfReady(), m.writeIndexIfReady(), m.notifyDone())
+	} else if m.state == StateProcessing && m.budgetUsed() >= 1 {
+		// Let the files in flight finish, but start no more until the user decides
+		m.state = StateBudgetExceeded
+	} else if dispatch {
+		// Start the next file in the slot this one freed
+		cmds = append(cmds, m.dispatchNextFile())
+	}
+	
+	return m, tea.Batch(cmds...)
+}
+
+// dispatchNextFile returns a command processing the next file that has not been started yet.
+// Nothing new is started while processing is paused or the token budget is exceeded.
+func (m *Model) dispatchNextFile() tea.Cmd {
+	if m.nextFile >= len(m.files) || m.state == StatePaused || m.state == StateBudgetExceeded {
+		return nil
+	}
+	
+	// Small files are documented together, taking up a single worker slot
+	if indices := m.nextBatch(); indices != nil {
+		cmd := processBatchAt(m.files, indices, *m)
+		m.nextFile += len(indices)
+		m.inFlight++
+		return cmd
+	}
+	
+	cmd := continueProcessingAt(m.files, m.nextFile, *m)
+	m.nextFile++
+	m.inFlight++
+	return cmd
+}
+
+// updateETA extrapolates the time remaining from the average time per processed file
+func (m *Model) updateETA() {
+	if m.processedFiles == 0 {
+		return
+	}
+	elapsed := time.Since(m.processingStartTime)
+	remaining := len(m.files) - m.processedFiles
+	m.eta = elapsed / time.Duration(m.processedFiles) * time.Duration(remaining)
+}
+
+// etaText renders the estimated time remaining for the processing screen
+func (m Model) etaText() string {
+	// Early estimates swing too much to be useful
+	if m.processedFiles < minFilesForETA {
+		return "ETA: Estimating…"
+	}
+	
+	// Longer estimates are rounded so they do not change on every file
+	eta := m.eta.Round(time.Second)
+	if eta > 30*time.Second {
+		eta = eta.Round(5 * time.Second)
+	}
+	return "ETA: " + eta.String()
+}
+
+// processingElapsed returns the time spent processing, up to now or until every file was processed
+func (m Model) processingElapsed() time.Duration {
+	end := time.Now()
+	if !m.processingEndTime.IsZero() {
+		end = m.processingEndTime
+	}
+	return end.Sub(m.processingStartTime)
+}
+
+// formatElapsed formats d as minutes and seconds, e.g. "2m 5s"
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// retryFailedFile processes a single failed file again
+func (m Model) retryFailedFile(index int) (tea.Model, tea.Cmd) {
+	file := m.failedFiles[index]
+	errMsg := m.failedFileErrors[index]
+	
+	// Forget the failure; it is recorded again if the retry fails too
+	m.failedFiles = append(m.failedFiles[:index:index], m.failedFiles[index+1:]...)
+	m.failedFileErrors = append(m.failedFileErrors[:index:index], m.failedFileErrors[index+1:]...)
+	for i, e := range m.errors {
+		if e == errMsg {
+			m.errors = append(m.errors[:i:i], m.errors[i+1:]...)
+			break
+		}
+	}
+	if m.selectedFailed >= len(m.failedFiles) && m.selectedFailed > 0 {
+		m.selectedFailed--
+	}
+	
+	m.files = []filehandler.FileInfo{file}
+	m.processedFiles = 0
+	m.nextFile = 0
+	m.inFlight = 0
+	m.errorRetryQueue = nil
+	m.retryCounts = nil
+	m.packageSizes = map[string]int{m.packages[file.Path]: 1}
+	m.packageLeft = map[string]int{m.packages[file.Path]: 1}
+	m.retrying = true
+	m.processingStartTime = time.Now()
+	m.processingEndTime = time.Time{}
+	m.state = StateProcessing
+	
+	dispatch := m.dispatchNextFile()
+	return m, tea.Batch(
+		m.progress.SetPercent(0),
+		dispatch,
+		m.spinner.Tick,
+	)
+}
+
+// generalErrors returns the errors not tied to a failed file
+func (m Model) generalErrors() []string {
+	fileErrors := make(map[string]bool, len(m.failedFileErrors))
+	for _, e := range m.failedFileErrors {
+		fileErrors[e] = true
+	}
+	
+	var errors []string
+	for _, e := range m.errors {
+		if !fileErrors[e] {
+			errors = append(errors, e)
+		}
+	}
+	return errors
+}
+
+// renderFailedFiles renders the failed files as a selectable list
+func (m Model) renderFailedFiles() string {
+	if len(m.failedFiles) == 0 {
+		return ""
+	}
+	
+	result := errorStyle.Render(fmt.Sprintf("%d files failed (use arrow keys to select, r to retry):", len(m.failedFiles))) + "\n"
+	for i, e := range m.failedFileErrors {
+		if i == m.selectedFailed {
+			result += selectedStyle.Render("› " + e) + "\n"
+		} else {
+			result += "  " + errorStyle.Render(e) + "\n"
+		}
+	}
+	return result
+}
+
+// workerCount returns the number of files processed at the same time
+func (m Model) workerCount() int {
+	if m.config.MaxConcurrentRequests < 1 {
+		return 1
+	}
+	return m.config.MaxConcurrentRequests
+}
+
+// Pause stops starting new files. Requests already in flight are allowed to finish.
+func (m Model) Pause() (tea.Model, tea.Cmd) {
+	if m.state != StateProcessing {
+		return m, nil
+	}
+	m.state = StatePaused
+	// No new file starts while paused, so the estimate holds until Resume
+	m.pausedRemainingTokens = m.remainingTokens()
+	return m, nil
+}
+
+// Resume continues processing after Pause, refilling every free worker slot
+func (m Model) Resume() (tea.Model, tea.Cmd) {
+	if m.state != StatePaused {
+		return m, nil
+	}
+	m.state = StateProcessing
+	
+	cmds := m.dispatchDueRetries()
+	for m.inFlight < m.workerCount() && m.nextFile < len(m.files) {
+		cmds = append(cmds, m.dispatchNextFile())
+	}
+	cmds = append(cmds, m.scheduleRetryTick())
+	return m, tea.Batch(cmds...)
+}
+
+// maxRemainingShown is how many of the files not yet documented the budget screen lists
+const maxRemainingShown = 10
+
+// renderRemainingFiles lists the files that have not been started, relative to the input directory
+func (m Model) renderRemainingFiles() string {
+	var remaining []string
+	for _, file := range m.files[m.nextFile:] {
+		if !file.IsDir {
+			relPath, _ := filepath.Rel(m.inputDir, file.Path)
+			remaining = append(remaining, relPath)
+		}
+	}
+	for _, entry := range m.errorRetryQueue {
+		relPath, _ := filepath.Rel(m.inputDir, m.files[entry.index].Path)
+		remaining = append(remaining, relPath+" (waiting to be retried)")
+	}
+	if len(remaining) == 0 {
+		return infoStyle.Render("Every file has been started.") + "\n"
+	}
+	
+	s := fmt.Sprintf("%d files not documented yet:\n", len(remaining))
+	for i, path := range remaining {
+		if i == maxRemainingShown {
+			s += fmt.Sprintf("  ... (%d more)\n", len(remaining)-maxRemainingShown)
+			break
+		}
+		s += fileStyle.Render("  " + path) + "\n"
+	}
+	return s
+}
+
+// budgetWarning warns once most of the session token budget has been used
+func (m Model) budgetWarning() string {
+	used := m.budgetUsed()
+	if used < config.BudgetWarningFraction {
+		return ""
+	}
+	return errorStyle.Render(fmt.Sprintf("⚠ %.0f%% of the token budget of %d used", used*100, m.config.SessionTokenBudget)) + "\n\n"
+}
+
+// resumeProfileName is the profile the budget screen saves the settings in for a new session
+const resumeProfileName = "resume"
+
+// budgetUsed returns the share of the session token budget used so far, or 0 if there is no
+// budget or the user chose to continue past it
+func (m Model) budgetUsed() float64 {
+	if m.ignoreBudget || m.config.Usage == nil {
+		return 0
+	}
+	return m.config.Usage.Run().BudgetUsed(m.config.SessionTokenBudget)
+}
+
+// BudgetExceeded reports whether the run stopped because the session token budget ran out
+func (m Model) BudgetExceeded() bool {
+	return m.budgetExceeded
+}
+
+// ResumeCommand returns how to resume in a new session, if the settings were saved for it
+func (m Model) ResumeCommand() string {
+	return m.resumeCommand
+}
+
+// remainingTokens estimates the prompt tokens still needed for files that have not been started
+func (m Model) remainingTokens() int {
+	tokens := 0
+	for _, file := range m.files[m.nextFile:] {
+		if !file.IsDir {
+			// The prompt of each file, as estimated by the dry run
+			tokens += tokenizer.Estimate(api.BuildDocumentationPrompt(file, string(m.projectType), m.fileHandler.Metadata, m.config.DocumentationStyle))
+		}
+	}
+	return tokens
+}
+
+// outputFileFor returns the documentation path in the output directory for a source file
+func (m Model) outputFileFor(path string) (string, error) {
+	if _, err := filepath.Rel(m.inputDir, path); err != nil {
+		return "", err
+	}
+	return filepath.Join(m.outputDir, config.ComputeOutputPath(m.config, m.inputDir, path)), nil
+}
+
+// completeFile marks the file at index as finished and, once every file in its package
+// is done, returns a command generating the package summary
+func (m *Model) completeFile(index int) tea.Cmd {
+	if index < 0 || index >= len(m.files) || m.files[index].IsDir {
+		return nil
+	}
+	
+	pkg := m.packages[m.files[index].Path]
+	m.lastPackage = pkg
+	m.packageLeft[pkg]--
+	if m.packageLeft[pkg] > 0 || !m.config.GenerateDirectorySummaries {
+		return nil
+	}
+	
+	return m.summarizePackage(pkg)
+}
+
+// packageProgress returns the header line showing how far the current package is, or ""
+// before the first file has finished
+func (m Model) packageProgress() string {
+	if m.lastPackage == "" {
+		return ""
+	}
+	size := m.packageSizes[m.lastPackage]
+	return infoStyle.Render(fmt.Sprintf("Package: %s (%d/%d files)", m.lastPackage, size-m.packageLeft[m.lastPackage], size)) + "\n"
+}
+
+// summarizePackage generates README_SUMMARY.md for a package from its files' documentation.
+// The summary is written to the directory containing all of the package's files.
+func (m Model) summarizePackage(pkg string) tea.Cmd {
+	return func() tea.Msg {
+		var paths []string
+		for _, file := range m.files {
+			if !file.IsDir && m.packages[file.Path] == pkg {
+				paths = append(paths, file.Path)
+			}
+		}
+		if len(paths) == 0 {
+			return dirSummaryMsg{}
+		}
+		dir := commonDir(paths)
+		
+		relDir, err := filepath.Rel(m.inputDir, dir)
+		if err != nil {
+			return dirSummaryMsg{err: fmt.Sprintf("Failed to get relative path for %s: %s", dir, err)}
+		}
+		
+		// Collect the documentation written for each file in the package
+		fileDocs := make(map[string]string)
+		for _, path := range paths {
+			outputFile, err := m.outputFileFor(path)
+			if err != nil {
+				continue
+			}
+			name, err := filepath.Rel(dir, path)
+			if err != nil {
+				continue
+			}
+			if doc, err := os.ReadFile(outputFile); err == nil {
+				fileDocs[filepath.ToSlash(name)] = frontmatter.Strip(string(doc))
+			}
+		}
+		
+		// Files that all failed leave nothing to summarize
+		if len(fileDocs) == 0 {
+			return dirSummaryMsg{}
+		}
+		
+		summary, err := docs.GenerateDirectorySummary(m.ctx, pkg, fileDocs, m.apiClient)
+		if err != nil {
+			return dirSummaryMsg{err: fmt.Sprintf("Failed to generate summary for %s: %s", pkg, err)}
+		}
+		
+		summaryFile := filepath.Join(m.outputDir, relDir, docs.SummaryFileName)
+		if err := os.WriteFile(summaryFile, []byte(summary), 0644); err != nil {
+			return dirSummaryMsg{err: fmt.Sprintf("Failed to write summary to %s: %s", summaryFile, err)}
+		}
+		
+		return dirSummaryMsg{}
+	}
+}
+
+// commonDir returns the deepest directory containing all of paths
+func commonDir(paths []string) string {
+	dir := filepath.Dir(paths[0])
+	for _, path := range paths[1:] {
+		for !strings.HasPrefix(path, dir+string(filepath.Separator)) && filepath.Dir(dir) != dir {
+			dir = filepath.Dir(dir)
+		}
+	}
+	return dir
+}
+
+// openSelectedDir navigates into the selected directory entry, or up for ".."
+func (m *Model) openSelectedDir() {
+	if m.selectedDir >= len(m.dirEntries) || !m.dirEntries[m.selectedDir].IsDir() {
+		return
+	}
+	entry := m.dirEntries[m.selectedDir]
+	
+	if entry.Name() == ".." {
+		m.openParentDir()
+		return
+	}
+	
+	// Go into the selected directory
+	newPath := filepath.Join(m.inputDir, entry.Name())
+	m.inputDir = newPath
+	m.dirHistory = append(m.dirHistory, newPath)
+	
+	// Reload directory entries
+	if err := m.loadDirectoryEntries(m.inputDir); err != nil {
+		m.errors = append(m.errors, fmt.Sprintf("Error loading directory: %s", err))
+	}
+}
+
+// openParentDir goes up one directory in the directory browser
+func (m *Model) openParentDir() {
+	if len(m.dirHistory) > 1 {
+		m.dirHistory = m.dirHistory[:len(m.dirHistory)-1]
+		m.inputDir = m.dirHistory[len(m.dirHistory)-1]
+	} else {
+		// Above the starting directory there is no history to go back to
+		parent := filepath.Dir(m.inputDir)
+		if parent == m.inputDir {
+			return
+		}
+		m.inputDir = parent
+		m.dirHistory = []string{parent}
+	}
+	
+	// Reload directory entries
+	if err := m.loadDirectoryEntries(m.inputDir); err != nil {
+		m.errors = append(m.errors, fmt.Sprintf("Error loading directory: %s", err))
+	}
+}
+
+// setDirFilter narrows the directory entries to names containing filter, ignoring case,
+// keeping the selection within the entries shown
+func (m *Model) setDirFilter(filter string) {
+	m.dirFilter = filter
+	m.dirEntries = DirectoryQuickFilter(m.allDirEntries, filter)
+	
+	if m.selectedDir >= len(m.dirEntries) {
+		m.selectedDir = len(m.dirEntries) - 1
+	}
+	if m.selectedDir < 0 {
+		m.selectedDir = 0
+	}
+}
+
+// DirectoryQuickFilter returns the entries whose names contain filter, ignoring case, in
+// their original order. An empty filter keeps every entry.
+func DirectoryQuickFilter(entries []os.DirEntry, filter string) []os.DirEntry {
+	if filter == "" {
+		return entries
+	}
+	
+	filter = strings.ToLower(filter)
+	var matches []os.DirEntry
+	for _, entry := range entries {
+		if strings.Contains(strings.ToLower(entry.Name()), filter) {
+			matches = append(matches, entry)
+		}
+	}
+	return matches
+}
+
+// toggleFileSelection adds or removes a file from the explicit selection
+func (m *Model) toggleFileSelection(path string) {
+	if m.selectedFiles[path] {
+		delete(m.selectedFiles, path)
+		return
+	}
+	m.selectedFiles[path] = true
+}
+
+// toggleAllFiles selects every file entry shown, or deselects them if all are already selected
+func (m *Model) toggleAllFiles() {
+	allSelected := true
+	var paths []string
+	for _, entry := range m.dirEntries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(m.inputDir, entry.Name())
+		paths = append(paths, path)
+		if !m.selectedFiles[path] {
+			allSelected = false
+		}
+	}
+	
+	for _, path := range paths {
+		if allSelected {
+			delete(m.selectedFiles, path)
+		} else {
+			m.selectedFiles[path] = true
+		}
+	}
+}
+
+// openPreview loads the first lines of a file into the preview pane
+func (m *Model) openPreview(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.errors = append(m.errors, fmt.Sprintf("Error reading file: %s", err))
+		return
+	}
+	
+	lines := strings.Split(string(data), "\n")
+	if len(lines) > previewLines {
+		lines = lines[:previewLines]
+	}
+	
+	m.preview = m.newViewport()
+	m.preview.SetContent(fileStyle.Render(strings.Join(lines, "\n")))
+	m.previewPath = path
+	m.state = StatePreview
+}
+
+// newViewport creates a scrollable pane sized to the terminal
+func (m Model) newViewport() viewport.Model {
+	width, height := 80, 20
+	if m.width > 4 {
+		width = m.width - 4
+	}
+	if m.height > 10+statusBarHeight {
+		height = m.height - 10 - statusBarHeight
+	}
+	return viewport.New(width, height)
+}
+
+// dryRun lists the files in the current directory that would be documented, with estimated cost
+func (m Model) dryRun() tea.Cmd {
+	return func() tea.Msg {
+		files, err := m.fileHandler.TraverseDirectoryConcurrent(m.inputDir)
+		if err != nil {
+			return dryRunMsg{err: fmt.Sprintf("Failed to traverse directory: %s", err)}
+		}
+		
+		if len(m.selectedFiles) > 0 {
+			files = filterSelected(files, m.selectedFiles)
+		}
+		
+		results := api.DryRun(m.ctx, m.config, files)
+		return dryRunMsg{table: api.FormatDryRunTable(m.inputDir, results), total: api.DryRunTotal(results), files: len(results)}
+	}
+}
+
+// dirWindow returns the range of directory entries shown, keeping the selection centered
+func (m Model) dirWindow() (startIndex, endIndex int) {
+	// If there are many entries, center the selected one
+	if len(m.dirEntries) > maxDirEntries && m.selectedDir > maxDirEntries/2 {
+		startIndex = m.selectedDir - maxDirEntries/2
+		if startIndex + maxDirEntries > len(m.dirEntries) {
+			startIndex = len(m.dirEntries) - maxDirEntries
+		}
+		if startIndex < 0 {
+			startIndex = 0
+		}
+	}
+	
+	endIndex = startIndex + maxDirEntries
+	if endIndex > len(m.dirEntries) {
+		endIndex = len(m.dirEntries)
+	}
+	return startIndex, endIndex
+}
+
+// dirBrowserHeader renders everything above the entry list in the directory browser
+func (m Model) dirBrowserHeader(title string) string {
+	return titleStyle.Render(title) + "\n\n" +
+		"Select input directory:\n\n" +
+		infoStyle.Render("Current directory: ") + renderBreadcrumb(m.inputDir) + "\n\n"
+}
+
+// listHeader renders the lines above the options of a list screen
+func (m Model) listHeader() string {
+	header := titleStyle.Render(m.title()) + "\n\n"
+	
+	switch m.state {
+	case StateSelectTheme:
+		return header + "Select a color theme (use arrow keys and enter, esc to cancel):\n\n"
+	case StateSelectProfile:
+		return header + "Select a saved profile (use arrow keys and enter):\n\n"
+	case StateSelectAPIType:
+		return header + "Select API type (use arrow keys and enter):\n\n"
+	case StateSelectAPIModel:
+		return header +
+			fmt.Sprintf("Selected API: %s\n\n", string(m.config.APIType)) +
+			"Select model (use arrow keys and enter):\n\n"
+	case StateSelectProjectType:
+		keySource := ""
+		if m.keyFromEnv {
+			keySource = dimStyle.Render(" (from environment)")
+		}
+		return header +
+			fmt.Sprintf("Using: %s / %s%s\n\n", string(m.config.APIType), m.config.APIModel, keySource) +
+			"Select project type (use arrow keys and enter):\n\n"
+	case StateSelectDocStyle:
+		return header +
+			fmt.Sprintf("Project type: %s\n\n", string(m.projectType)) +
+			"Select documentation style (use arrow keys and enter):\n\n"
+	case StateSelectInputDir:
+		return m.dirBrowserHeader(m.title())
+	}
+	return header
+}
+
+// listCursor returns the selected index and length of the list on the current screen,
+// or nil if the screen has no list
+func (m *Model) listCursor() (*int, int) {
+	switch m.state {
+	case StateSelectTheme:
+		return &m.selectedTheme, len(m.themes)
+	case StateSelectProfile:
+		return &m.selectedProfile, len(m.profileNames) + 1
+	case StateSelectAPIType:
+		return &m.selectedAPIType, len(m.apiTypes)
+	case StateSelectAPIModel:
+		return &m.selectedModel, len(m.apiModels)
+	case StateSelectProjectType:
+		return &m.selectedType, len(m.projectTypes)
+	case StateSelectDocStyle:
+		return &m.selectedStyle, len(m.docStyles)
+	case StateSelectInputDir:
+		return &m.selectedDir, len(m.dirEntries)
+	}
+	return nil, 0
+}
+
+// entryAtRow maps a screen row to the index of the list entry rendered there.
+// headerRows is the number of rows above the list. It returns -1 for rows outside the list.
+func entryAtRow(y, headerRows, startIndex, endIndex int) int {
+	index := startIndex + y - headerRows
+	if y < headerRows || index >= endIndex {
+		return -1
+	}
+	return index
+}
+
+// handleMouse moves the selection of the current list with clicks and the scroll wheel.
+// Double-clicking an entry is the same as pressing enter on it.
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	cursor, count := m.listCursor()
+	if cursor == nil || msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		if *cursor > 0 {
+			*cursor--
+		}
+		return m, nil
+	case tea.MouseButtonWheelDown:
+		if *cursor < count-1 {
+			*cursor++
+		}
+		return m, nil
+	case tea.MouseButtonLeft:
+	default:
+		return m, nil
+	}
+	
+	// Only the directory browser scrolls; other lists are shown in full
+	startIndex, endIndex := 0, count
+	if m.state == StateSelectInputDir {
+		startIndex, endIndex = m.dirWindow()
+	}
+	headerRows := strings.Count(m.listHeader(), "\n")
+	index := entryAtRow(msg.Y, headerRows, startIndex, endIndex)
+	if index < 0 {
+		return m, nil
+	}
+	
+	now := time.Now()
+	doubleClick := index == *cursor && msg.Y == m.lastClickY && now.Sub(m.lastClickTime) <= doubleClickInterval
+	*cursor = index
+	
+	if doubleClick {
+		// Reset so a third click does not count as another double click
+		m.lastClickTime = time.Time{}
+		return m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+	
+	m.lastClickTime = now
+	m.lastClickY = msg.Y
+	return m, nil
+}
+
+// Config returns the configuration used by the model, so callers can apply command-line flags
+func (m Model) Config() *config.Config {
+	return m.config
+}
+
+// SetInputDir opens the directory browser in dir instead of the working directory and
+// preselects the project type detected there
+func (m *Model) SetInputDir(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("error accessing directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path is not a directory: %s", dir)
+	}
+	
+	m.inputDir = dir
+	m.dirHistory = []string{dir}
+	detectedType := filehandler.DetectProjectType(dir)
+	for i, projectType := range m.projectTypes {
+		if projectType == detectedType {
+			m.selectedType = i
+			break
+		}
+	}
+	return nil
+}
+
+// FileHandler returns the file handler used to traverse the input directory
+func (m Model) FileHandler() *filehandler.FileHandler {
+	return m.fileHandler
+}
+
+// isTextEntry reports whether the current screen is a free-text input field
+func (m Model) isTextEntry() bool {
+	switch m.state {
+	case StateEnterCustomEndpoint, StateEnterCustomModel, StateEnterAPIKey, StateEnterInputDir, StateEnterOutputDir, StateEnterExcludePaths:
+		return true
+	case StateSelectInputDir:
+		return m.filteringDirs
+	}
+	return false
+}
+
+// shutdown cancels in-flight API calls and quits, waiting for the current file while processing
+func (m Model) shutdown() (tea.Model, tea.Cmd) {
+	m.cancelFunc()
+	
+	state := m.state
+	if state != StateProcessing && !((state == StatePaused || state == StateBudgetExceeded) && m.inFlight > 0) {
+		return m, tea.Quit
+	}
+	
+	m.state = StateStopping
+	return m, tea.Tick(stopTimeout, func(time.Time) tea.Msg {
+		return stopTimeoutMsg{}
+	})
+}
+
+// validateKey checks the API key in the background and whether it is already in the keychain
+func (m Model) validateKey() tea.Cmd {
+	client := m.apiClient
+	apiKey := m.apiKey
+	apiType := m.config.APIType
+	useKeyring := m.config.UseKeyring
+	
+	return func() tea.Msg {
+		if err := client.ValidateKey(m.ctx); err != nil {
+			return keyValidatedMsg{err: err}
+		}
+		
+		saved := false
+		if useKeyring {
+			stored, err := config.NewKeyringStore().Get(config.KeyringService, config.KeyringKeyName(apiType))
+			saved = err == nil && stored == apiKey
+		}
+		return keyValidatedMsg{savedInKeyring: saved}
+	}
+}
+
+// applyProfile loads the named profile into the config and preselects its choices in every list
+func (m *Model) applyProfile(name string) {
+	profile := m.profiles[name]
+	profile.Apply(m.config)
+	m.profileName = name
+	
+	for i, apiType := range m.apiTypes {
+		if apiType == m.config.APIType {
+			m.selectedAPIType = i
+		}
+	}
+	m.apiModels = types.APIModelMap[m.config.APIType]
+	m.selectedModel = 0
+	for i, model := range m.apiModels {
+		if model == m.config.APIModel {
+			m.selectedModel = i
+		}
+	}
+	m.customEndpoint = m.config.CustomEndpoint
+	m.customModelName = m.config.CustomModelName
+	
+	for i, projectType := range m.projectTypes {
+		if string(projectType) == profile.ProjectType {
+			m.selectedType = i
+		}
+	}
+	
+	m.apiKey = m.config.GetActiveAPIKey()
+}
+
+// deleteProfile removes the named profile from the profile list and from disk
+func (m *Model) deleteProfile(name string) {
+	delete(m.profiles, name)
+	if err := m.profiles.Save(); err != nil {
+		m.errors = append(m.errors, fmt.Sprintf("Failed to save profiles: %s", err))
+	}
+	
+	m.profileNames = m.profiles.Names()
+	if m.selectedProfile > len(m.profileNames) {
+		m.selectedProfile = len(m.profileNames)
+	}
+}
+
+// UseProfile loads the named saved profile, so the start screen skips straight to the API key
+func (m *Model) UseProfile(name string) error {
+	if _, ok := m.profiles[name]; !ok {
+		return fmt.Errorf("profile %q not found", name)
+	}
+	m.applyProfile(name)
+	return nil
+}
+
+// SaveProfile saves the current configuration and project type as the named profile
+func (m Model) SaveProfile(name string) error {
+	m.profiles[name] = config.NewProfile(m.config, string(m.projectType))
+	return m.profiles.Save()
+}
+
+// enterAPIKey moves on to API key entry, prefilled with a key loaded from the keychain or
+// environment. A key from the environment is used as is, without showing the screen, and
+// APIs authenticating without a key, such as Bedrock, skip the screen too.
+func (m Model) enterAPIKey() Model {
+	m.apiKey = m.config.GetActiveAPIKey()
+	m.keyFromEnv = m.config.APIKeyFromEnv(m.config.APIType)
+	if !m.keyFromEnv && config.RequiresAPIKey(m.config.APIType) {
+		m.state = StateEnterAPIKey
+		return m
+	}
+	
+	if err := m.createAPIClient(); err != nil {
+		m.errors = append(m.errors, fmt.Sprintf("Error creating API client: %s", err))
+		m.keyFromEnv = false
+		m.state = StateEnterAPIKey
+		return m
+	}
+	return m.continueAfterAPIKey()
+}
+
+// continueAfterAPIKey moves on from API key entry to advanced settings or project selection
+func (m Model) continueAfterAPIKey() Model {
+	// Offer per-provider rate limits when several providers are configured
+	if providers := m.config.ConfiguredAPITypes(); len(providers) > 1 {
+		m.rateLimitProviders = providers
+		m.rateLimitInputs = make([]string, len(providers))
+		for i, provider := range providers {
+			m.rateLimitInputs[i] = fmt.Sprintf("%d", m.config.GetRateLimit(provider).Milliseconds())
+		}
+		m.selectedRateLimit = 0
+		m.state = StateAdvancedSettings
+		return m
+	}
+	
+	m.state = StateSelectProjectType
+	return m
+}
+
+// Message types
+type progressMsg float64
+type dirSummaryMsg struct {
+	err string
+}
+type notificationMsg struct {
+	err string
+}
+type setupDocMsg struct {
+	err string
+}
+type readmeMsg struct {
+	path string
+	err  string
+}
+type overviewMsg struct {
+	path string
+	err  string
+}
+type architectureMsg struct {
+	path string
+	err  string
+}
+type glossaryMsg struct {
+	path string
+	err  string
+}
+type indexMsg struct {
+	indexPath    string
+	htmlPath     string
+	coveragePath    string
+	coveragePercent float64 // Files with up-to-date documentation, from 0 to 100
+	reviewCount     int     // Files scoring below MinDocScore in the coverage report
+	err          string
+}
+type uploadMsg struct {
+	uri string
+	err string
+}
+type dryRunMsg struct {
+	table string
+	total api.DryRunResult
+	files int
+	err   string
+}
+type keyValidatedMsg struct {
+	err            error
+	savedInKeyring bool
+}
+type stopTimeoutMsg struct{}
+type fileProcessedMsg struct {
+	index                int
+	path                 string
+	apiCall              bool          // False if the file was skipped
+	tooLarge             bool          // Skipped for being too large to document
+	duration             time.Duration // Time taken to document the file
+	tokens               int           // Estimated prompt and response tokens
+	lintErrors           int           // Markdown problems found with LintOutput
+	promptVersionChanged bool          // Unchanged, but documented again for an outdated prompt version
+	moreInBatch          bool          // More results of the same batch follow
+	saveErr              string        // Recording the file as documented failed, so it is documented again next run
+}
+type fileErrorMsg struct {
+	index       int
+	err         string
+	transient   bool // Rate limit or network error, retried automatically
+	moreInBatch bool // More results of the same batch follow
+}
+type batchProcessedMsg struct {
+	results []tea.Msg // A fileProcessedMsg or fileErrorMsg per file in the batch
+}
+type filesLoadedMsg struct {
+	files []filehandler.FileInfo
+}
+
+// applyProjectConfig merges .structura.yaml from the input directory into the config.
+// Choices already made in the TUI take precedence over the file.
+func (m *Model) applyProjectConfig() {
+	projectConfig, err := config.LoadProjectConfig(m.inputDir)
+	if err != nil {
+		m.errors = append(m.errors, fmt.Sprintf("Error loading project config: %s", err))
+		return
+	}
+	if projectConfig == nil {
+		return
+	}
+	
+	apiModel, style := m.config.APIModel, m.config.DocumentationStyle
+	config.MergeProjectConfig(m.config, projectConfig)
+	m.config.APIModel, m.config.DocumentationStyle = apiModel, style
+	
+	// Prefill the output directory from the project config
+	if m.outputDir == "" {
+		m.outputDir = m.config.OutputDir
+	}
+}
+
+// createAPIClient creates the documentation client for the configured API type
+func (m *Model) createAPIClient() error {
+	// Transient errors are retried through the retry queue, so the client tries each request once
+	m.config.CallerRetries = true
+	client, err := api.CreateDocumentationClient(m.config, false)
+	if err != nil {
+		return err
+	}
+	m.apiClient = client
+	return nil
+}
+
+// renderErrors renders the error messages
+func renderErrors(errors []string) string {
+	if len(errors) == 0 {
+		return ""
 	}
 	
 	result := errorStyle.Render("Errors:") + "\n"
@@ -748,8 +2987,11 @@ func (m *Model) loadDirectoryEntries(path string) error {
 	}
 	
 	// Combine directories and files
-	m.dirEntries = append(dirs, files...)
+	m.allDirEntries = append(dirs, files...)
+	m.dirEntries = m.allDirEntries
 	m.selectedDir = 0
+	m.dirFilter = ""
+	m.filteringDirs = false
 	
 	return nil
 }
@@ -765,119 +3007,303 @@ func (d *dirEntry) IsDir() bool                { return d.isDir }
 func (d *dirEntry) Type() os.FileMode          { return os.ModeDir }
 func (d *dirEntry) Info() (os.FileInfo, error) { return nil, nil }
 
-// generateStructureDocumentation creates documentation for the project structure and setup
-func (m Model) generateStructureDocumentation() {
-	// 1. Generate project structure documentation
-	structureDoc := "# Project Structure\n\n"
-	structureDoc += "This document provides an overview of the project's directory structure and organization.\n\n"
+// projectDocs returns the writer of the documents describing the project as a whole
+func (m Model) projectDocs() *docs.ProjectDocs {
+	return &docs.ProjectDocs{
+		Client:      m.apiClient,
+		Config:      m.config,
+		RootDir:     m.inputDir,
+		OutputDir:   m.outputDir,
+		ProjectType: m.projectType,
+		Files:       m.files,
+	}
+}
+
+// generateSetupDocumentation writes PROJECT_SETUP.md. It runs alongside file processing and
+// falls back to generic instructions if the API cannot produce a setup guide.
+func (m Model) generateSetupDocumentation() tea.Cmd {
+	projectDocs := m.projectDocs()
+	return func() tea.Msg {
+		if _, err := projectDocs.WriteSetup(m.ctx); err != nil {
+			return setupDocMsg{err: fmt.Sprintf("Failed to write setup documentation: %s", err)}
+		}
+		return setupDocMsg{}
+	}
+}
+
+// generateReadmeIfReady returns a command writing README.md once all files and the setup
+// guide are done, if README generation is enabled
+func (m *Model) generateReadmeIfReady() tea.Cmd {
+	if !m.config.GenerateReadme || m.retrying || m.setupPending || m.readmePending || m.readmePath != "" {
+		return nil
+	}
+	if m.state != StateDone {
+		return nil
+	}
+	
+	m.readmePending = true
+	return m.generateReadme()
+}
+
+// generateOverviewIfReady returns a command writing PROJECT_OVERVIEW.md once all files, the
+// structure and the setup guide are done, if overview generation is enabled
+func (m *Model) generateOverviewIfReady() tea.Cmd {
+	if !m.config.GenerateProjectOverview || m.retrying || m.setupPending || m.overviewPending || m.overviewPath != "" {
+		return nil
+	}
+	if m.state != StateDone {
+		return nil
+	}
+	
+	m.overviewPending = true
+	return m.generateProjectOverview()
+}
+
+// generateArchitectureIfReady returns a command writing ARCHITECTURE.md once all files are
+// done, if architecture documentation is enabled
+func (m *Model) generateArchitectureIfReady() tea.Cmd {
+	if !m.config.GenerateArchitectureDocs || m.retrying || m.architecturePending || m.architecturePath != "" {
+		return nil
+	}
+	if m.state != StateDone {
+		return nil
+	}
+	
+	m.architecturePending = true
+	return m.generateArchitecture()
+}
+
+// generateGlossaryIfReady returns a command writing GLOSSARY.md once all files are done, if
+// glossary generation is enabled
+func (m *Model) generateGlossaryIfReady() tea.Cmd {
+	if !m.config.GenerateGlossary || m.retrying || m.glossaryPending || m.glossaryPath != "" {
+		return nil
+	}
+	if m.state != StateDone {
+		return nil
+	}
+	
+	m.glossaryPending = true
+	return m.generateGlossary()
+}
+
+// notifyDone returns a command showing a desktop notification and posting the results to the
+// webhook once processing has finished, if enabled. Retrying a single failed file does not notify.
+func (m Model) notifyDone() tea.Cmd {
+	if m.retrying {
+		return nil
+	}
+	
+	var notifiers []notification.Notifier
+	if m.config.DesktopNotification {
+		notifiers = append(notifiers, notification.DesktopNotifier{Title: "Structura"})
+	}
+	if m.config.WebhookURL != "" {
+		notifiers = append(notifiers, notification.NewWebhookNotifier(m.config.WebhookURL, m.config.WebhookSecret))
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
 	
-	// Create a map to track directories and their files
-	dirMap := make(map[string][]string)
+	result := m.runResult()
+	return func() tea.Msg {
+		// A failed notification is shown as an error but does not fail the run
+		var errs []string
+		for _, notifier := range notifiers {
+			if err := notifier.Notify(context.Background(), result); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		return notificationMsg{err: strings.Join(errs, "; ")}
+	}
+}
+
+// runResult summarises the finished run for notifiers
+func (m Model) runResult() notification.RunResult {
+	failed := make(map[string]bool, len(m.failedFiles))
+	for _, file := range m.failedFiles {
+		failed[file.Path] = true
+	}
 	
-	// Organize files by directory
+	var files []notification.FileResult
 	for _, file := range m.files {
 		if file.IsDir {
 			continue
 		}
-		
-		// Get directory path
-		dir := filepath.Dir(file.Path)
-		relDir, err := filepath.Rel(m.inputDir, dir)
+		relPath, err := filepath.Rel(m.inputDir, file.Path)
 		if err != nil {
-			continue
+			relPath = file.Path
 		}
-		
-		if relDir == "." {
-			relDir = "Root"
+		status := notification.FileDocumented
+		if failed[file.Path] {
+			status = notification.FileFailed
 		}
-		
-		// Add file to directory map
-		dirMap[relDir] = append(dirMap[relDir], filepath.Base(file.Path))
+		files = append(files, notification.FileResult{Path: filepath.ToSlash(relPath), Status: status})
 	}
 	
-	// Add directories and files to documentation
-	structureDoc += "## Directory Structure\n\n"
-	for dir, files := range dirMap {
-		structureDoc += fmt.Sprintf("### %s\n\n", dir)
+	processed := m.processedFiles - len(m.failedFiles)
+	return notification.RunResult{
+		Status:         notification.RunStatus(processed, len(m.failedFiles)),
+		ProcessedCount: processed,
+		ErrorCount:     len(m.failedFiles),
+		OutputDir:      m.outputDir,
+		Duration:       m.processingElapsed(),
+		GeneratedAt:    time.Now(),
+		Files:          files,
+	}
+}
+
+
+// generateReadme writes README.md from the generated structure, setup and file documentation
+func (m Model) generateReadme() tea.Cmd {
+	projectDocs := m.projectDocs()
+	return func() tea.Msg {
+		readmePath, err := projectDocs.WriteReadme(m.ctx)
+		if err != nil {
+			return readmeMsg{err: fmt.Sprintf("Failed to write README: %s", err)}
+		}
+		return readmeMsg{path: readmePath}
+	}
+}
+
+// generateProjectOverview writes PROJECT_OVERVIEW.md, asking the API how the project fits together
+func (m Model) generateProjectOverview() tea.Cmd {
+	projectDocs := m.projectDocs()
+	return func() tea.Msg {
+		overviewPath, err := projectDocs.WriteOverview(m.ctx)
+		if err != nil {
+			return overviewMsg{err: fmt.Sprintf("Failed to write project overview: %s", err)}
+		}
+		return overviewMsg{path: overviewPath}
+	}
+}
+
+// generateArchitecture writes ARCHITECTURE.md from the directory tree and the first sentence
+// of each file's documentation
+func (m Model) generateArchitecture() tea.Cmd {
+	projectDocs := m.projectDocs()
+	return func() tea.Msg {
+		architecturePath, err := projectDocs.WriteArchitecture(m.ctx)
+		if err != nil {
+			return architectureMsg{err: fmt.Sprintf("Failed to write architecture documentation: %s", err)}
+		}
+		return architectureMsg{path: architecturePath}
+	}
+}
+
+// generateGlossary writes GLOSSARY.md defining the terms used most in the file documentation
+func (m Model) generateGlossary() tea.Cmd {
+	projectDocs := m.projectDocs()
+	return func() tea.Msg {
+		glossaryPath, err := projectDocs.WriteGlossary(m.ctx)
+		if err != nil {
+			return glossaryMsg{err: fmt.Sprintf("Failed to write glossary: %s", err)}
+		}
+		return glossaryMsg{path: glossaryPath}
+	}
+}
+
+// writeIndexIfReady returns a command writing INDEX.md and the search index once every Markdown
+// file has been written, followed by the HTML pages if the HTML output format is selected. Cross references
+// between the files are added first if enabled.
+func (m *Model) writeIndexIfReady() tea.Cmd {
+	if m.setupPending || m.readmePending || m.overviewPending || m.architecturePending || m.glossaryPending || m.indexPending {
+		return nil
+	}
+	if m.state != StateDone {
+		return nil
+	}
+	
+	m.indexPending = true
+	outputDir := m.outputDir
+	projectName := filepath.Base(m.inputDir)
+	html := m.config.OutputFormat == config.OutputFormatHTML
+	crossReferences := m.config.CrossReferenceLinks
+	cfg := m.config
+	files := m.files
+	inputDir := m.inputDir
+	stats := m.coverageStats()
+	mkdocs := m.config.GenerateMkdocsConfig
+	siteName := m.projectName
+	if siteName == "" {
+		siteName = projectName
+	}
+	var symbolFiles []filehandler.FileInfo // Files whose symbols are linked, relative to the input directory
+	if m.config.InjectCrossReferences {
+		for _, file := range m.files {
+			if relPath, err := filepath.Rel(m.inputDir, file.Path); err == nil && !file.IsDir {
+				symbolFiles = append(symbolFiles, filehandler.FileInfo{Path: filepath.ToSlash(relPath)})
+			}
+		}
+	}
+	return func() tea.Msg {
+		if crossReferences {
+			if err := linker.AddCrossReferences(outputDir); err != nil {
+				return indexMsg{err: fmt.Sprintf("Failed to add cross references: %s", err)}
+			}
+		}
+		if symbolFiles != nil {
+			docPath := func(relPath string) string { return config.OutputRelPath(cfg, relPath) }
+			if err := docs.LinkCrossReferences(outputDir, symbolFiles, docPath); err != nil {
+				return indexMsg{err: fmt.Sprintf("Failed to link symbols: %s", err)}
+			}
+		}
+		
+		docPath := func(relPath string) string { return config.OutputRelPath(cfg, relPath) }
+		stats.Scores = linter.ScoreFiles(files, inputDir, outputDir, docPath)
+		if err := docs.GenerateCoverageReport(outputDir, stats); err != nil {
+			return indexMsg{err: fmt.Sprintf("Failed to write %s: %s", docs.CoverageFileName, err)}
+		}
+		msg := indexMsg{
+			coveragePath:    filepath.Join(outputDir, docs.CoverageFileName),
+			coveragePercent: stats.Percentage(),
+			reviewCount:     len(linter.BelowMinimum(stats.Scores, cfg.MinDocScore)),
+		}
+		var err error
 		
-		// Add files in the directory
-		if len(files) > 0 {
-			structureDoc += "Files:\n"
-			for _, file := range files {
-				structureDoc += fmt.Sprintf("- `%s`\n", file)
+		if msg.indexPath, err = docs.GenerateIndex(outputDir); err != nil {
+			msg.err = fmt.Sprintf("Failed to write %s: %s", docs.IndexFileName, err)
+			return msg
+		}
+		if _, err := search.WriteIndex(outputDir); err != nil {
+			msg.err = fmt.Sprintf("Failed to write %s: %s", search.IndexFileName, err)
+			return msg
+		}
+		if mkdocs {
+			if err := docs.GenerateMkdocsConfig(outputDir, siteName); err != nil {
+				msg.err = fmt.Sprintf("Failed to write %s: %s", docs.MkdocsFileName, err)
+				return msg
 			}
-			structureDoc += "\n"
 		}
+		if !html {
+			return msg
+		}
+		
+		if msg.htmlPath, err = docs.WriteHTMLSite(outputDir, projectName); err != nil {
+			msg.err = fmt.Sprintf("Failed to write HTML documentation: %s", err)
+		}
+		return msg
 	}
-	
-	// Write project structure documentation
-	structureFilePath := filepath.Join(m.outputDir, "PROJECT_STRUCTURE.md")
-	os.WriteFile(structureFilePath, []byte(structureDoc), 0644)
-	
-	// 2. Generate setup documentation
-	setupDoc := "# Project Setup\n\n"
-	setupDoc += "This document provides information on how to set up and run this project.\n\n"
-	
-	// Look for common setup files
-	setupFiles := []string{
-		"package.json", "go.mod", "requirements.txt", "Gemfile", 
-		"pom.xml", "build.gradle", "Makefile", "pubspec.yaml",
-		"composer.json", "setup.py", "CMakeLists.txt",
+}
+
+// uploadOutput returns a command uploading the output directory to the output bucket, if one
+// is configured. Retrying a single failed file uploads again so the bucket stays in sync.
+func (m *Model) uploadOutput() tea.Cmd {
+	if m.config.OutputBucket == "" {
+		return nil
 	}
 	
-	// Section for dependencies
-	setupDoc += "## Dependencies\n\n"
-	
-	// Find and document setup files
-	foundSetupFiles := false
-	for _, file := range m.files {
-		fileName := filepath.Base(file.Path)
-		for _, setupFileName := range setupFiles {
-			if fileName == setupFileName {
-				foundSetupFiles = true
-				setupDoc += fmt.Sprintf("### %s\n\n", fileName)
-				setupDoc += "```\n"
-				// Limit content size to avoid overly large documents
-				content := file.Content
-				if len(content) > 2000 {
-					content = content[:2000] + "\n... (content truncated)"
-				}
-				setupDoc += content + "\n"
-				setupDoc += "```\n\n"
-			}
-		}
-	}
-	
-	if !foundSetupFiles {
-		setupDoc += "No standard setup files found in the project.\n\n"
-	}
-	
-	// Add installation and running instructions
-	setupDoc += "## Installation\n\n"
-	setupDoc += "Please follow these steps to install and set up the project:\n\n"
-	setupDoc += "1. Clone the repository\n"
-	setupDoc += "2. Install dependencies\n"
-	
-	// Add project type specific instructions
-	switch m.projectType {
-	case filehandler.ProjectTypeNode, filehandler.ProjectTypeReact:
-		setupDoc += "   ```\n   npm install\n   ```\n"
-	case filehandler.ProjectTypeGo:
-		setupDoc += "   ```\n   go mod download\n   ```\n"
-	case filehandler.ProjectTypePython, filehandler.ProjectTypeDjango:
-		setupDoc += "   ```\n   pip install -r requirements.txt\n   ```\n"
-	case filehandler.ProjectTypeRuby, filehandler.ProjectTypeRails:
-		setupDoc += "   ```\n   bundle install\n   ```\n"
-	case filehandler.ProjectTypeJava:
-		setupDoc += "   ```\n   mvn install\n   ```\n"
-	case filehandler.ProjectTypeFlutter:
-		setupDoc += "   ```\n   flutter pub get\n   ```\n"
-	}
-	
-	setupDoc += "\n## Running the Project\n\n"
-	setupDoc += "Specific instructions for running this project will depend on its configuration.\n"
-	
-	// Write setup documentation
-	setupFilePath := filepath.Join(m.outputDir, "PROJECT_SETUP.md")
-	os.WriteFile(setupFilePath, []byte(setupDoc), 0644)
-}
\ No newline at end of file
+	m.uploadPending = true
+	cfg := m.config
+	outputDir := m.outputDir
+	return func() tea.Msg {
+		uploader, err := s3.NewS3Uploader(cfg)
+		if err != nil {
+			return uploadMsg{err: fmt.Sprintf("Failed to upload the documentation: %s", err)}
+		}
+		if _, err := output.SyncDir(uploader, outputDir); err != nil {
+			return uploadMsg{err: fmt.Sprintf("Failed to upload the documentation: %s", err)}
+		}
+		return uploadMsg{uri: uploader.URI()}
+	}
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sentinel errors matching an *APIError of the corresponding kind with errors.Is
+var (
+	ErrRateLimit      = errors.New("rate limit exceeded")
+	ErrInvalidKey     = errors.New("invalid API key")
+	ErrNetworkError   = errors.New("network error")
+	ErrContextTooLong = errors.New("prompt exceeds the model's context length")
+)
+
+// contextTooLongMessages are fragments of the error bodies APIs return for prompts
+// exceeding the model's context window
+var contextTooLongMessages = []string{
+	"context_length_exceeded",
+	"maximum context length",
+	"context window",
+	"prompt is too long",
+	"too many tokens",
+	"input is too long",
+}
+
+// IsContextTooLongResponse reports whether an API response with the given status code and
+// body rejected the request for exceeding the model's context length
+func IsContextTooLongResponse(statusCode int, body string) bool {
+	if statusCode == 413 {
+		return true
+	}
+	if statusCode != 400 {
+		return false
+	}
+
+	body = strings.ToLower(body)
+	for _, message := range contextTooLongMessages {
+		if strings.Contains(body, message) {
+			return true
+		}
+	}
+	return false
+}
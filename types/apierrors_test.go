@@ -0,0 +1,74 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	sentinels := []error{ErrRateLimit, ErrInvalidKey, ErrNetworkError, ErrContextTooLong}
+	tests := []struct {
+		name string
+		err  *APIError
+		want error // The only sentinel matched
+	}{
+		{"rate limit", &APIError{StatusCode: 429, IsRateLimit: true}, ErrRateLimit},
+		{"invalid key", &APIError{StatusCode: 401, IsInvalidKey: true}, ErrInvalidKey},
+		{"network", &APIError{IsNetworkError: true, Cause: io.ErrUnexpectedEOF}, ErrNetworkError},
+		{"context too long", &APIError{StatusCode: 400, IsContextTooLong: true}, ErrContextTooLong},
+		{"other", &APIError{StatusCode: 500}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The kind survives wrapping, as errors pass through the generation pipeline
+			wrapped := fmt.Errorf("documenting main.go: %w", tt.err)
+			for _, sentinel := range sentinels {
+				if got := errors.Is(wrapped, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(%v) = %v", sentinel, got)
+				}
+			}
+
+			var apiErr *APIError
+			if !errors.As(wrapped, &apiErr) || apiErr != tt.err {
+				t.Fatalf("errors.As did not find the *APIError in %v", wrapped)
+			}
+			if apiErr.StatusCode != tt.err.StatusCode {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.err.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAPIErrorUnwrapsCause(t *testing.T) {
+	err := fmt.Errorf("request failed: %w", &APIError{Message: "API request failed", IsNetworkError: true, Cause: io.ErrUnexpectedEOF})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("errors.Is(%v, io.ErrUnexpectedEOF) = false, want the cause to be unwrapped", err)
+	}
+	if errors.Unwrap(&APIError{}) != nil {
+		t.Error("APIError without a cause unwraps to a non-nil error")
+	}
+}
+
+func TestIsContextTooLongResponse(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{413, "", true},
+		{400, `{"error": {"code": "context_length_exceeded"}}`, true},
+		{400, "This model's Maximum Context Length is 8192 tokens", true},
+		{400, `{"message": "Input is too long for requested model."}`, true},
+		{400, `{"error": "invalid JSON"}`, false},
+		{500, "prompt is too long", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsContextTooLongResponse(tt.status, tt.body); got != tt.want {
+			t.Errorf("IsContextTooLongResponse(%d, %q) = %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
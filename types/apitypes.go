@@ -1,3 +1,5 @@
+// Package types holds the API type definitions shared by the api, config and tui
+// packages. It is the single place these are declared; other packages import it directly.
 package types
 
 // APIType represents the type of API to use
@@ -10,6 +12,15 @@ const (
 	APITypeChatGPT APIType = "chatgpt"
 	// APITypeGemini represents the Google Gemini API
 	APITypeGemini APIType = "gemini"
+	// APITypeGroq represents the Groq API
+	APITypeGroq APIType = "groq"
+	// APITypeCustom represents a custom OpenAI-compatible API endpoint
+	APITypeCustom APIType = "custom"
+	// APITypeBedrock represents Claude and Titan models on AWS Bedrock
+	APITypeBedrock APIType = "bedrock"
+	// APITypeMock represents an offline mock API for tests and development.
+	// It is not offered in APITypes.
+	APITypeMock APIType = "mock"
 )
 
 // APITypes returns a list of all supported API types
@@ -18,6 +29,9 @@ func APITypes() []APIType {
 		APITypeDeepseek,
 		APITypeChatGPT,
 		APITypeGemini,
+		APITypeGroq,
+		APITypeCustom,
+		APITypeBedrock,
 	}
 }
 
@@ -26,9 +40,12 @@ var APIModelMap = map[APIType][]string{
 	APITypeDeepseek: {"deepseek-chat", "deepseek-coder"},
 	APITypeChatGPT:  {"gpt-3.5-turbo", "gpt-4", "gpt-4-turbo", "gpt-4o"},
 	APITypeGemini:   {"gemini-pro", "gemini-1.5-pro"},
+	APITypeGroq:     {"llama3-70b-8192", "llama3-8b-8192", "mixtral-8x7b-32768"},
+	APITypeBedrock:  {"anthropic.claude-3-sonnet-20240229-v1:0", "anthropic.claude-3-haiku-20240307-v1:0", "amazon.titan-text-express-v1"},
 }
 
-// APIError represents an error that occurred during an API call
+// APIError represents an error that occurred during an API call. It matches ErrRateLimit,
+// ErrInvalidKey, ErrNetworkError and ErrContextTooLong with errors.Is according to its kind.
 type APIError struct {
 	StatusCode     int
 	Message        string
@@ -36,9 +53,32 @@ type APIError struct {
 	IsInvalidKey   bool
 	IsNetworkError bool
 	RawResponse    string
+
+	IsContextTooLong bool  // The prompt exceeded the model's context length
+	Cause            error // Underlying error, such as the HTTP client's for network errors
 }
 
 // Error implements the error interface for APIError
 func (e *APIError) Error() string {
 	return e.Message
+}
+
+// Unwrap returns the underlying error
+func (e *APIError) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is the sentinel error for the kind of e
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrRateLimit:
+		return e.IsRateLimit
+	case ErrInvalidKey:
+		return e.IsInvalidKey
+	case ErrNetworkError:
+		return e.IsNetworkError
+	case ErrContextTooLong:
+		return e.IsContextTooLong
+	}
+	return false
 }
\ No newline at end of file